@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/api"
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/security"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+	"github.com/rgonzalez12/dbd-analytics/internal/store"
+)
+
+// Outcomes of a single check
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+type checkResult struct {
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	Detail   string   `json:"detail,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// warningCapture is a slog handler that keeps warnings and errors so each
+// check can report what the code it ran complained about, and drops the rest
+type warningCapture struct {
+	lines *capturedLines
+	attrs []slog.Attr
+}
+
+type capturedLines struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (c *warningCapture) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelWarn
+}
+
+func (c *warningCapture) Handle(_ context.Context, record slog.Record) error {
+	var parts []string
+	for _, attr := range c.attrs {
+		parts = append(parts, attr.String())
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		parts = append(parts, attr.String())
+		return true
+	})
+	line := record.Message
+	if len(parts) > 0 {
+		line += " (" + strings.Join(parts, " ") + ")"
+	}
+	c.lines.mu.Lock()
+	c.lines.lines = append(c.lines.lines, line)
+	c.lines.mu.Unlock()
+	return nil
+}
+
+func (c *warningCapture) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &warningCapture{lines: c.lines, attrs: append(append([]slog.Attr{}, c.attrs...), attrs...)}
+}
+
+func (c *warningCapture) WithGroup(string) slog.Handler {
+	return c
+}
+
+// drain returns and clears the captured lines
+func (c *warningCapture) drain() []string {
+	c.lines.mu.Lock()
+	defer c.lines.mu.Unlock()
+	lines := c.lines.lines
+	c.lines.lines = nil
+	return lines
+}
+
+// runCheck validates the configuration and the services it points at, prints
+// a report and returns the exit code: 0 when everything passed, 1 when any
+// check failed (or warned, with -strict), 2 on bad usage
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	offline := fs.Bool("offline", false, "skip checks that reach Steam or Redis")
+	strict := fs.Bool("strict", false, "fail on warnings, such as ignored invalid settings")
+	jsonOut := fs.Bool("json", false, "print the report as JSON")
+	timeout := fs.Duration("timeout", 10*time.Second, "limit for each network check")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s check [flags]\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	// Child loggers are captured too, so warnings from WithContext land here
+	capture := &warningCapture{lines: &capturedLines{}}
+	log.Logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
+	loadEnvironment()
+	log.Logger = slog.New(capture)
+	slog.SetDefault(log.Logger)
+
+	// Steam errors quote the request URL, key included; reports end up in CI logs
+	redact := func(text string) string { return text }
+	if key := os.Getenv("STEAM_API_KEY"); key != "" {
+		redact = strings.NewReplacer(key, "[REDACTED]").Replace
+	}
+
+	var results []checkResult
+	run := func(name string, check func() (string, string)) {
+		status, detail := check()
+		result := checkResult{Name: name, Status: status, Detail: redact(detail)}
+		for _, warning := range capture.drain() {
+			result.Warnings = append(result.Warnings, redact(warning))
+		}
+		if result.Status == checkOK && len(result.Warnings) > 0 {
+			result.Status = checkWarn
+		}
+		results = append(results, result)
+	}
+
+	var config api.APIConfig
+	run("environment", func() (string, string) {
+		if err := security.ValidateEnvironment(); err != nil {
+			return checkFail, err.Error()
+		}
+		return checkOK, ""
+	})
+	run("config", func() (string, string) {
+		config = api.LoadAllConfigFromEnv()
+		return checkOK, ""
+	})
+	run("steam_key", func() (string, string) {
+		switch {
+		case config.DemoMode:
+			return checkSkip, "demo mode serves bundled fixtures"
+		case *offline:
+			return checkSkip, "-offline"
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		client := steam.NewClient()
+		defer client.Close()
+		if apiErr := client.VerifyKey(ctx); apiErr != nil {
+			return checkFail, apiErr.Error()
+		}
+		return checkOK, "key accepted by Steam"
+	})
+	run("cache", checkCache)
+	run("redis", func() (string, string) {
+		invalidation := cache.GetInvalidationConfigFromEnv()
+		switch {
+		case invalidation.Backend != "redis":
+			return checkSkip, "CACHE_INVALIDATION_BACKEND is not redis"
+		case *offline:
+			return checkSkip, "-offline"
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		bus := cache.NewRedisInvalidationBus(invalidation.RedisAddr, invalidation.RedisPassword, invalidation.Channel, cache.DefaultConfig().Redis)
+		if err := bus.Ping(ctx); err != nil {
+			return checkFail, err.Error()
+		}
+		return checkOK, invalidation.RedisAddr
+	})
+	run("shared_counters", func() (string, string) {
+		config := cache.GetCounterConfigFromEnv()
+		counters, err := cache.NewCounterStore(config)
+		switch {
+		case err != nil:
+			return checkFail, err.Error()
+		case counters == nil:
+			return checkSkip, "SHARED_COUNTERS_BACKEND is not set"
+		case *offline:
+			return checkSkip, "-offline"
+		}
+		defer counters.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		if err := counters.Ping(ctx); err != nil {
+			return checkFail, err.Error()
+		}
+		return checkOK, config.RedisAddr
+	})
+	run("player_store", func() (string, string) {
+		return checkStoreFile("PLAYER_STORE_PATH", func(path string) error {
+			players, err := store.NewPlayers(path)
+			if err != nil {
+				return err
+			}
+			return players.Close()
+		})
+	})
+	run("snapshot_store", func() (string, string) {
+		dir := os.Getenv("SNAPSHOT_STORE_DIR")
+		if dir == "" {
+			return checkSkip, "SNAPSHOT_STORE_DIR not set"
+		}
+		if _, err := store.NewSnapshots(dir); err != nil {
+			return checkFail, err.Error()
+		}
+		if err := checkWritable(dir); err != nil {
+			return checkFail, err.Error()
+		}
+		return checkOK, dir
+	})
+	run("schema_store", func() (string, string) {
+		// Only a fallback for Steam outages, so a problem here is a warning
+		dir := os.Getenv("SCHEMA_STORE_DIR")
+		if dir == "" {
+			return checkSkip, "SCHEMA_STORE_DIR not set"
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return checkWarn, err.Error()
+		}
+		if err := checkWritable(dir); err != nil {
+			return checkWarn, err.Error()
+		}
+		return checkOK, dir
+	})
+	run("rarity_history", func() (string, string) {
+		return checkStoreFile("RARITY_HISTORY_PATH", func(path string) error {
+			_, err := store.NewRarityHistory(path, 0)
+			return err
+		})
+	})
+	run("profile_store", func() (string, string) {
+		return checkStoreFile("PROFILE_STORE_PATH", func(path string) error {
+			_, err := store.NewProfiles(path, 0, 0)
+			return err
+		})
+	})
+
+	failed, warned := 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case checkFail:
+			failed++
+		case checkWarn:
+			warned++
+		}
+	}
+	passed := failed == 0 && (!*strict || warned == 0)
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(map[string]interface{}{"passed": passed, "checks": results})
+	} else {
+		for _, result := range results {
+			fmt.Printf("%-5s %-15s %s\n", result.Status, result.Name, result.Detail)
+			for _, warning := range result.Warnings {
+				fmt.Printf("      %-15s %s\n", "", warning)
+			}
+		}
+		verdict := "passed"
+		if !passed {
+			verdict = "FAILED"
+		}
+		fmt.Printf("check %s: %d failed, %d warned\n", verdict, failed, warned)
+	}
+
+	if !passed {
+		return 1
+	}
+	return 0
+}
+
+// checkCache builds the cache the server would and round-trips a value
+func checkCache() (string, string) {
+	manager, err := cache.NewManager(cache.PlayerStatsConfig())
+	if err != nil {
+		return checkFail, err.Error()
+	}
+	defer manager.Close()
+
+	key := cache.GenerateKey("check", fmt.Sprint(time.Now().UnixNano()))
+	c := manager.GetCache()
+	if err := c.Set(key, "ok", time.Minute); err != nil {
+		return checkFail, fmt.Sprintf("write failed: %v", err)
+	}
+	if value, found := c.Get(key); !found || value != "ok" {
+		return checkFail, "a value written to the cache could not be read back"
+	}
+	c.Delete(key)
+	return checkOK, string(manager.GetConfig().Type)
+}
+
+// checkStoreFile opens the store file named by env and checks its directory
+// is writable; an unset variable keeps the store in memory, which is fine
+func checkStoreFile(env string, open func(path string) error) (string, string) {
+	path := os.Getenv(env)
+	if path == "" {
+		return checkSkip, env + " not set; kept in memory"
+	}
+	if err := open(path); err != nil {
+		return checkFail, err.Error()
+	}
+	if err := checkWritable(filepath.Dir(path)); err != nil {
+		return checkFail, err.Error()
+	}
+	return checkOK, path
+}
+
+// checkWritable creates and removes a file in dir the way the stores'
+// atomic writes do
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".check-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
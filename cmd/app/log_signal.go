@@ -0,0 +1,24 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// watchLogLevelSignal toggles debug logging on SIGUSR1, for hosts where the
+// admin API isn't reachable: `kill -USR1 <pid>` once to enable, again to restore
+func watchLogLevelSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	go func() {
+		for range signals {
+			level := log.ToggleDebug()
+			log.Info("Log level toggled by SIGUSR1", "level", level.String())
+		}
+	}()
+}
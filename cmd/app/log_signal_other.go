@@ -0,0 +1,7 @@
+//go:build !unix
+
+package main
+
+// watchLogLevelSignal is a no-op where SIGUSR1 doesn't exist; use
+// PUT /api/admin/log-level instead
+func watchLogLevelSignal() {}
@@ -1,39 +1,89 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
-	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
-	"github.com/rgonzalez12/dbd-analytics/internal/api"
+	"github.com/rgonzalez12/dbd-analytics/internal/buildinfo"
 	"github.com/rgonzalez12/dbd-analytics/internal/log"
-	"github.com/rgonzalez12/dbd-analytics/internal/security"
+	"github.com/rgonzalez12/dbd-analytics/internal/profiling"
+	"github.com/rgonzalez12/dbd-analytics/server"
 )
 
 func main() {
 	log.Initialize()
 
+	// `app check` validates the configuration and exits instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		os.Exit(runCheck(os.Args[2:]))
+	}
+
 	// Load environment variables first
 	loadEnvironment()
 
-	// Validate security configuration on startup
-	if err := security.ValidateEnvironment(); err != nil {
+	build := buildinfo.Get()
+	log.Info("Starting dbd-analytics",
+		"version", build.Version,
+		"commit", build.Commit,
+		"build_time", build.BuildTime,
+		"modified", build.Modified,
+		"go_version", build.GoVersion,
+		"started_at", buildinfo.StartTime().UTC().Format(time.RFC3339Nano))
+
+	// Validates the environment before anything starts listening
+	srv, err := server.New(server.Config{Explorer: true, DevCORS: true})
+	if err != nil {
 		log.Error("Security validation failed", "error", err.Error())
 		os.Exit(1)
 	}
 
+	// pprof and runtime stats stay off the public router on their own listener
+	if _, err := profiling.Start(profiling.FromEnv()); err != nil {
+		log.Error("Profiling server failed to start", "error", err.Error())
+		os.Exit(1)
+	}
+
+	watchLogLevelSignal()
+
 	port := getPort()
-	r := setupRouter()
+	httpServer := &http.Server{Addr: port, Handler: srv}
 
 	fmt.Printf("🚀 Server running on http://localhost%s\n", port)
-	fmt.Printf("💡 Try: http://localhost%s/api/player/[steam_id]\n", port)
-
-	if err := http.ListenAndServe(port, r); err != nil {
-		log.Error("Server failed", "error", err.Error())
-		os.Exit(1)
+	fmt.Printf("💡 Explore: http://localhost%s/ or http://localhost%s/api/player/[steam_id]\n", port, port)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Server failed", "error", err.Error())
+			os.Exit(1)
+		}
+	}()
+	<-ctx.Done()
+	stop() // a second signal kills the process outright
+
+	// Drain first so requests still in flight read the cache without their
+	// writes failing, then wait for them before anything is closed
+	timeout := getShutdownTimeout()
+	log.Info("Shutting down; waiting for in-flight requests", "timeout", timeout)
+	srv.Drain()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Warn("In-flight requests did not finish before the shutdown timeout", "error", err.Error())
 	}
+	if err := srv.Close(); err != nil {
+		log.Warn("Handler close failed", "error", err.Error())
+	}
+	log.Info("Shutdown complete")
 }
 
 func loadEnvironment() {
@@ -58,33 +108,10 @@ func getPort() string {
 	return port
 }
 
-func setupRouter() *mux.Router {
-	r := mux.NewRouter()
-
-	// Basic CORS middleware for development
-	r.Use(func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-			if req.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
-			}
-
-			next.ServeHTTP(w, req)
-		})
-	})
-
-	// Home route
-	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintln(w, "🎮 DBD Analytics API - TypeScript client test ready!")
-	}).Methods("GET")
-
-	// Register API routes with proper routing
-	apiRouter := r.PathPrefix("/api").Subrouter()
-	api.RegisterRoutes(apiRouter)
-
-	return r
+// getShutdownTimeout is how long in-flight requests get to finish on shutdown
+func getShutdownTimeout() time.Duration {
+	if secs, err := strconv.Atoi(os.Getenv("SHUTDOWN_TIMEOUT_SECS")); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return 30 * time.Second
 }
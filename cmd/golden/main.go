@@ -0,0 +1,245 @@
+// Command golden replays recorded Steam payloads through the stat and
+// achievement mapping pipeline and compares the result against golden JSON,
+// so the mapping tables can be refactored without silent output changes.
+//
+//	go run ./cmd/golden            # verify every case
+//	go run ./cmd/golden -update    # rewrite golden files after an intended change
+//
+// Each case is a directory holding the raw Steam responses it needs:
+// schema.json, user_stats.json, achievements.json and percentages.json. A
+// missing payload is answered with 404, which exercises the fallback paths.
+// Every case is also round-tripped through MessagePack and must match its JSON.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/msgpack"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+const goldenFile = "expected.golden.json"
+
+// payloadFiles maps Steam endpoints to the recorded payload that answers them
+var payloadFiles = map[string]string{
+	"/ISteamUserStats/GetSchemaForGame/v2/":                         "schema.json",
+	"/ISteamUserStats/GetUserStatsForGame/v2/":                      "user_stats.json",
+	"/ISteamUserStats/GetPlayerAchievements/v0001/":                 "achievements.json",
+	"/ISteamUserStats/GetGlobalAchievementPercentagesForApp/v0002/": "percentages.json",
+}
+
+// goldenOutput is everything the pipeline produces for one player
+type goldenOutput struct {
+	Stats        *steam.PlayerStatsResponse `json:"stats"`
+	Achievements goldenAchievements         `json:"achievements"`
+}
+
+type goldenAchievements struct {
+	Mapped  []steam.AchievementMapping `json:"mapped"`
+	Summary map[string]interface{}     `json:"summary"`
+	Unknown []string                   `json:"unknown"` // API names only; first-seen times vary per run
+}
+
+func main() {
+	dir := flag.String("dir", filepath.Join("internal", "steam", "testdata", "golden"), "directory of golden cases")
+	update := flag.Bool("update", false, "rewrite golden files from the current pipeline output")
+	run := flag.String("run", "", "only run cases whose name contains this substring")
+	flag.Parse()
+
+	// Keep the report readable; LOG_LEVEL=debug still shows pipeline logs
+	if os.Getenv("LOG_LEVEL") == "" {
+		os.Setenv("LOG_LEVEL", "error")
+	}
+	log.Initialize()
+
+	entries, err := os.ReadDir(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read cases: %v\n", err)
+		os.Exit(2)
+	}
+
+	failed, ran := 0, 0
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.Contains(entry.Name(), *run) {
+			continue
+		}
+		ran++
+		caseDir := filepath.Join(*dir, entry.Name())
+
+		ok, err := runCase(caseDir, *update)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("FAIL %s: %v\n", entry.Name(), err)
+		case !ok:
+			failed++
+		case *update:
+			fmt.Printf("updated %s\n", entry.Name())
+		default:
+			fmt.Printf("ok   %s\n", entry.Name())
+		}
+	}
+
+	if ran == 0 {
+		fmt.Fprintln(os.Stderr, "no golden cases matched")
+		os.Exit(2)
+	}
+	if failed > 0 {
+		fmt.Printf("%d of %d golden cases failed; rerun with -update if the change is intended\n", failed, ran)
+		os.Exit(1)
+	}
+}
+
+func runCase(caseDir string, update bool) (bool, error) {
+	server := httptest.NewServer(payloadHandler(caseDir))
+	defer server.Close()
+
+	// The pipeline builds its clients from the environment
+	os.Setenv("STEAM_API_KEY", "golden")
+	os.Setenv("STEAM_API_BASE_URLS", server.URL)
+	// A schema persisted by an earlier case must not stand in for a missing one
+	os.Setenv("SCHEMA_STORE_DIR", "")
+
+	actual, err := renderCase()
+	if err != nil {
+		return false, err
+	}
+	if err := checkMsgpackParity(actual); err != nil {
+		return false, err
+	}
+
+	path := filepath.Join(caseDir, goldenFile)
+	if update {
+		return true, os.WriteFile(path, actual, 0o644)
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("missing golden file (run with -update to create it): %w", err)
+	}
+	if bytes.Equal(expected, actual) {
+		return true, nil
+	}
+
+	line, want, got := firstDifference(expected, actual)
+	fmt.Printf("FAIL %s: output differs from %s at line %d\n  want: %s\n  got:  %s\n",
+		filepath.Base(caseDir), goldenFile, line, want, got)
+	return false, nil
+}
+
+// renderCase runs the same mapping calls the API handlers make, with no cache
+func renderCase() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client := steam.NewClient()
+	defer client.Close()
+
+	// The steam ID only has to be well-formed; payloads are served per case
+	const steamID = "76561198000000042"
+
+	stats, err := steam.MapPlayerStats(ctx, steamID, nil, client)
+	if err != nil {
+		return nil, fmt.Errorf("MapPlayerStats: %w", err)
+	}
+
+	raw, apiErr := client.GetPlayerAchievementsContext(ctx, steamID, 381210)
+	if apiErr != nil {
+		return nil, fmt.Errorf("GetPlayerAchievements: %s", apiErr.Message)
+	}
+
+	mapper := steam.NewAchievementMapper(client)
+	mapped := mapper.MapPlayerAchievements(raw)
+
+	unknown := []string{}
+	for _, u := range mapper.GetUnknownAchievements() {
+		unknown = append(unknown, u.APIName)
+	}
+	sort.Strings(unknown)
+
+	out, err := json.MarshalIndent(goldenOutput{
+		Stats: stats,
+		Achievements: goldenAchievements{
+			Mapped:  mapped,
+			Summary: mapper.GetAchievementSummary(mapped),
+			Unknown: unknown,
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+func payloadHandler(caseDir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, known := payloadFiles[r.URL.Path]
+		if !known {
+			http.Error(w, "no recorded payload for "+r.URL.Path, http.StatusNotFound)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join(caseDir, name))
+		if err != nil {
+			http.Error(w, name+" not recorded for this case", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+// checkMsgpackParity round-trips the rendered JSON through MessagePack, as
+// Accept: application/msgpack responses do, and requires it to come back unchanged
+func checkMsgpackParity(rendered []byte) error {
+	encoded, err := msgpack.FromJSON(rendered)
+	if err != nil {
+		return fmt.Errorf("msgpack encode: %w", err)
+	}
+	decoded, err := msgpack.ToJSON(encoded)
+	if err != nil {
+		return fmt.Errorf("msgpack decode: %w", err)
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, rendered); err != nil {
+		return err
+	}
+	if !bytes.Equal(compact.Bytes(), decoded) {
+		line, want, got := firstDifference(indent(compact.Bytes()), indent(decoded))
+		return fmt.Errorf("msgpack output differs from JSON at line %d\n  want: %s\n  got:  %s", line, want, got)
+	}
+	return nil
+}
+
+func indent(compact []byte) []byte {
+	var out bytes.Buffer
+	json.Indent(&out, compact, "", "  ")
+	return out.Bytes()
+}
+
+func firstDifference(expected, actual []byte) (int, string, string) {
+	want := bufio.NewScanner(bytes.NewReader(expected))
+	got := bufio.NewScanner(bytes.NewReader(actual))
+	for line := 1; ; line++ {
+		hasWant, hasGot := want.Scan(), got.Scan()
+		if !hasWant && !hasGot {
+			return line, "", ""
+		}
+		if want.Text() != got.Text() || hasWant != hasGot {
+			return line, strings.TrimSpace(want.Text()), strings.TrimSpace(got.Text())
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGolden runs every golden case under go test, so a mapping change that
+// alters output fails the build until the goldens are regenerated with
+// go run ./cmd/golden -update
+func TestGolden(t *testing.T) {
+	dir := filepath.Join("..", "..", "internal", "steam", "testdata", "golden")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read cases: %v", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		t.Run(entry.Name(), func(t *testing.T) {
+			ok, err := runCase(filepath.Join(dir, entry.Name()), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Fatal("output differs from " + goldenFile + "; rerun go run ./cmd/golden -update if the change is intended")
+			}
+		})
+	}
+}
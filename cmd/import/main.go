@@ -0,0 +1,293 @@
+// Command import loads exported player snapshots (NDJSON or CSV) into the
+// player store so a new instance can be seeded or migrated from another one.
+//
+//	go run ./cmd/import -store ./data/players.json snapshots.ndjson export.csv
+//
+// Stop the API server first: it keeps the store in memory and would overwrite
+// the imported file on its next flush.
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+	"github.com/rgonzalez12/dbd-analytics/internal/store"
+)
+
+// snapshot accepts both store records and exported player API responses
+type snapshot struct {
+	SteamID       string    `json:"steam_id"`
+	PersonaName   string    `json:"persona_name"`
+	DisplayName   string    `json:"display_name"`
+	Avatar        string    `json:"avatar"`
+	PreviousNames []string  `json:"previous_names"`
+	FirstSeen     time.Time `json:"first_seen"`
+	LastSeen      time.Time `json:"last_seen"`
+	LastUpdated   time.Time `json:"last_updated"`
+}
+
+type report struct {
+	read       int
+	invalid    int
+	duplicates int
+	added      int
+	updated    int
+	unchanged  int
+}
+
+func main() {
+	_ = godotenv.Load()
+	log.Initialize()
+
+	storePath := flag.String("store", os.Getenv("PLAYER_STORE_PATH"), "player store file to import into (default $PLAYER_STORE_PATH)")
+	format := flag.String("format", "auto", "input format: auto, ndjson or csv")
+	dryRun := flag.Bool("dry-run", false, "validate and report without writing the store")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] FILE... (use - for stdin)\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *storePath == "" && !*dryRun {
+		fmt.Fprintln(os.Stderr, "a store path is required: pass -store or set PLAYER_STORE_PATH")
+		os.Exit(2)
+	}
+
+	target := *storePath
+	if *dryRun {
+		target = ""
+	}
+	players, err := store.NewPlayers(target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open store: %v\n", err)
+		os.Exit(1)
+	}
+
+	var total report
+	seen := make(map[string]time.Time) // steam ID -> newest last_seen in this import
+	for _, path := range flag.Args() {
+		r, err := importFile(players, path, *format, seen)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s: read=%d invalid=%d duplicates=%d added=%d updated=%d unchanged=%d\n",
+			path, r.read, r.invalid, r.duplicates, r.added, r.updated, r.unchanged)
+		total.read += r.read
+		total.invalid += r.invalid
+		total.duplicates += r.duplicates
+		total.added += r.added
+		total.updated += r.updated
+		total.unchanged += r.unchanged
+	}
+
+	if err := players.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write store: %v\n", err)
+		os.Exit(1)
+	}
+
+	mode := "imported"
+	if *dryRun {
+		mode = "dry run"
+	}
+	fmt.Printf("%s: read=%d invalid=%d duplicates=%d added=%d updated=%d unchanged=%d players=%d\n",
+		mode, total.read, total.invalid, total.duplicates, total.added, total.updated, total.unchanged, players.Count())
+}
+
+func importFile(players *store.Players, path, format string, seen map[string]time.Time) (report, error) {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return report{}, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	if format == "auto" {
+		format = "ndjson"
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			format = "csv"
+		}
+	}
+
+	var r report
+	apply := func(line int, snap snapshot) {
+		r.read++
+		record, err := toRecord(snap)
+		if err != nil {
+			r.invalid++
+			log.Warn("Skipping invalid snapshot", "file", path, "line", line, "error", err)
+			return
+		}
+
+		// Identical snapshots (same player, same moment) are counted once
+		if last, ok := seen[record.SteamID]; ok && last.Equal(record.LastSeen) {
+			r.duplicates++
+			return
+		}
+		if last, ok := seen[record.SteamID]; !ok || record.LastSeen.After(last) {
+			seen[record.SteamID] = record.LastSeen
+		}
+
+		switch players.Merge(record) {
+		case store.MergeAdded:
+			r.added++
+		case store.MergeUpdated:
+			r.updated++
+		default:
+			r.unchanged++
+		}
+	}
+
+	switch format {
+	case "ndjson":
+		return r, readNDJSON(in, apply, &r)
+	case "csv":
+		return r, readCSV(in, apply, &r)
+	}
+	return r, fmt.Errorf("unknown format %q (want ndjson or csv)", format)
+}
+
+func readNDJSON(in io.Reader, apply func(int, snapshot), r *report) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024) // full player exports can be large
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var snap snapshot
+		if err := json.Unmarshal([]byte(text), &snap); err != nil {
+			r.read++
+			r.invalid++
+			log.Warn("Skipping malformed NDJSON line", "line", line, "error", err)
+			continue
+		}
+		apply(line, snap)
+	}
+	return scanner.Err()
+}
+
+func readCSV(in io.Reader, apply func(int, snapshot), r *report) error {
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["steam_id"]; !ok {
+		return errors.New("CSV header must include a steam_id column")
+	}
+
+	get := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	line := 1
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		line++
+		if err != nil {
+			r.read++
+			r.invalid++
+			log.Warn("Skipping malformed CSV row", "line", line, "error", err)
+			continue
+		}
+
+		snap := snapshot{
+			SteamID:     get(row, "steam_id"),
+			PersonaName: get(row, "persona_name"),
+			DisplayName: get(row, "display_name"),
+			Avatar:      get(row, "avatar"),
+		}
+		if names := get(row, "previous_names"); names != "" {
+			snap.PreviousNames = strings.Split(names, "|")
+		}
+		var parseErr error
+		for name, dst := range map[string]*time.Time{"first_seen": &snap.FirstSeen, "last_seen": &snap.LastSeen, "last_updated": &snap.LastUpdated} {
+			if value := get(row, name); value != "" {
+				if *dst, err = time.Parse(time.RFC3339, value); err != nil {
+					parseErr = fmt.Errorf("%s: %w", name, err)
+				}
+			}
+		}
+		if parseErr != nil {
+			r.read++
+			r.invalid++
+			log.Warn("Skipping CSV row with bad timestamp", "line", line, "error", parseErr)
+			continue
+		}
+		apply(line, snap)
+	}
+}
+
+// toRecord validates a snapshot and normalises it into a store record
+func toRecord(snap snapshot) (store.PlayerRecord, error) {
+	steamID := strings.TrimSpace(snap.SteamID)
+	if !steam.IsSteamID64(steamID) {
+		return store.PlayerRecord{}, fmt.Errorf("invalid steam_id %q", snap.SteamID)
+	}
+
+	name := strings.TrimSpace(snap.PersonaName)
+	if name == "" {
+		name = strings.TrimSpace(snap.DisplayName)
+	}
+	if name == "" {
+		return store.PlayerRecord{}, errors.New("missing persona_name/display_name")
+	}
+	if len([]rune(name)) > 64 {
+		return store.PlayerRecord{}, fmt.Errorf("persona name longer than 64 characters")
+	}
+
+	lastSeen := snap.LastSeen
+	if lastSeen.IsZero() {
+		lastSeen = snap.LastUpdated
+	}
+	if lastSeen.IsZero() {
+		return store.PlayerRecord{}, errors.New("missing last_seen/last_updated timestamp")
+	}
+	if lastSeen.After(time.Now().Add(24 * time.Hour)) {
+		return store.PlayerRecord{}, fmt.Errorf("last_seen %s is in the future", lastSeen.Format(time.RFC3339))
+	}
+
+	return store.PlayerRecord{
+		SteamID:       steamID,
+		PersonaName:   name,
+		Avatar:        strings.TrimSpace(snap.Avatar),
+		PreviousNames: snap.PreviousNames,
+		FirstSeen:     snap.FirstSeen.UTC(),
+		LastSeen:      lastSeen.UTC(),
+	}, nil
+}
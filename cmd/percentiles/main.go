@@ -0,0 +1,329 @@
+// Command percentiles crawls a sample of public profiles and writes the
+// community baseline file (see internal/percentiles) of per-stat value
+// distributions. Rerun it periodically to refresh the baselines.
+//
+//	go run ./cmd/percentiles -store ./data/players.json -sample 1000 -out ./data/percentiles.json
+//	go run ./cmd/percentiles -sample 500 seeds.txt   # one Steam ID64 per line
+//
+// Progress is checkpointed to a state file (default <out>.state), so an
+// interrupted crawl resumes where it stopped when rerun with the same flags.
+// The sample is picked with a seeded shuffle and the output only depends on
+// the sampled values and -date, so reruns from the same state are identical.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/percentiles"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+const (
+	checkpointEvery = 25
+	fetchTimeout    = 30 * time.Second
+)
+
+// crawlState is the resumable checkpoint. Done maps each crawled Steam ID to
+// its stat values, or null when the profile's stats are private.
+type crawlState struct {
+	Seed       int64                         `json:"seed"`
+	Source     string                        `json:"source"`
+	Candidates []string                      `json:"candidates"`
+	Done       map[string]map[string]float64 `json:"done"`
+}
+
+func main() {
+	_ = godotenv.Load()
+	log.Initialize()
+
+	storePath := flag.String("store", os.Getenv("PLAYER_STORE_PATH"), "player store to sample Steam IDs from when no seed files are given (default $PLAYER_STORE_PATH)")
+	out := flag.String("out", "data/percentiles.json", "distribution file to write")
+	statePath := flag.String("state", "", "checkpoint file (default <out>.state)")
+	sample := flag.Int("sample", 500, "number of profiles to crawl")
+	seed := flag.Int64("seed", 1, "shuffle seed used to pick the sample")
+	rate := flag.Float64("rate", 1, "Steam requests per second")
+	date := flag.String("date", time.Now().UTC().Format("2006-01-02"), "generated_on date recorded in the output")
+	restart := flag.Bool("restart", false, "discard an existing checkpoint instead of resuming it")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] [SEEDFILE...]\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *sample <= 0 || *rate <= 0 {
+		fmt.Fprintln(os.Stderr, "-sample and -rate must be positive")
+		os.Exit(2)
+	}
+	if _, err := time.Parse("2006-01-02", *date); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -date %q (want YYYY-MM-DD)\n", *date)
+		os.Exit(2)
+	}
+	if *statePath == "" {
+		*statePath = *out + ".state"
+	}
+
+	ids, source, err := loadCandidates(flag.Args(), *storePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	state := newState(ids, source, *seed, *sample)
+	if !*restart {
+		if err := resumeState(*statePath, state); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := steam.NewClient()
+	pending, crawlErr := crawl(ctx, client, state, *statePath, *rate)
+	client.Close()
+	if err := saveState(*statePath, state); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write checkpoint: %v\n", err)
+		os.Exit(1)
+	}
+	if crawlErr != nil || pending > 0 {
+		fmt.Printf("crawled %d of %d profiles; rerun to resume from %s\n",
+			len(state.Done), len(state.Candidates), *statePath)
+		os.Exit(1)
+	}
+
+	file := buildFile(state, *date)
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode distributions: %v\n", err)
+		os.Exit(1)
+	}
+	if err := writeFileAtomic(*out, append(data, '\n')); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		os.Exit(1)
+	}
+	fmt.Printf("wrote %s: sample_size=%d candidates=%d stats=%d\n",
+		*out, file.SampleSize, file.Candidates, len(file.Stats))
+}
+
+// loadCandidates reads Steam IDs from seed files, or from the player store
+// when none are given, and returns them deduplicated and sorted
+func loadCandidates(paths []string, storePath string) ([]string, string, error) {
+	unique := make(map[string]bool)
+	source := "seeds:" + strings.Join(paths, ",")
+
+	if len(paths) == 0 {
+		if storePath == "" {
+			return nil, "", errors.New("no candidates: pass seed files, -store or set PLAYER_STORE_PATH")
+		}
+		data, err := os.ReadFile(storePath)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read player store: %w", err)
+		}
+		var records []struct {
+			SteamID string `json:"steam_id"`
+		}
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, "", fmt.Errorf("failed to parse player store: %w", err)
+		}
+		for _, record := range records {
+			unique[record.SteamID] = true
+		}
+		source = "store:" + storePath
+	}
+
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, "", err
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			unique[strings.TrimSpace(scanner.Text())] = true
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, "", fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	ids := make([]string, 0, len(unique))
+	for id := range unique {
+		if steam.IsSteamID64(id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, "", errors.New("no valid Steam ID64s found in the candidate source")
+	}
+	sort.Strings(ids)
+	return ids, source, nil
+}
+
+// newState picks the sample: a seeded shuffle of the sorted candidates, so
+// the same inputs and seed always choose the same profiles
+func newState(ids []string, source string, seed int64, sample int) *crawlState {
+	rand.New(rand.NewSource(seed)).Shuffle(len(ids), func(i, j int) {
+		ids[i], ids[j] = ids[j], ids[i]
+	})
+	if len(ids) > sample {
+		ids = ids[:sample]
+	}
+	return &crawlState{
+		Seed:       seed,
+		Source:     source,
+		Candidates: ids,
+		Done:       make(map[string]map[string]float64),
+	}
+}
+
+// resumeState loads crawl progress from path into state, refusing a
+// checkpoint taken with a different sample
+func resumeState(path string, state *crawlState) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var saved crawlState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	if saved.Seed != state.Seed || saved.Source != state.Source ||
+		strings.Join(saved.Candidates, ",") != strings.Join(state.Candidates, ",") {
+		return fmt.Errorf("checkpoint %s was taken with a different sample; pass -restart to discard it", path)
+	}
+	if saved.Done != nil {
+		state.Done = saved.Done
+	}
+	log.Info("Resuming percentile crawl", "checkpoint", path, "done", len(state.Done), "candidates", len(state.Candidates))
+	return nil
+}
+
+// crawl fetches every candidate not yet in state.Done, at most rate requests
+// per second. Retryable failures are left pending for the next run; it
+// returns how many remain.
+func crawl(ctx context.Context, client *steam.Client, state *crawlState, statePath string, rate float64) (int, error) {
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer ticker.Stop()
+
+	pending, fetched := 0, 0
+	for _, steamID := range state.Candidates {
+		if _, done := state.Done[steamID]; done {
+			continue
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return pending, ctx.Err()
+		}
+
+		fetchCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+		stats, apiErr := client.GetUserStatsForGame(fetchCtx, steamID, 0)
+		cancel()
+
+		switch {
+		case apiErr == nil:
+			values := make(map[string]float64, len(stats.Stats))
+			for _, stat := range stats.Stats {
+				values[stat.Name] = stat.Value
+			}
+			state.Done[steamID] = values
+		case apiErr.Retryable:
+			pending++
+			log.Warn("Profile fetch failed, leaving it for the next run", "steam_id", steamID, "error", apiErr)
+			continue
+		default:
+			// Private or stat-less profiles are crawled but not sampled
+			state.Done[steamID] = nil
+		}
+
+		if fetched++; fetched%checkpointEvery == 0 {
+			if err := saveState(statePath, state); err != nil {
+				return pending, err
+			}
+			log.Info("Percentile crawl progress", "done", len(state.Done), "candidates", len(state.Candidates))
+		}
+	}
+	return pending, nil
+}
+
+func buildFile(state *crawlState, date string) percentiles.File {
+	values := make(map[string][]float64)
+	sampled := 0
+	for _, steamID := range state.Candidates {
+		stats := state.Done[steamID]
+		if stats == nil {
+			continue
+		}
+		sampled++
+		for name, value := range stats {
+			values[name] = append(values[name], value)
+		}
+	}
+
+	file := percentiles.File{
+		Version:     percentiles.FormatVersion,
+		GeneratedOn: date,
+		SampleSize:  sampled,
+		Candidates:  len(state.Candidates),
+		Seed:        state.Seed,
+		Source:      state.Source,
+		Stats:       make(map[string]percentiles.Distribution, len(values)),
+	}
+	for name, statValues := range values {
+		file.Stats[name] = percentiles.NewDistribution(statValues)
+	}
+	return file
+}
+
+func saveState(path string, state *crawlState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes then renames so an interrupted run never leaves a truncated file
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".percentiles-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
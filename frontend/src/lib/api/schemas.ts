@@ -4,7 +4,15 @@ export const DataSourceInfoSchema = z.object({
   success: z.boolean(),
   source: z.enum(['cache','api','fallback']),
   error: z.string().optional(),
-  fetched_at: z.string().optional()
+  fetched_at: z.string().optional(),
+  stale: z.boolean().optional(),
+  stale_age_seconds: z.number().optional()
+}).passthrough();
+
+const GradePeakSchema = z.object({
+  grade: z.string(),
+  rank: z.number().int(),
+  achieved_at: z.string()
 }).passthrough();
 
 const MappedAchievementSchema = z.object({
@@ -71,6 +79,11 @@ export const ApiPlayerStatsSchema = z.object({
   camper_new_item: z.coerce.number().int().nonnegative().nullable().optional(),
   time_played_hours: z.coerce.number().int().nonnegative().nullable().optional(),
   achievements: AchievementDataSchema.optional(),
+  achievements_state: z.enum(['ok', 'empty', 'private', 'unavailable']).optional(),
+  peak_grades: z.object({
+    killer: GradePeakSchema.optional(),
+    survivor: GradePeakSchema.optional()
+  }).optional(),
   data_sources: z.object({
     stats: DataSourceInfoSchema.optional(),
     achievements: DataSourceInfoSchema.optional()
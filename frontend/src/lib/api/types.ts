@@ -14,6 +14,8 @@ export type ApiStat = {
   icon?: string;
   alias?: string;
   matched_by?: 'schema' | 'alias' | 'fallback';
+  unit?: string; // e.g. 'generators_equivalent' for fractional "equivalent" stats
+  rounded?: number;
 };
 
 export type ApiStatsSummary = {
@@ -92,9 +94,16 @@ export type ApiPlayerStats = {
     adept_survivors?: Record<string, boolean>;
     adept_killers?: Record<string, boolean>;
   };
+  // Why achievements is present or missing
+  achievements_state?: 'ok' | 'empty' | 'private' | 'unavailable';
+  // All-time best grades; live grades reset monthly
+  peak_grades?: {
+    killer?: { grade: string; rank: number; achieved_at: string };
+    survivor?: { grade: string; rank: number; achieved_at: string };
+  };
   data_sources?: {
-    stats?: { success: boolean; source: 'cache'|'api'|'fallback'; error?: string; fetched_at?: string };
-    achievements?: { success: boolean; source: 'cache'|'api'|'fallback'; error?: string; fetched_at?: string };
+    stats?: { success: boolean; source: 'cache'|'api'|'fallback'; error?: string; fetched_at?: string; stale?: boolean; stale_age_seconds?: number };
+    achievements?: { success: boolean; source: 'cache'|'api'|'fallback'; error?: string; fetched_at?: string; stale?: boolean; stale_age_seconds?: number };
   };
 };
 
@@ -177,8 +186,8 @@ export type Player = {
     AdeptKillers?: Record<string, boolean>;
   };
   sources?: {
-    stats?: { success: boolean; source: 'cache'|'api'|'fallback'; error?: string; fetched_at?: string };
-    achievements?: { success: boolean; source: 'cache'|'api'|'fallback'; error?: string; fetched_at?: string };
+    stats?: { success: boolean; source: 'cache'|'api'|'fallback'; error?: string; fetched_at?: string; stale?: boolean; stale_age_seconds?: number };
+    achievements?: { success: boolean; source: 'cache'|'api'|'fallback'; error?: string; fetched_at?: string; stale?: boolean; stale_age_seconds?: number };
   };
 };
 
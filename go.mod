@@ -5,3 +5,5 @@ go 1.23.4
 require github.com/gorilla/mux v1.8.1
 
 require github.com/joho/godotenv v1.5.1
+
+require golang.org/x/text v0.28.0
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+const (
+	// achievementRetryDelay is how long after a failed fetch the one retry runs
+	achievementRetryDelay = 30 * time.Second
+	// maxPendingAchievementRetries bounds the timers held during a Steam outage
+	maxPendingAchievementRetries = 1000
+)
+
+var (
+	achievementRetriesScheduled = metrics.NewCounter("dbd_achievement_retries_scheduled",
+		"Deferred achievement refetches queued after a retryable failure in a combined fetch.")
+	achievementRetriesRecovered = metrics.NewCounter("dbd_achievement_retries_recovered",
+		"Deferred achievement refetches that patched achievements into the cached combined response.")
+)
+
+// achievementRetryable reports whether an achievements failure is likely
+// transient
+func achievementRetryable(err error) bool {
+	if errors.Is(err, cache.ErrCircuitOpen) {
+		return true
+	}
+	var apiErr *steam.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Type {
+	case steam.ErrorTypeRateLimit, steam.ErrorTypeTimeout, steam.ErrorTypeNetwork,
+		steam.ErrorTypeInternal: // failed connections surface as internal errors
+		return true
+	}
+	// Upstream 4xx answers (private profiles get 403) won't change on a retry
+	return apiErr.Retryable || apiErr.StatusCode >= 500
+}
+
+// achievementRetries holds at most one pending refetch per player
+type achievementRetries struct {
+	mu      sync.Mutex
+	pending map[string]*time.Timer
+	closed  bool
+}
+
+func newAchievementRetries() *achievementRetries {
+	return &achievementRetries{pending: make(map[string]*time.Timer)}
+}
+
+// stop cancels every pending retry
+func (q *achievementRetries) stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	for steamID, timer := range q.pending {
+		timer.Stop()
+		delete(q.pending, steamID)
+	}
+}
+
+// scheduleAchievementRetry queues one deferred refetch of steamID's
+// achievements after a retryable failure, so the partial combined response
+// in the cache is patched instead of served without achievements until it
+// expires
+func (h *Handler) scheduleAchievementRetry(steamID string, cause error) {
+	if h.cacheManager() == nil || !achievementRetryable(cause) {
+		return
+	}
+	delay := achievementRetryDelay
+	if errors.Is(cause, cache.ErrCircuitOpen) && h.config.CBResetTimeout > delay {
+		delay = h.config.CBResetTimeout // no point retrying before the breaker half-opens
+	}
+
+	q := h.achRetries
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed || q.pending[steamID] != nil || len(q.pending) >= maxPendingAchievementRetries {
+		return
+	}
+	q.pending[steamID] = time.AfterFunc(delay, func() {
+		q.mu.Lock()
+		delete(q.pending, steamID)
+		closed := q.closed
+		q.mu.Unlock()
+		if !closed {
+			h.retryAchievements(steamID)
+		}
+	})
+	achievementRetriesScheduled.Add(1)
+	log.Info("Achievement refetch scheduled",
+		"steam_id", steamID,
+		"delay", delay,
+		"error_type", classifyError(cause))
+}
+
+// retryAchievements refetches achievements and patches them into the cached
+// combined response if it is still there and still missing them
+func (h *Handler) retryAchievements(steamID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), SteamAPITimeout)
+	defer cancel()
+
+	achievements, source, err := h.fetchPlayerAchievementsWithSource(ctx, steamID)
+	if err != nil {
+		log.Warn("Deferred achievement refetch failed; leaving the cached response as is",
+			"steam_id", steamID,
+			"error", err)
+		return
+	}
+	h.patchCombinedAchievements(steamID, achievements, source)
+}
+
+// patchCombinedAchievements writes refetched achievements into the cached
+// combined response. Stale fallback data is left out: cached for the full TTL
+// as a fresh success, it would outlive the outage that produced it.
+func (h *Handler) patchCombinedAchievements(steamID string, achievements *models.AchievementData, source string) {
+	if source == "fallback" {
+		log.Info("Deferred achievement refetch only found stale data; leaving the cached response as is",
+			"steam_id", steamID)
+		return
+	}
+
+	manager := h.cacheManager()
+	key := playerCombinedCache.Key(steamID)
+	response, found := cache.Get(manager.GetCache(), key)
+	if !found {
+		return // expired meanwhile; the next request assembles a complete response
+	}
+	if response.DataSources.Achievements.Success {
+		return
+	}
+
+	response.Achievements = achievements
+	response.AchievementsState = achievementsState(achievements, nil)
+	response.DataSources.Achievements = models.DataSourceInfo{
+		Success:   true,
+		Source:    source,
+		FetchedAt: time.Now(),
+	}
+	ttl := manager.TTLFor(cache.PlayerCombinedPrefix, manager.GetConfig().TTL.PlayerCombined)
+	if err := cache.Set(manager.GetCache(), key, response, ttl); err != nil {
+		log.Warn("Failed to update cached combined response with achievements",
+			"steam_id", steamID,
+			"error", err)
+		return
+	}
+	achievementRetriesRecovered.Add(1)
+	log.Info("Achievements recovered into cached combined response",
+		"steam_id", steamID,
+		"source", source)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+const retrySteamID = "76561198000000077"
+
+// newRetryHandler returns a handler with a cache holding a combined response
+// for retrySteamID whose achievements fetch failed
+func newRetryHandler(t *testing.T) *Handler {
+	t.Helper()
+	manager, err := cache.NewManager(cache.PlayerStatsConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { manager.Close() })
+	h := &Handler{achRetries: newAchievementRetries()}
+	h.cacheMgr.Store(manager)
+
+	partial := models.PlayerStatsWithAchievements{}
+	partial.DataSources.Achievements = models.DataSourceInfo{Success: false, Source: "api", Error: "steam achievements failed"}
+	if err := cache.Set(manager.GetCache(), playerCombinedCache.Key(retrySteamID), partial, 0); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func cachedCombined(t *testing.T, h *Handler) models.PlayerStatsWithAchievements {
+	t.Helper()
+	response, found := cache.Get(h.cacheManager().GetCache(), playerCombinedCache.Key(retrySteamID))
+	if !found {
+		t.Fatal("combined response dropped from the cache")
+	}
+	return response
+}
+
+// TestAchievementRetryLeavesStaleFallbackOut requires a retry that only got
+// the breaker's stale fallback to leave the partial response alone, where a
+// fresh result is patched in
+func TestAchievementRetryLeavesStaleFallbackOut(t *testing.T) {
+	achievements := &models.AchievementData{}
+
+	h := newRetryHandler(t)
+	h.patchCombinedAchievements(retrySteamID, achievements, "fallback")
+	if got := cachedCombined(t, h); got.DataSources.Achievements.Success || got.Achievements != nil {
+		t.Errorf("stale fallback patched in: %+v", got.DataSources.Achievements)
+	}
+
+	h = newRetryHandler(t)
+	h.patchCombinedAchievements(retrySteamID, achievements, "api")
+	got := cachedCombined(t, h)
+	if !got.DataSources.Achievements.Success || got.DataSources.Achievements.Source != "api" || got.Achievements == nil {
+		t.Errorf("fresh achievements not patched in: %+v", got.DataSources.Achievements)
+	}
+}
+
+// TestAchievementRetryable checks retryability survives the wrapping between
+// the Steam client and the combined fetch
+func TestAchievementRetryable(t *testing.T) {
+	wrap := func(err error) error {
+		return fmt.Errorf("steam achievements failed: %w", fmt.Errorf("steam API error: %w", err))
+	}
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"breaker open", wrap(cache.ErrCircuitOpen), true},
+		{"rate limited", wrap(steam.NewRateLimitError()), true},
+		{"upstream 503", wrap(steam.NewAPIError(http.StatusServiceUnavailable, "HTTP 503")), true},
+		{"upstream 500", wrap(steam.NewAPIError(http.StatusInternalServerError, "HTTP 500")), true},
+		{"private profile", wrap(steam.NewAPIError(http.StatusForbidden, "HTTP 403")), false},
+		{"not found", wrap(steam.NewNotFoundError("player")), false},
+		{"untyped", errors.New("circuit breaker open"), false},
+	}
+	for _, c := range cases {
+		if got := achievementRetryable(c.err); got != c.want {
+			t.Errorf("%s: retryable %v, want %v", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/notify"
+)
+
+// adminToken returns the shared secret holding every admin scope
+func adminToken() string {
+	return os.Getenv("ADMIN_TOKEN")
+}
+
+// TestNotification fires a synthetic event through the notifier so channel configuration can be verified
+func (h *Handler) TestNotification(w http.ResponseWriter, r *http.Request) {
+	if !h.requireNotifier(w, r) {
+		return
+	}
+
+	known := make([]string, len(notify.EventTypes))
+	for i, t := range notify.EventTypes {
+		known[i] = string(t)
+	}
+	params := newQueryParams(r)
+	eventType := notify.EventType(params.enum("event", known, string(notify.EventTest)))
+	if err := params.err(); err != nil {
+		writeParamErrors(w, r, err)
+		return
+	}
+
+	results := h.notifier.Fire(r.Context(), notify.Event{
+		Type:     eventType,
+		Severity: notify.SeverityInfo,
+		Title:    "Test notification",
+		Message:  "Test fire from the dbd-analytics admin endpoint; no action required",
+		Fields:   map[string]interface{}{"routed_event_type": string(eventType)},
+	})
+
+	delivered := 0
+	for _, result := range results {
+		if result.Success {
+			delivered++
+		}
+	}
+
+	streamJSONResponse(w, r, map[string]interface{}{
+		"event_type": eventType,
+		"delivered":  delivered,
+		"results":    results,
+	})
+}
+
+// GetDeadLetters lists notifications no channel accepted, with each channel's
+// queue depth and circuit state
+func (h *Handler) GetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if !h.requireNotifier(w, r) {
+		return
+	}
+	letters := h.notifier.DeadLetters()
+	writeJSONResponse(w, map[string]interface{}{
+		"dead_letters": letters,
+		"count":        len(letters),
+		"channels":     h.notifier.Health(),
+	})
+}
+
+// ClearDeadLetters drops every dead-lettered notification
+func (h *Handler) ClearDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if !h.requireNotifier(w, r) {
+		return
+	}
+	writeJSONResponse(w, map[string]interface{}{"cleared": h.notifier.ClearDeadLetters()})
+}
+
+// ReplayDeadLetters requeues dead-lettered notifications on their channels
+func (h *Handler) ReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if !h.requireNotifier(w, r) {
+		return
+	}
+	writeJSONResponse(w, map[string]interface{}{"replayed": h.notifier.ReplayDeadLetters()})
+}
+
+func (h *Handler) requireNotifier(w http.ResponseWriter, r *http.Request) bool {
+	if h.notifier == nil {
+		writeError(w, r, "NOTIFICATIONS_DISABLED", "No notification channels are configured", http.StatusServiceUnavailable, nil, nil)
+		return false
+	}
+	return true
+}
+
+// EvictCache deletes one key (?key=), a namespace (?prefix=) or everything (?all=true)
+// on this instance and broadcasts the eviction to replicas when a bus is configured
+func (h *Handler) EvictCache(w http.ResponseWriter, r *http.Request) {
+	if h.cacheManager() == nil {
+		writeError(w, r, "CACHE_DISABLED", "Caching is not enabled on this instance", http.StatusServiceUnavailable, nil, nil)
+		return
+	}
+
+	params := newQueryParams(r)
+	key, prefix := params.string("key"), params.string("prefix")
+	all := params.boolean("all", false)
+
+	selectors := 0
+	for _, set := range []bool{key != "", prefix != "", all} {
+		if set {
+			selectors++
+		}
+	}
+	if selectors != 1 {
+		params.fail("key", "Exactly one of key, prefix or all=true is required", nil)
+	}
+	if err := params.err(); err != nil {
+		writeParamErrors(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"broadcast": h.cacheManager().InvalidationStatus()["enabled"],
+	}
+	switch {
+	case key != "":
+		if err := h.cacheManager().Invalidate(key); err != nil {
+			writeError(w, r, "CACHE_ERROR", err.Error(), http.StatusInternalServerError, nil, nil)
+			return
+		}
+		response["key"] = key
+	case prefix != "":
+		response["prefix"] = prefix
+		response["removed"] = h.cacheManager().InvalidatePrefix(prefix)
+	default:
+		if err := h.cacheManager().GetCache().Clear(); err != nil {
+			writeError(w, r, "CACHE_ERROR", err.Error(), http.StatusInternalServerError, nil, nil)
+			return
+		}
+		response["all"] = true
+	}
+
+	writeJSONResponse(w, response)
+}
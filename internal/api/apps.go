@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// appFromRequest resolves the title a player request targets: the {appid}
+// path segment, else ?appid=, else the configured default
+func appFromRequest(r *http.Request) (*steam.App, error) {
+	params := newQueryParams(r)
+	id := mux.Vars(r)["appid"]
+	if id == "" {
+		id = params.string("appid")
+	}
+	if id == "" {
+		return steam.DefaultApp(), nil
+	}
+	app, ok := steam.LookupApp(id)
+	if !ok {
+		ids := steam.AppIDs()
+		params.fail("appid", "appid must be one of: "+strings.Join(ids, ", "), ids)
+		return nil, params.err()
+	}
+	return app, nil
+}
+
+// withApp puts the requested title on the request context, so the Steam
+// calls and cache keys below it target that app
+func withApp(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		app, err := appFromRequest(r)
+		if err != nil {
+			writeParamErrors(w, r, err)
+			return
+		}
+		w.Header().Set("X-Steam-App-ID", app.ID)
+		next(w, r.WithContext(steam.WithApp(r.Context(), app)))
+	}
+}
+
+// playerCacheID is the cache identity of a player within the app ctx carries
+func playerCacheID(ctx context.Context, steamID string) string {
+	return steam.AppFromContext(ctx).ScopedID(steamID)
+}
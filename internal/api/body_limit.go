@@ -0,0 +1,90 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// BodyLimit bounds the request body a route accepts
+type BodyLimit struct {
+	MaxBytes int64 // total body size
+	MaxDepth int   // nesting of JSON objects and arrays; 0 skips the check
+}
+
+// bodyLimit returns the configured limits with a route-specific byte cap;
+// routeMax <= 0 keeps the global MAX_BODY_BYTES
+func (h *Handler) bodyLimit(routeMax int64) BodyLimit {
+	limit := BodyLimit{MaxBytes: h.config.MaxBodyBytes, MaxDepth: h.config.MaxJSONDepth}
+	if routeMax > 0 {
+		limit.MaxBytes = routeMax
+	}
+	return limit
+}
+
+// withBodyLimit rejects oversized or too deeply nested bodies with 413 before the
+// handler runs. The body is read up front (it is bounded) and handed on as a
+// fresh reader, so handlers decode it as usual.
+func withBodyLimit(limit BodyLimit, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit.MaxBytes {
+			writeBodyTooLarge(w, r, limit)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit.MaxBytes))
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				writeBodyTooLarge(w, r, limit)
+				return
+			}
+			writeValidationError(w, r, "Could not read request body", "body")
+			return
+		}
+
+		if limit.MaxDepth > 0 && jsonDepthExceeds(body, limit.MaxDepth) {
+			writeError(w, r, "PAYLOAD_TOO_DEEP",
+				fmt.Sprintf("Request body nests JSON deeper than %d levels", limit.MaxDepth),
+				http.StatusRequestEntityTooLarge,
+				map[string]interface{}{"max_depth": limit.MaxDepth},
+				nil)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next(w, r)
+	}
+}
+
+func writeBodyTooLarge(w http.ResponseWriter, r *http.Request, limit BodyLimit) {
+	writeError(w, r, "PAYLOAD_TOO_LARGE",
+		fmt.Sprintf("Request body exceeds %d bytes", limit.MaxBytes),
+		http.StatusRequestEntityTooLarge,
+		map[string]interface{}{"max_bytes": limit.MaxBytes},
+		nil)
+}
+
+// jsonDepthExceeds walks the tokens of body and stops as soon as nesting passes
+// maxDepth. Malformed JSON is left for the handler to reject with its own message.
+func jsonDepthExceeds(body []byte, maxDepth int) bool {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			if depth++; depth > maxDepth {
+				return true
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
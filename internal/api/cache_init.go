@@ -0,0 +1,160 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+const (
+	cacheRetryInitialBackoff = 2 * time.Second
+	cacheRetryMaxBackoff     = time.Minute
+)
+
+// Cache namespaces for the player data the handlers assemble
+var (
+	playerStatsCache        = cache.NewNamespace[models.PlayerStats](cache.PlayerStatsPrefix)
+	playerSummaryCache      = cache.NewNamespace[steam.SteamPlayer](cache.PlayerSummaryPrefix)
+	playerAchievementsCache = cache.NewNamespace[*models.AchievementData](cache.PlayerAchievementsPrefix)
+	structuredStatsCache    = cache.NewNamespace[*models.StatsData](cache.StructuredStatsPrefix)
+	playerInventoryCache    = cache.NewNamespace[models.PlayerInventory](cache.PlayerInventoryPrefix)
+	playerCombinedCache     = cache.NewNamespace[models.PlayerStatsWithAchievements](cache.PlayerCombinedPrefix)
+)
+
+// Cache modes reported by /health
+const (
+	cacheModeEnabled  = "enabled"
+	cacheModeRetrying = "retrying" // running without a cache while NewManager is retried
+)
+
+// cacheInitState tracks how the handler got (or is still getting) its cache
+type cacheInitState struct {
+	mu          sync.Mutex
+	mode        string
+	attempts    int
+	lastError   string
+	nextAttempt time.Time
+	enabledAt   time.Time
+}
+
+func (s *cacheInitState) status() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := map[string]interface{}{
+		"mode":     s.mode,
+		"attempts": s.attempts,
+	}
+	if s.lastError != "" {
+		status["last_error"] = s.lastError
+	}
+	if s.mode == cacheModeRetrying {
+		status["next_attempt"] = s.nextAttempt.UTC().Format(time.RFC3339)
+	}
+	if !s.enabledAt.IsZero() {
+		status["enabled_at"] = s.enabledAt.UTC().Format(time.RFC3339)
+	}
+	return status
+}
+
+// cacheManager returns the cache manager, or nil while running without one.
+// It only ever goes from nil to set, so callers may check and then call again.
+func (h *Handler) cacheManager() *cache.Manager {
+	return h.cacheMgr.Load()
+}
+
+// sharedCache returns the manager's cache, or nil while running without one;
+// cache.GetOrLoad then loads directly
+func (h *Handler) sharedCache() cache.Cache {
+	if manager := h.cacheManager(); manager != nil {
+		return manager.GetCache()
+	}
+	return nil
+}
+
+// cacheTTL is the manager's tuned TTL for prefix over the configured base
+func (h *Handler) cacheTTL(prefix string, base func(cache.TTLConfig) time.Duration) time.Duration {
+	manager := h.cacheManager()
+	if manager == nil {
+		return 0
+	}
+	return manager.TTLFor(prefix, base(manager.GetConfig().TTL))
+}
+
+// installCacheManager makes m the handler's cache manager
+func (h *Handler) installCacheManager(m *cache.Manager) {
+	m.SetTTLScale(h.steamClient.Quota().TTLMultiplier)
+	h.cacheMgr.Store(m)
+
+	h.cacheInit.mu.Lock()
+	h.cacheInit.mode = cacheModeEnabled
+	h.cacheInit.attempts++
+	h.cacheInit.enabledAt = time.Now()
+	h.cacheInit.mu.Unlock()
+}
+
+// retryCacheInit keeps calling cache.NewManager with exponential backoff after
+// a failed start, so a backend that comes up late (e.g. Redis still starting in
+// docker-compose) is picked up without a restart. Requests are served uncached
+// meanwhile.
+func (h *Handler) retryCacheInit(firstErr error) {
+	h.cacheInit.mu.Lock()
+	h.cacheInit.mode = cacheModeRetrying
+	h.cacheInit.attempts = 1
+	h.cacheInit.lastError = firstErr.Error()
+	h.cacheInit.nextAttempt = time.Now().Add(cacheRetryInitialBackoff)
+	h.cacheInit.mu.Unlock()
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	var once sync.Once
+	h.stopCacheRetry = func() {
+		once.Do(func() { close(stop) })
+		<-done
+	}
+
+	go func() {
+		defer close(done)
+		backoff := cacheRetryInitialBackoff
+		for {
+			select {
+			case <-time.After(backoff):
+			case <-stop:
+				return
+			}
+
+			manager, err := cache.NewManager(cache.PlayerStatsConfig())
+			if err == nil {
+				h.installCacheManager(manager)
+				log.Info("Cache manager initialized after retry",
+					"cache_type", string(manager.GetConfig().Type),
+					"attempts", h.cacheInit.status()["attempts"])
+				// The schema worker started without a cache; restart it with one
+				if h.stopSchemaWorker != nil {
+					h.stopSchemaWorker()
+				}
+				h.startSchemaWorker(manager.GetCache())
+				return
+			}
+
+			if backoff *= 2; backoff > cacheRetryMaxBackoff {
+				backoff = cacheRetryMaxBackoff
+			}
+			h.cacheInit.mu.Lock()
+			h.cacheInit.attempts++
+			h.cacheInit.lastError = err.Error()
+			h.cacheInit.nextAttempt = time.Now().Add(backoff)
+			attempts := h.cacheInit.attempts
+			h.cacheInit.mu.Unlock()
+
+			log.Warn("Cache manager initialization failed, retrying",
+				"error", err,
+				"attempts", attempts,
+				"next_attempt_in", backoff)
+		}
+	}()
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// cachePolicies are the values ?cache= takes
+var cachePolicies = []string{string(cache.PolicyBypass), string(cache.PolicyRefresh), string(cache.PolicyOnly)}
+
+// withCachePolicy applies ?cache= to the player request below it, for support
+// and debugging: bypass skips cache reads but stores what it fetches, refresh
+// evicts the player and refetches, and only answers from the cache or 404s
+// without touching Steam. The older fresh=true means cache=refresh. Callers
+// need the API key or an admin token with the cache:control scope.
+func (h *Handler) withCachePolicy(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := newQueryParams(r)
+		policy := cache.Policy(params.enum("cache", cachePolicies, ""))
+		if params.boolean("fresh", false) {
+			if policy != cache.PolicyDefault && policy != cache.PolicyRefresh {
+				params.fail("fresh", "fresh=true means cache=refresh and cannot be combined with another cache policy", nil)
+			}
+			policy = cache.PolicyRefresh
+		}
+		if err := params.err(); err != nil {
+			writeParamErrors(w, r, err)
+			return
+		}
+		if policy == cache.PolicyDefault {
+			next(w, r)
+			return
+		}
+
+		if !h.mayControlCache(r) {
+			writeError(w, r, "CACHE_POLICY_FORBIDDEN",
+				"cache= and fresh=true need the API key or an admin token with the "+ScopeCacheControl+" scope",
+				http.StatusForbidden,
+				map[string]interface{}{"cache": policy, "required_scope": ScopeCacheControl},
+				nil)
+			return
+		}
+		// Fetching past the cache is the first thing to go when the daily Steam budget runs low
+		if policy != cache.PolicyOnly && !h.config.DemoMode && !h.steamClient.Quota().AllowFresh() {
+			quota := h.steamClient.Quota().Status()
+			writeError(w, r, "FRESH_BYPASS_REJECTED",
+				"Steam API quota is under pressure; cached data only until usage drops",
+				http.StatusTooManyRequests,
+				map[string]interface{}{"quota_level": quota["level"], "quota_usage": quota["usage"]},
+				nil)
+			return
+		}
+
+		log.Info("Cache policy applied",
+			"cache", policy,
+			"path", r.URL.Path,
+			"client_ip", getClientIP(r))
+		w.Header().Set("X-Cache-Policy", string(policy))
+		next(w, r.WithContext(cache.WithPolicy(r.Context(), policy)))
+	}
+}
+
+// mayControlCache reports whether the caller may set ?cache=: an admin token
+// holding cache:control, or the API key when one is configured
+func (h *Handler) mayControlCache(r *http.Request) bool {
+	if scopes, ok := scopesFor(h.adminCredentials, r.Header.Get("X-Admin-Token")); ok && scopes[ScopeCacheControl] {
+		return true
+	}
+	return hasValidAPIKey(r)
+}
+
+// resolvePlayerID is ResolveSteamIDAs, except that under cache=only a vanity
+// name resolves only through the binding recorded when it was last served.
+// found is false when there is none; Steam is never asked.
+func (h *Handler) resolvePlayerID(ctx context.Context, input string, idType steam.IDType) (string, steam.IDType, bool, *steam.APIError) {
+	if cache.PolicyFromContext(ctx) != cache.PolicyOnly || idType == steam.IDTypeSteamID ||
+		(idType == steam.IDTypeAuto && steam.IsSteamID64(input)) {
+		steamID, resolvedAs, err := h.steamClient.ResolveSteamIDAs(ctx, input, idType)
+		return steamID, resolvedAs, true, err
+	}
+	if owner, bound := h.players.VanityOwner(input); bound {
+		return owner.SteamID, steam.IDTypeVanity, true, nil
+	}
+	return "", "", false, nil
+}
+
+// writeNotCached answers a cache=only request the cache can't serve
+func writeNotCached(w http.ResponseWriter, r *http.Request, player string) {
+	writeError(w, r, "NOT_CACHED", "Not in the cache; cache=only never fetches from Steam", http.StatusNotFound,
+		map[string]interface{}{"player": player, "cache": cache.PolicyOnly},
+		nil)
+}
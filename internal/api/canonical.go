@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+)
+
+// canonicalHeader asks for canonical JSON: object keys sorted at every level
+// and no insignificant whitespace, so the same data always encodes to the
+// same bytes. Snapshot-diff tooling and external golden tests set it.
+const canonicalHeader = "X-Canonical-JSON"
+
+var canonicalResponses = metrics.NewCounter("dbd_canonical_responses",
+	"Responses re-encoded as canonical JSON.")
+
+// wantsCanonical reports whether the request sets X-Canonical-JSON to a true value
+func wantsCanonical(r *http.Request) bool {
+	on, err := strconv.ParseBool(r.Header.Get(canonicalHeader))
+	return err == nil && on
+}
+
+// canonicalJSON re-encodes one JSON document with sorted keys. Numbers keep
+// their original text, so nothing is rounded on the way through.
+func canonicalJSON(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n")), nil
+}
+
+// canonicalBody re-encodes a JSON body, or each line of an NDJSON body. Other
+// content types come back as nil with no error.
+func canonicalBody(contentType string, body []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/json":
+		return canonicalJSON(body)
+	case ndjsonContentType:
+		var out bytes.Buffer
+		for _, line := range bytes.Split(body, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			doc, err := canonicalJSON(line)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(doc)
+			out.WriteByte('\n')
+		}
+		return out.Bytes(), nil
+	}
+	return nil, nil
+}
+
+// CanonicalJSONMiddleware answers requests carrying X-Canonical-JSON: true
+// with canonical JSON. Arrays keep the order handlers give them, which is
+// already deterministic, so only key order and spacing change. The response
+// is buffered in full, so streamed and progressive responses arrive in one
+// piece; MessagePack and other non-JSON bodies pass through untouched.
+func CanonicalJSONMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", canonicalHeader)
+			if !wantsCanonical(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			bw := &bufferedWriter{header: w.Header()}
+			next.ServeHTTP(bw, r)
+			if bw.statusCode == 0 {
+				bw.statusCode = http.StatusOK
+			}
+
+			body := bw.body.Bytes()
+			canonical, err := canonicalBody(w.Header().Get("Content-Type"), body)
+			switch {
+			case err != nil:
+				// Serve the original rather than fail the request
+				log.Error("Failed to re-encode response as canonical JSON",
+					"path", r.URL.Path,
+					"error", err)
+			case canonical != nil:
+				body = canonical
+				w.Header().Set(canonicalHeader, "true")
+				canonicalResponses.Add(1)
+			}
+			if r.Method == http.MethodHead {
+				w.Header().Del("Content-Length") // counted the original encoding
+			} else if w.Header().Get("Content-Length") != "" {
+				w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			}
+			w.WriteHeader(bw.statusCode)
+			w.Write(body)
+		})
+	}
+}
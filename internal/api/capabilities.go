@@ -0,0 +1,123 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/gorilla/mux"
+	"github.com/rgonzalez12/dbd-analytics/internal/buildinfo"
+	"github.com/rgonzalez12/dbd-analytics/internal/flags"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// capabilityRoute is one registered endpoint as reported by /capabilities
+type capabilityRoute struct {
+	Path    string   `json:"path"`
+	Methods []string `json:"methods"`
+}
+
+// listRoutes walks the router once registration is done, so the endpoint list
+// always matches what this deployment actually serves (opt-in routes included)
+func listRoutes(router *mux.Router) []capabilityRoute {
+	var routes []capabilityRoute
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		routes = append(routes, capabilityRoute{Path: path, Methods: methods})
+		return nil
+	})
+	return routes
+}
+
+// GetCapabilities describes what this deployment supports so generic clients can
+// feature-detect instead of hardcoding assumptions. Feature flags are evaluated
+// for the calling client, matching what its player responses will contain.
+func (h *Handler) GetCapabilities(w http.ResponseWriter, r *http.Request) {
+	authMode := "none"
+	if os.Getenv("API_KEY") != "" {
+		authMode = "api_key"
+	}
+
+	cacheInfo := map[string]interface{}{"enabled": false}
+	if h.cacheManager() != nil {
+		invalidation := h.cacheManager().InvalidationBackend()
+		if invalidation == "" {
+			invalidation = "local"
+		}
+		cacheInfo = map[string]interface{}{
+			"enabled":      true,
+			"type":         h.cacheManager().GetConfig().Type,
+			"redis":        invalidation == "redis",
+			"invalidation": invalidation,
+		}
+	}
+
+	channels := []string{}
+	if h.notifier != nil {
+		channels = h.notifier.Channels()
+		sort.Strings(channels)
+	}
+
+	writeJSONResponse(w, map[string]interface{}{
+		"build":     buildinfo.Get(),
+		"demo_mode": h.config.DemoMode,
+		"auth": map[string]interface{}{
+			"mode":   authMode,
+			"header": "X-API-Key",
+			"admin":  len(h.adminCredentials) > 0,
+		},
+		"features": map[string]interface{}{
+			"analytics":       h.flagEnabled(r, flags.Analytics),
+			"percentiles":     h.flagEnabled(r, flags.Percentiles),
+			"roadmap":         h.flagEnabled(r, flags.Roadmap),
+			"inventory":       h.config.InventoryEnabled,
+			"icon_mirror":     h.config.IconMirrorEnabled,
+			"integrity_flags": h.config.IntegrityFlagsEnabled,
+			"player_store": map[string]interface{}{
+				"persistent": h.players.Persistent(),
+			},
+			"cache": cacheInfo,
+			"notifications": map[string]interface{}{
+				"enabled":  h.notifier != nil,
+				"channels": channels,
+			},
+		},
+		"parameters": map[string]interface{}{
+			"lang":   steam.Languages(),
+			"locale": steam.Locales(),
+			"cache":  cachePolicies,
+			"wait_for_fresh": map[string]interface{}{
+				"enabled":     h.config.MaxWaitForFreshSecs > 0,
+				"max_seconds": h.config.MaxWaitForFreshSecs,
+			},
+			"appid": map[string]interface{}{
+				"default": steam.DefaultApp().ID,
+				"allowed": steam.AppIDs(),
+			},
+			"accept": responseMediaTypes,
+			"id_types": []string{
+				string(steam.IDTypeAuto), string(steam.IDTypeSteamID), string(steam.IDTypeVanity),
+			},
+			"stats": map[string]interface{}{
+				"category":   steam.StatCategories,
+				"value_type": steam.StatValueTypes,
+				"sort":       steam.StatSorts,
+				"max_limit":  maxStatListLimit,
+				"group_by":   steam.StatGroupings,
+			},
+		},
+		"limits": map[string]interface{}{
+			"max_body_bytes": h.config.MaxBodyBytes,
+			"max_json_depth": h.config.MaxJSONDepth,
+			"squad_size":     map[string]int{"min": minSquadSize, "max": maxSquadSize},
+			"group_size":     map[string]int{"min": minGroupSize, "max": maxGroupSize},
+			"groups_per_key": h.config.GroupMaxPerKey,
+			"batch_players":  maxBatchPlayers,
+		},
+		"endpoints": h.routes,
+	})
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/demo"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// rarityRecordInterval is how often the recorder checks for a missing daily
+// snapshot; it fetches at most once a day per title
+const rarityRecordInterval = time.Hour
+
+var catalogSorts = []string{"name", "rarity", "change"}
+
+// GetAchievementCatalog lists the title's achievements with their global
+// rarity and its trend over ?days= (default RARITY_TREND_DAYS). ?sharp_only=true
+// keeps the achievements whose rarity jumped sharply in the window, usually
+// after a balance patch; ?sort= orders by name, rarity (rarest first) or
+// change (largest move first).
+func (h *Handler) GetAchievementCatalog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := newQueryParams(r)
+	days := params.intRange("days", h.config.RarityTrendDays, 1, h.rarity.Days())
+	sharpOnly := params.boolean("sharp_only", false)
+	sortBy := params.enum("sort", catalogSorts, "name")
+	if err := params.err(); err != nil {
+		writeParamErrors(w, r, err)
+		return
+	}
+
+	if h.config.DemoMode {
+		catalog := demoCatalog()
+		w.Header().Set("X-Demo-Mode", "true")
+		streamJSONResponse(w, r, filterCatalog(catalog, sharpOnly, sortBy))
+		return
+	}
+
+	app := steam.AppFromContext(ctx)
+	var shared cache.Cache
+	if manager := h.cacheManager(); manager != nil {
+		shared = manager.GetCache()
+	}
+
+	var (
+		schema         *steam.SchemaGame
+		schemaErr      *steam.APIError
+		percentages    map[string]float64
+		percentagesErr error
+	)
+	group := h.workers.Group(ctx)
+	group.Go("catalog_schema", func(taskCtx context.Context) error {
+		schema, schemaErr = h.steamClient.GetSchemaForGameCached(taskCtx, shared)
+		if schemaErr != nil {
+			return schemaErr
+		}
+		return nil
+	})
+	group.Go("catalog_percentages", func(taskCtx context.Context) error {
+		percentages, percentagesErr = h.steamClient.GetGlobalAchievementPercentagesCached(taskCtx, shared)
+		return percentagesErr
+	})
+	if group.Wait(); ctx.Err() != nil {
+		writeTimeoutError(w, r, "achievement_catalog")
+		return
+	}
+	if schemaErr != nil {
+		writeErrorResponse(w, schemaErr)
+		return
+	}
+
+	history := h.rarity.Snapshots(app.ID)
+	catalog := models.AchievementCatalog{
+		AppID:        app.ID,
+		Achievements: steam.CatalogFromSchema(schema, app),
+		History: models.RarityHistoryInfo{
+			Snapshots:  len(history),
+			WindowDays: days,
+			Persistent: h.rarity.Persistent(),
+		},
+		GeneratedAt: time.Now().UTC(),
+	}
+	if len(history) > 0 {
+		catalog.History.Oldest = history[0].Date
+		catalog.History.Newest = history[len(history)-1].Date
+	}
+
+	if percentagesErr != nil {
+		if len(history) == 0 {
+			log.Warn("Global achievement percentages unavailable for catalog",
+				"app_id", app.ID,
+				"error", percentagesErr)
+			writeError(w, r, "RARITY_UNAVAILABLE", "Global achievement percentages are unavailable", http.StatusBadGateway, nil, nil)
+			return
+		}
+		// The newest recorded snapshot stands in, flagged by the warning
+		percentages = history[len(history)-1].Percentages
+		catalog.Warnings = append(catalog.Warnings, "Live rarity unavailable; showing the snapshot from "+catalog.History.Newest)
+	}
+
+	steam.ApplyRarityTrends(catalog.Achievements, percentages, history, catalog.GeneratedAt, steam.RarityTrendConfig{
+		WindowDays:  days,
+		SharpPoints: float64(h.config.RaritySharpChangePoints),
+	})
+	streamJSONResponse(w, r, filterCatalog(catalog, sharpOnly, sortBy))
+}
+
+// filterCatalog applies ?sharp_only and ?sort to catalog
+func filterCatalog(catalog models.AchievementCatalog, sharpOnly bool, sortBy string) models.AchievementCatalog {
+	entries := catalog.Achievements
+	if sharpOnly {
+		kept := make([]models.CatalogAchievement, 0)
+		for _, entry := range entries {
+			if entry.Trend != nil && entry.Trend.SharpChange != nil {
+				kept = append(kept, entry)
+			}
+		}
+		entries = kept
+	}
+
+	switch sortBy {
+	case "rarity":
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Rarity < entries[j].Rarity })
+	case "change":
+		sort.SliceStable(entries, func(i, j int) bool { return trendMagnitude(entries[i]) > trendMagnitude(entries[j]) })
+	}
+
+	catalog.Achievements = entries
+	catalog.Count = len(entries)
+	return catalog
+}
+
+func trendMagnitude(entry models.CatalogAchievement) float64 {
+	if entry.Trend == nil {
+		return -1 // achievements without history sort last
+	}
+	return math.Abs(entry.Trend.Change)
+}
+
+// demoCatalog lists every achievement in the bundled fixtures with its
+// fixture rarity; fixtures have no history, so there are no trends
+func demoCatalog() models.AchievementCatalog {
+	catalog := models.AchievementCatalog{
+		AppID:       steam.DBDAppID,
+		Demo:        true,
+		GeneratedAt: demo.LoadedAt(),
+	}
+	seen := make(map[string]bool)
+	for _, fixture := range demo.Players() {
+		player, ok := demo.Player(fixture["steam_id"])
+		if !ok || player.Achievements == nil {
+			continue
+		}
+		for _, achievement := range player.Achievements.MappedAchievements {
+			if seen[achievement.ID] {
+				continue
+			}
+			seen[achievement.ID] = true
+			catalog.Achievements = append(catalog.Achievements, models.CatalogAchievement{
+				ID:          achievement.ID,
+				DisplayName: achievement.DisplayName,
+				Description: achievement.Description,
+				Icon:        achievement.Icon,
+				Hidden:      achievement.Hidden,
+				Character:   achievement.Character,
+				Type:        achievement.Type,
+				Rarity:      achievement.Rarity,
+			})
+		}
+	}
+	sort.Slice(catalog.Achievements, func(i, j int) bool {
+		return catalog.Achievements[i].DisplayName < catalog.Achievements[j].DisplayName
+	})
+	return catalog
+}
+
+// startRarityRecorder takes a daily snapshot of every registered title's
+// global achievement percentages; demo fixtures have no live rarity
+func (h *Handler) startRarityRecorder() {
+	if h.config.DemoMode {
+		return
+	}
+	stop := make(chan struct{})
+	ticker := time.NewTicker(rarityRecordInterval)
+	h.stopRarity = func() {
+		ticker.Stop()
+		close(stop)
+	}
+
+	go func() {
+		h.recordRarity()
+		for {
+			select {
+			case <-ticker.C:
+				h.recordRarity()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// recordRarity fetches percentages for each title without a snapshot today.
+// It bypasses the cache, which may hold yesterday's values for up to a day.
+func (h *Handler) recordRarity() {
+	now := time.Now()
+	for _, appID := range steam.AppIDs() {
+		if h.rarity.Has(appID, now) {
+			continue
+		}
+		app, ok := steam.LookupApp(appID)
+		if !ok {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(steam.WithApp(context.Background(), app), SteamAPITimeout)
+		percentages, err := h.steamClient.FetchGlobalAchievementPercentages(ctx)
+		cancel()
+		if err != nil {
+			log.Warn("Failed to fetch achievement rarity snapshot", "app_id", appID, "error", err)
+			continue
+		}
+		if len(percentages) == 0 {
+			continue
+		}
+		if _, err := h.rarity.Record(appID, now, percentages); err != nil {
+			log.Warn("Failed to persist achievement rarity snapshot", "app_id", appID, "error", err)
+			continue
+		}
+		log.Info("Recorded achievement rarity snapshot", "app_id", appID, "achievements", len(percentages))
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+var (
+	coalescedRequests = metrics.NewCounter("dbd_combined_coalesced",
+		"Combined player requests served from an identical request already in flight.")
+	coalesceFallbacks = metrics.NewCounter("dbd_combined_coalesce_fallbacks",
+		"Combined player requests that waited on an in-flight request but assembled their own response.")
+)
+
+// combinedFlight is one combined response being assembled. The fields are
+// written by the leader before done is closed and only read after.
+type combinedFlight struct {
+	done     chan struct{}
+	response models.PlayerStatsWithAchievements // undecorated, as it would be cached
+	warnings []string
+	ok       bool
+}
+
+// coalescer lets concurrent combined requests for the same player share one
+// assembly. Steam calls are already deduplicated below the handler; this also
+// saves the mapping and allocation work done per request.
+type coalescer struct {
+	mu      sync.Mutex
+	flights map[string]*combinedFlight
+	maxWait time.Duration // 0 disables coalescing
+}
+
+func newCoalescer(maxWait time.Duration) *coalescer {
+	return &coalescer{flights: make(map[string]*combinedFlight), maxWait: maxWait}
+}
+
+// join returns the flight for key and whether the caller leads it. The leader
+// must call finish exactly once; followers call wait. A nil flight means
+// coalescing is disabled and the caller works alone.
+func (c *coalescer) join(key string) (*combinedFlight, bool) {
+	if c == nil || c.maxWait <= 0 {
+		return nil, true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if flight, ok := c.flights[key]; ok {
+		return flight, false
+	}
+	flight := &combinedFlight{done: make(chan struct{})}
+	c.flights[key] = flight
+	return flight, true
+}
+
+// finish publishes the leader's outcome; ok=false sends followers off to
+// assemble their own response, so leader failures aren't shared
+func (c *coalescer) finish(key string, flight *combinedFlight, response models.PlayerStatsWithAchievements, warnings []string, ok bool) {
+	if flight == nil {
+		return
+	}
+	c.mu.Lock()
+	if c.flights[key] == flight {
+		delete(c.flights, key)
+	}
+	c.mu.Unlock()
+
+	flight.response, flight.warnings, flight.ok = response, warnings, ok
+	close(flight.done)
+}
+
+// wait blocks until the leader finishes, maxWait passes or ctx ends. It
+// reports the shared response when the leader succeeded in time.
+func (c *coalescer) wait(ctx context.Context, flight *combinedFlight) (models.PlayerStatsWithAchievements, []string, bool) {
+	timer := time.NewTimer(c.maxWait)
+	defer timer.Stop()
+
+	select {
+	case <-flight.done:
+		if flight.ok {
+			coalescedRequests.Add(1)
+			return flight.response, flight.warnings, true
+		}
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	coalesceFallbacks.Add(1)
+	return models.PlayerStatsWithAchievements{}, nil, false
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/demo"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// CompareAchievements compares two players' unlocked achievements and adepts.
+// ?players=a,b takes SteamID64s, vanity names or percent-encoded profile URLs.
+// Both players' achievements are required; a missing display name is a warning.
+func (h *Handler) CompareAchievements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	start := time.Now()
+
+	raw := strings.Split(r.URL.Query().Get("players"), ",")
+	if len(raw) != 2 {
+		writeValidationError(w, r, "players must list exactly two players, separated by a comma", "players")
+		return
+	}
+	ids := make([]string, len(raw))
+	idTypes := make([]steam.IDType, len(raw))
+	for i := range raw {
+		id, idType, _, err := parsePlayerID(strings.TrimSpace(raw[i]), steam.IDTypeAuto)
+		if err != nil {
+			writeValidationError(w, r, fmt.Sprintf("players[%d]: %s", i, err.Message), "players")
+			return
+		}
+		ids[i], idTypes[i] = id, idType
+	}
+
+	if h.config.DemoMode {
+		h.serveDemoComparison(w, r, ids)
+		return
+	}
+
+	resolved := make([]string, len(ids))
+	resolveErrs := make([]*steam.APIError, len(ids))
+	group := h.workers.Group(ctx)
+	for i := range ids {
+		group.Go(fmt.Sprintf("resolve_%d", i), func(taskCtx context.Context) error {
+			resolved[i], _, resolveErrs[i] = h.steamClient.ResolveSteamIDAs(taskCtx, ids[i], idTypes[i])
+			if resolveErrs[i] != nil {
+				return resolveErrs[i]
+			}
+			return nil
+		})
+	}
+	if group.Wait(); ctx.Err() != nil {
+		writeTimeoutError(w, r, "compare_achievements")
+		return
+	}
+	for _, err := range resolveErrs {
+		if err != nil {
+			writeErrorResponse(w, err)
+			return
+		}
+	}
+	if resolved[0] == resolved[1] {
+		writeValidationError(w, r, "players lists the same player twice", "players")
+		return
+	}
+
+	type playerResult struct {
+		stats        models.PlayerStats
+		statsErr     error
+		achievements *models.AchievementData
+		achErr       error
+	}
+	results := make([]playerResult, len(resolved))
+	group = h.workers.Group(ctx)
+	for i, steamID := range resolved {
+		group.Go(fmt.Sprintf("stats_%d", i), func(taskCtx context.Context) error {
+			results[i].stats, _, results[i].statsErr = h.fetchPlayerStatsWithSource(taskCtx, steamID)
+			return results[i].statsErr
+		})
+		group.Go(fmt.Sprintf("achievements_%d", i), func(taskCtx context.Context) error {
+			results[i].achievements, _, results[i].achErr = h.fetchPlayerAchievementsWithSource(taskCtx, steamID)
+			return results[i].achErr
+		})
+	}
+	if err := group.Wait(); ctx.Err() != nil {
+		writeTimeoutError(w, r, "compare_achievements")
+		return
+	} else if err != nil {
+		log.Debug("Achievement comparison fetch completed with source errors", "errors", err.Error())
+	}
+
+	players := make([]steam.ComparePlayerData, len(results))
+	var warnings []string
+	for i, result := range results {
+		if result.achErr != nil {
+			log.Warn("Achievement comparison failed: achievements unavailable",
+				"steam_id", resolved[i],
+				"error", result.achErr,
+				"error_type", classifyError(result.achErr))
+			var steamErr *steam.APIError
+			if errors.As(result.achErr, &steamErr) {
+				writeErrorResponse(w, steamErr)
+				return
+			}
+			writeErrorResponse(w, steam.NewInternalError(result.achErr))
+			return
+		}
+		if result.achievements == nil {
+			writeError(w, r, "ACHIEVEMENTS_UNAVAILABLE", "Achievements could not be fetched", http.StatusBadGateway,
+				map[string]interface{}{"steam_id": resolved[i]},
+				nil)
+			return
+		}
+		players[i] = steam.ComparePlayerData{SteamID: resolved[i], Achievements: result.achievements}
+		if result.statsErr != nil {
+			warnings = append(warnings, fmt.Sprintf("Profile unavailable for %s: %s", resolved[i], classifyError(result.statsErr)))
+			continue
+		}
+		players[i].DisplayName = result.stats.DisplayName
+	}
+
+	comparison := steam.BuildAchievementComparison(players[0], players[1])
+	comparison.Warnings = warnings
+	comparison.GeneratedAt = time.Now().UTC()
+
+	log.Info("Achievement comparison generated",
+		"both", len(comparison.Achievements.Both),
+		"only_a", len(comparison.Achievements.OnlyA),
+		"only_b", len(comparison.Achievements.OnlyB),
+		"winner", comparison.BraggingRights.Winner,
+		"duration", time.Since(start))
+
+	streamJSONResponse(w, r, comparison)
+}
+
+// serveDemoComparison compares bundled fixtures when demo mode is active
+func (h *Handler) serveDemoComparison(w http.ResponseWriter, r *http.Request, ids []string) {
+	players := make([]steam.ComparePlayerData, len(ids))
+	for i, id := range ids {
+		player, found := demo.Player(id)
+		if !found {
+			writeError(w, r, "DEMO_PLAYER_NOT_FOUND",
+				"Demo mode is active; only bundled demo players are available",
+				http.StatusNotFound,
+				map[string]interface{}{"demo_players": demo.Players(), "field": "players"},
+				nil)
+			return
+		}
+		if player.Achievements == nil {
+			writeError(w, r, "ACHIEVEMENTS_PRIVATE", "This demo player's achievements are private", http.StatusForbidden,
+				map[string]interface{}{"steam_id": player.SteamID},
+				nil)
+			return
+		}
+		players[i] = steam.ComparePlayerData{
+			SteamID:      player.SteamID,
+			DisplayName:  player.DisplayName,
+			Achievements: player.Achievements,
+		}
+	}
+	if players[0].SteamID == players[1].SteamID {
+		writeValidationError(w, r, "players lists the same player twice", "players")
+		return
+	}
+
+	comparison := steam.BuildAchievementComparison(players[0], players[1])
+	comparison.Demo = true
+	comparison.GeneratedAt = demo.LoadedAt()
+	w.Header().Set("X-Demo-Mode", "true")
+	streamJSONResponse(w, r, comparison)
+}
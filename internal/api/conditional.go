@@ -0,0 +1,101 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/msgpack"
+)
+
+// bufferedWriteTimeout bounds flushing a buffered response, so a client that
+// stops reading can't hold the handler past its route deadline
+const bufferedWriteTimeout = 10 * time.Second
+
+// setLastModified opts a response into conditional fetch: the route's timeout
+// wrapper derives an ETag from the buffered body and answers If-None-Match /
+// If-Modified-Since with 304 instead of resending the payload.
+func setLastModified(w http.ResponseWriter, modified time.Time) {
+	if modified.IsZero() {
+		return
+	}
+	w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+}
+
+// setStableETag sets the ETag from stable, the response body before its
+// per-request fields (data_age_seconds) are filled in, so the validator only
+// changes with the data. The negotiated format is mixed in because JSON,
+// canonical JSON and MessagePack bodies of the same data are different
+// representations.
+func setStableETag(w http.ResponseWriter, r *http.Request, stable interface{}) {
+	encoded, err := json.Marshal(stable)
+	if err != nil {
+		return
+	}
+	if acceptsMsgpack(r) {
+		encoded = append(encoded, msgpack.ContentType...)
+	} else if wantsCanonical(r) {
+		encoded = append(encoded, canonicalHeader...)
+	}
+	sum := sha256.Sum256(encoded)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+}
+
+// writeBuffered flushes a buffered handler response. Successful responses that
+// carry Last-Modified get an ETag (the handler's stable one when set) and
+// Content-Length, and HEAD requests get the headers without the body.
+func writeBuffered(w http.ResponseWriter, r *http.Request, status int, body []byte) {
+	header := w.Header()
+	if status == http.StatusOK && header.Get("Last-Modified") != "" {
+		etag := header.Get("ETag")
+		if etag == "" {
+			sum := sha256.Sum256(body)
+			etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+			header.Set("ETag", etag)
+		}
+
+		if notModified(r, etag, header.Get("Last-Modified")) {
+			for _, key := range []string{"Content-Type", "Content-Length"} {
+				header.Del(key)
+			}
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	// Unsupported only for writers without a connection, which is not an error
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(bufferedWriteTimeout))
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+// notModified evaluates the request's validators; If-None-Match takes precedence
+// over If-Modified-Since as in RFC 9110 section 13.2.2
+func notModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(ims)
+}
@@ -2,11 +2,16 @@ package api
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/rgonzalez12/dbd-analytics/internal/demo"
+	"github.com/rgonzalez12/dbd-analytics/internal/flags"
 	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
 )
 
 // APIConfig holds configurable parameters for API behavior
@@ -20,6 +25,7 @@ type APIConfig struct {
 	APITimeoutSecs          int `json:"api_timeout_secs"`
 	OverallTimeoutSecs      int `json:"overall_timeout_secs"`
 	AchievementsTimeoutSecs int `json:"achievements_timeout_secs"`
+	RequestTimeoutSecs      int `json:"request_timeout_secs"` // Hard per-route deadline
 
 	// Retry Configuration
 	MaxRetries    int `json:"max_retries"`
@@ -30,13 +36,68 @@ type APIConfig struct {
 	RateLimit  int `json:"rate_limit"`  // Requests per minute
 	BurstLimit int `json:"burst_limit"` // Burst capacity
 
+	// Fan-out worker pool
+	WorkerPoolSize int `json:"worker_pool_size"` // Max concurrent upstream fetch tasks
+
+	// Schema worker
+	SchemaRefreshHours int `json:"schema_refresh_hours"` // How often the adept map is rebuilt from the schema
+
+	// Startup warmup of schema, global percentages and adept map; 0 disables
+	WarmupTimeoutSecs int `json:"warmup_timeout_secs"`
+
+	// Request body limits; routes may lower MaxBodyBytes for themselves
+	MaxBodyBytes int64 `json:"max_body_bytes"`
+	MaxJSONDepth int   `json:"max_json_depth"`
+
+	// How long a combined request waits on an identical one already in flight; 0 disables
+	CoalesceMaxWaitMs int `json:"coalesce_max_wait_ms"`
+
+	// Background refresh of tracked players
+	TrackMaxPerKey      int `json:"track_max_per_key"`     // registrations per API key (or IP)
+	TrackMaxTotal       int `json:"track_max_total"`       // distinct players across all clients
+	TrackInactiveDays   int `json:"track_inactive_days"`   // untracked after this long without stat changes
+	TrackRefreshMinutes int `json:"track_refresh_minutes"` // how often each tracked player is refetched
+
+	// Named groups of players per API key (or IP); 0 for no limit
+	GroupMaxPerKey int `json:"group_max_per_key"`
+
+	// Oldest persisted snapshot served on a cache miss while a refresh runs; 0 disables
+	SnapshotMaxAgeHours int `json:"snapshot_max_age_hours"`
+
+	// Longest ?wait_for_fresh a player request may hold the connection for; 0 disables
+	MaxWaitForFreshSecs int `json:"max_wait_for_fresh_secs"`
+
+	// Achievement catalog rarity trends
+	RarityTrendDays         int `json:"rarity_trend_days"`          // default window trends are measured over
+	RaritySharpChangePoints int `json:"rarity_sharp_change_points"` // day-over-day move marked as a sharp change
+
+	// Synthetic end-to-end probe of a public test profile; an empty ID disables it
+	ProbeSteamID      string `json:"probe_steam_id"`
+	ProbeIntervalSecs int    `json:"probe_interval_secs"`
+
+	// Linked Steam accounts verified through Steam OpenID; off unless the
+	// public base URL Steam returns players to is set
+	PublicBaseURL      string `json:"public_base_url"`
+	SteamOpenIDURL     string `json:"steam_openid_url"`
+	ProfileMaxAccounts int    `json:"profile_max_accounts"` // linked accounts per profile
+
+	// Optional Features
+	IntegrityFlagsEnabled bool `json:"integrity_flags_enabled"` // Heuristic cheat-review flags
+	DemoMode              bool `json:"demo_mode"`               // Serve bundled fixtures instead of Steam
+	InventoryEnabled      bool `json:"inventory_enabled"`       // Steam Community inventory lookups
+	IconMirrorEnabled     bool `json:"icon_mirror_enabled"`     // Serve achievement icons from this host
+	SandboxEnabled        bool `json:"sandbox_enabled"`         // Canned /sandbox responses for UI development
+
 	// Computed fields for convenience
 	APITimeout          time.Duration `json:"-"`
 	OverallTimeout      time.Duration `json:"-"`
 	AchievementsTimeout time.Duration `json:"-"`
+	RequestTimeout      time.Duration `json:"-"`
 	CBResetTimeout      time.Duration `json:"-"`
 	BaseBackoff         time.Duration `json:"-"`
 	MaxBackoff          time.Duration `json:"-"`
+	CoalesceMaxWait     time.Duration `json:"-"`
+	MaxWaitForFresh     time.Duration `json:"-"`
 }
 
 // DefaultAPIConfig returns sensible production defaults
@@ -51,6 +112,7 @@ func DefaultAPIConfig() APIConfig {
 		APITimeoutSecs:          10,
 		OverallTimeoutSecs:      30,
 		AchievementsTimeoutSecs: 5,
+		RequestTimeoutSecs:      5,
 
 		// Retry - Exponential backoff with jitter
 		MaxRetries:    3,    // Up to 3 retries
@@ -60,15 +122,47 @@ func DefaultAPIConfig() APIConfig {
 		// Rate Limiting - Conservative for Steam API
 		RateLimit:  100, // 100 requests per minute
 		BurstLimit: 10,  // Allow bursts of 10
+
+		WorkerPoolSize: 64,
+
+		SchemaRefreshHours: 24,
+
+		WarmupTimeoutSecs: 20,
+
+		MaxBodyBytes: 64 << 10,
+		MaxJSONDepth: 16,
+
+		CoalesceMaxWaitMs: 2000,
+
+		TrackMaxPerKey:      25,
+		TrackMaxTotal:       1000,
+		TrackInactiveDays:   14,
+		TrackRefreshMinutes: 15,
+		GroupMaxPerKey:      10,
+
+		SnapshotMaxAgeHours: 168,
+
+		MaxWaitForFreshSecs: 30,
+
+		RarityTrendDays:         30,
+		RaritySharpChangePoints: 3,
+
+		ProbeIntervalSecs: 300,
+
+		SteamOpenIDURL:     steam.DefaultOpenIDURL,
+		ProfileMaxAccounts: 5,
 	}
 
 	// Compute derived fields
 	config.APITimeout = time.Duration(config.APITimeoutSecs) * time.Second
 	config.OverallTimeout = time.Duration(config.OverallTimeoutSecs) * time.Second
 	config.AchievementsTimeout = time.Duration(config.AchievementsTimeoutSecs) * time.Second
+	config.RequestTimeout = time.Duration(config.RequestTimeoutSecs) * time.Second
 	config.CBResetTimeout = time.Duration(config.CBResetTimeoutSecs) * time.Second
 	config.BaseBackoff = time.Duration(config.BaseBackoffMs) * time.Millisecond
 	config.MaxBackoff = time.Duration(config.MaxBackoffMs) * time.Millisecond
+	config.CoalesceMaxWait = time.Duration(config.CoalesceMaxWaitMs) * time.Millisecond
+	config.MaxWaitForFresh = time.Duration(config.MaxWaitForFreshSecs) * time.Second
 
 	return config
 }
@@ -85,6 +179,7 @@ func LoadAPIConfigFromEnv() APIConfig {
 	config.APITimeoutSecs = getEnvInt("API_TIMEOUT_SECS", config.APITimeoutSecs)
 	config.OverallTimeoutSecs = getEnvInt("OVERALL_TIMEOUT_SECS", config.OverallTimeoutSecs)
 	config.AchievementsTimeoutSecs = getEnvInt("ACHIEVEMENTS_TIMEOUT_SECS", config.AchievementsTimeoutSecs)
+	config.RequestTimeoutSecs = getEnvInt("REQUEST_TIMEOUT_SECS", config.RequestTimeoutSecs)
 
 	config.MaxRetries = getEnvInt("MAX_RETRIES", config.MaxRetries)
 	config.BaseBackoffMs = getEnvInt("BASE_BACKOFF_MS", config.BaseBackoffMs)
@@ -93,6 +188,35 @@ func LoadAPIConfigFromEnv() APIConfig {
 	config.RateLimit = getEnvInt("RATE_LIMIT_PER_MIN", config.RateLimit)
 	config.BurstLimit = getEnvInt("BURST_LIMIT", config.BurstLimit)
 
+	config.WorkerPoolSize = getEnvInt("WORKER_POOL_SIZE", config.WorkerPoolSize)
+	config.SchemaRefreshHours = getEnvInt("STEAM_SCHEMA_TTL_HOURS", config.SchemaRefreshHours)
+	config.WarmupTimeoutSecs = getEnvInt("WARMUP_TIMEOUT_SECS", config.WarmupTimeoutSecs)
+	config.MaxBodyBytes = int64(getEnvInt("MAX_BODY_BYTES", int(config.MaxBodyBytes)))
+	config.MaxJSONDepth = getEnvInt("MAX_JSON_DEPTH", config.MaxJSONDepth)
+	config.CoalesceMaxWaitMs = getEnvInt("COALESCE_MAX_WAIT_MS", config.CoalesceMaxWaitMs)
+	config.TrackMaxPerKey = getEnvInt("TRACK_MAX_PER_KEY", config.TrackMaxPerKey)
+	config.TrackMaxTotal = getEnvInt("TRACK_MAX_TOTAL", config.TrackMaxTotal)
+	config.TrackInactiveDays = getEnvInt("TRACK_INACTIVE_DAYS", config.TrackInactiveDays)
+	config.TrackRefreshMinutes = getEnvInt("TRACK_REFRESH_MINUTES", config.TrackRefreshMinutes)
+	config.GroupMaxPerKey = getEnvInt("GROUP_MAX_PER_KEY", config.GroupMaxPerKey)
+	config.SnapshotMaxAgeHours = getEnvInt("SNAPSHOT_MAX_AGE_HOURS", config.SnapshotMaxAgeHours)
+	config.MaxWaitForFreshSecs = getEnvInt("MAX_WAIT_FOR_FRESH_SECS", config.MaxWaitForFreshSecs)
+	config.RarityTrendDays = getEnvInt("RARITY_TREND_DAYS", config.RarityTrendDays)
+	config.RaritySharpChangePoints = getEnvInt("RARITY_SHARP_CHANGE_POINTS", config.RaritySharpChangePoints)
+	config.ProbeSteamID = strings.TrimSpace(os.Getenv("PROBE_STEAM_ID"))
+	config.ProbeIntervalSecs = getEnvInt("PROBE_INTERVAL_SECS", config.ProbeIntervalSecs)
+	config.PublicBaseURL = strings.TrimSuffix(strings.TrimSpace(os.Getenv("PUBLIC_BASE_URL")), "/")
+	if openIDURL := strings.TrimSpace(os.Getenv("STEAM_OPENID_URL")); openIDURL != "" {
+		config.SteamOpenIDURL = openIDURL
+	}
+	config.ProfileMaxAccounts = getEnvInt("PROFILE_MAX_ACCOUNTS", config.ProfileMaxAccounts)
+
+	config.IntegrityFlagsEnabled = getEnvBool("INTEGRITY_FLAGS_ENABLED", config.IntegrityFlagsEnabled)
+	config.DemoMode = demo.Enabled()
+	config.InventoryEnabled = getEnvBool("INVENTORY_ENABLED", config.InventoryEnabled)
+	config.IconMirrorEnabled = getEnvBool("ICON_MIRROR_ENABLED", config.IconMirrorEnabled)
+	config.SandboxEnabled = getEnvBool("SANDBOX_ENABLED", config.SandboxEnabled)
+
 	// Apply validation and fix invalid values
 	if config.CBMaxFails <= 0 {
 		config.CBMaxFails = 5
@@ -106,6 +230,9 @@ func LoadAPIConfigFromEnv() APIConfig {
 	if config.AchievementsTimeoutSecs <= 0 {
 		config.AchievementsTimeoutSecs = 5
 	}
+	if config.RequestTimeoutSecs <= 0 {
+		config.RequestTimeoutSecs = 5
+	}
 	if config.MaxRetries < 0 {
 		config.MaxRetries = 3
 	}
@@ -118,15 +245,89 @@ func LoadAPIConfigFromEnv() APIConfig {
 	if config.RateLimit <= 0 {
 		config.RateLimit = 100
 	}
+	if config.WorkerPoolSize <= 0 {
+		config.WorkerPoolSize = 64
+	}
+	if config.SchemaRefreshHours <= 0 {
+		config.SchemaRefreshHours = 24
+	}
+	if config.WarmupTimeoutSecs < 0 {
+		config.WarmupTimeoutSecs = 0
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = 64 << 10
+	}
+	if config.MaxJSONDepth < 0 {
+		config.MaxJSONDepth = 0
+	}
+	if config.CoalesceMaxWaitMs < 0 {
+		config.CoalesceMaxWaitMs = 0
+	}
+	if config.TrackMaxPerKey < 0 {
+		config.TrackMaxPerKey = 25
+	}
+	if config.TrackMaxTotal < 0 {
+		config.TrackMaxTotal = 1000
+	}
+	if config.TrackInactiveDays < 0 {
+		config.TrackInactiveDays = 0
+	}
+	if config.TrackRefreshMinutes <= 0 {
+		config.TrackRefreshMinutes = 15
+	}
+	if config.GroupMaxPerKey < 0 {
+		config.GroupMaxPerKey = 10
+	}
+	if config.SnapshotMaxAgeHours < 0 {
+		config.SnapshotMaxAgeHours = 0
+	}
+	if config.MaxWaitForFreshSecs < 0 {
+		config.MaxWaitForFreshSecs = 0
+	}
+	if config.RarityTrendDays <= 0 {
+		config.RarityTrendDays = 30
+	}
+	if config.RaritySharpChangePoints <= 0 {
+		config.RaritySharpChangePoints = 3
+	}
+	if config.ProbeIntervalSecs < 30 {
+		config.ProbeIntervalSecs = 300 // faster probing spends quota for little extra signal
+	}
+	if config.PublicBaseURL != "" && !absoluteHTTPURL(config.PublicBaseURL) {
+		log.Warn("Ignoring PUBLIC_BASE_URL; account linking needs an absolute http(s) URL", "public_base_url", config.PublicBaseURL)
+		config.PublicBaseURL = ""
+	}
+	if !absoluteHTTPURL(config.SteamOpenIDURL) {
+		log.Warn("Ignoring STEAM_OPENID_URL; expected an absolute http(s) URL", "steam_openid_url", config.SteamOpenIDURL)
+		config.SteamOpenIDURL = steam.DefaultOpenIDURL
+	}
+	if config.ProfileMaxAccounts < 2 {
+		config.ProfileMaxAccounts = 5 // a profile exists to hold more than one account
+	}
 
 	// Compute derived fields
 	config.APITimeout = time.Duration(config.APITimeoutSecs) * time.Second
 	config.OverallTimeout = time.Duration(config.OverallTimeoutSecs) * time.Second
 	config.AchievementsTimeout = time.Duration(config.AchievementsTimeoutSecs) * time.Second
+	config.RequestTimeout = time.Duration(config.RequestTimeoutSecs) * time.Second
 	config.CBResetTimeout = time.Duration(config.CBResetTimeoutSecs) * time.Second
 	config.BaseBackoff = time.Duration(config.BaseBackoffMs) * time.Millisecond
 	config.MaxBackoff = time.Duration(config.MaxBackoffMs) * time.Millisecond
+	config.CoalesceMaxWait = time.Duration(config.CoalesceMaxWaitMs) * time.Millisecond
+	config.MaxWaitForFresh = time.Duration(config.MaxWaitForFreshSecs) * time.Second
+
+	return config
+}
 
+// LoadAllConfigFromEnv reads every handler setting NewHandler reads without
+// starting anything, so invalid values are logged exactly as at startup. The
+// cache and stores are opened separately by whoever checks them.
+func LoadAllConfigFromEnv() APIConfig {
+	config := LoadAPIConfigFromEnv()
+	steam.ConfigureAppsFromEnv()
+	steam.QuotaConfigFromEnv()
+	loadAdminCredentials()
+	flags.FromEnv().Close()
 	return config
 }
 
@@ -147,6 +348,29 @@ func getEnvInt(envKey string, fallback int) int {
 	return fallback
 }
 
+// getEnvBool safely parses a boolean from environment variable with fallback
+func getEnvBool(envKey string, fallback bool) bool {
+	if value := os.Getenv(envKey); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			log.Debug("Configuration loaded from environment",
+				"env_key", envKey,
+				"value", parsed)
+			return parsed
+		}
+		log.Warn("Invalid boolean in environment variable, using fallback",
+			"env_key", envKey,
+			"value", value,
+			"fallback", fallback)
+	}
+	return fallback
+}
+
+// absoluteHTTPURL reports whether raw is an http or https URL with a host
+func absoluteHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
 // Validate performs basic validation on configuration values
 func (c *APIConfig) Validate() error {
 	if c.CBMaxFails <= 0 {
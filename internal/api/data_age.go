@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+// cachedFetchTime returns when key's entry was written, which is when its
+// data came from Steam, or fallback when the entry has gone since
+func cachedFetchTime[T any](c cache.Cache, key cache.Key[T], fallback time.Time) time.Time {
+	if storedAt, ok := cache.StoredAt(c, key); ok {
+		return storedAt
+	}
+	return fallback
+}
+
+// dataAsOf is when the oldest data in a response was fetched: the earliest
+// FetchedAt among the sources that succeeded
+func dataAsOf(sources models.DataSourceStatus) time.Time {
+	var oldest time.Time
+	for _, source := range []models.DataSourceInfo{sources.Stats, sources.Achievements, sources.StructuredStats} {
+		if !source.Success || source.FetchedAt.IsZero() {
+			continue
+		}
+		if oldest.IsZero() || source.FetchedAt.Before(oldest) {
+			oldest = source.FetchedAt
+		}
+	}
+	return oldest
+}
+
+// setDataAge sets the Age header to the whole seconds since asOf and returns
+// them for the body's data_age_seconds
+func setDataAge(w http.ResponseWriter, asOf, now time.Time) int64 {
+	if asOf.IsZero() {
+		return 0
+	}
+	age := int64(now.Sub(asOf).Seconds())
+	if age < 0 {
+		age = 0
+	}
+	w.Header().Set("Age", strconv.FormatInt(age, 10))
+	return age
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// includeEndedEvents reports whether the request asked for the stats of
+// events that are over with ?include=events
+func includeEndedEvents(r *http.Request) bool {
+	return newQueryParams(r).set("include", playerIncludes)["events"]
+}
+
+// groupEventStats moves the stats of app's limited-time events out of data
+// into one group per event, ordered by their first stat. Groups for events
+// that ended before now are dropped unless includeEnded. data may be shared
+// with the cache and is never modified.
+func groupEventStats(app *steam.App, data *models.StatsData, includeEnded bool, now time.Time) (*models.StatsData, []models.EventStats) {
+	if data == nil || len(app.Events) == 0 {
+		return data, nil
+	}
+
+	var (
+		kept    []interface{}
+		groups  []models.EventStats
+		indexes = map[string]int{}
+	)
+	for i, raw := range data.Stats {
+		event, ok := app.StatEventFor(rawStatID(raw))
+		if !ok {
+			if kept != nil {
+				kept = append(kept, raw)
+			}
+			continue
+		}
+		if kept == nil {
+			kept = append(make([]interface{}, 0, len(data.Stats)), data.Stats[:i]...)
+		}
+		if event.Ended(now) && !includeEnded {
+			continue
+		}
+		index, seen := indexes[event.ID]
+		if !seen {
+			index = len(groups)
+			indexes[event.ID] = index
+			groups = append(groups, eventGroup(event, now))
+		}
+		groups[index].Stats = append(groups[index].Stats, raw)
+	}
+	if kept == nil {
+		return data, nil
+	}
+	return &models.StatsData{Stats: kept, Summary: data.Summary}, groups
+}
+
+func eventGroup(event steam.StatEvent, now time.Time) models.EventStats {
+	group := models.EventStats{
+		ID:     event.ID,
+		Name:   event.Name,
+		Starts: event.Starts,
+		Active: !event.Ended(now) && !now.Before(event.Starts),
+	}
+	if !event.Ends.IsZero() {
+		ends := event.Ends
+		group.Ends = &ends
+	}
+	return group
+}
+
+// rawStatID is the ID of a structured stat held as a steam.Stat or, when
+// decoded from fixtures or snapshots, a generic map
+func rawStatID(raw interface{}) string {
+	switch stat := raw.(type) {
+	case steam.Stat:
+		return stat.ID
+	case map[string]interface{}:
+		id, _ := stat["id"].(string)
+		return id
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/flags"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+// flagEnabled evaluates a rollout flag for the calling client. API keys get
+// their overrides and a stable bucket; anonymous clients are bucketed by IP.
+func (h *Handler) flagEnabled(r *http.Request, name string) bool {
+	apiKey := r.Header.Get("X-API-Key")
+	clientID := apiKey
+	if clientID == "" {
+		clientID = getClientIP(r)
+	}
+	return h.flags.Enabled(name, apiKey, clientID)
+}
+
+func writeFeatureDisabled(w http.ResponseWriter, r *http.Request, name string) {
+	writeError(w, r, "FEATURE_DISABLED",
+		"This feature is not currently enabled for your client",
+		http.StatusNotFound,
+		map[string]interface{}{"feature": name},
+		nil)
+}
+
+// applyResponseFlags shapes a player response for the calling client. Flagged
+// blocks are applied at serve time rather than stored in the cache, so a flag
+// change takes effect on the next request instead of after the cached TTL.
+// Achievement data may be shared with the cache and is copied before editing.
+func (h *Handler) applyResponseFlags(r *http.Request, response models.PlayerStatsWithAchievements) models.PlayerStatsWithAchievements {
+	if h.flagEnabled(r, flags.Analytics) {
+		if response.Analytics == nil {
+			response.Analytics = buildAnalytics(response.PlayerStats, response.Stats)
+		}
+	} else {
+		response.Analytics = nil
+	}
+
+	if !h.flagEnabled(r, flags.Percentiles) && response.Achievements != nil && len(response.Achievements.MappedAchievements) > 0 {
+		stripped := *response.Achievements
+		stripped.MappedAchievements = make([]models.MappedAchievement, len(response.Achievements.MappedAchievements))
+		for i, ach := range response.Achievements.MappedAchievements {
+			ach.Rarity = 0
+			stripped.MappedAchievements[i] = ach
+		}
+		response.Achievements = &stripped
+	}
+
+	return response
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// GetGradeContext summarizes the killer and survivor grades of players this
+// service has fetched, from each one's latest snapshot. season=YYYY-MM (or
+// "current") keeps only snapshots taken in that grade season, since grades
+// reset monthly; scope=tracked keeps only players registered for refreshes.
+func (h *Handler) GetGradeContext(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	params := newQueryParams(r)
+	scope := params.enum("scope", []string{"all", "tracked"}, "all")
+	season := params.string("season")
+	if season == "current" {
+		season = models.GradeSeason(time.Now())
+	}
+	var from, until time.Time
+	if season != "" {
+		var err error
+		if from, until, err = models.GradeSeasonBounds(season); err != nil {
+			params.fail("season", "season must be YYYY-MM or current", nil)
+		}
+	}
+	if err := params.err(); err != nil {
+		writeParamErrors(w, r, err)
+		return
+	}
+
+	names := steam.GradeNames()
+	rankOf := make(map[string]int, len(names))
+	for rank, name := range names {
+		rankOf[name] = rank
+	}
+	killer := make([]int, len(names))
+	survivor := make([]int, len(names))
+
+	players := 0
+	for steamID, snapshot := range h.players.LastSnapshots() {
+		if season != "" && (snapshot.TakenAt.Before(from) || !snapshot.TakenAt.Before(until)) {
+			continue
+		}
+		if scope == "tracked" && !h.tracked.Has(steamID) {
+			continue
+		}
+		counted := false
+		if rank, ok := rankOf[snapshot.KillerGrade]; ok {
+			killer[rank]++
+			counted = true
+		}
+		if rank, ok := rankOf[snapshot.SurvivorGrade]; ok {
+			survivor[rank]++
+			counted = true
+		}
+		if counted {
+			players++
+		}
+	}
+
+	response := models.GradeContext{
+		Season:      season,
+		Scope:       scope,
+		Players:     players,
+		Killer:      gradeDistribution(names, killer),
+		Survivor:    gradeDistribution(names, survivor),
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	log.Info("Grade context computed",
+		"season", season,
+		"scope", scope,
+		"players", players,
+		"duration", time.Since(start))
+
+	streamJSONResponse(w, r, response)
+}
+
+// gradeDistribution turns per-rank counts into buckets with shares
+func gradeDistribution(names []string, counts []int) models.GradeDistribution {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	distribution := models.GradeDistribution{
+		Total:  total,
+		Grades: make([]models.GradeBucket, len(names)),
+	}
+	below := 0
+	for rank, count := range counts {
+		distribution.Grades[rank] = models.GradeBucket{
+			Grade:        names[rank],
+			Rank:         rank,
+			Count:        count,
+			Percent:      sharePercent(count, total),
+			BelowPercent: sharePercent(below, total),
+		}
+		// The median is the grade holding the middle player
+		if total > 0 && distribution.Median == "" && 2*(below+count) > total {
+			distribution.Median = names[rank]
+		}
+		below += count
+	}
+	return distribution
+}
+
+// sharePercent is part/total as a percentage to one decimal place
+func sharePercent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*1000) / 10
+}
@@ -0,0 +1,274 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+	"github.com/rgonzalez12/dbd-analytics/internal/store"
+)
+
+const (
+	minGroupSize      = 2
+	maxGroupSize      = 25
+	maxGroupNameRunes = 64
+	maxGroupBodySize  = 8 << 10 // enforced by the route's body limit
+)
+
+type createGroupRequest struct {
+	Name     string   `json:"name"`
+	SteamIDs []string `json:"steam_ids"`
+}
+
+// CreateGroup registers a named group of players. Body: {"name": "...",
+// "steam_ids": [...]}, each a SteamID64, vanity name or profile URL. Members
+// are tracked for the caller so the group's stats stay warm; deleting the
+// group leaves those registrations in place.
+func (h *Handler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var body createGroupRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		writeValidationError(w, r, "Request body must be JSON of the form {\"name\": \"...\", \"steam_ids\": [...]}", "body")
+		return
+	}
+	name := strings.TrimSpace(body.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxGroupNameRunes {
+		writeValidationError(w, r, fmt.Sprintf("name must be between 1 and %d characters", maxGroupNameRunes), "name")
+		return
+	}
+	if len(body.SteamIDs) < minGroupSize || len(body.SteamIDs) > maxGroupSize {
+		writeValidationError(w, r, fmt.Sprintf("steam_ids must list between %d and %d players", minGroupSize, maxGroupSize), "steam_ids")
+		return
+	}
+
+	ids := make([]string, len(body.SteamIDs))
+	idTypes := make([]steam.IDType, len(body.SteamIDs))
+	for i, raw := range body.SteamIDs {
+		id, idType, _, err := parsePlayerID(strings.TrimSpace(raw), steam.IDTypeAuto)
+		if err != nil {
+			writeValidationError(w, r, err.Message, fmt.Sprintf("steam_ids[%d]", i))
+			return
+		}
+		ids[i], idTypes[i] = id, idType
+	}
+
+	// Resolve vanity names so one player is one member however they were addressed
+	resolved := make([]string, len(ids))
+	resolveErrs := make([]*steam.APIError, len(ids))
+	group := h.workers.Group(ctx)
+	for i := range ids {
+		group.Go(fmt.Sprintf("resolve_%d", i), func(taskCtx context.Context) error {
+			resolved[i], _, resolveErrs[i] = h.steamClient.ResolveSteamIDAs(taskCtx, ids[i], idTypes[i])
+			if resolveErrs[i] != nil {
+				return resolveErrs[i]
+			}
+			return nil
+		})
+	}
+	if group.Wait(); ctx.Err() != nil {
+		writeTimeoutError(w, r, "create_group")
+		return
+	}
+
+	seen := make(map[string]int, len(resolved))
+	for i, id := range resolved {
+		if resolveErrs[i] != nil {
+			writeErrorResponse(w, resolveErrs[i])
+			return
+		}
+		if first, dup := seen[id]; dup {
+			writeValidationError(w, r, fmt.Sprintf("steam_ids[%d] and steam_ids[%d] are the same player", first, i), "steam_ids")
+			return
+		}
+		seen[id] = i
+	}
+
+	owner := trackOwner(r)
+	var newlyTracked []string
+	rollback := func() {
+		for _, steamID := range newlyTracked {
+			h.tracked.Untrack(owner, steamID)
+		}
+	}
+	for _, steamID := range resolved {
+		_, created, err := h.tracked.Track(owner, steamID)
+		if err != nil {
+			rollback()
+			scope, limit := "client", h.config.TrackMaxPerKey
+			if errors.Is(err, store.ErrGlobalTrackLimit) {
+				scope, limit = "service", h.config.TrackMaxTotal
+			}
+			writeError(w, r, "TRACK_LIMIT_REACHED", err.Error(), http.StatusForbidden,
+				map[string]interface{}{"scope": scope, "limit": limit, "steam_id": steamID},
+				nil)
+			return
+		}
+		if created {
+			newlyTracked = append(newlyTracked, steamID)
+		}
+	}
+
+	created, err := h.groups.Create(owner, name, resolved)
+	if err != nil {
+		rollback()
+		writeError(w, r, "GROUP_LIMIT_REACHED", err.Error(), http.StatusForbidden,
+			map[string]interface{}{"limit": h.config.GroupMaxPerKey},
+			nil)
+		return
+	}
+
+	log.Info("Group created", "group_id", created.ID, "members", len(created.Members), "newly_tracked", len(newlyTracked))
+	writeJSONResponseWithStatus(w, created, http.StatusCreated)
+}
+
+// ListGroups returns the caller's groups, newest first
+func (h *Handler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	groups := h.groups.List(trackOwner(r))
+	writeJSONResponse(w, map[string]interface{}{
+		"groups": groups,
+		"count":  len(groups),
+		"limits": map[string]interface{}{
+			"per_key":     h.config.GroupMaxPerKey,
+			"min_members": minGroupSize,
+			"max_members": maxGroupSize,
+		},
+	})
+}
+
+// GetGroup returns a group's definition; anyone holding the ID may read it
+func (h *Handler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.groupFromRequest(w, r)
+	if !ok {
+		return
+	}
+	writeJSONResponse(w, group)
+}
+
+// DeleteGroup removes one of the caller's groups
+func (h *Handler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if !h.groups.Delete(trackOwner(r), id) {
+		writeError(w, r, "GROUP_NOT_FOUND", "Your client has no group with this ID", http.StatusNotFound,
+			map[string]interface{}{"group_id": id},
+			nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetGroupStats is a group's team dashboard: every member's headline stats,
+// pooled totals and a leaderboard per metric. A member whose stats can't be
+// fetched is listed as unavailable with a warning rather than failing the group.
+func (h *Handler) GetGroupStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	start := time.Now()
+
+	stored, ok := h.groupFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	type memberResult struct {
+		stats         models.PlayerStats
+		statsErr      error
+		achievements  *models.AchievementData
+		achErr        error
+		structured    *models.StatsData
+		structuredErr error
+	}
+	results := make([]memberResult, len(stored.Members))
+	group := h.workers.Group(ctx)
+	for i, steamID := range stored.Members {
+		group.Go(fmt.Sprintf("stats_%d", i), func(taskCtx context.Context) error {
+			results[i].stats, _, results[i].statsErr = h.fetchPlayerStatsWithSource(taskCtx, steamID)
+			return results[i].statsErr
+		})
+		group.Go(fmt.Sprintf("achievements_%d", i), func(taskCtx context.Context) error {
+			results[i].achievements, _, results[i].achErr = h.fetchPlayerAchievementsWithSource(taskCtx, steamID)
+			return results[i].achErr
+		})
+		group.Go(fmt.Sprintf("structured_%d", i), func(taskCtx context.Context) error {
+			results[i].structured, _, results[i].structuredErr = h.fetchPlayerStructuredStatsWithSource(taskCtx, steamID)
+			return results[i].structuredErr
+		})
+	}
+	if err := group.Wait(); ctx.Err() != nil {
+		writeTimeoutError(w, r, "group_stats")
+		return
+	} else if err != nil {
+		log.Debug("Group fetch completed with source errors", "group_id", stored.ID, "errors", err.Error())
+	}
+
+	now := time.Now().UTC()
+	members := make([]steam.GroupMemberData, len(results))
+	var warnings []string
+	for i, result := range results {
+		steamID := stored.Members[i]
+		members[i].SteamID = steamID
+		if result.statsErr != nil {
+			warnings = append(warnings, fmt.Sprintf("Stats unavailable for %s: %s", steamID, classifyError(result.statsErr)))
+			continue
+		}
+		members[i].Stats = &results[i].stats
+		members[i].Achievements = result.achievements
+		if result.achErr != nil {
+			warnings = append(warnings, fmt.Sprintf("Achievements unavailable for %s: %s", steamID, classifyError(result.achErr)))
+		}
+		if result.structuredErr != nil {
+			warnings = append(warnings, fmt.Sprintf("Grades unavailable for %s: %s", steamID, classifyError(result.structuredErr)))
+			continue
+		}
+		grades := currentGrades(result.structured, now)
+		if grades.Killer != nil {
+			members[i].KillerGrade = grades.Killer.Grade
+		}
+		if grades.Survivor != nil {
+			members[i].SurvivorGrade = grades.Survivor.Grade
+		}
+	}
+
+	stats := steam.BuildGroupStats(members)
+	stats.ID, stats.Name = stored.ID, stored.Name
+	stats.Warnings = warnings
+
+	if stats.Aggregate.AvailableMembers == 0 {
+		writeError(w, r, "GROUP_STATS_UNAVAILABLE", "No member's stats could be fetched", http.StatusBadGateway,
+			map[string]interface{}{"group_id": stored.ID, "warnings": warnings},
+			nil)
+		return
+	}
+
+	log.Info("Group stats generated",
+		"group_id", stored.ID,
+		"members", len(stats.Members),
+		"available", stats.Aggregate.AvailableMembers,
+		"warnings", len(warnings),
+		"duration", time.Since(start))
+
+	streamJSONResponse(w, r, stats)
+}
+
+// groupFromRequest looks up {id}, answering 404 when there is no such group
+func (h *Handler) groupFromRequest(w http.ResponseWriter, r *http.Request) (store.Group, bool) {
+	id := mux.Vars(r)["id"]
+	group, ok := h.groups.Get(id)
+	if !ok {
+		writeError(w, r, "GROUP_NOT_FOUND", "No group with this ID", http.StatusNotFound,
+			map[string]interface{}{"group_id": id},
+			nil)
+		return store.Group{}, false
+	}
+	return group, true
+}
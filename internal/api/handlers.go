@@ -3,18 +3,31 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/rgonzalez12/dbd-analytics/internal/buildinfo"
 	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/demo"
+	"github.com/rgonzalez12/dbd-analytics/internal/flags"
 	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
 	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/notify"
+	"github.com/rgonzalez12/dbd-analytics/internal/pool"
 	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+	"github.com/rgonzalez12/dbd-analytics/internal/store"
 )
 
 const (
@@ -29,19 +42,80 @@ var (
 )
 
 type Handler struct {
-	steamClient  *steam.Client
-	cacheManager *cache.Manager
+	steamClient *steam.Client
+	cacheMgr    atomic.Pointer[cache.Manager] // nil until a cache is up; see cacheManager()
+	config      APIConfig
+	workers     *pool.Pool
+
+	stopSchemaWorker func()
+	notifier         *notify.Notifier  // nil when no notification channels are configured
+	players          *store.Players    // previously-served players, for name search
+	icons            *steam.IconMirror // nil unless ICON_MIRROR_ENABLED
+	flags            *flags.Set        // progressive rollout of heavy response blocks
+	routes           []capabilityRoute // registered endpoints, filled in by RegisterRoutes
+	linkCallback     string            // path Steam returns players linking an account to, filled in by RegisterRoutes
+	adminCredentials []adminCredential // X-Admin-Token values and their scopes, filled in by RegisterRoutes
+	capture          *supportCapture   // admin-armed request recording, filled in by RegisterRoutes
+	combined         *coalescer        // shares in-flight combined responses per player
+	tracked          *store.Tracked    // players refreshed in the background
+	groups           *store.Groups     // named sets of players with team dashboards
+	profiles         *store.Profiles   // linked Steam accounts, verified through Steam OpenID
+	snapshots        *store.Snapshots  // last full response per tracked player, for cold starts
+	achRetries       *achievementRetries
+	stopTracking     func()
+	trackPace        trackPacer // refresher back-off while Steam is degraded
+	cacheInit        cacheInitState
+	stopCacheRetry   func() // nil unless cache initialization is being retried
+
+	rarity     *store.RarityHistory // daily global achievement percentages, for rarity trends
+	stopRarity func()
+
+	counters cache.CounterStore // nil unless rate limits and the Steam quota are shared
+	limiter  *RequestLimiter    // the per-client rate limiter, set by RegisterRoutes
+	prober   *prober            // nil unless PROBE_STEAM_ID is set
 }
 
 func NewHandler() *Handler {
+	config := LoadAPIConfigFromEnv()
+	steam.ConfigureAppsFromEnv()
+	workers := pool.New(pool.Config{
+		Size:        config.WorkerPoolSize,
+		TaskTimeout: SteamAPITimeout,
+	})
+	// Schema and percentage fetches made while mapping count against the same quota
+	steamClient := steam.NewClient()
+	steam.UseClient(steamClient)
+
 	cacheManager, err := cache.NewManager(cache.PlayerStatsConfig())
 	if err != nil {
 		log.Error("Failed to initialize cache manager, proceeding without cache",
 			"error", err,
-			"fallback", "direct_steam_api_calls")
-		return &Handler{
-			steamClient: steam.NewClient(),
+			"fallback", "direct_steam_api_calls",
+			"retry", "background")
+		h := &Handler{
+			steamClient: steamClient,
+			config:      config,
+			workers:     workers,
+			players:     store.PlayersFromEnv(),
+			flags:       flags.FromEnv(),
+			combined:    newCoalescer(config.CoalesceMaxWait),
+			tracked:     newTracked(config),
+			groups:      store.NewGroups(config.GroupMaxPerKey),
+			profiles:    store.ProfilesFromEnv(config.ProfileMaxAccounts, profileLinkTTL),
+			snapshots:   store.SnapshotsFromEnv(),
+			rarity:      store.RarityHistoryFromEnv(),
+			achRetries:  newAchievementRetries(),
 		}
+		h.shareCounters()
+		h.startIconMirror()
+		h.warmup(nil)
+		h.startSchemaWorker(nil)
+		h.startNotifier()
+		h.startTrackRefresher()
+		h.startRarityRecorder()
+		h.startProber()
+		h.retryCacheInit(err)
+		return h
 	}
 
 	log.Info("API handler initialized with caching enabled",
@@ -49,10 +123,78 @@ func NewHandler() *Handler {
 		"max_entries", cacheManager.GetConfig().Memory.MaxEntries,
 		"default_ttl", cacheManager.GetConfig().Memory.DefaultTTL)
 
-	return &Handler{
-		steamClient:  steam.NewClient(),
-		cacheManager: cacheManager,
+	h := &Handler{
+		steamClient: steamClient,
+		config:      config,
+		workers:     workers,
+		players:     store.PlayersFromEnv(),
+		flags:       flags.FromEnv(),
+		combined:    newCoalescer(config.CoalesceMaxWait),
+		tracked:     newTracked(config),
+		groups:      store.NewGroups(config.GroupMaxPerKey),
+		profiles:    store.ProfilesFromEnv(config.ProfileMaxAccounts, profileLinkTTL),
+		snapshots:   store.SnapshotsFromEnv(),
+		rarity:      store.RarityHistoryFromEnv(),
+		achRetries:  newAchievementRetries(),
+	}
+	h.installCacheManager(cacheManager)
+	h.shareCounters()
+	h.startIconMirror()
+	h.warmup(cacheManager.GetCache())
+	h.startSchemaWorker(cacheManager.GetCache())
+	h.startNotifier()
+	h.startTrackRefresher()
+	h.startRarityRecorder()
+	h.startProber()
+	return h
+}
+
+// shareCounters moves the Steam quota, and the rate limiter RegisterRoutes
+// builds, onto cluster-wide counters when SHARED_COUNTERS_BACKEND is set
+func (h *Handler) shareCounters() {
+	config := cache.GetCounterConfigFromEnv()
+	counters, err := cache.NewCounterStore(config)
+	if err != nil {
+		log.Warn("Shared counters disabled, limits stay per instance", "error", err.Error())
+		return
+	}
+	if counters == nil {
+		return
+	}
+	h.counters = counters
+	h.steamClient.Quota().ShareCounts(counters)
+	log.Info("Rate limits and Steam quota shared across replicas", "backend", config.Backend, "addr", config.RedisAddr)
+}
+
+// startNotifier installs the process-wide notifier when any channel is configured
+func (h *Handler) startNotifier() {
+	channels := notify.ChannelsFromEnv()
+	if len(channels) == 0 {
+		return
+	}
+	h.notifier = notify.New(notify.GetConfigFromEnv(), channels...)
+	notify.SetDefault(h.notifier)
+	log.Info("Notifications enabled", "channels", h.notifier.Channels())
+}
+
+// warmup prefetches shared Steam data before the first request arrives so it
+// doesn't pay for the schema and percentages fetches; failures are non-fatal
+func (h *Handler) warmup(c cache.Cache) {
+	if h.config.DemoMode || h.config.WarmupTimeoutSecs == 0 {
+		return
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(h.config.WarmupTimeoutSecs)*time.Second)
+	defer cancel()
+	h.steamClient.Warmup(ctx, c)
+}
+
+// startSchemaWorker keeps the memoized adept map current; demo mode never talks to Steam
+func (h *Handler) startSchemaWorker(c cache.Cache) {
+	if h.config.DemoMode {
+		return
+	}
+	interval := time.Duration(h.config.SchemaRefreshHours) * time.Hour
+	h.stopSchemaWorker = h.steamClient.StartSchemaWorker(interval, c)
 }
 
 func convertToPlayerStats(dbdStats steam.DBDPlayerStats, avatar string) models.PlayerStats {
@@ -118,7 +260,9 @@ func isValidVanityURL(vanity string) bool {
 	return vanityURLRegex.MatchString(vanity)
 }
 
-func validateSteamIDOrVanity(input string) *steam.APIError {
+// validateSteamIDOrVanity checks a player identifier against how it will be interpreted.
+// In auto mode an all-digit value is accepted as a vanity URL when it is not a valid SteamID64.
+func validateSteamIDOrVanity(input string, idType steam.IDType) *steam.APIError {
 	if input == "" {
 		return steam.NewValidationError("Steam ID or vanity URL required")
 	}
@@ -127,27 +271,138 @@ func validateSteamIDOrVanity(input string) *steam.APIError {
 		return steam.NewValidationError("Input too long. Steam ID must be 17 digits or vanity URL 3-32 characters")
 	}
 
-	if len(input) >= 7 && input[:7] == "7656119" {
+	switch idType {
+	case steam.IDTypeSteamID:
 		if !validateSteamID(input) {
 			return steam.NewValidationError("Invalid Steam ID format. Must be 17 digits starting with 7656119")
 		}
 		return nil
+	case steam.IDTypeVanity:
+		if !isValidVanityURL(input) {
+			return steam.NewValidationError("Invalid vanity URL format. Must be 3-32 characters, alphanumeric with underscore/hyphen only")
+		}
+		return nil
+	}
+
+	if validateSteamID(input) || isValidVanityURL(input) {
+		return nil
 	}
 
 	if digitOnlyRegex.MatchString(input) {
 		return steam.NewValidationError("Invalid Steam ID format. Must be 17 digits starting with 7656119")
 	}
+	return steam.NewValidationError("Invalid vanity URL format. Must be 3-32 characters, alphanumeric with underscore/hyphen only")
+}
+
+// playerIncludes are the optional blocks ?include= adds to a combined response
+var playerIncludes = []string{"timing", "events"}
 
-	if !isValidVanityURL(input) {
-		return steam.NewValidationError("Invalid vanity URL format. Must be 3-32 characters, alphanumeric with underscore/hyphen only")
+// playerOptions are the query parameters of the combined player endpoint
+type playerOptions struct {
+	localeOptions
+	WaitForFresh time.Duration // long-poll a refresh for up to this long; capped to maxWait
+	Timing       bool          // ?include=timing: report where the request's time went
+}
+
+func playerOptionsFromRequest(r *http.Request, maxWait time.Duration) (playerOptions, error) {
+	params := newQueryParams(r)
+	opts := playerOptions{
+		localeOptions: params.localeOptions(),
+		WaitForFresh:  params.duration("wait_for_fresh"),
+		Timing:        params.set("include", playerIncludes)["timing"],
+	}
+	if opts.WaitForFresh > maxWait {
+		opts.WaitForFresh = maxWait
+	}
+	if params.string("wait_for_fresh") != "" {
+		switch {
+		case maxWait <= 0:
+			params.fail("wait_for_fresh", "wait_for_fresh is disabled on this deployment", nil)
+		case cache.PolicyFromContext(r.Context()) != cache.PolicyDefault:
+			params.fail("wait_for_fresh", "wait_for_fresh cannot be combined with cache= or fresh=true", nil)
+		case !steam.AppFromContext(r.Context()).IsDBD():
+			params.fail("wait_for_fresh", "wait_for_fresh is only available for Dead by Daylight", nil)
+		}
 	}
+	return opts, params.err()
+}
 
-	return nil
+// playerIDFromRequest reads the {steamid} path value and its ?id_type interpretation,
+// returning the name of the offending parameter alongside any validation error.
+// A full profile URL (percent-encoded so it stays one path segment) is reduced to
+// its vanity name or SteamID64, which also fixes how it is interpreted.
+func playerIDFromRequest(r *http.Request) (string, steam.IDType, string, *steam.APIError) {
+	steamID := mux.Vars(r)["steamid"]
+	if unescaped, err := url.PathUnescape(steamID); err == nil {
+		steamID = unescaped
+	}
+
+	idType, ok := steam.ParseIDType(r.URL.Query().Get("id_type"))
+	if !ok {
+		return steamID, "", "id_type", steam.NewValidationError("id_type must be one of: auto, steamid, vanity")
+	}
+
+	return parsePlayerID(steamID, idType)
+}
+
+// parsePlayerID reduces a pasted profile URL to its identifier and validates the
+// result under idType, naming the offending field on failure
+func parsePlayerID(steamID string, idType steam.IDType) (string, steam.IDType, string, *steam.APIError) {
+	if id, urlType, isURL := steam.ParseProfileURL(steamID); isURL {
+		if id == "" {
+			return steamID, idType, "steam_id", steam.NewValidationError("Unrecognized Steam profile URL. Expected steamcommunity.com/id/<name> or steamcommunity.com/profiles/<steamid64>")
+		}
+		if idType != steam.IDTypeAuto && idType != urlType {
+			return id, idType, "id_type", steam.NewValidationError("id_type conflicts with the profile URL, which identifies a " + string(urlType))
+		}
+		steamID, idType = id, urlType
+	}
+
+	if err := validateSteamIDOrVanity(steamID, idType); err != nil {
+		return steamID, idType, "steam_id", err
+	}
+	return steamID, idType, "", nil
+}
+
+// Drain is the first step of shutdown, taken before in-flight requests are
+// waited on: the cache keeps serving reads but skips writes
+func (h *Handler) Drain() {
+	if h.cacheManager() != nil {
+		h.cacheManager().Drain()
+	}
 }
 
 func (h *Handler) Close() error {
-	if h.cacheManager != nil {
-		return h.cacheManager.Close()
+	// Stop retrying first so the schema worker isn't swapped while we stop it
+	if h.stopCacheRetry != nil {
+		h.stopCacheRetry()
+	}
+	if h.stopSchemaWorker != nil {
+		h.stopSchemaWorker()
+	}
+	if h.stopTracking != nil {
+		h.stopTracking()
+	}
+	if h.stopRarity != nil {
+		h.stopRarity()
+	}
+	if h.prober != nil {
+		h.prober.stop()
+	}
+	h.achRetries.stop()
+	h.steamClient.Close()
+	if h.notifier != nil {
+		h.notifier.Close()
+	}
+	h.flags.Close()
+	if h.counters != nil {
+		h.counters.Close()
+	}
+	if err := h.players.Close(); err != nil {
+		log.Warn("Failed to flush player store on shutdown", "error", err)
+	}
+	if h.cacheManager() != nil {
+		return h.cacheManager().Close()
 	}
 	return nil
 }
@@ -282,6 +537,7 @@ func writeJSONResponseWithStatus(w http.ResponseWriter, data interface{}, status
 	}
 
 	w.WriteHeader(statusCode)
+	bufferedBytes.Add(int64(len(responseBytes)))
 
 	log.Info("successful_response_sent",
 		"status_code", statusCode,
@@ -317,24 +573,53 @@ func writePartialDataResponse(w http.ResponseWriter, data interface{}, warnings
 }
 
 func (h *Handler) GetPlayerStatsWithAchievements(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), DefaultRequestTimeout)
-	defer cancel()
+	// The route's timeout wrapper owns the hard deadline; phases below observe it
+	ctx := r.Context()
 
 	start := time.Now()
-	steamID := mux.Vars(r)["steamid"]
+	steamID, idType, invalidField, validationErr := playerIDFromRequest(r)
 
 	requestLogger := log.HTTPRequestContext(r.Method, r.URL.Path, steamID, r.RemoteAddr)
 
-	if err := validateSteamIDOrVanity(steamID); err != nil {
+	if err := validationErr; err != nil {
 		log.ErrorContext(string(err.Type), steamID).Warn("Invalid Steam ID format in GetPlayerStatsWithAchievements",
 			"user_agent", r.UserAgent(),
 			"error_message", err.Message,
 			"validation_type", string(err.Type))
-		writeValidationError(w, r, err.Message, "steam_id")
+		writeValidationError(w, r, err.Message, invalidField)
 		return
 	}
 
-	resolvedSteamID, resolveErr := h.steamClient.ResolveSteamID(steamID)
+	opts, err := playerOptionsFromRequest(r, h.config.MaxWaitForFresh)
+	if err != nil {
+		writeParamErrors(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Language", opts.Lang)
+	if mux.Vars(r)["role"] != "" && !steam.AppFromContext(ctx).IsDBD() {
+		writeValidationError(w, r, "Killer and survivor views are only available for Dead by Daylight", "role")
+		return
+	}
+	if mux.Vars(r)["view"] == "maps" && len(steam.AppFromContext(ctx).Realms) == 0 {
+		writeValidationError(w, r, "Map stats are only available for Dead by Daylight", "view")
+		return
+	}
+	if opts.Timing {
+		ctx = withRequestTiming(ctx, start)
+		r = r.WithContext(ctx)
+	}
+
+	if h.config.DemoMode {
+		h.serveDemoPlayer(w, r, steamID, opts.Lang, opts.Format)
+		return
+	}
+
+	policy := cache.PolicyFromContext(ctx)
+	resolvedSteamID, resolvedAs, found, resolveErr := h.resolvePlayerID(ctx, steamID, idType)
+	if !found {
+		writeNotCached(w, r, steamID)
+		return
+	}
 	if resolveErr != nil {
 		requestLogger.Error("Failed to resolve Steam ID/vanity URL",
 			"error", resolveErr.Message,
@@ -343,26 +628,42 @@ func (h *Handler) GetPlayerStatsWithAchievements(w http.ResponseWriter, r *http.
 		writeErrorResponse(w, resolveErr)
 		return
 	}
+	if !h.checkVanityBinding(w, r, steamID, resolvedSteamID, resolvedAs) {
+		return
+	}
+
+	// Snapshots, tracking and peak grades are DBD features; other titles are
+	// cached and coalesced under an app-scoped identity
+	app := steam.AppFromContext(ctx)
+	cacheID := playerCacheID(ctx, resolvedSteamID)
+
+	// Without a cache every response is live already, so there is nothing to wait for
+	if opts.WaitForFresh > 0 && h.cacheManager() != nil &&
+		h.serveWaitForFresh(w, r, resolvedSteamID, resolvedAs, opts, requestLogger) {
+		return
+	}
+
+	// Role and map views are small enough not to need splitting
+	if acceptsNDJSON(r) && r.Method != http.MethodHead && mux.Vars(r)["role"] == "" && mux.Vars(r)["view"] == "" {
+		h.serveProgressive(w, r, steamID, resolvedSteamID, resolvedAs, opts, requestLogger)
+		return
+	}
 
-	var combinedCacheKey string
 	var combinedCacheHit bool
-	if h.cacheManager != nil {
-		combinedCacheKey = cache.GenerateKey(cache.PlayerCombinedPrefix, resolvedSteamID)
-		if cached, found := h.cacheManager.GetCache().Get(combinedCacheKey); found {
-			if response, ok := cached.(models.PlayerStatsWithAchievements); ok {
-				combinedCacheHit = true
-				requestLogger.Info("Combined cache hit",
-					"display_name", response.DisplayName,
-					"has_achievements", response.Achievements != nil,
-					"duration", time.Since(start))
-				writeJSONResponse(w, response)
-				return
-			} else {
-				requestLogger.Warn("Invalid combined cache entry type, removing",
-					"expected", "models.PlayerStatsWithAchievements",
-					"actual", fmt.Sprintf("%T", cached))
-				h.cacheManager.GetCache().Delete(combinedCacheKey)
-			}
+	if h.cacheManager() != nil && policy == cache.PolicyRefresh {
+		h.evictPlayer(ctx, resolvedSteamID)
+	} else if h.cacheManager() != nil && policy != cache.PolicyBypass {
+		lookupStart := time.Now()
+		response, found := cache.Get(h.sharedCache(), playerCombinedCache.Key(cacheID))
+		timingFromContext(ctx).observe(phaseCache, lookupStart)
+		if found {
+			combinedCacheHit = true
+			requestLogger.Info("Combined cache hit",
+				"display_name", response.DisplayName,
+				"has_achievements", response.Achievements != nil,
+				"duration", time.Since(start))
+			h.writeCombinedResponse(w, r, resolvedSteamID, resolvedAs, opts.Lang, opts.Format, response, nil)
+			return
 		}
 	}
 
@@ -374,6 +675,80 @@ func (h *Handler) GetPlayerStatsWithAchievements(w http.ResponseWriter, r *http.
 		"resolved_steam_id", resolvedSteamID,
 		"was_vanity_url", steamID != resolvedSteamID)
 
+	select {
+	case <-ctx.Done():
+		writeTimeoutError(w, r, "player_stats_with_achievements")
+		return
+	default:
+	}
+
+	// After a restart the cache is empty; a tracked player's persisted snapshot
+	// answers at once while the live response is assembled in the background
+	if policy == cache.PolicyDefault && app.IsDBD() && h.serveSnapshot(w, r, resolvedSteamID, resolvedAs, opts.Lang, opts.Format) {
+		return
+	}
+
+	// An identical request already assembling this player is waited on rather
+	// than repeated; requests with a cache policy always do their own work
+	var flight *combinedFlight
+	if policy == cache.PolicyDefault {
+		var leader bool
+		if flight, leader = h.combined.join(cacheID); !leader {
+			if shared, warnings, ok := h.combined.wait(ctx, flight); ok {
+				requestLogger.Info("Combined response shared with in-flight request",
+					"resolved_steam_id", resolvedSteamID,
+					"duration", time.Since(start))
+				h.writeCombinedResponse(w, r, resolvedSteamID, resolvedAs, opts.Lang, opts.Format, shared, warnings)
+				return
+			}
+			if ctx.Err() != nil {
+				writeTimeoutError(w, r, "player_stats_with_achievements")
+				return
+			}
+			flight = nil
+		}
+	}
+	var (
+		shared         models.PlayerStatsWithAchievements
+		sharedWarnings []string
+		sharedOK       bool
+	)
+	defer func() {
+		h.combined.finish(cacheID, flight, shared, sharedWarnings, sharedOK)
+	}()
+
+	response, warnings, err := h.assembleCombined(ctx, steamID, resolvedSteamID, start, requestLogger)
+	if ctx.Err() != nil {
+		writeTimeoutError(w, r, "player_stats_with_achievements")
+		return
+	}
+	if err != nil {
+		var steamErr *steam.APIError
+		switch {
+		case errors.Is(err, cache.ErrNotCached):
+			writeNotCached(w, r, steamID)
+		case errors.As(err, &steamErr) && steamErr.Type == steam.ErrorTypeTimeout:
+			writeTimeoutError(w, r, "player_stats")
+		default:
+			writeErrorResponse(w, steam.NewInternalError(err))
+		}
+		return
+	}
+	shared, sharedWarnings, sharedOK = response, warnings, true
+	h.writeCombinedResponse(w, r, resolvedSteamID, resolvedAs, opts.Lang, opts.Format, response, warnings)
+	if !app.IsDBD() {
+		return
+	}
+	h.publishStatsChanged(resolvedSteamID, response)
+	if h.tracked.Has(resolvedSteamID) {
+		h.persistSnapshot(resolvedSteamID, response)
+	}
+}
+
+// assembleCombined fetches stats, achievements and structured stats in
+// parallel and builds the combined response, caching it when complete. Only
+// a failed stats fetch (or ctx ending) is an error; the rest degrade.
+func (h *Handler) assembleCombined(ctx context.Context, steamID, resolvedSteamID string, start time.Time, requestLogger *slog.Logger) (models.PlayerStatsWithAchievements, []string, error) {
 	type fetchResult struct {
 		stats                 models.PlayerStats
 		achievements          *models.AchievementData
@@ -384,45 +759,78 @@ func (h *Handler) GetPlayerStatsWithAchievements(w http.ResponseWriter, r *http.
 		statsSource           string
 		achSource             string
 		structuredStatsSource string
+		bans                  *steam.PlayerBans
+		bansError             error
 	}
 
-	select {
-	case <-ctx.Done():
-		writeTimeoutError(w, r, "player_stats_with_achievements")
-		return
-	default:
-	}
-
+	// Each fetch runs on the shared worker pool with a SteamAPITimeout deadline;
+	// a slow optional source degrades to partial data instead of failing the request.
+	// If the route deadline passes first, Wait cancels the stragglers and result is
+	// never read again; the pool counts them as abandoned on /api/metrics.
 	result := fetchResult{}
-	resultChan := make(chan struct{}, 3) // Changed from 2 to 3
-
-	go func() {
-		defer func() { resultChan <- struct{}{} }()
-		result.stats, result.statsSource, result.statsError = h.fetchPlayerStatsWithSource(resolvedSteamID)
-	}()
+	group := h.workers.Group(ctx)
+
+	// Progressive responses get the stats half as soon as both stats fetches
+	// are in; whichever finishes second hands it over
+	ready := statsReadyFromContext(ctx)
+	var statsPending atomic.Int32
+	statsPending.Store(2)
+	statsFetched := func() {
+		if ready != nil && statsPending.Add(-1) == 0 && result.statsError == nil {
+			ready(h.statsHalf(ctx, resolvedSteamID, result.stats, result.statsSource,
+				result.structuredStats, result.structuredStatsSource, result.structuredStatsError))
+		}
+	}
 
-	go func() {
-		defer func() { resultChan <- struct{}{} }()
-		result.achievements, result.achSource, result.achError = h.fetchPlayerAchievementsWithSource(resolvedSteamID)
-	}()
+	group.Go("stats", func(taskCtx context.Context) error {
+		defer statsFetched()
+		result.stats, result.statsSource, result.statsError = h.fetchPlayerStatsWithSource(taskCtx, resolvedSteamID)
+		return result.statsError
+	})
+
+	group.Go("achievements", func(taskCtx context.Context) error {
+		result.achievements, result.achSource, result.achError = h.fetchPlayerAchievementsWithSource(taskCtx, resolvedSteamID)
+		return result.achError
+	})
+
+	group.Go("structured_stats", func(taskCtx context.Context) error {
+		defer statsFetched()
+		result.structuredStats, result.structuredStatsSource, result.structuredStatsError = h.fetchPlayerStructuredStatsWithSource(taskCtx, resolvedSteamID)
+		return result.structuredStatsError
+	})
+
+	// Bans aren't cached, so cache=only goes without them
+	if h.config.IntegrityFlagsEnabled && cache.PolicyFromContext(ctx) != cache.PolicyOnly {
+		group.Go("bans", func(taskCtx context.Context) error {
+			bans, err := h.steamClient.GetPlayerBansContext(taskCtx, resolvedSteamID)
+			if err != nil {
+				result.bansError = err
+				return err
+			}
+			result.bans = bans
+			return nil
+		})
+	}
 
-	go func() {
-		defer func() { resultChan <- struct{}{} }()
-		result.structuredStats, result.structuredStatsSource, result.structuredStatsError = h.fetchPlayerStructuredStatsWithSource(resolvedSteamID)
-	}()
+	if err := group.Wait(); ctx.Err() != nil {
+		return models.PlayerStatsWithAchievements{}, nil, ctx.Err()
+	} else if err != nil {
+		requestLogger.Debug("Combined fetch completed with source errors", "errors", err.Error())
+	}
 
-	timeout := time.After(SteamAPITimeout)
-	completedCount := 0
-	for completedCount < 3 { // Changed from 2 to 3
-		select {
-		case <-resultChan:
-			completedCount++
-		case <-ctx.Done():
-			writeTimeoutError(w, r, "player_stats_with_achievements")
-			return
-		case <-timeout:
-			writeTimeoutError(w, r, "player_stats_with_achievements")
-			return
+	if h.cacheManager() != nil {
+		// Feed upstream health to adaptive TTL tuning; cache hits say nothing about Steam
+		for _, outcome := range []struct {
+			source string
+			err    error
+		}{
+			{result.statsSource, result.statsError},
+			{result.achSource, result.achError},
+			{result.structuredStatsSource, result.structuredStatsError},
+		} {
+			if outcome.source == "api" {
+				h.cacheManager().RecordUpstream(outcome.err)
+			}
 		}
 	}
 
@@ -447,6 +855,18 @@ func (h *Handler) GetPlayerStatsWithAchievements(w http.ResponseWriter, r *http.
 		},
 	}
 
+	// Sources answered from the cache were fetched when their entry was written
+	cacheID := playerCacheID(ctx, resolvedSteamID)
+	if result.statsSource == "cache" {
+		response.DataSources.Stats.FetchedAt = cachedFetchTime(h.sharedCache(), playerStatsCache.Key(cacheID), response.DataSources.Stats.FetchedAt)
+	}
+	if result.achSource == "cache" {
+		response.DataSources.Achievements.FetchedAt = cachedFetchTime(h.sharedCache(), playerAchievementsCache.Key(cacheID), response.DataSources.Achievements.FetchedAt)
+	}
+	if result.structuredStatsSource == "cache" {
+		response.DataSources.StructuredStats.FetchedAt = cachedFetchTime(h.sharedCache(), structuredStatsCache.Key(cacheID), response.DataSources.StructuredStats.FetchedAt)
+	}
+
 	// Include structured stats if successful
 	if result.structuredStatsError == nil {
 		response.Stats = result.structuredStats
@@ -467,19 +887,39 @@ func (h *Handler) GetPlayerStatsWithAchievements(w http.ResponseWriter, r *http.
 			"original_steam_id", steamID,
 			"resolved_steam_id", resolvedSteamID,
 			"duration", time.Since(start))
-		writeErrorResponse(w, steam.NewInternalError(result.statsError))
-		return
+		return models.PlayerStatsWithAchievements{}, nil, result.statsError
+	}
+
+	if h.config.IntegrityFlagsEnabled {
+		if result.bansError != nil {
+			requestLogger.Warn("Failed to fetch player bans - integrity flags computed without ban correlation",
+				"error", result.bansError,
+				"error_type", classifyError(result.bansError))
+		}
+		response.IntegrityFlags = steam.EvaluateIntegrity(result.stats, result.bans)
+		if len(response.IntegrityFlags) > 0 {
+			requestLogger.Info("Integrity flags raised for manual review",
+				"resolved_steam_id", resolvedSteamID,
+				"flag_count", len(response.IntegrityFlags))
+		}
 	}
 
-	// Always initialize achievements to prevent frontend errors
-	response.Achievements = &models.AchievementData{
-		AdeptSurvivors: make(map[string]bool),
-		AdeptKillers:   make(map[string]bool),
-		LastUpdated:    time.Now(),
+	// Achievements stay nil (omitted) on failure; achievements_state says why
+	response.AchievementsState = achievementsState(result.achievements, result.achError)
+
+	servedStale := result.achError == nil && result.achSource == "fallback"
+	if servedStale {
+		age := time.Since(result.achievements.LastUpdated)
+		response.DataSources.Achievements.Stale = true
+		response.DataSources.Achievements.StaleAgeSeconds = int64(age.Seconds())
+		response.DataSources.Achievements.FetchedAt = result.achievements.LastUpdated
+		requestLogger.Warn("Serving stale achievements from circuit breaker fallback",
+			"resolved_steam_id", resolvedSteamID,
+			"stale_age", age.Round(time.Second))
 	}
 
 	if result.achError != nil {
-		// Achievements failed but stats succeeded - return partial data with empty achievements
+		// Achievements failed but stats succeeded - return partial data without achievements
 		errorType := classifyError(result.achError)
 		response.DataSources.Achievements.Error = result.achError.Error()
 
@@ -515,19 +955,26 @@ func (h *Handler) GetPlayerStatsWithAchievements(w http.ResponseWriter, r *http.
 			"killer_unlocks", countUnlocked(result.achievements.AdeptKillers))
 	}
 
-	if h.cacheManager != nil && combinedCacheKey != "" {
-		config := h.cacheManager.GetConfig()
-		if err := h.cacheManager.GetCache().Set(combinedCacheKey, response, config.TTL.PlayerCombined); err != nil {
+	// Stale responses aren't cached so the next request after recovery is fresh
+	if h.cacheManager() != nil && !servedStale {
+		combinedCacheKey := playerCombinedCache.Key(playerCacheID(ctx, resolvedSteamID))
+		ttl := h.cacheTTL(cache.PlayerCombinedPrefix, func(ttl cache.TTLConfig) time.Duration { return ttl.PlayerCombined })
+		if err := cache.Set(h.cacheManager().GetCache(), combinedCacheKey, response, ttl); err != nil {
 			requestLogger.Error("Failed to cache combined response",
 				"error", err,
 				"cache_key", combinedCacheKey)
 		} else {
 			requestLogger.Debug("Combined response cached successfully",
 				"cache_key", combinedCacheKey,
-				"ttl", config.TTL.PlayerCombined)
+				"ttl", ttl)
 		}
 	}
 
+	// The partial response was cached above; patch achievements in once Steam recovers
+	if result.achError != nil && h.cacheManager() != nil && steam.AppFromContext(ctx).IsDBD() {
+		h.scheduleAchievementRetry(resolvedSteamID, result.achError)
+	}
+
 	requestLogger.Info("Successfully processed combined player data request",
 		"persona_name", result.stats.DisplayName,
 		"original_steam_id", steamID,
@@ -536,80 +983,246 @@ func (h *Handler) GetPlayerStatsWithAchievements(w http.ResponseWriter, r *http.
 		"achievements_success", result.achError == nil,
 		"duration", time.Since(start))
 
+	var warnings []string
 	if result.achError != nil {
-		warnings := []string{
+		warnings = []string{
 			"Achievement data unavailable: " + result.achError.Error(),
 		}
-		writePartialDataResponse(w, response, warnings)
+	}
+	return response, warnings, nil
+
+}
+
+// writeCombinedResponse records the player and applies the per-request
+// decoration (flags, language, locale, icons) to an undecorated combined
+// response, which may be shared with other requests and so is only copied
+func (h *Handler) writeCombinedResponse(w http.ResponseWriter, r *http.Request, steamID string, resolvedAs steam.IDType, lang string, format steam.StatFormatter, response models.PlayerStatsWithAchievements, warnings []string) {
+	response = h.decorateCombined(r, resolvedAs, lang, format, h.recordCombined(r, steamID, resolvedAs, response))
+	w.Header().Set("X-Resolved-As", string(resolvedAs))
+	setLastModified(w, response.DataSources.Stats.FetchedAt)
+
+	setStableETag(w, r, combinedBody(r, response))
+	response.DataAgeSeconds = setDataAge(w, dataAsOf(response.DataSources), time.Now())
+	body := combinedBody(r, response)
+	if len(warnings) > 0 {
+		writePartialDataResponse(w, body, warnings)
 	} else {
-		writeJSONResponse(w, response)
+		writeJSONResponse(w, body)
 	}
 }
 
-func (h *Handler) fetchPlayerStatsWithSource(steamID string) (models.PlayerStats, string, error) {
-	if h.cacheManager != nil {
-		cacheKey := cache.GenerateKey(cache.PlayerStatsPrefix, steamID)
-		if cached, found := h.cacheManager.GetCache().Get(cacheKey); found {
-			if playerStats, ok := cached.(models.PlayerStats); ok {
-				return playerStats, "cache", nil
-			}
+// recordCombined records the player a combined response is served for and
+// returns a copy with their peak grades. It must run once per request.
+func (h *Handler) recordCombined(r *http.Request, steamID string, resolvedAs steam.IDType, response models.PlayerStatsWithAchievements) models.PlayerStatsWithAchievements {
+	h.players.Record(steamID, response.DisplayName, response.Avatar)
+	h.bindRequestVanity(r, steamID, resolvedAs)
+	if steam.AppFromContext(r.Context()).IsDBD() {
+		response.PeakGrades = h.recordPeakGrades(steamID, response.Stats)
+	}
+	return response
+}
+
+// decorateCombined returns a copy of response with the per-request
+// decoration applied; it records nothing, so it may run more than once
+func (h *Handler) decorateCombined(r *http.Request, resolvedAs steam.IDType, lang string, format steam.StatFormatter, response models.PlayerStatsWithAchievements) models.PlayerStatsWithAchievements {
+	response.ResolvedAs = string(resolvedAs)
+	response = h.applyResponseFlags(r, response)
+	response.Stats = localizeStats(response.Stats, lang, format)
+	response.Stats, response.Events = groupEventStats(steam.AppFromContext(r.Context()), response.Stats, includeEndedEvents(r), time.Now())
+	response.Achievements = h.mirrorIcons(response.Achievements)
+	if timing := timingFromContext(r.Context()); timing != nil {
+		response.Timing = timing.report()
+	}
+	return response
+}
+
+// evictPlayer drops every cached layer for a player of the app ctx carries so
+// the next fetch goes to Steam
+func (h *Handler) evictPlayer(ctx context.Context, steamID string) {
+	h.cacheManager().GetCache().Delete(cache.GenerateKey(cache.PlayerCombinedPrefix, playerCacheID(ctx, steamID)))
+	h.evictPlayerSources(ctx, steamID)
+}
+
+// evictPlayerSources drops the per-source layers a combined response is
+// assembled from, leaving the combined entry to serve until it is replaced
+func (h *Handler) evictPlayerSources(ctx context.Context, steamID string) {
+	c := h.cacheManager().GetCache()
+	cacheID := playerCacheID(ctx, steamID)
+	for _, key := range []string{
+		cache.GenerateKey(cache.PlayerStatsPrefix, cacheID),
+		cache.GenerateKey(cache.PlayerAchievementsPrefix, cacheID),
+		cache.GenerateKey(cache.StructuredStatsPrefix, cacheID),
+		cache.GenerateKey(cache.UserStatsPrefix, steamID, steam.AppFromContext(ctx).ID),
+	} {
+		c.Delete(key)
+	}
+}
+
+// serveDemoPlayer answers player requests from bundled fixtures when demo mode is active
+func (h *Handler) serveDemoPlayer(w http.ResponseWriter, r *http.Request, steamID, lang string, format steam.StatFormatter) {
+	response, found := demo.Player(steamID)
+	if !found {
+		writeError(w, r, "DEMO_PLAYER_NOT_FOUND",
+			"Demo mode is active; only bundled demo players are available",
+			http.StatusNotFound,
+			map[string]interface{}{"demo_players": demo.Players()},
+			nil)
+		return
+	}
+
+	now := demo.LoadedAt()
+	source := models.DataSourceInfo{Success: true, Source: "demo", FetchedAt: now}
+	response.DataSources = models.DataSourceStatus{
+		Stats:           source,
+		Achievements:    source,
+		StructuredStats: source,
+	}
+	response.AchievementsState = achievementsState(response.Achievements, nil)
+	if response.Achievements == nil {
+		response.AchievementsState = models.AchievementsPrivate
+		response.DataSources.Achievements = models.DataSourceInfo{
+			Success:   false,
+			Source:    "demo",
+			Error:     "Achievements unavailable (private profile fixture)",
+			FetchedAt: now,
 		}
 	}
 
-	summary, err := h.steamClient.GetPlayerSummary(steamID)
-	if err != nil {
-		return models.PlayerStats{}, "api", fmt.Errorf("steam summary failed: %w", err)
+	if h.config.IntegrityFlagsEnabled {
+		response.IntegrityFlags = steam.EvaluateIntegrity(response.PlayerStats, nil)
 	}
+	*response = h.applyResponseFlags(r, *response)
+	response.Stats = localizeStats(response.Stats, lang, format)
+	response.Stats, response.Events = groupEventStats(steam.AppFromContext(r.Context()), response.Stats, includeEndedEvents(r), time.Now())
 
-	rawStats, err := h.steamClient.GetPlayerStats(steamID)
-	if err != nil {
-		return models.PlayerStats{}, "api", fmt.Errorf("steam stats failed: %w", err)
+	h.players.Record(response.SteamID, response.DisplayName, response.Avatar)
+	response.PeakGrades = h.recordPeakGrades(response.SteamID, response.Stats)
+
+	if timing := timingFromContext(r.Context()); timing != nil {
+		response.Timing = timing.report()
 	}
 
-	playerStats := steam.MapSteamStats(rawStats.Stats, summary.SteamID, summary.PersonaName)
-	flatPlayerStats := convertToPlayerStats(playerStats, summary.AvatarFull)
+	w.Header().Set("X-Demo-Mode", "true")
+	setLastModified(w, now)
+	setStableETag(w, r, combinedBody(r, *response))
+	response.DataAgeSeconds = setDataAge(w, dataAsOf(response.DataSources), time.Now())
+	writeJSONResponse(w, combinedBody(r, *response))
+}
 
-	if h.cacheManager != nil {
-		cacheKey := cache.GenerateKey(cache.PlayerStatsPrefix, steamID)
-		config := h.cacheManager.GetConfig()
-		h.cacheManager.GetCache().Set(cacheKey, flatPlayerStats, config.TTL.PlayerStats)
+// combinedBody is the combined response, or the killer or survivor view of
+// it on the role routes and the per-map view on the maps route
+func combinedBody(r *http.Request, response models.PlayerStatsWithAchievements) interface{} {
+	if role := mux.Vars(r)["role"]; role != "" {
+		return roleView(response, role)
+	}
+	if mux.Vars(r)["view"] == "maps" {
+		return mapsView(steam.AppFromContext(r.Context()), response)
 	}
+	return response
+}
 
-	return flatPlayerStats, "api", nil
+func (h *Handler) fetchPlayerStatsWithSource(ctx context.Context, steamID string) (models.PlayerStats, string, error) {
+	cacheKey := playerStatsCache.Key(playerCacheID(ctx, steamID))
+	ttl := h.cacheTTL(cache.PlayerStatsPrefix, func(ttl cache.TTLConfig) time.Duration { return ttl.PlayerStats })
+	lookupStart := time.Now()
+	playerStats, hit, err := cache.GetOrLoad(ctx, h.sharedCache(), cacheKey, ttl, func(ctx context.Context) (models.PlayerStats, error) {
+		return h.loadPlayerStats(ctx, steamID)
+	})
+	if hit {
+		timingFromContext(ctx).observe(phaseCache, lookupStart)
+		return playerStats, "cache", nil
+	}
+	return playerStats, "api", err
 }
 
-func (h *Handler) fetchPlayerAchievementsWithSource(steamID string) (*models.AchievementData, string, error) {
-	if h.cacheManager != nil {
-		cacheKey := cache.GenerateKey(cache.PlayerAchievementsPrefix, steamID)
-		if cached, found := h.cacheManager.GetCache().Get(cacheKey); found {
-			if achievements, ok := cached.(*models.AchievementData); ok {
-				age := time.Since(achievements.LastUpdated)
-				log.Debug("Achievement cache hit",
-					"steam_id", steamID,
-					"cache_age", age,
-					"cache_key", cacheKey)
-				return achievements, "cache", nil
-			} else {
-				log.Warn("Invalid achievement cache entry type, removing",
-					"steam_id", steamID,
-					"cache_key", cacheKey,
-					"expected", "*models.AchievementData",
-					"actual", fmt.Sprintf("%T", cached))
-				h.cacheManager.GetCache().Delete(cacheKey)
-			}
+// loadPlayerStats fetches a player's summary and stats and flattens them
+func (h *Handler) loadPlayerStats(ctx context.Context, steamID string) (models.PlayerStats, error) {
+	// Summary and stats are independent, so fetch them together. Both are required:
+	// the first failure cancels the other. Plain goroutines rather than the worker
+	// pool because this already runs inside a pool task and must not wait on a slot.
+	fetchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg                  sync.WaitGroup
+		summary             *steam.SteamPlayer
+		rawStats            *steam.SteamPlayerstats
+		summaryErr, statErr *steam.APIError
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if summary, summaryErr = h.steamClient.GetPlayerSummaryContext(fetchCtx, steamID); summaryErr != nil {
+			cancel()
 		}
+	}()
+	go func() {
+		defer wg.Done()
+		if rawStats, statErr = h.steamClient.GetPlayerStatsContext(fetchCtx, steamID); statErr != nil {
+			cancel()
+		}
+	}()
+	wg.Wait()
+
+	// Report the root cause, not the timeout the summary saw after stats cancelled it
+	summaryCancelled := summaryErr != nil && summaryErr.Type == steam.ErrorTypeTimeout && statErr != nil && ctx.Err() == nil
+	if summaryErr != nil && !summaryCancelled {
+		return models.PlayerStats{}, fmt.Errorf("steam summary failed: %w", summaryErr)
+	}
+	if statErr != nil {
+		return models.PlayerStats{}, fmt.Errorf("steam stats failed: %w", statErr)
 	}
 
+	mappingStart := time.Now()
+	playerStats := steam.MapSteamStats(rawStats.Stats, summary.SteamID, summary.PersonaName)
+	flatPlayerStats := convertToPlayerStats(playerStats, summary.AvatarFull)
+	timingFromContext(ctx).observe(phaseMapping, mappingStart)
+
+	return flatPlayerStats, nil
+}
+
+func (h *Handler) fetchPlayerAchievementsWithSource(ctx context.Context, steamID string) (*models.AchievementData, string, error) {
+	cacheKey := playerAchievementsCache.Key(playerCacheID(ctx, steamID))
+	ttl := h.cacheTTL(cache.PlayerAchievementsPrefix, func(ttl cache.TTLConfig) time.Duration { return ttl.PlayerAchievements })
+
+	source := "api"
+	lookupStart := time.Now()
+	achievements, hit, err := cache.GetOrLoad(ctx, h.sharedCache(), cacheKey, ttl, func(ctx context.Context) (*models.AchievementData, error) {
+		achievements, stale, err := h.loadPlayerAchievements(ctx, steamID)
+		if stale {
+			source = "fallback"
+			return achievements, cache.SkipStore // already cached once; re-storing would reset its age
+		}
+		return achievements, err
+	})
+	if err != nil {
+		return nil, "api", err
+	}
+	if hit {
+		timingFromContext(ctx).observe(phaseCache, lookupStart)
+		log.Debug("Achievement cache hit",
+			"steam_id", steamID,
+			"cache_age", time.Since(achievements.LastUpdated),
+			"cache_key", cacheKey)
+		return achievements, "cache", nil
+	}
+	return achievements, source, nil
+}
+
+// loadPlayerAchievements fetches and maps a player's achievements, through the
+// circuit breaker when there is one. The bool reports that the breaker's
+// fallback served data cached earlier instead.
+func (h *Handler) loadPlayerAchievements(ctx context.Context, steamID string) (*models.AchievementData, bool, error) {
 	var rawAchievements *steam.PlayerAchievements
 	var apiErr error
 
-	if h.cacheManager != nil && h.cacheManager.GetCircuitBreaker() != nil {
-		result, err := h.cacheManager.GetCircuitBreaker().ExecuteWithStaleCache(
-			cache.GenerateKey(cache.PlayerAchievementsPrefix, steamID),
+	if h.cacheManager() != nil && h.cacheManager().GetCircuitBreaker() != nil {
+		result, stale, err := h.cacheManager().GetCircuitBreaker().ExecuteWithStaleCacheInfo(
+			string(playerAchievementsCache.Key(playerCacheID(ctx, steamID))),
 			func() (interface{}, error) {
-				achievements, apiErr := h.steamClient.GetPlayerAchievements(steamID, 381210)
+				achievements, apiErr := h.steamClient.GetPlayerAchievementsContext(ctx, steamID, steam.AppFromContext(ctx).NumericID())
 				if apiErr != nil {
-					return nil, fmt.Errorf("steam API error: %s", apiErr.Message)
+					return nil, fmt.Errorf("steam API error: %w", apiErr)
 				}
 				return achievements, nil
 			},
@@ -617,6 +1230,12 @@ func (h *Handler) fetchPlayerAchievementsWithSource(steamID string) (*models.Ach
 
 		if err != nil {
 			apiErr = err
+		} else if staleData, ok := result.(*models.AchievementData); ok && stale.Served {
+			// The fallback entry is the processed data we cached earlier; serve it as-is
+			log.Warn("Steam achievements unavailable, serving stale cached achievements",
+				"steam_id", steamID,
+				"stale_age", stale.Age.Round(time.Second))
+			return staleData, true, nil
 		} else if achievements, ok := result.(*steam.PlayerAchievements); ok {
 			rawAchievements = achievements
 		} else {
@@ -624,9 +1243,9 @@ func (h *Handler) fetchPlayerAchievementsWithSource(steamID string) (*models.Ach
 		}
 	} else {
 		var steamErr *steam.APIError
-		rawAchievements, steamErr = h.steamClient.GetPlayerAchievements(steamID, 381210)
+		rawAchievements, steamErr = h.steamClient.GetPlayerAchievementsContext(ctx, steamID, steam.AppFromContext(ctx).NumericID())
 		if steamErr != nil {
-			apiErr = fmt.Errorf("steam API error: %s", steamErr.Message)
+			apiErr = fmt.Errorf("steam API error: %w", steamErr)
 		}
 	}
 
@@ -635,17 +1254,19 @@ func (h *Handler) fetchPlayerAchievementsWithSource(steamID string) (*models.Ach
 			"steam_id", steamID,
 			"error", apiErr,
 			"error_type", classifyError(apiErr),
-			"circuit_breaker_active", h.cacheManager != nil && h.cacheManager.GetCircuitBreaker() != nil)
-		return nil, "api", fmt.Errorf("steam achievements failed: %w", apiErr)
+			"circuit_breaker_active", h.cacheManager() != nil && h.cacheManager().GetCircuitBreaker() != nil)
+		return nil, false, fmt.Errorf("steam achievements failed: %w", apiErr)
 	}
 
-	ctx := context.Background()
-	adeptMap, err := h.steamClient.GetAdeptMapCached(ctx, h.cacheManager.GetCache())
+	timing := timingFromContext(ctx)
+	defer timing.observe(phaseMapping, time.Now())
+	ctx = timing.mapping(ctx)
+	adeptMap, err := h.steamClient.GetAdeptMapCached(ctx, h.sharedCache())
 	if err != nil {
 		log.Warn("Failed to get adept map from schema, falling back to hardcoded mapping",
 			"error", err)
 		adeptMap = make(map[string]steam.AdeptEntry)
-		for apiName, character := range steam.AdeptAchievementMapping {
+		for apiName, character := range steam.AppFromContext(ctx).Adepts {
 			adeptMap[apiName] = steam.AdeptEntry{
 				Character: character.Name,
 				Kind:      character.Type,
@@ -653,26 +1274,37 @@ func (h *Handler) fetchPlayerAchievementsWithSource(steamID string) (*models.Ach
 		}
 	}
 
-	mappedData := steam.GetAchievements(rawAchievements, h.cacheManager.GetCache())
+	// Rarity is stripped per client at serve time; when no client sees it the
+	// percentage fetch is skipped too, so turning the flag off sheds that load
+	if h.flags.OffForAll(flags.Percentiles) {
+		ctx = steam.WithoutPercentages(ctx)
+	}
+	mappedData := steam.GetAchievementsContext(ctx, rawAchievements, h.sharedCache())
 	mappedAchievements := mappedData["achievements"].([]steam.AchievementMapping)
 	summary := mappedData["summary"].(map[string]interface{})
+	schemaSource, _ := mappedData["schema_source"].(string)
 
 	adeptSurv := make(map[string]bool)
 	adeptKill := make(map[string]bool)
 
+	// Unknown-kind adepts (strict mode) stay out of both sides
 	for _, entry := range adeptMap {
-		if entry.Kind == "killer" {
+		switch entry.Kind {
+		case "killer":
 			adeptKill[entry.Character] = false
-		} else {
+		case "unknown":
+		default:
 			adeptSurv[entry.Character] = false
 		}
 	}
 
 	for _, rawAch := range rawAchievements.Achievements {
 		if entry, ok := adeptMap[rawAch.APIName]; ok {
-			if entry.Kind == "killer" {
+			switch entry.Kind {
+			case "killer":
 				adeptKill[entry.Character] = rawAch.Achieved == 1
-			} else {
+			case "unknown":
+			default:
 				adeptSurv[entry.Character] = rawAch.Achieved == 1
 			}
 		}
@@ -718,10 +1350,12 @@ func (h *Handler) fetchPlayerAchievementsWithSource(steamID string) (*models.Ach
 			UnlockedCount:     summary["unlocked_count"].(int),
 			SurvivorCount:     getIntFromMap(summary, "adept_survivor_count", 0),
 			KillerCount:       getIntFromMap(summary, "adept_killer_count", 0),
+			UnknownAdeptCount: getIntFromMap(summary, "adept_unknown_count", 0),
 			GeneralCount:      summary["general_count"].(int),
 			AdeptSurvivors:    summary["adept_survivors"].([]string),
 			AdeptKillers:      summary["adept_killers"].([]string),
 			CompletionRate:    summary["completion_rate"].(float64),
+			SchemaSource:      schemaSource,
 		},
 		LastUpdated: time.Now(),
 	}
@@ -732,34 +1366,18 @@ func (h *Handler) fetchPlayerAchievementsWithSource(steamID string) (*models.Ach
 			Name:        mapped.Name,
 			DisplayName: mapped.DisplayName,
 			Description: mapped.Description,
+			Icon:        mapped.Icon,
+			IconGray:    mapped.IconGray,
+			Hidden:      mapped.Hidden,
 			Character:   mapped.Character,
 			Type:        mapped.Type,
 			Unlocked:    mapped.Unlocked,
 			UnlockTime:  mapped.UnlockTime,
+			Rarity:      mapped.Rarity,
 		}
 	}
 
-	if h.cacheManager != nil {
-		cacheKey := cache.GenerateKey(cache.PlayerAchievementsPrefix, steamID)
-		config := h.cacheManager.GetConfig()
-
-		if err := h.cacheManager.GetCache().Set(cacheKey, processedAchievements, config.TTL.PlayerAchievements); err != nil {
-			log.Error("Failed to cache achievements",
-				"steam_id", steamID,
-				"error", err,
-				"cache_key", cacheKey,
-				"ttl", config.TTL.PlayerAchievements)
-		} else {
-			log.Debug("Achievements cached successfully",
-				"steam_id", steamID,
-				"cache_key", cacheKey,
-				"ttl", config.TTL.PlayerAchievements,
-				"survivor_count", len(processedAchievements.AdeptSurvivors),
-				"killer_count", len(processedAchievements.AdeptKillers))
-		}
-	}
-
-	return processedAchievements, "api", nil
+	return processedAchievements, false, nil
 }
 
 func classifyError(err error) string {
@@ -793,6 +1411,25 @@ func classifyError(err error) string {
 	}
 }
 
+// achievementsState classifies an achievements fetch for the
+// achievements_state field. A 403 is how Steam answers for hidden game details.
+func achievementsState(data *models.AchievementData, err error) string {
+	if err != nil {
+		if classifyError(err) == "private_profile" || strings.Contains(err.Error(), "HTTP 403") {
+			return models.AchievementsPrivate
+		}
+		return models.AchievementsUnavailable
+	}
+	if data == nil {
+		return models.AchievementsUnavailable
+	}
+	if data.Summary.UnlockedCount == 0 && countMappedUnlocked(data.MappedAchievements) == 0 &&
+		countUnlocked(data.AdeptSurvivors) == 0 && countUnlocked(data.AdeptKillers) == 0 {
+		return models.AchievementsEmpty
+	}
+	return models.AchievementsOK
+}
+
 func countUnlocked(achievements map[string]bool) int {
 	count := 0
 	for _, unlocked := range achievements {
@@ -807,20 +1444,31 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	status := map[string]interface{}{
 		"status":    "healthy",
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
-		"version":   "1.0.0",
+		"version":   buildinfo.Version,
 		"services": map[string]string{
 			"steam_api": "available",
 			"cache":     "available",
 		},
 	}
 
-	if h.cacheManager != nil {
-		cacheStatus := h.cacheManager.GetCacheStatus()
+	status["steam_api_hosts"] = h.steamClient.HostStatus()
+	status["worker_pool"] = h.workers.Stats()
+	status["goroutines"] = runtime.NumGoroutine()
+
+	if h.config.DemoMode {
+		status["services"].(map[string]string)["steam_api"] = "demo"
+		status["demo_mode"] = true
+		status["demo_players"] = demo.Players()
+	}
+
+	if h.cacheManager() != nil {
+		cacheStatus := h.cacheManager().GetCacheStatus()
 		status["services"].(map[string]string)["cache"] = "available"
 		status["cache_status"] = cacheStatus
 	} else {
 		status["services"].(map[string]string)["cache"] = "disabled"
 	}
+	status["cache_mode"] = h.cacheInit.status()
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
@@ -830,22 +1478,96 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(status)
 }
 
-// fetchPlayerStructuredStatsWithSource fetches structured stats using schema as source of truth
-func (h *Handler) fetchPlayerStructuredStatsWithSource(steamID string) (*models.StatsData, string, error) {
-	if h.cacheManager != nil {
-		// Try to fetch from cache first
-		cacheKey := cache.GenerateKey("structured_stats", steamID)
-		if cached, found := h.cacheManager.GetCache().Get(cacheKey); found {
-			if statsData, ok := cached.(*models.StatsData); ok {
-				return statsData, "cache", nil
+// Status serves the public status document: build metadata, uptime and a
+// summary of cache and upstream health. Unlike /health it omits internal config.
+func (h *Handler) Status(w http.ResponseWriter, r *http.Request) {
+	uptime := buildinfo.Uptime()
+	overall := "operational"
+
+	hosts := h.steamClient.HostStatus()
+	healthyHosts := 0
+	for _, host := range hosts {
+		if host.Healthy {
+			healthyHosts++
+		}
+	}
+	steamStatus := "operational"
+	switch {
+	case h.config.DemoMode:
+		steamStatus = "demo"
+	case healthyHosts == 0:
+		steamStatus = "down"
+		overall = "degraded"
+	case healthyHosts < len(hosts):
+		steamStatus = "degraded"
+	}
+	if !h.config.DemoMode && h.steamClient.Quota().Level() == steam.QuotaCritical {
+		overall = "degraded"
+	}
+
+	cacheSection := map[string]interface{}{"status": "disabled"}
+	if h.cacheManager() != nil {
+		stats := h.cacheManager().GetCache().Stats()
+		cacheSection = map[string]interface{}{
+			"status":   "operational",
+			"entries":  stats.Entries,
+			"hit_rate": stats.HitRate,
+		}
+		if cb := h.cacheManager().GetCircuitBreaker(); cb != nil {
+			breaker := "closed"
+			switch cb.GetState() {
+			case cache.CircuitOpen:
+				breaker = "open"
+				overall = "degraded"
+			case cache.CircuitHalfOpen:
+				breaker = "half-open"
 			}
+			cacheSection["circuit_breaker"] = breaker
 		}
+	}
 
-		// Cache miss - fetch from API with cache
-		ctx := context.Background()
-		statsResponse, err := steam.MapPlayerStats(ctx, steamID, h.cacheManager.GetCache(), h.steamClient)
+	status := map[string]interface{}{
+		"status":         overall,
+		"timestamp":      time.Now().UTC().Format(time.RFC3339),
+		"build":          buildinfo.Get(),
+		"started_at":     buildinfo.StartTime().UTC().Format(time.RFC3339),
+		"uptime_seconds": int64(uptime.Seconds()),
+		"uptime":         uptime.Round(time.Second).String(),
+		"demo_mode":      h.config.DemoMode,
+		"known_players":  h.players.Count(),
+		"cache":          cacheSection,
+		"feature_flags":  h.flags.Snapshot(),
+		"error_budget":   metrics.Default().Snapshot(),
+		"upstream": map[string]interface{}{
+			"steam_api": map[string]interface{}{
+				"status":        steamStatus,
+				"hosts_total":   len(hosts),
+				"hosts_healthy": healthyHosts,
+				"quota":         h.steamClient.Quota().Status(),
+				"probe":         h.prober.Status(),
+			},
+		},
+		"track_refresher": h.trackPace.Status(),
+	}
+
+	if h.config.DemoMode {
+		status["demo_players"] = demo.Players()
+	}
+
+	writeJSONResponse(w, status)
+}
+
+// fetchPlayerStructuredStatsWithSource fetches structured stats using schema as source of truth
+func (h *Handler) fetchPlayerStructuredStatsWithSource(ctx context.Context, steamID string) (*models.StatsData, string, error) {
+	cacheKey := structuredStatsCache.Key(playerCacheID(ctx, steamID))
+	ttl := h.cacheTTL(cache.StructuredStatsPrefix, func(ttl cache.TTLConfig) time.Duration { return ttl.PlayerStats })
+	lookupStart := time.Now()
+	statsData, hit, err := cache.GetOrLoad(ctx, h.sharedCache(), cacheKey, ttl, func(ctx context.Context) (*models.StatsData, error) {
+		timing := timingFromContext(ctx)
+		defer timing.observe(phaseMapping, time.Now())
+		statsResponse, err := steam.MapPlayerStats(timing.mapping(ctx), steamID, h.sharedCache(), h.steamClient)
 		if err != nil {
-			return nil, "api", err
+			return nil, err
 		}
 
 		statsData := &models.StatsData{
@@ -857,32 +1579,246 @@ func (h *Handler) fetchPlayerStructuredStatsWithSource(steamID string) (*models.
 		for i, stat := range statsResponse.Stats {
 			statsData.Stats[i] = stat
 		}
+		return statsData, nil
+	})
+	if err != nil {
+		return nil, "api", err
+	}
+	if hit {
+		timingFromContext(ctx).observe(phaseCache, lookupStart)
+		return statsData, "cache", nil
+	}
+	return statsData, "api", nil
+}
+
+// GetPlayerInventory returns a player's public DBD Steam inventory (charms, outfits)
+func (h *Handler) GetPlayerInventory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	start := time.Now()
+	steamID, idType, invalidField, validationErr := playerIDFromRequest(r)
+
+	requestLogger := log.HTTPRequestContext(r.Method, r.URL.Path, steamID, r.RemoteAddr)
+
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr.Message, invalidField)
+		return
+	}
+
+	if h.config.DemoMode {
+		player, found := demo.Player(steamID)
+		if !found {
+			writeError(w, r, "DEMO_PLAYER_NOT_FOUND",
+				"Demo mode is active; only bundled demo players are available",
+				http.StatusNotFound,
+				map[string]interface{}{"demo_players": demo.Players()},
+				nil)
+			return
+		}
+		inventory := models.PlayerInventory{
+			SteamID:     player.SteamID,
+			Items:       []models.InventoryItem{},
+			Summary:     map[string]int{},
+			Demo:        true,
+			LastUpdated: demo.LoadedAt(),
+		}
+		setLastModified(w, inventory.LastUpdated)
+		writeJSONResponse(w, inventory)
+		return
+	}
 
-		// Cache the result
-		config := h.cacheManager.GetConfig()
-		if cacheErr := h.cacheManager.GetCache().Set(cacheKey, statsData, config.TTL.PlayerStats); cacheErr != nil {
-			log.Warn("Failed to cache structured stats", "cache_key", cacheKey, "error", cacheErr)
+	resolvedSteamID, resolvedAs, resolveErr := h.steamClient.ResolveSteamIDAs(ctx, steamID, idType)
+	if resolveErr != nil {
+		writeErrorResponse(w, resolveErr)
+		return
+	}
+	if !h.checkVanityBinding(w, r, steamID, resolvedSteamID, resolvedAs) {
+		return
+	}
+	w.Header().Set("X-Resolved-As", string(resolvedAs))
+
+	cacheKey := playerInventoryCache.Key(playerCacheID(ctx, resolvedSteamID))
+	ttl := h.cacheTTL(cache.PlayerInventoryPrefix, func(ttl cache.TTLConfig) time.Duration { return ttl.PlayerInventory })
+	inventory, hit, err := cache.GetOrLoad(ctx, h.sharedCache(), cacheKey, ttl, func(ctx context.Context) (models.PlayerInventory, error) {
+		inventory, apiErr := h.steamClient.GetPlayerInventory(ctx, resolvedSteamID)
+		if apiErr != nil {
+			return models.PlayerInventory{}, apiErr
 		}
+		return *inventory, nil
+	})
+	if hit {
+		inventory.CacheHit = true
+		setLastModified(w, inventory.LastUpdated)
+		writeJSONResponse(w, inventory)
+		return
+	}
+	if err != nil {
+		apiErr := steam.AsAPIError(err)
+		requestLogger.Warn("Failed to fetch player inventory",
+			"error", apiErr.Message,
+			"error_type", string(apiErr.Type),
+			"duration", time.Since(start))
+		writeErrorResponse(w, apiErr)
+		return
+	}
+
+	requestLogger.Info("Player inventory request completed",
+		"items", len(inventory.Items),
+		"duration", time.Since(start))
+
+	setLastModified(w, inventory.LastUpdated)
+	writeJSONResponse(w, inventory)
+}
+
+// GetPlayerRoadmap orders a player's locked achievements by effort: global rarity
+// blended with progress toward the stat counter an achievement is earned by.
+func (h *Handler) GetPlayerRoadmap(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	start := time.Now()
+	steamID, idType, invalidField, validationErr := playerIDFromRequest(r)
 
-		return statsData, "api", nil
+	requestLogger := log.HTTPRequestContext(r.Method, r.URL.Path, steamID, r.RemoteAddr)
+
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr.Message, invalidField)
+		return
+	}
+
+	if !h.flagEnabled(r, flags.Roadmap) {
+		writeFeatureDisabled(w, r, flags.Roadmap)
+		return
 	}
 
-	// No cache - direct API call
-	ctx := context.Background()
-	statsResponse, err := steam.MapPlayerStats(ctx, steamID, nil, h.steamClient)
+	params := newQueryParams(r)
+	limit := params.intRange("limit", 10, 1, 50)
+	if err := params.err(); err != nil {
+		writeParamErrors(w, r, err)
+		return
+	}
+
+	if h.config.DemoMode {
+		player, found := demo.Player(steamID)
+		if !found {
+			writeError(w, r, "DEMO_PLAYER_NOT_FOUND",
+				"Demo mode is active; only bundled demo players are available",
+				http.StatusNotFound,
+				map[string]interface{}{"demo_players": demo.Players()},
+				nil)
+			return
+		}
+		roadmap := models.AchievementRoadmap{
+			SteamID:     player.SteamID,
+			Entries:     []models.RoadmapEntry{},
+			Demo:        true,
+			LastUpdated: demo.LoadedAt(),
+		}
+		if player.Achievements != nil {
+			roadmap.Entries = steam.BuildRoadmap(player.Achievements.MappedAchievements, statValuesFromStatsData(player.Stats), limit)
+			roadmap.LockedCount = len(player.Achievements.MappedAchievements) - countMappedUnlocked(player.Achievements.MappedAchievements)
+		}
+		setLastModified(w, roadmap.LastUpdated)
+		writeJSONResponse(w, roadmap)
+		return
+	}
+
+	resolvedSteamID, resolvedAs, resolveErr := h.steamClient.ResolveSteamIDAs(ctx, steamID, idType)
+	if resolveErr != nil {
+		writeErrorResponse(w, resolveErr)
+		return
+	}
+	if !h.checkVanityBinding(w, r, steamID, resolvedSteamID, resolvedAs) {
+		return
+	}
+	w.Header().Set("X-Resolved-As", string(resolvedAs))
+
+	achievements, _, err := h.fetchPlayerAchievementsWithSource(ctx, resolvedSteamID)
 	if err != nil {
-		return nil, "api", err
+		var steamErr *steam.APIError
+		if errors.As(err, &steamErr) {
+			writeErrorResponse(w, steamErr)
+			return
+		}
+		writeErrorResponse(w, steam.NewInternalError(err))
+		return
+	}
+	achievements = h.mirrorIcons(achievements)
+
+	// Stat progress is optional: without it the roadmap falls back to rarity alone
+	statValues := make(map[string]float64)
+	var rawStats *steam.SteamPlayerstats
+	var statsErr *steam.APIError
+	appID := steam.AppFromContext(ctx).NumericID()
+	if h.cacheManager() != nil {
+		rawStats, statsErr = h.steamClient.GetUserStatsForGameCached(ctx, resolvedSteamID, appID, h.cacheManager().GetCache())
+	} else {
+		rawStats, statsErr = h.steamClient.GetUserStatsForGame(ctx, resolvedSteamID, appID)
+	}
+	if statsErr != nil {
+		requestLogger.Warn("Player stats unavailable for roadmap, ranking by rarity only",
+			"error", statsErr.Message)
+	} else {
+		for _, stat := range rawStats.Stats {
+			statValues[stat.Name] = stat.Value
+		}
 	}
 
-	statsData := &models.StatsData{
-		Stats:   make([]interface{}, len(statsResponse.Stats)),
-		Summary: statsResponse.Summary,
+	roadmap := models.AchievementRoadmap{
+		SteamID:     resolvedSteamID,
+		Entries:     steam.BuildRoadmap(achievements.MappedAchievements, statValues, limit),
+		LockedCount: len(achievements.MappedAchievements) - countMappedUnlocked(achievements.MappedAchievements),
+		LastUpdated: time.Now().UTC(),
 	}
+	// Track the achievement fetch time so cached roadmaps validate as unchanged
+	if !achievements.LastUpdated.IsZero() {
+		roadmap.LastUpdated = achievements.LastUpdated.UTC()
+	}
+
+	requestLogger.Info("Achievement roadmap generated",
+		"locked_count", roadmap.LockedCount,
+		"entries", len(roadmap.Entries),
+		"with_stat_progress", len(statValues) > 0,
+		"duration", time.Since(start))
 
-	// Copy stats
-	for i, stat := range statsResponse.Stats {
-		statsData.Stats[i] = stat
+	setLastModified(w, roadmap.LastUpdated)
+	writeJSONResponse(w, roadmap)
+}
+
+// buildAnalytics derives the analytics block from flat and structured stats
+func buildAnalytics(stats models.PlayerStats, structured *models.StatsData) *models.PlayerAnalytics {
+	statValues := statValuesFromStatsData(structured)
+	return &models.PlayerAnalytics{
+		Killer:   steam.BuildKillerAnalytics(stats, statValues),
+		Survivor: steam.BuildSurvivorAnalytics(stats, statValues),
 	}
+}
 
-	return statsData, "api", nil
+// statValuesFromStatsData extracts stat counters from structured stats, which hold
+// steam.Stat values when built live and generic maps when decoded from JSON fixtures
+func statValuesFromStatsData(data *models.StatsData) map[string]float64 {
+	values := make(map[string]float64)
+	if data == nil {
+		return values
+	}
+	for _, raw := range data.Stats {
+		switch stat := raw.(type) {
+		case steam.Stat:
+			values[stat.ID] = stat.Value
+		case map[string]interface{}:
+			id, _ := stat["id"].(string)
+			value, ok := stat["value"].(float64)
+			if id != "" && ok {
+				values[id] = value
+			}
+		}
+	}
+	return values
+}
+
+func countMappedUnlocked(achievements []models.MappedAchievement) int {
+	count := 0
+	for _, ach := range achievements {
+		if ach.Unlocked {
+			count++
+		}
+	}
+	return count
 }
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+const benchSteamID = "76561198000000042"
+
+// BenchmarkLoadPlayerStats compares loading a player's stats with the summary
+// and stats calls issued together against issuing them one after the other.
+// With Steam answering in 20ms, concurrent should take about half as long.
+func BenchmarkLoadPlayerStats(b *testing.B) {
+	fake := newFakeSteam(b, 20*time.Millisecond)
+	h := &Handler{steamClient: fake.newClient(b)}
+	ctx := context.Background()
+
+	b.Run("concurrent", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := h.loadPlayerStats(ctx, benchSteamID); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := h.steamClient.GetPlayerSummaryContext(ctx, benchSteamID); err != nil {
+				b.Fatal(err.Message)
+			}
+			if _, err := h.steamClient.GetPlayerStatsContext(ctx, benchSteamID); err != nil {
+				b.Fatal(err.Message)
+			}
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// iconAssetPrefix is where mirrored achievement icons are served from
+const iconAssetPrefix = "/api/assets/achievements/"
+
+// startIconMirror enables the icon mirror when ICON_MIRROR_ENABLED is set; demo
+// mode has no Steam access to mirror from
+func (h *Handler) startIconMirror() {
+	if !h.config.IconMirrorEnabled || h.config.DemoMode {
+		return
+	}
+	h.icons = steam.IconMirrorFromEnv(h.steamClient)
+}
+
+// mirrorIcons points achievement icons at the local mirror. Achievement data may
+// be shared with the cache, so a rewritten copy is returned instead of editing it.
+func (h *Handler) mirrorIcons(data *models.AchievementData) *models.AchievementData {
+	if h.icons == nil || data == nil || len(data.MappedAchievements) == 0 {
+		return data
+	}
+
+	mirrored := *data
+	mirrored.MappedAchievements = make([]models.MappedAchievement, len(data.MappedAchievements))
+	for i, ach := range data.MappedAchievements {
+		if steam.ValidIconID(ach.ID) {
+			h.icons.Remember(ach.ID, ach.Icon, ach.IconGray)
+			if ach.Icon != "" {
+				ach.Icon = iconAssetPrefix + ach.ID + ".png"
+			}
+			if ach.IconGray != "" {
+				ach.IconGray = iconAssetPrefix + ach.ID + "_gray.png"
+			}
+		}
+		mirrored.MappedAchievements[i] = ach
+	}
+	return &mirrored
+}
+
+// GetAchievementIcon serves a mirrored achievement icon, downloading it from the
+// Steam CDN on first request. {file} is "<id>.png" or "<id>_gray.png".
+func (h *Handler) GetAchievementIcon(w http.ResponseWriter, r *http.Request) {
+	if h.icons == nil {
+		writeError(w, r, "ICON_MIRROR_DISABLED", "Achievement icon mirroring is not enabled", http.StatusNotFound, nil, nil)
+		return
+	}
+
+	file := mux.Vars(r)["file"]
+	id, ok := strings.CutSuffix(file, ".png")
+	if !ok {
+		writeValidationError(w, r, "icon file must end in .png", "file")
+		return
+	}
+	id, gray := strings.CutSuffix(id, "_gray")
+	if !steam.ValidIconID(id) {
+		writeValidationError(w, r, "invalid achievement ID", "file")
+		return
+	}
+
+	path, err := h.icons.Path(r.Context(), id, gray)
+	if err != nil {
+		if errors.Is(err, steam.ErrIconNotFound) {
+			writeError(w, r, "ICON_NOT_FOUND", "No achievement icon with that ID", http.StatusNotFound, nil, nil)
+			return
+		}
+		log.Warn("Failed to mirror achievement icon", "id", id, "gray", gray, "error", err)
+		writeError(w, r, "ICON_UNAVAILABLE", "Achievement icon could not be fetched from Steam", http.StatusBadGateway, nil, nil)
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		writeErrorResponse(w, steam.NewInternalError(err))
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		writeErrorResponse(w, steam.NewInternalError(err))
+		return
+	}
+
+	// Steam serves most icons as JPEG despite the .png route, so sniff the bytes
+	sniff := make([]byte, 512)
+	n, _ := f.Read(sniff)
+	if _, err := f.Seek(0, 0); err != nil {
+		writeErrorResponse(w, steam.NewInternalError(err))
+		return
+	}
+	w.Header().Set("Content-Type", http.DetectContentType(sniff[:n]))
+	w.Header().Set("Cache-Control", "public, max-age=604800")
+	http.ServeContent(w, r, file, info.ModTime(), f)
+}
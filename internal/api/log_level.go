@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// maxLogLevelDuration caps a timed level change so debug logging left on by
+// mistake still turns itself off the same day
+const maxLogLevelDuration = 24 * time.Hour
+
+type logLevelRequest struct {
+	Level    string `json:"level"`
+	Module   string `json:"module"`
+	Duration string `json:"duration"`
+}
+
+// GetLogLevel reports the global level, module overrides and any pending revert
+func (h *Handler) GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	writeJSONResponse(w, log.Levels())
+}
+
+// SetLogLevel changes the global level, or one module's (the last element of
+// a package path, e.g. steam or cache), without restarting. Body:
+// {"level": "debug", "module": "steam", "duration": "15m"}; with a duration
+// every level returns to the startup level once it elapses. Level "reset"
+// drops the module's override, or every override without a module.
+func (h *Handler) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var body logLevelRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		writeValidationError(w, r, "Request body must be JSON of the form {\"level\": \"debug\", \"module\": \"...\", \"duration\": \"15m\"}", "body")
+		return
+	}
+	module := strings.ToLower(strings.TrimSpace(body.Module))
+
+	if strings.EqualFold(strings.TrimSpace(body.Level), "reset") {
+		log.ResetLevel(module)
+		log.Info("Log level reset", "module", module, "client_ip", getClientIP(r))
+		writeJSONResponse(w, log.Levels())
+		return
+	}
+
+	level, err := log.ParseLevel(body.Level)
+	if err != nil || strings.TrimSpace(body.Level) == "" {
+		writeValidationError(w, r, "level must be one of debug, info, warn, error or reset", "level")
+		return
+	}
+	var duration time.Duration
+	if body.Duration != "" {
+		duration, err = time.ParseDuration(body.Duration)
+		if err != nil || duration <= 0 || duration > maxLogLevelDuration {
+			writeValidationError(w, r, "duration must be a positive Go duration such as 15m, at most 24h", "duration")
+			return
+		}
+	}
+
+	log.SetLevel(module, level, duration)
+	log.Info("Log level changed",
+		"module", module,
+		"level", level.String(),
+		"duration", duration.String(),
+		"client_ip", getClientIP(r))
+	writeJSONResponse(w, log.Levels())
+}
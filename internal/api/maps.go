@@ -0,0 +1,70 @@
+package api
+
+import (
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// mapsView regroups the per-map generator and escape stats of a decorated
+// combined response by realm and map, in the order their first stats appear
+func mapsView(app *steam.App, response models.PlayerStatsWithAchievements) models.PlayerMaps {
+	view := models.PlayerMaps{
+		SteamID:        response.SteamID,
+		DisplayName:    response.DisplayName,
+		Avatar:         response.Avatar,
+		Realms:         make([]models.RealmStats, 0),
+		DataSources:    response.DataSources,
+		Demo:           response.Demo,
+		ResolvedAs:     response.ResolvedAs,
+		LastUpdated:    response.DataSources.Stats.FetchedAt,
+		DataAgeSeconds: response.DataAgeSeconds,
+		Timing:         response.Timing,
+	}
+
+	realmIndexes := map[string]int{}
+	mapIndexes := map[string]int{}
+	for _, stat := range statsFromStatsData(response.Stats) {
+		statMap, ok := app.StatMapFor(stat.ID)
+		if !ok {
+			continue
+		}
+		r, seen := realmIndexes[statMap.RealmID]
+		if !seen {
+			r = len(view.Realms)
+			realmIndexes[statMap.RealmID] = r
+			view.Realms = append(view.Realms, models.RealmStats{ID: statMap.RealmID, Name: statMap.Realm})
+		}
+		realm := &view.Realms[r]
+		key := statMap.RealmID + "/" + statMap.MapID
+		m, seen := mapIndexes[key]
+		if !seen {
+			m = len(realm.Maps)
+			mapIndexes[key] = m
+			realm.Maps = append(realm.Maps, models.MapStats{ID: statMap.MapID, Name: statMap.Map, Identified: statMap.Identified})
+		}
+		group := &realm.Maps[m]
+		group.Stats = append(group.Stats, stat)
+		group.Total++
+		if stat.Value > 0 {
+			group.Progressed++
+		}
+	}
+
+	for r := range view.Realms {
+		realm := &view.Realms[r]
+		progressed, total := 0, 0
+		for m := range realm.Maps {
+			group := &realm.Maps[m]
+			group.Completion = sharePercent(group.Progressed, group.Total)
+			group.Completed = group.Progressed == group.Total
+			progressed += group.Progressed
+			total += group.Total
+			view.MapsTotal++
+			if group.Completed {
+				view.MapsCompleted++
+			}
+		}
+		realm.Completion = sharePercent(progressed, total)
+	}
+	return view
+}
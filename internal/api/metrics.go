@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+)
+
+// tracingEnabled reports whether incoming trace context should be attached to
+// latency observations as exemplars (TRACING_ENABLED=true)
+func tracingEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("TRACING_ENABLED"))
+	return enabled
+}
+
+// traceIDFromRequest extracts the trace ID from a W3C traceparent header
+// ("00-<32 hex trace id>-<16 hex span id>-<flags>"); empty when absent or malformed
+func traceIDFromRequest(r *http.Request) string {
+	parts := strings.Split(strings.TrimSpace(r.Header.Get("traceparent")), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || parts[1] == strings.Repeat("0", 32) {
+		return ""
+	}
+	for _, c := range parts[1] {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return ""
+		}
+	}
+	return parts[1]
+}
+
+// Metrics serves latency histograms in OpenMetrics text format for Prometheus scraping
+func (h *Handler) Metrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	metrics.WriteOpenMetrics(w)
+}
@@ -3,14 +3,19 @@ package api
 import (
 	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/hex"
+	"errors"
+	"math"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
 	"github.com/rgonzalez12/dbd-analytics/internal/log"
 	"github.com/rgonzalez12/dbd-analytics/internal/steam"
 )
@@ -56,6 +61,11 @@ type RequestLimiter struct {
 	maxReqs int           // requests per window
 	window  time.Duration // time window
 	cleanup time.Duration // cleanup interval
+
+	// shared enforces the limit across replicas; local buckets take over
+	// while it is unreachable
+	shared        cache.CounterStore
+	sharedFailing atomic.Bool
 }
 
 type TokenBucket struct {
@@ -81,19 +91,74 @@ func NewRequestLimiter(maxReqs int, window time.Duration) *RequestLimiter {
 
 // Allow checks if a request should be allowed
 func (rl *RequestLimiter) Allow(clientID string) bool {
+	allowed, _ := rl.Take(clientID)
+	return allowed
+}
+
+// LimitState is a client's limiter state after a request, as reported in X-RateLimit-* headers
+type LimitState struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time // when the bucket next refills
+}
+
+// sharedTakeTimeout bounds a shared count on the request path; past it the
+// request is limited by the local bucket instead
+const sharedTakeTimeout = 250 * time.Millisecond
+
+// ShareCounts enforces the limit cluster-wide through store
+func (rl *RequestLimiter) ShareCounts(store cache.CounterStore) {
+	rl.shared = store
+}
+
+// Take consumes a token for clientID and reports the bucket state afterwards
+func (rl *RequestLimiter) Take(clientID string) (bool, LimitState) {
+	return rl.TakeContext(context.Background(), clientID)
+}
+
+// TakeContext is Take bounded by ctx, which only matters with shared counts
+func (rl *RequestLimiter) TakeContext(ctx context.Context, clientID string) (bool, LimitState) {
+	return rl.TakeN(ctx, clientID, 1)
+}
+
+// TakeN consumes n tokens for a request that costs more than one, such as a
+// batch lookup fanning out to Steam. Nothing is taken unless all n are left.
+func (rl *RequestLimiter) TakeN(ctx context.Context, clientID string, n int) (bool, LimitState) {
+	if rl.shared != nil {
+		ctx, cancel := context.WithTimeout(ctx, sharedTakeTimeout)
+		count, err := rl.shared.Take(ctx, "ratelimit:"+clientID, int64(n), int64(rl.maxReqs), rl.window)
+		cancel()
+		if err == nil {
+			if rl.sharedFailing.Swap(false) {
+				log.Info("Shared rate limit counters recovered")
+			}
+			return count.Allowed, LimitState{
+				Limit:     rl.maxReqs,
+				Remaining: max(rl.maxReqs-int(count.Count), 0),
+				Reset:     count.Reset,
+			}
+		}
+		// A busy pool is load, not an outage: count this one locally quietly
+		if !errors.Is(err, cache.ErrCountersBusy) && !rl.sharedFailing.Swap(true) {
+			log.Warn("Shared rate limit counters unavailable, limiting per instance", "error", err.Error())
+		}
+	}
+	return rl.takeLocal(clientID, n)
+}
+
+func (rl *RequestLimiter) takeLocal(clientID string, n int) (bool, LimitState) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
 	bucket, exists := rl.clients[clientID]
 	if !exists {
 		bucket = &TokenBucket{
-			tokens:     rl.maxReqs - 1, // consume one token immediately
+			tokens:     rl.maxReqs,
 			lastRefill: time.Now(),
 			capacity:   rl.maxReqs,
 			refillRate: rl.window,
 		}
 		rl.clients[clientID] = bucket
-		return true
 	}
 
 	// Refill tokens based on time passed
@@ -107,12 +172,27 @@ func (rl *RequestLimiter) Allow(clientID string) bool {
 	}
 
 	// Check if we have tokens available
-	if bucket.tokens > 0 {
-		bucket.tokens--
-		return true
+	if bucket.tokens >= n {
+		bucket.tokens -= n
+		return true, bucket.state()
 	}
 
-	return false
+	return false, bucket.state()
+}
+
+func (b *TokenBucket) state() LimitState {
+	return LimitState{
+		Limit:     b.capacity,
+		Remaining: b.tokens,
+		Reset:     b.lastRefill.Add(b.refillRate),
+	}
+}
+
+// setRateLimitHeaders advertises the limiter state so clients can self-throttle
+func setRateLimitHeaders(w http.ResponseWriter, state LimitState) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(state.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(state.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(state.Reset.Unix(), 10))
 }
 
 func (rl *RequestLimiter) cleanupRoutine() {
@@ -136,14 +216,11 @@ func (rl *RequestLimiter) cleanupRoutine() {
 func RateLimitMiddleware(limiter *RequestLimiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Use client fingerprint for more accurate rate limiting
-			clientFingerprint, ok := r.Context().Value(clientFingerprintKey).(string)
-			if !ok {
-				// Fallback to IP if fingerprint not available
-				clientFingerprint = getClientIP(r)
-			}
+			clientFingerprint := rateLimitClient(r)
+			allowed, state := limiter.TakeContext(r.Context(), clientFingerprint)
+			setRateLimitHeaders(w, state)
 
-			if !limiter.Allow(clientFingerprint) {
+			if !allowed {
 				log.Warn("Rate limit exceeded",
 					"client_fingerprint", clientFingerprint,
 					"user_agent", r.UserAgent(),
@@ -152,15 +229,7 @@ func RateLimitMiddleware(limiter *RequestLimiter) func(http.Handler) http.Handle
 					"max_requests", limiter.maxReqs,
 					"window", limiter.window)
 
-				// Rate limit headers
-				w.Header().Set("Content-Type", "application/json")
-				w.Header().Set("Retry-After", strconv.Itoa(int(limiter.window.Seconds())))
-				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limiter.maxReqs))
-				w.Header().Set("X-RateLimit-Window", limiter.window.String())
-
-				// Use our existing error response structure
-				apiErr := steam.NewRateLimitErrorWithRetryAfter(int(limiter.window.Seconds()))
-				writeErrorResponse(w, apiErr)
+				writeRateLimited(w, limiter, state)
 				return
 			}
 
@@ -169,43 +238,28 @@ func RateLimitMiddleware(limiter *RequestLimiter) func(http.Handler) http.Handle
 	}
 }
 
-// getClientIP extracts the real client IP, checking various headers
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header (but validate it's not spoofed)
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		// Take the first IP (leftmost) which should be the original client
-		if firstIP := parseFirstIP(xff); firstIP != "" {
-			return firstIP
-		}
-	}
-
-	// Check X-Real-IP header
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
+// rateLimitClient is who a request is rate limited as: its client
+// fingerprint, or its IP when the fingerprint isn't available
+func rateLimitClient(r *http.Request) string {
+	if fingerprint, ok := r.Context().Value(clientFingerprintKey).(string); ok {
+		return fingerprint
 	}
-
-	// Fall back to RemoteAddr
-	return parseIPFromRemoteAddr(r.RemoteAddr)
+	return getClientIP(r)
 }
 
-// parseFirstIP extracts the first IP from a comma-separated list
-func parseFirstIP(ips string) string {
-	for i, char := range ips {
-		if char == ',' || char == ' ' {
-			return ips[:i]
-		}
+// writeRateLimited answers a request the limiter refused
+func writeRateLimited(w http.ResponseWriter, limiter *RequestLimiter, state LimitState) {
+	w.Header().Set("Content-Type", "application/json")
+	retryAfter := int(math.Ceil(time.Until(state.Reset).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
 	}
-	return ips
-}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	w.Header().Set("X-RateLimit-Window", limiter.window.String())
 
-// parseIPFromRemoteAddr extracts IP from "ip:port" format
-func parseIPFromRemoteAddr(addr string) string {
-	for i := len(addr) - 1; i >= 0; i-- {
-		if addr[i] == ':' {
-			return addr[:i]
-		}
-	}
-	return addr
+	// Use our existing error response structure
+	apiErr := steam.NewRateLimitErrorWithRetryAfter(retryAfter)
+	writeErrorResponse(w, apiErr)
 }
 
 // SecurityMiddleware adds security headers and protection
@@ -226,9 +280,10 @@ func SecurityMiddleware() func(http.Handler) http.Handler {
 				allowedOrigins = "*" // Development fallback
 			}
 			w.Header().Set("Access-Control-Allow-Origin", allowedOrigins)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, If-None-Match, If-Modified-Since, X-Canonical-JSON, X-Profile-Token")
 			w.Header().Set("Access-Control-Max-Age", "3600")
+			w.Header().Set("Access-Control-Expose-Headers", "X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, Retry-After, ETag, Last-Modified, Age, X-Canonical-JSON, X-Cache-Policy")
 
 			// Block suspicious requests
 			userAgent := r.Header.Get("User-Agent")
@@ -277,6 +332,14 @@ func min(a, b int) int {
 	return b
 }
 
+// hasValidAPIKey reports whether the request carries the configured API key.
+// Without API_KEY no key is valid, since X-API-Key is then whatever the
+// client chose to send.
+func hasValidAPIKey(r *http.Request) bool {
+	apiKey := os.Getenv("API_KEY")
+	return apiKey != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("X-API-Key")), []byte(apiKey)) == 1
+}
+
 // APIKeyMiddleware adds optional API key authentication for public endpoints
 func APIKeyMiddleware() func(http.Handler) http.Handler {
 	requiredKey := os.Getenv("API_KEY")
@@ -289,8 +352,10 @@ func APIKeyMiddleware() func(http.Handler) http.Handler {
 				return
 			}
 
-			// Skip for cache and metrics endpoints (they have their own auth)
-			if strings.HasPrefix(r.URL.Path, "/api/cache/") || r.URL.Path == "/metrics" {
+			// Skip for cache and metrics endpoints (they have their own auth), the public status and capabilities pages,
+			// and the account link callback, which Steam redirects players to and the OpenID assertion authenticates
+			if strings.HasPrefix(r.URL.Path, "/api/cache/") || r.URL.Path == "/metrics" ||
+				r.URL.Path == "/api/status" || r.URL.Path == "/api/capabilities" || r.URL.Path == "/api/profile/link/callback" {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/msgpack"
+)
+
+// responseMediaTypes are the representations withMsgpack routes can negotiate
+var responseMediaTypes = []string{"application/json", msgpack.ContentType}
+
+// acceptsMsgpack reports whether the Accept header asks for MessagePack.
+// application/x-msgpack is accepted as the older unregistered spelling.
+func acceptsMsgpack(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if mediaType != msgpack.ContentType && mediaType != "application/x-msgpack" {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// bufferedWriter captures a handler's response so it can be re-encoded
+type bufferedWriter struct {
+	header     http.Header
+	body       bytes.Buffer
+	statusCode int
+}
+
+func (bw *bufferedWriter) Header() http.Header { return bw.header }
+
+func (bw *bufferedWriter) Write(p []byte) (int, error) {
+	if bw.statusCode == 0 {
+		bw.statusCode = http.StatusOK
+	}
+	return bw.body.Write(p)
+}
+
+func (bw *bufferedWriter) WriteHeader(statusCode int) {
+	if bw.statusCode == 0 {
+		bw.statusCode = statusCode
+	}
+}
+
+// withMsgpack lets a JSON route answer Accept: application/msgpack. The
+// handler writes JSON as usual and the body is transcoded afterwards, so both
+// representations always carry the same data. It goes inside withTimeout so
+// the ETag is computed over the bytes actually sent.
+func withMsgpack(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		if !acceptsMsgpack(r) {
+			next(w, r)
+			return
+		}
+
+		bw := &bufferedWriter{header: w.Header()}
+		next(bw, r)
+		if bw.statusCode == 0 {
+			bw.statusCode = http.StatusOK
+		}
+
+		body := bw.body.Bytes()
+		if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") && len(body) > 0 {
+			encoded, err := msgpack.FromJSON(body)
+			if err != nil {
+				// Serve the JSON rather than fail the request
+				log.Error("Failed to transcode response to MessagePack",
+					"path", r.URL.Path,
+					"error", err)
+			} else {
+				body = encoded
+				w.Header().Set("Content-Type", msgpack.ContentType)
+			}
+		}
+		w.WriteHeader(bw.statusCode)
+		w.Write(body)
+	}
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// paramError is one invalid query parameter
+type paramError struct {
+	Field   string   `json:"field"`
+	Message string   `json:"message"`
+	Allowed []string `json:"allowed,omitempty"` // accepted values, for enumerations
+}
+
+// paramErrors lists every invalid parameter of a request
+type paramErrors []paramError
+
+func (e paramErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// queryParams reads typed values from a request's query string. Invalid values
+// are recorded rather than returned, so handlers parse everything into an
+// options struct and then answer a single 400 that lists every mistake.
+type queryParams struct {
+	values url.Values
+	errs   paramErrors
+}
+
+func newQueryParams(r *http.Request) *queryParams {
+	return &queryParams{values: r.URL.Query()}
+}
+
+// err returns the collected errors, or nil when every parameter was valid
+func (p *queryParams) err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	return p.errs
+}
+
+func (p *queryParams) fail(field, message string, allowed []string) {
+	p.errs = append(p.errs, paramError{Field: field, Message: message, Allowed: allowed})
+}
+
+// string returns the trimmed value of name, or "" when absent
+func (p *queryParams) string(name string) string {
+	return strings.TrimSpace(p.values.Get(name))
+}
+
+// enum returns the lowercased value of name if it is one of allowed, or
+// fallback when absent
+func (p *queryParams) enum(name string, allowed []string, fallback string) string {
+	raw := strings.ToLower(p.string(name))
+	if raw == "" {
+		return fallback
+	}
+	if !slices.Contains(allowed, raw) {
+		p.fail(name, name+" must be one of: "+strings.Join(allowed, ", "), allowed)
+		return fallback
+	}
+	return raw
+}
+
+// intRange returns name as an integer within [min, max], or fallback when absent
+func (p *queryParams) intRange(name string, fallback, min, max int) int {
+	raw := p.string(name)
+	if raw == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(raw)
+	if err != nil || parsed < min || parsed > max {
+		p.fail(name, fmt.Sprintf("%s must be an integer between %d and %d", name, min, max), nil)
+		return fallback
+	}
+	return parsed
+}
+
+// offset returns a non-negative paging offset, 0 when absent
+func (p *queryParams) offset() int {
+	raw := p.string("offset")
+	if raw == "" {
+		return 0
+	}
+	parsed, err := strconv.Atoi(raw)
+	if err != nil || parsed < 0 {
+		p.fail("offset", "offset must be a non-negative integer", nil)
+		return 0
+	}
+	return parsed
+}
+
+// set returns the comma-separated values of name, each of which must be one
+// of allowed; empty when absent
+func (p *queryParams) set(name string, allowed []string) map[string]bool {
+	values := make(map[string]bool)
+	for _, value := range strings.Split(p.string(name), ",") {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		if !slices.Contains(allowed, value) {
+			p.fail(name, fmt.Sprintf("%s values must be among: %s", name, strings.Join(allowed, ", ")), allowed)
+			continue
+		}
+		values[value] = true
+	}
+	return values
+}
+
+// boolean returns name as true or false, or fallback when absent
+func (p *queryParams) boolean(name string, fallback bool) bool {
+	raw := p.string(name)
+	if raw == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(raw)
+	if err != nil {
+		p.fail(name, name+" must be true or false", nil)
+		return fallback
+	}
+	return parsed
+}
+
+// duration returns name as a positive Go duration ("30s") or whole seconds
+// ("30"), or 0 when absent
+func (p *queryParams) duration(name string) time.Duration {
+	raw := p.string(name)
+	if raw == "" {
+		return 0
+	}
+	parsed, err := time.ParseDuration(raw)
+	if err != nil {
+		if seconds, convErr := strconv.Atoi(raw); convErr == nil {
+			parsed, err = time.Duration(seconds)*time.Second, nil
+		}
+	}
+	if err != nil || parsed <= 0 {
+		p.fail(name, name+" must be a positive duration such as 30s", nil)
+		return 0
+	}
+	return parsed
+}
+
+// language returns the ?lang translation for stat display names
+func (p *queryParams) language() string {
+	lang, ok := steam.ParseLanguage(p.values.Get("lang"))
+	if !ok {
+		p.fail("lang", "Unsupported lang; expected one of: "+strings.Join(steam.Languages(), ", "), steam.Languages())
+		return steam.DefaultLanguage
+	}
+	return lang
+}
+
+// formatter returns the ?locale number and duration formatting, chosen
+// independently of the lang used for names
+func (p *queryParams) formatter() steam.StatFormatter {
+	format, ok := steam.StatFormatterFor(p.values.Get("locale"))
+	if !ok {
+		p.fail("locale", "Unsupported locale; expected one of: "+strings.Join(steam.Locales(), ", "), steam.Locales())
+		format, _ = steam.StatFormatterFor("")
+	}
+	return format
+}
+
+// writeParamErrors answers 400 for invalid query parameters. details.field
+// names the first one, as for any validation error; details.errors lists all.
+func writeParamErrors(w http.ResponseWriter, r *http.Request, err error) {
+	errs, ok := err.(paramErrors)
+	if !ok || len(errs) == 0 {
+		writeValidationError(w, r, err.Error(), "")
+		return
+	}
+	message := errs[0].Message
+	if len(errs) > 1 {
+		fields := make([]string, len(errs))
+		for i, e := range errs {
+			fields[i] = e.Field
+		}
+		message = fmt.Sprintf("%d invalid query parameters: %s", len(errs), strings.Join(fields, ", "))
+	}
+	writeError(w, r, "VALIDATION_ERROR", message, http.StatusBadRequest,
+		map[string]interface{}{"field": errs[0].Field, "errors": errs},
+		nil)
+}
+
+// localeOptions are the lang and locale parameters shared by stat responses
+type localeOptions struct {
+	Lang   string
+	Format steam.StatFormatter
+}
+
+func (p *queryParams) localeOptions() localeOptions {
+	return localeOptions{Lang: p.language(), Format: p.formatter()}
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// recordPeakGrades notes the grades in a player's structured stats and returns
+// their all-time peaks
+func (h *Handler) recordPeakGrades(steamID string, stats *models.StatsData) *models.PeakGrades {
+	return h.players.ObserveGrades(steamID, currentGrades(stats, time.Now().UTC()))
+}
+
+// currentGrades reads the live killer and survivor grades from structured stats
+func currentGrades(stats *models.StatsData, now time.Time) models.PeakGrades {
+	var observed models.PeakGrades
+	for _, stat := range statsFromStatsData(stats) {
+		if stat.ValueType != "grade" {
+			continue
+		}
+		rank, grade, ok := steam.GradeRank(stat.Value, stat.ID)
+		if !ok {
+			continue
+		}
+		peak := &models.GradePeak{Grade: grade, Rank: rank, AchievedAt: now}
+		switch stat.Alias {
+		case "killer_grade":
+			observed.Killer = peak
+		case "survivor_grade":
+			observed.Survivor = peak
+		}
+	}
+	return observed
+}
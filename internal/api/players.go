@@ -0,0 +1,311 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/demo"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+const (
+	maxBatchPlayers = steam.MaxSummaryBatch
+	// batchStatsConcurrency caps the stats fetches one lookup runs at once, so
+	// a full batch leaves pool slots for concurrent requests
+	batchStatsConcurrency = 8
+)
+
+// GetPlayers looks up several players at once: ?steamids= takes up to 100
+// comma-separated SteamID64s. Summaries the cache doesn't hold come from one
+// batched GetPlayerSummaries call, and stats are fetched per player with
+// bounded concurrency. Both are cached per player, sharing entries with the
+// single-player endpoints. A player who can't be found, or whose stats can't
+// be fetched, carries an error rather than failing the lookup.
+//
+// A lookup can cost Steam a call per player, so it is charged to the rate
+// limit per player whose stats aren't cached. While the circuit breaker is
+// open or the daily quota is past its soft limit, only cached players are
+// served.
+func (h *Handler) GetPlayers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	start := time.Now()
+
+	ids, ok := batchSteamIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if h.config.DemoMode {
+		h.serveDemoPlayers(w, r, ids)
+		return
+	}
+
+	cacheOnly := h.batchCacheOnly()
+	if cacheOnly == "" && h.limiter != nil {
+		// The request already paid one token; every further player whose
+		// stats must come from Steam costs another, as it would looked up
+		// alone. A full uncached batch takes the whole per-minute allowance.
+		if uncached := h.uncachedStats(ctx, ids); uncached > 1 {
+			allowed, state := h.limiter.TakeN(ctx, rateLimitClient(r), uncached-1)
+			setRateLimitHeaders(w, state)
+			if !allowed {
+				log.Warn("Player batch exceeds rate limit",
+					"requested", len(ids),
+					"uncached", uncached,
+					"remaining", state.Remaining)
+				writeRateLimited(w, h.limiter, state)
+				return
+			}
+		}
+	}
+
+	summaries, summaryErr := h.fetchPlayerSummaries(ctx, ids, cacheOnly != "")
+	if ctx.Err() != nil {
+		writeTimeoutError(w, r, "players")
+		return
+	}
+	if len(summaries) == 0 && summaryErr != nil {
+		writeErrorResponse(w, summaryErr)
+		return
+	}
+
+	batch := models.BatchPlayers{
+		Players:   make([]models.BatchPlayer, len(ids)),
+		Requested: len(ids),
+		CacheOnly: cacheOnly,
+	}
+	group := h.workers.Group(ctx).Limit(batchStatsConcurrency)
+	for i, steamID := range ids {
+		player := &batch.Players[i]
+		player.SteamID = steamID
+		summary, found := summaries[steamID]
+		switch {
+		case found:
+			player.Summary = summaryModel(summary)
+		case cacheOnly != "":
+			player.Error = models.BatchNotCached
+			continue
+		case summaryErr != nil:
+			player.Error = models.BatchSummaryUnavailable
+			continue
+		default:
+			player.Error = models.BatchPlayerNotFound
+			continue
+		}
+		if cacheOnly != "" {
+			if stats, ok := cache.Get(h.sharedCache(), playerStatsCache.Key(playerCacheID(ctx, steamID))); ok {
+				player.Stats, player.Source = &stats, "cache"
+			} else {
+				player.Error = models.BatchNotCached
+			}
+			continue
+		}
+		group.Go(fmt.Sprintf("stats_%d", i), func(taskCtx context.Context) error {
+			stats, source, err := h.fetchPlayerStatsForSummary(taskCtx, summary)
+			if err != nil {
+				player.Error = batchStatsError(err)
+				return err
+			}
+			player.Stats, player.Source = &stats, source
+			return nil
+		})
+	}
+	if err := group.Wait(); ctx.Err() != nil {
+		writeTimeoutError(w, r, "players")
+		return
+	} else if err != nil {
+		log.Debug("Player batch completed with stats errors", "errors", err.Error())
+	}
+
+	for _, player := range batch.Players {
+		if player.Summary != nil {
+			batch.Found++
+		}
+		if player.Stats != nil {
+			batch.WithStats++
+			h.players.Record(player.SteamID, player.Stats.DisplayName, player.Stats.Avatar)
+		}
+	}
+	batch.GeneratedAt = time.Now().UTC()
+
+	log.Info("Player batch generated",
+		"requested", batch.Requested,
+		"found", batch.Found,
+		"with_stats", batch.WithStats,
+		"cache_only", cacheOnly,
+		"duration", time.Since(start))
+
+	streamJSONResponse(w, r, batch)
+}
+
+// batchSteamIDs parses ?steamids=, dropping repeats. Only SteamID64s are
+// taken: resolving vanity names would cost a Steam call each, which is what
+// batching avoids.
+func batchSteamIDs(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	raw := r.URL.Query().Get("steamids")
+	if strings.TrimSpace(raw) == "" {
+		writeValidationError(w, r, "steamids is required: up to 100 comma-separated SteamID64s", "steamids")
+		return nil, false
+	}
+	parts := strings.Split(raw, ",")
+	if len(parts) > maxBatchPlayers {
+		writeValidationError(w, r, fmt.Sprintf("steamids may list at most %d players", maxBatchPlayers), "steamids")
+		return nil, false
+	}
+
+	ids := make([]string, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+	for i, part := range parts {
+		id := strings.TrimSpace(part)
+		if !validateSteamID(id) {
+			writeValidationError(w, r, fmt.Sprintf("steamids[%d]: %q is not a SteamID64", i, id), "steamids")
+			return nil, false
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, true
+}
+
+// batchCacheOnly says why a batch lookup must not reach Steam right now, or
+// "" when it may: fanning out to a hundred calls would only deepen an outage
+// or a quota squeeze
+func (h *Handler) batchCacheOnly() string {
+	if manager := h.cacheManager(); manager != nil {
+		if cb := manager.GetCircuitBreaker(); cb != nil && cb.GetState() == cache.CircuitOpen {
+			return "circuit_open"
+		}
+	}
+	switch h.steamClient.Quota().Level() {
+	case steam.QuotaCritical:
+		return "quota_critical"
+	case steam.QuotaElevated:
+		return "quota_elevated"
+	}
+	return ""
+}
+
+// uncachedStats counts the steamIDs whose stats a lookup would fetch from
+// Steam, without touching the cache's hit and miss counts
+func (h *Handler) uncachedStats(ctx context.Context, steamIDs []string) int {
+	shared := h.sharedCache()
+	uncached := 0
+	for _, steamID := range steamIDs {
+		if _, ok := cache.StoredAt(shared, playerStatsCache.Key(playerCacheID(ctx, steamID))); !ok {
+			uncached++
+		}
+	}
+	return uncached
+}
+
+// fetchPlayerSummaries returns the summaries of steamIDs keyed by ID: from the
+// cache where it holds them, and from one batched Steam call for the rest
+// unless cacheOnly. IDs Steam knows no account for are absent. When the Steam
+// call fails the cached summaries are returned with its error.
+func (h *Handler) fetchPlayerSummaries(ctx context.Context, steamIDs []string, cacheOnly bool) (map[string]steam.SteamPlayer, *steam.APIError) {
+	shared := h.sharedCache()
+	summaries := make(map[string]steam.SteamPlayer, len(steamIDs))
+	var missing []string
+	for _, steamID := range steamIDs {
+		if summary, ok := cache.Get(shared, playerSummaryCache.Key(steamID)); ok {
+			summaries[steamID] = summary
+		} else {
+			missing = append(missing, steamID)
+		}
+	}
+	if len(missing) == 0 || cacheOnly {
+		return summaries, nil
+	}
+
+	fetched, apiErr := h.steamClient.GetPlayerSummariesContext(ctx, missing)
+	if apiErr != nil {
+		log.Warn("Batched player summaries failed",
+			"requested", len(missing),
+			"cached", len(summaries),
+			"error", apiErr.Message)
+		return summaries, apiErr
+	}
+	ttl := h.cacheTTL(cache.PlayerSummaryPrefix, func(ttl cache.TTLConfig) time.Duration { return ttl.PlayerSummary })
+	for steamID, summary := range fetched {
+		summaries[steamID] = summary
+		if shared != nil {
+			cache.Set(shared, playerSummaryCache.Key(steamID), summary, ttl)
+		}
+	}
+	return summaries, nil
+}
+
+// fetchPlayerStatsForSummary is fetchPlayerStatsWithSource for a player whose
+// summary is already known, so a cache miss costs only the stats call
+func (h *Handler) fetchPlayerStatsForSummary(ctx context.Context, summary steam.SteamPlayer) (models.PlayerStats, string, error) {
+	cacheKey := playerStatsCache.Key(playerCacheID(ctx, summary.SteamID))
+	ttl := h.cacheTTL(cache.PlayerStatsPrefix, func(ttl cache.TTLConfig) time.Duration { return ttl.PlayerStats })
+	playerStats, hit, err := cache.GetOrLoad(ctx, h.sharedCache(), cacheKey, ttl, func(ctx context.Context) (models.PlayerStats, error) {
+		rawStats, statErr := h.steamClient.GetPlayerStatsContext(ctx, summary.SteamID)
+		if statErr != nil {
+			return models.PlayerStats{}, fmt.Errorf("steam stats failed: %w", statErr)
+		}
+		return convertToPlayerStats(steam.MapSteamStats(rawStats.Stats, summary.SteamID, summary.PersonaName), summary.AvatarFull), nil
+	})
+	if hit {
+		return playerStats, "cache", nil
+	}
+	return playerStats, "api", err
+}
+
+// batchStatsError classifies a player's stats failure. Steam answers 403 for
+// hidden game details, which is how private profiles show up in a batch.
+func batchStatsError(err error) string {
+	if strings.Contains(err.Error(), "HTTP 403") {
+		return "private_profile"
+	}
+	return classifyError(err)
+}
+
+func summaryModel(summary steam.SteamPlayer) *models.PlayerSummary {
+	return &models.PlayerSummary{
+		SteamID:     summary.SteamID,
+		DisplayName: summary.PersonaName,
+		Avatar:      summary.Avatar,
+		AvatarFull:  summary.AvatarFull,
+	}
+}
+
+// serveDemoPlayers answers a lookup from the bundled demo players; any other
+// ID is reported not found
+func (h *Handler) serveDemoPlayers(w http.ResponseWriter, r *http.Request, ids []string) {
+	batch := models.BatchPlayers{
+		Players:     make([]models.BatchPlayer, len(ids)),
+		Requested:   len(ids),
+		Demo:        true,
+		GeneratedAt: demo.LoadedAt(),
+	}
+	for i, steamID := range ids {
+		batch.Players[i].SteamID = steamID
+		player, found := demo.Player(steamID)
+		if !found {
+			batch.Players[i].Error = models.BatchPlayerNotFound
+			continue
+		}
+		stats := player.PlayerStats
+		batch.Players[i].Summary = &models.PlayerSummary{
+			SteamID:     stats.SteamID,
+			DisplayName: stats.DisplayName,
+			AvatarFull:  stats.Avatar,
+		}
+		batch.Players[i].Stats = &stats
+		batch.Players[i].Source = "demo"
+		batch.Found++
+		batch.WithStats++
+	}
+	w.Header().Set("X-Demo-Mode", "true")
+	streamJSONResponse(w, r, batch)
+}
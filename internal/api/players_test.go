@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+func getPlayers(t *testing.T, root *mux.Router, ids []string) (*httptest.ResponseRecorder, models.BatchPlayers) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/players?steamids="+strings.Join(ids, ","), nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("User-Agent", "players-test")
+	rec := httptest.NewRecorder()
+	root.ServeHTTP(rec, req)
+	var batch models.BatchPlayers
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &batch); err != nil {
+			t.Fatalf("batch body: %v", err)
+		}
+	}
+	return rec, batch
+}
+
+// TestGetPlayersChargesUncachedStats requires a batch lookup to cost a rate
+// limit token per player it fetches from Steam, and a repeat served from the
+// cache to cost only the request's own
+func TestGetPlayersChargesUncachedStats(t *testing.T) {
+	fake := newFakeSteam(t, 0)
+	root := mux.NewRouter()
+	handler := RegisterRoutes(root.PathPrefix("/api").Subrouter())
+	t.Cleanup(func() { handler.Close() })
+
+	ids := make([]string, 10)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("765611980000001%02d", i)
+	}
+	remaining := func(rec *httptest.ResponseRecorder) int {
+		n, err := strconv.Atoi(rec.Header().Get("X-RateLimit-Remaining"))
+		if err != nil {
+			t.Fatalf("X-RateLimit-Remaining: %v", err)
+		}
+		return n
+	}
+
+	rec, batch := getPlayers(t, root, ids)
+	if rec.Code != http.StatusOK || batch.WithStats != len(ids) {
+		t.Fatalf("first lookup: status %d, %d of %d with stats", rec.Code, batch.WithStats, len(ids))
+	}
+	if got, want := remaining(rec), 100-len(ids); got != want {
+		t.Errorf("after an uncached batch of %d: %d tokens left, want %d", len(ids), got, want)
+	}
+
+	calls := fake.calls.Load()
+	rec, _ = getPlayers(t, root, ids)
+	if got, want := remaining(rec), 100-len(ids)-1; got != want {
+		t.Errorf("after a cached batch: %d tokens left, want %d", got, want)
+	}
+	if fake.calls.Load() != calls {
+		t.Errorf("cached batch made %d Steam calls", fake.calls.Load()-calls)
+	}
+
+	// 89 tokens remain; 95 more uncached players cost 95
+	more := make([]string, 95)
+	for i := range more {
+		more[i] = fmt.Sprintf("765611980000002%02d", i)
+	}
+	if rec, _ = getPlayers(t, root, more); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("batch over the remaining allowance: status %d, want 429", rec.Code)
+	}
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// Outcomes of one synthetic probe run
+const (
+	probeOK       = "ok"       // every source answered
+	probeDegraded = "degraded" // stats answered but achievements or structured stats failed
+	probeFailed   = "failed"   // no response could be assembled
+	probeSkipped  = "skipped"  // the Steam quota is under pressure
+)
+
+var (
+	probeRuns     = metrics.NewCounter("dbd_probe_runs", "Synthetic end-to-end probe runs.")
+	probeFailures = metrics.NewCounter("dbd_probe_failures", "Synthetic probe runs that assembled no response.")
+	probeDegrades = metrics.NewCounter("dbd_probe_degraded", "Synthetic probe runs where an optional source failed.")
+	probeSkips    = metrics.NewCounter("dbd_probe_skipped", "Synthetic probe runs skipped under Steam quota pressure.")
+	probeUp       = metrics.NewGauge("dbd_probe_up", "1 when the last synthetic probe assembled a full response, else 0.")
+	probeLatency  = metrics.NewGauge("dbd_probe_latency_ms", "Duration of the last synthetic probe run in milliseconds.")
+	probeLastOK   = metrics.NewGauge("dbd_probe_last_success_timestamp_seconds", "Unix time of the last fully successful synthetic probe.")
+)
+
+// prober fetches a public test profile end to end on a schedule, through the
+// same cache eviction, worker pool, Steam client and mapping a fresh request
+// uses, so operators see real pipeline health when no users are around
+type prober struct {
+	mu      sync.Mutex
+	last    map[string]interface{}
+	lastOK  time.Time
+	stop    func()
+	steamID string
+}
+
+// startProber runs the probe every PROBE_INTERVAL_SECS when PROBE_STEAM_ID
+// is set; demo mode never talks to Steam
+func (h *Handler) startProber() {
+	if h.config.DemoMode || h.config.ProbeSteamID == "" {
+		return
+	}
+	if !validateSteamID(h.config.ProbeSteamID) {
+		log.Warn("Synthetic probe disabled; PROBE_STEAM_ID is not a SteamID64", "probe_steam_id", h.config.ProbeSteamID)
+		return
+	}
+	p := &prober{steamID: h.config.ProbeSteamID}
+	stop := make(chan struct{})
+	ticker := time.NewTicker(time.Duration(h.config.ProbeIntervalSecs) * time.Second)
+	p.stop = func() {
+		ticker.Stop()
+		close(stop)
+	}
+	h.prober = p
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				h.runProbe(p)
+			case <-stop:
+				return
+			}
+		}
+	}()
+	log.Info("Synthetic probe enabled", "steam_id", p.steamID, "interval_secs", h.config.ProbeIntervalSecs)
+}
+
+// runProbe assembles the probe profile's response from Steam and records
+// the outcome
+func (h *Handler) runProbe(p *prober) {
+	start := time.Now()
+	probeRuns.Add(1)
+	if !h.steamClient.Quota().AllowFresh() {
+		probeSkips.Add(1)
+		p.record(probeSkipped, start, 0, nil, "")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(steam.WithApp(context.Background(), steam.DefaultApp()), h.config.RequestTimeout)
+	defer cancel()
+	if h.cacheManager() != nil {
+		h.evictPlayer(ctx, p.steamID)
+	}
+	response, _, err := h.assembleCombined(ctx, p.steamID, p.steamID, start, log.PlayerContext(p.steamID))
+	elapsed := time.Since(start)
+	metrics.ObserveLatency("synthetic_probe", elapsed, "")
+	probeLatency.Set(elapsed.Milliseconds())
+
+	outcome := probeOK
+	var failed []string
+	switch {
+	case err != nil:
+		outcome = probeFailed
+	default:
+		sources := response.DataSources
+		for _, source := range []struct {
+			name string
+			info models.DataSourceInfo
+		}{
+			{"stats", sources.Stats},
+			{"achievements", sources.Achievements},
+			{"structured_stats", sources.StructuredStats},
+		} {
+			if !source.info.Success {
+				failed = append(failed, source.name)
+			}
+		}
+		if len(failed) > 0 {
+			outcome = probeDegraded
+		}
+	}
+
+	errText := ""
+	switch outcome {
+	case probeOK:
+		probeUp.Set(1)
+		probeLastOK.Set(time.Now().Unix())
+	case probeDegraded:
+		probeUp.Set(0)
+		probeDegrades.Add(1)
+		log.Warn("Synthetic probe degraded", "steam_id", p.steamID, "failed_sources", failed, "duration", elapsed)
+	case probeFailed:
+		probeUp.Set(0)
+		probeFailures.Add(1)
+		errText = err.Error()
+		log.Error("Synthetic probe failed", "steam_id", p.steamID, "error", errText, "duration", elapsed)
+	}
+	p.record(outcome, start, elapsed, failed, errText)
+}
+
+func (p *prober) record(outcome string, at time.Time, elapsed time.Duration, failed []string, errText string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if outcome == probeOK {
+		p.lastOK = at
+	}
+	last := map[string]interface{}{
+		"outcome":     outcome,
+		"at":          at.UTC().Format(time.RFC3339),
+		"duration_ms": elapsed.Milliseconds(),
+	}
+	if len(failed) > 0 {
+		last["failed_sources"] = failed
+	}
+	if errText != "" {
+		last["error"] = errText
+	}
+	p.last = last
+}
+
+// Status summarises the probe for the health endpoint
+func (p *prober) Status() map[string]interface{} {
+	if p == nil {
+		return map[string]interface{}{"enabled": false}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status := map[string]interface{}{
+		"enabled":  true,
+		"steam_id": p.steamID,
+		"last":     p.last,
+	}
+	if !p.lastOK.IsZero() {
+		status["last_success"] = p.lastOK.UTC().Format(time.RFC3339)
+	}
+	return status
+}
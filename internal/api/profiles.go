@@ -0,0 +1,257 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+	"github.com/rgonzalez12/dbd-analytics/internal/store"
+)
+
+const (
+	profileTokenHeader     = "X-Profile-Token"
+	profileLinkCookie      = "profile_link_state"
+	profileLinkTTL         = 10 * time.Minute // to finish signing in at Steam
+	maxProfileLinkBodySize = 1 << 10          // enforced by the route's body limit
+)
+
+type beginProfileLinkRequest struct {
+	ProfileID string `json:"profile_id"`
+}
+
+// BeginProfileLink starts linking a Steam account. With no body it links to
+// a new profile; with {"profile_id": "..."} and the profile's X-Profile-Token
+// it adds an account to that profile. The response's redirect_url is where
+// to send the player to sign in to the account at Steam, which proves they
+// own it; Steam then returns them to the link callback. The sign-in must
+// finish in the same browser: the state is also set in an HttpOnly cookie
+// that the callback requires.
+func (h *Handler) BeginProfileLink(w http.ResponseWriter, r *http.Request) {
+	var body beginProfileLinkRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		writeValidationError(w, r, "Request body must be empty or JSON of the form {\"profile_id\": \"...\"}", "body")
+		return
+	}
+
+	state, expires, err := h.profiles.BeginLink(body.ProfileID, r.Header.Get(profileTokenHeader))
+	if err != nil {
+		writeProfileError(w, r, body.ProfileID, err)
+		return
+	}
+
+	h.setLinkStateCookie(w, state, expires)
+	returnTo := h.profileLinkReturnTo(state)
+	writeJSONResponse(w, map[string]interface{}{
+		"redirect_url": steam.OpenIDLoginURL(h.config.SteamOpenIDURL, returnTo, h.config.PublicBaseURL+"/"),
+		"expires_at":   expires.UTC(),
+	})
+}
+
+// CompleteProfileLink is where Steam returns a player after signing in. The
+// assertion is verified with Steam before the account is linked, and only
+// in the browser that began the link. A new or recovered profile's token is
+// returned only here, in profile_token.
+func (h *Handler) CompleteProfileLink(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	state := query.Get("state")
+	if state == "" {
+		writeValidationError(w, r, "state is required; start linking with POST /api/profile/link", "state")
+		return
+	}
+	// A state arriving without its cookie was begun elsewhere: a link URL
+	// sent to another player would otherwise link their account
+	cookie, err := r.Cookie(profileLinkCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		writeError(w, r, "LINK_STATE_MISMATCH", "This link was started in another browser; start linking again from this one", http.StatusForbidden, nil, nil)
+		return
+	}
+
+	steamID, apiErr := h.steamClient.VerifyOpenID(r.Context(), h.config.SteamOpenIDURL, h.profileLinkReturnTo(state), query)
+	if apiErr != nil {
+		log.Warn("Steam OpenID assertion rejected", "error", apiErr.Message)
+		writeErrorResponse(w, apiErr)
+		return
+	}
+
+	profile, token, err := h.profiles.CompleteLink(state, steamID)
+	h.setLinkStateCookie(w, "", time.Time{}) // the state is used up either way
+	if err != nil {
+		writeProfileError(w, r, "", err)
+		return
+	}
+
+	log.Info("Steam account linked", "profile_id", profile.ID, "steam_id", steamID, "accounts", len(profile.Accounts))
+	response := map[string]interface{}{
+		"profile":         profile,
+		"linked_steam_id": steamID,
+	}
+	if token != "" {
+		response["profile_token"] = token
+	}
+	writeJSONResponse(w, response)
+}
+
+// UnlinkProfileAccount removes an account from a profile; unlinking the last
+// account deletes the profile
+func (h *Handler) UnlinkProfileAccount(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	profile, remains, err := h.profiles.Unlink(vars["id"], r.Header.Get(profileTokenHeader), vars["steamid"])
+	if err != nil {
+		writeProfileError(w, r, vars["id"], err)
+		return
+	}
+	if !remains {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSONResponse(w, profile)
+}
+
+// GetProfile combines a profile's linked accounts: each account's headline
+// stats, and totals across them as one player. An account whose stats can't
+// be fetched is listed as unavailable with a warning rather than failing the
+// profile.
+func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	start := time.Now()
+
+	id := mux.Vars(r)["id"]
+	profile, ok := h.profiles.Get(id)
+	if !ok {
+		writeProfileError(w, r, id, store.ErrProfileNotFound)
+		return
+	}
+
+	accounts := make([]steam.ProfileAccountData, len(profile.Accounts))
+	statsErrs := make([]error, len(accounts))
+	achErrs := make([]error, len(accounts))
+	gradeErrs := make([]error, len(accounts))
+	group := h.workers.Group(ctx)
+	for i, account := range profile.Accounts {
+		steamID := account.SteamID
+		accounts[i].SteamID, accounts[i].LinkedAt = steamID, account.LinkedAt
+		group.Go(fmt.Sprintf("stats_%d", i), func(taskCtx context.Context) error {
+			stats, _, err := h.fetchPlayerStatsWithSource(taskCtx, steamID)
+			if err == nil {
+				accounts[i].Stats = &stats
+			}
+			statsErrs[i] = err
+			return err
+		})
+		group.Go(fmt.Sprintf("achievements_%d", i), func(taskCtx context.Context) error {
+			accounts[i].Achievements, _, achErrs[i] = h.fetchPlayerAchievementsWithSource(taskCtx, steamID)
+			return achErrs[i]
+		})
+		group.Go(fmt.Sprintf("structured_%d", i), func(taskCtx context.Context) error {
+			structured, _, err := h.fetchPlayerStructuredStatsWithSource(taskCtx, steamID)
+			if err == nil {
+				accounts[i].Grades = currentGrades(structured, time.Now().UTC())
+			}
+			gradeErrs[i] = err
+			return err
+		})
+	}
+	if err := group.Wait(); ctx.Err() != nil {
+		writeTimeoutError(w, r, "profile")
+		return
+	} else if err != nil {
+		log.Debug("Profile fetch completed with source errors", "profile_id", id, "errors", err.Error())
+	}
+
+	var warnings []string
+	for i, account := range accounts {
+		switch {
+		case statsErrs[i] != nil:
+			accounts[i].Achievements = nil
+			warnings = append(warnings, fmt.Sprintf("Stats unavailable for %s: %s", account.SteamID, classifyError(statsErrs[i])))
+			continue
+		case achErrs[i] != nil:
+			warnings = append(warnings, fmt.Sprintf("Achievements unavailable for %s: %s", account.SteamID, classifyError(achErrs[i])))
+		}
+		if gradeErrs[i] != nil {
+			warnings = append(warnings, fmt.Sprintf("Grades unavailable for %s: %s", account.SteamID, classifyError(gradeErrs[i])))
+		}
+	}
+
+	stats := steam.BuildProfileStats(accounts)
+	stats.ID = profile.ID
+	stats.Warnings = warnings
+
+	if stats.Combined.AvailableAccounts == 0 {
+		writeError(w, r, "PROFILE_STATS_UNAVAILABLE", "No linked account's stats could be fetched", http.StatusBadGateway,
+			map[string]interface{}{"profile_id": profile.ID, "warnings": warnings},
+			nil)
+		return
+	}
+
+	log.Info("Profile stats generated",
+		"profile_id", profile.ID,
+		"accounts", len(stats.Accounts),
+		"available", stats.Combined.AvailableAccounts,
+		"warnings", len(warnings),
+		"duration", time.Since(start))
+
+	streamJSONResponse(w, r, stats)
+}
+
+// setLinkStateCookie binds a link's state to the browser that began it, for
+// the callback only; an empty state clears the cookie. It is Lax, not
+// Strict, because Steam returns the player with a cross-site navigation.
+func (h *Handler) setLinkStateCookie(w http.ResponseWriter, state string, expires time.Time) {
+	cookie := &http.Cookie{
+		Name:     profileLinkCookie,
+		Value:    state,
+		Path:     h.linkCallback,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(h.config.PublicBaseURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	}
+	if state == "" {
+		cookie.MaxAge = -1
+	} else {
+		cookie.Expires = expires
+		cookie.MaxAge = int(time.Until(expires).Seconds())
+	}
+	http.SetCookie(w, cookie)
+}
+
+// profileLinkReturnTo is the callback URL a link's Steam sign-in returns to
+func (h *Handler) profileLinkReturnTo(state string) string {
+	return h.config.PublicBaseURL + h.linkCallback + "?state=" + url.QueryEscape(state)
+}
+
+// writeProfileError answers a profile store error with its status
+func writeProfileError(w http.ResponseWriter, r *http.Request, profileID string, err error) {
+	details := map[string]interface{}{}
+	if profileID != "" {
+		details["profile_id"] = profileID
+	}
+	switch {
+	case errors.Is(err, store.ErrProfileNotFound):
+		writeError(w, r, "PROFILE_NOT_FOUND", "No profile with this ID", http.StatusNotFound, details, nil)
+	case errors.Is(err, store.ErrProfileToken):
+		writeError(w, r, "PROFILE_TOKEN_INVALID", "Valid "+profileTokenHeader+" header required for this profile", http.StatusForbidden, details, nil)
+	case errors.Is(err, store.ErrLinkExpired):
+		writeError(w, r, "LINK_EXPIRED", err.Error(), http.StatusGone, details, nil)
+	case errors.Is(err, store.ErrAccountLinked):
+		writeError(w, r, "ACCOUNT_ALREADY_LINKED", err.Error(), http.StatusConflict, details, nil)
+	case errors.Is(err, store.ErrProfileAccountLimit):
+		writeError(w, r, "PROFILE_ACCOUNT_LIMIT", err.Error(), http.StatusForbidden, details, nil)
+	case errors.Is(err, store.ErrAccountNotLinked):
+		writeError(w, r, "ACCOUNT_NOT_LINKED", err.Error(), http.StatusNotFound, details, nil)
+	default:
+		writeErrorResponse(w, steam.NewInternalError(err))
+	}
+}
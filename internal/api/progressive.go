@@ -0,0 +1,220 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// ndjsonContentType asks for a progressive player response: one JSON
+// document per line, flushed as each is ready
+const ndjsonContentType = "application/x-ndjson"
+
+var progressiveResponses = metrics.NewCounter("dbd_progressive_responses",
+	"Player responses sent as stats and achievements documents.")
+
+// acceptsNDJSON reports whether the request's Accept header asks for NDJSON
+func acceptsNDJSON(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || mediaType != ndjsonContentType {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+type statsReadyKey struct{}
+
+// withStatsReady has assembleCombined pass the stats half of the response to
+// ready as soon as it has it, before achievements are in. ready runs on a
+// worker and must not block.
+func withStatsReady(ctx context.Context, ready func(models.PlayerStatsWithAchievements)) context.Context {
+	return context.WithValue(ctx, statsReadyKey{}, ready)
+}
+
+func statsReadyFromContext(ctx context.Context) func(models.PlayerStatsWithAchievements) {
+	ready, _ := ctx.Value(statsReadyKey{}).(func(models.PlayerStatsWithAchievements))
+	return ready
+}
+
+// statsHalf is an undecorated combined response from the stats fetches
+// alone, with achievements pending
+func (h *Handler) statsHalf(ctx context.Context, steamID string, stats models.PlayerStats, statsSource string, structured *models.StatsData, structuredSource string, structuredErr error) models.PlayerStatsWithAchievements {
+	cacheID := playerCacheID(ctx, steamID)
+	now := time.Now()
+	response := models.PlayerStatsWithAchievements{
+		PlayerStats:       stats,
+		AchievementsState: models.AchievementsPending,
+		DataSources: models.DataSourceStatus{
+			Stats:           models.DataSourceInfo{Success: true, Source: statsSource, FetchedAt: now},
+			StructuredStats: models.DataSourceInfo{Success: structuredErr == nil, Source: structuredSource, FetchedAt: now},
+		},
+	}
+	if statsSource == "cache" {
+		response.DataSources.Stats.FetchedAt = cachedFetchTime(h.sharedCache(), playerStatsCache.Key(cacheID), now)
+	}
+	if structuredErr != nil {
+		response.DataSources.StructuredStats.Error = structuredErr.Error()
+	} else {
+		response.Stats = structured
+		if structuredSource == "cache" {
+			response.DataSources.StructuredStats.FetchedAt = cachedFetchTime(h.sharedCache(), structuredStatsCache.Key(cacheID), now)
+		}
+	}
+	return response
+}
+
+// ndjsonStream writes one document per line, starting the stream on the
+// first so errors before it still get a normal JSON response
+type ndjsonStream struct {
+	w       http.ResponseWriter
+	out     io.Writer
+	started bool
+}
+
+var errStreamRefused = errors.New("response can no longer be streamed")
+
+func (s *ndjsonStream) send(doc interface{}) error {
+	if !s.started {
+		s.w.Header().Set("Content-Type", ndjsonContentType)
+		s.w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+		if starter, ok := s.w.(streamStarter); ok {
+			out, ok := starter.startStream(http.StatusOK)
+			if !ok {
+				return errStreamRefused
+			}
+			s.out = out
+		} else {
+			s.w.WriteHeader(http.StatusOK)
+			s.out = s.w
+		}
+		s.started = true
+		progressiveResponses.Add(1)
+	}
+	if err := json.NewEncoder(s.out).Encode(doc); err != nil {
+		return err
+	}
+	http.NewResponseController(s.w).Flush()
+	return nil
+}
+
+// serveProgressive answers Accept: application/x-ndjson on the combined
+// player route with two documents: the response without achievements as soon
+// as stats are in, then the achievements and final data sources. A slow
+// achievements fetch then delays only the second document instead of the
+// whole response.
+func (h *Handler) serveProgressive(w http.ResponseWriter, r *http.Request, steamID, resolvedSteamID string, resolvedAs steam.IDType, opts playerOptions, requestLogger *slog.Logger) {
+	ctx := r.Context()
+	app := steam.AppFromContext(ctx)
+	stream := &ndjsonStream{w: w}
+	w.Header().Set("X-Resolved-As", string(resolvedAs))
+
+	// The player is recorded with the first document only
+	sendStats := func(half models.PlayerStatsWithAchievements) bool {
+		half = h.recordCombined(r, resolvedSteamID, resolvedAs, half)
+		half = h.decorateCombined(r, resolvedAs, opts.Lang, opts.Format, half)
+		half.Achievements = nil
+		half.AchievementsState = models.AchievementsPending
+		half.DataAgeSeconds = int64(time.Since(dataAsOf(half.DataSources)).Seconds())
+		return stream.send(models.ProgressiveStats{Part: "stats", PlayerStatsWithAchievements: half}) == nil
+	}
+	sendAchievements := func(response models.PlayerStatsWithAchievements, warnings []string) {
+		if !stream.started && !sendStats(response) {
+			return
+		}
+		response = h.decorateCombined(r, resolvedAs, opts.Lang, opts.Format, response)
+		stream.send(models.ProgressiveAchievements{
+			Part:              "achievements",
+			Achievements:      response.Achievements,
+			AchievementsState: response.AchievementsState,
+			IntegrityFlags:    response.IntegrityFlags,
+			DataSources:       response.DataSources,
+			Warnings:          warnings,
+			Timing:            response.Timing,
+		})
+	}
+
+	if policy := cache.PolicyFromContext(ctx); policy == cache.PolicyRefresh && h.cacheManager() != nil {
+		h.evictPlayer(ctx, resolvedSteamID)
+	} else if h.cacheManager() != nil && policy != cache.PolicyBypass {
+		if response, found := cache.Get(h.sharedCache(), playerCombinedCache.Key(playerCacheID(ctx, resolvedSteamID))); found {
+			sendAchievements(response, nil)
+			return
+		}
+	}
+
+	type assembled struct {
+		response models.PlayerStatsWithAchievements
+		warnings []string
+		err      error
+	}
+	early := make(chan models.PlayerStatsWithAchievements, 1)
+	done := make(chan assembled, 1)
+	go func() {
+		readyCtx := withStatsReady(ctx, func(half models.PlayerStatsWithAchievements) {
+			select {
+			case early <- half:
+			default:
+			}
+		})
+		response, warnings, err := h.assembleCombined(readyCtx, steamID, resolvedSteamID, time.Now(), requestLogger)
+		done <- assembled{response, warnings, err}
+	}()
+
+	var result assembled
+	for waiting := true; waiting; {
+		select {
+		case half := <-early:
+			if !sendStats(half) {
+				requestLogger.Debug("Progressive stats document not sent", "resolved_steam_id", resolvedSteamID)
+			}
+		case result = <-done:
+			waiting = false
+		}
+	}
+
+	switch {
+	case ctx.Err() != nil && stream.started:
+		requestLogger.Warn("Progressive response cut short before achievements",
+			"resolved_steam_id", resolvedSteamID,
+			"cause", ctx.Err().Error())
+		return
+	case ctx.Err() != nil:
+		writeTimeoutError(w, r, "player_stats_with_achievements")
+		return
+	case result.err != nil:
+		var steamErr *steam.APIError
+		if errors.As(result.err, &steamErr) && steamErr.Type == steam.ErrorTypeTimeout {
+			writeTimeoutError(w, r, "player_stats")
+			return
+		}
+		writeErrorResponse(w, steam.NewInternalError(result.err))
+		return
+	}
+
+	sendAchievements(result.response, result.warnings)
+	if !app.IsDBD() {
+		return
+	}
+	h.publishStatsChanged(resolvedSteamID, result.response)
+	if h.tracked.Has(resolvedSteamID) {
+		h.persistSnapshot(resolvedSteamID, result.response)
+	}
+}
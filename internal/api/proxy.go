@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// privateNetworks is what TRUSTED_PROXIES=private expands to: loopback plus the
+// private ranges Docker, Kubernetes and most reverse-proxy sidecars live on
+var privateNetworks = []string{
+	"127.0.0.0/8", "::1/128",
+	"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16",
+	"fc00::/7",
+}
+
+var (
+	trustedProxiesOnce sync.Once
+	trustedProxies     []netip.Prefix
+	cloudflareProxies  []netip.Prefix
+)
+
+// trustedProxyPrefixes parses TRUSTED_PROXIES and CLOUDFLARE_PROXIES once
+func trustedProxyPrefixes() ([]netip.Prefix, []netip.Prefix) {
+	trustedProxiesOnce.Do(func() {
+		trustedProxies = parseProxyList("TRUSTED_PROXIES")
+		cloudflareProxies = parseProxyList("CLOUDFLARE_PROXIES")
+		if len(trustedProxies) > 0 || len(cloudflareProxies) > 0 {
+			log.Info("Trusted proxies configured",
+				"prefixes", len(trustedProxies),
+				"cloudflare_prefixes", len(cloudflareProxies))
+		}
+	})
+	return trustedProxies, cloudflareProxies
+}
+
+// parseProxyList reads a comma-separated list of CIDRs or bare IPs from the
+// named variable, where "private" adds the loopback and private ranges.
+// Invalid entries are logged and skipped. Unset trusts no proxy.
+func parseProxyList(name string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(os.Getenv(name), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.EqualFold(entry, "private") {
+			for _, cidr := range privateNetworks {
+				prefixes = append(prefixes, netip.MustParsePrefix(cidr))
+			}
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		log.Warn("Ignoring invalid "+name+" entry", "entry", entry)
+	}
+	return prefixes
+}
+
+// isTrustedProxy reports whether ip is a proxy whose forwarding headers are
+// believed: any TRUSTED_PROXIES or CLOUDFLARE_PROXIES address
+func isTrustedProxy(ip string) bool {
+	trusted, cloudflare := trustedProxyPrefixes()
+	return inPrefixes(ip, trusted) || inPrefixes(ip, cloudflare)
+}
+
+// isCloudflareProxy reports whether ip is one of the configured Cloudflare edges
+func isCloudflareProxy(ip string) bool {
+	_, cloudflare := trustedProxyPrefixes()
+	return inPrefixes(ip, cloudflare)
+}
+
+func inPrefixes(ip string, prefixes []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// getClientIP returns the address of the client that made the request. Proxy
+// headers are only honored when the direct peer is a trusted proxy; otherwise
+// anyone could pick their own rate-limit bucket by sending X-Forwarded-For.
+// CF-Connecting-IP wins only when the peer is a configured Cloudflare edge,
+// since any client behind another proxy can set it. Otherwise the rightmost
+// untrusted X-Forwarded-For hop is used, then X-Real-IP.
+func getClientIP(r *http.Request) string {
+	peer := parseIPFromRemoteAddr(r.RemoteAddr)
+	if !isTrustedProxy(peer) {
+		return peer
+	}
+
+	if isCloudflareProxy(peer) {
+		if cf := strings.TrimSpace(r.Header.Get("CF-Connecting-IP")); validIP(cf) {
+			return cf
+		}
+	}
+
+	// Walk right to left: every hop we trust appended the address it saw, so the
+	// first untrusted one is the client; anything further left is client-supplied
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if !validIP(hop) {
+				break
+			}
+			if !isTrustedProxy(hop) || i == 0 {
+				return hop
+			}
+		}
+	}
+
+	if xri := strings.TrimSpace(r.Header.Get("X-Real-IP")); validIP(xri) {
+		return xri
+	}
+
+	return peer
+}
+
+func validIP(s string) bool {
+	_, err := netip.ParseAddr(s)
+	return s != "" && err == nil
+}
+
+// parseIPFromRemoteAddr extracts IP from "ip:port" format, including bracketed IPv6
+func parseIPFromRemoteAddr(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
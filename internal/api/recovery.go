@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+)
+
+// statusRecorder remembers the status code a handler wrote
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (sr *statusRecorder) WriteHeader(statusCode int) {
+	if !sr.wroteHeader {
+		sr.status = statusCode
+		sr.wroteHeader = true
+	}
+	sr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (sr *statusRecorder) Write(p []byte) (int, error) {
+	if !sr.wroteHeader {
+		sr.WriteHeader(http.StatusOK)
+	}
+	return sr.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the connection, so streamed
+// responses can flush
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+// RecoveryMiddleware turns handler panics into 500 responses and feeds request,
+// 5xx and panic counts into the instance error budget
+func RecoveryMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			metrics.Record(metrics.Requests)
+
+			defer func() {
+				if p := recover(); p != nil {
+					metrics.Record(metrics.Panics)
+					log.Error("Recovered panic in HTTP handler",
+						"panic", p,
+						"method", r.Method,
+						"path", r.URL.Path,
+						"stack", string(debug.Stack()))
+					if !recorder.wroteHeader {
+						writeError(recorder, r, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError, nil, nil)
+					}
+				}
+				if recorder.status >= 500 {
+					metrics.Record(metrics.ServerErrors)
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
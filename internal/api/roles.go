@@ -0,0 +1,71 @@
+package api
+
+import (
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+// roleView keeps the parts of a decorated combined response that concern
+// role, roughly halving what role-focused UIs download
+func roleView(response models.PlayerStatsWithAchievements, role string) models.PlayerRoleView {
+	view := models.PlayerRoleView{
+		SteamID:           response.SteamID,
+		DisplayName:       response.DisplayName,
+		Avatar:            response.Avatar,
+		Role:              role,
+		Stats:             make([]interface{}, 0),
+		AchievementsState: response.AchievementsState,
+		DataSources:       response.DataSources,
+		Demo:              response.Demo,
+		ResolvedAs:        response.ResolvedAs,
+		LastUpdated:       response.DataSources.Stats.FetchedAt,
+		DataAgeSeconds:    response.DataAgeSeconds,
+		Timing:            response.Timing,
+	}
+
+	for _, stat := range statsFromStatsData(response.Stats) {
+		if stat.Category == role {
+			view.Stats = append(view.Stats, stat)
+		}
+	}
+	if response.Stats != nil {
+		if summary, ok := response.Stats.Summary.(map[string]interface{}); ok {
+			view.Grade, _ = summary[role+"_grade"].(string)
+		}
+	}
+
+	var adepts map[string]bool
+	switch role {
+	case "killer":
+		view.Pips = response.KillerPips
+		if response.PeakGrades != nil {
+			view.PeakGrade = response.PeakGrades.Killer
+		}
+		if response.Achievements != nil {
+			adepts = response.Achievements.AdeptKillers
+		}
+		if response.Analytics != nil && response.Analytics.Killer != nil {
+			view.Analytics = &models.PlayerAnalytics{Killer: response.Analytics.Killer}
+		}
+	case "survivor":
+		view.Pips = response.SurvivorPips
+		if response.PeakGrades != nil {
+			view.PeakGrade = response.PeakGrades.Survivor
+		}
+		if response.Achievements != nil {
+			adepts = response.Achievements.AdeptSurvivors
+		}
+		if response.Analytics != nil && response.Analytics.Survivor != nil {
+			view.Analytics = &models.PlayerAnalytics{Survivor: response.Analytics.Survivor}
+		}
+	}
+
+	if adepts != nil {
+		view.Adepts = adepts
+		for _, unlocked := range adepts {
+			if unlocked {
+				view.AdeptsUnlocked++
+			}
+		}
+	}
+	return view
+}
@@ -5,22 +5,211 @@ import (
 	"time"
 )
 
-func RegisterRoutes(router *mux.Router) {
+// RegisterRoutes mounts the API on router and returns its handler, which the
+// caller drains and closes on shutdown
+func RegisterRoutes(router *mux.Router) *Handler {
 	handler := NewHandler()
 
 	// Create rate limiter (100 requests per minute per client)
 	rateLimiter := NewRequestLimiter(100, time.Minute)
+	if handler.counters != nil {
+		rateLimiter.ShareCounts(handler.counters)
+	}
+	handler.limiter = rateLimiter
 
-	// Apply global middleware for all routes
+	// Admin routes are authorized by the scopes routeScopes declares for them
+	credentials := loadAdminCredentials()
+	handler.adminCredentials = credentials
+	handler.capture = newSupportCapture(credentials)
+
+	// Apply global middleware for all routes. Support capture wraps everything so
+	// bundles show what clients saw; recovery is next so it sees every status.
+	if len(credentials) > 0 {
+		router.Use(handler.capture.middleware)
+	}
+	router.Use(RecoveryMiddleware())
+	router.Use(CanonicalJSONMiddleware())
 	router.Use(RequestIDMiddleware())
 	router.Use(SecurityMiddleware())
 	router.Use(RateLimitMiddleware(rateLimiter))
 	router.Use(APIKeyMiddleware())
 
-	// Player data endpoints
-	router.HandleFunc("/player/{steamid}", handler.GetPlayerStatsWithAchievements).Methods("GET")
+	// Player data endpoints; HEAD lets clients check ETag/Last-Modified before downloading.
+	// Each also answers Accept: application/msgpack with the same data.
+	// ?wait_for_fresh long-polls a refresh, so its budget extends the deadline.
+	// The stat endpoints serve any registered Steam title, chosen by an
+	// /{appid} prefix or ?appid= and defaulting to DEFAULT_APP_ID.
+	// ?cache= (or fresh=true) overrides cache use for support and debugging.
+	playerStats := withApp(handler.withCachePolicy(withTimeoutExtension(handler.waitForFreshBudget,
+		withTimeout(handler.config.RequestTimeout, "player_stats_with_achievements", withMsgpack(handler.GetPlayerStatsWithAchievements)))))
+	router.HandleFunc("/player/{steamid}", playerStats).Methods("GET", "HEAD")
+	router.HandleFunc("/{appid:[0-9]+}/player/{steamid}", playerStats).Methods("GET", "HEAD")
+
+	// Killer or survivor half of the same response for role-focused UIs
+	router.HandleFunc("/player/{steamid}/{role:killer|survivor}", playerStats).Methods("GET", "HEAD")
+	router.HandleFunc("/{appid:[0-9]+}/player/{steamid}/{role:killer|survivor}", playerStats).Methods("GET", "HEAD")
+
+	// Per-map generator and escape stats grouped by realm, with map completion
+	router.HandleFunc("/player/{steamid}/{view:maps}", playerStats).Methods("GET", "HEAD")
+	router.HandleFunc("/{appid:[0-9]+}/player/{steamid}/{view:maps}", playerStats).Methods("GET", "HEAD")
+
+	router.HandleFunc("/player/{steamid}/roadmap",
+		withApp(withTimeout(handler.config.RequestTimeout, "player_roadmap", withMsgpack(handler.GetPlayerRoadmap)))).Methods("GET", "HEAD")
+
+	// Mapped stat list with server-side filtering, sorting and paging
+	statsList := withApp(handler.withCachePolicy(withTimeout(handler.config.RequestTimeout, "player_stats_list", withMsgpack(handler.GetPlayerStatsList))))
+	router.HandleFunc("/player/{steamid}/stats", statsList).Methods("GET", "HEAD")
+	router.HandleFunc("/{appid:[0-9]+}/player/{steamid}/stats", statsList).Methods("GET", "HEAD")
+
+	// Stat totals per category and value type for dashboards
+	statsSummary := withApp(handler.withCachePolicy(withTimeout(handler.config.RequestTimeout, "player_stats_summary", withMsgpack(handler.GetPlayerStatsSummary))))
+	router.HandleFunc("/player/{steamid}/stats/summary", statsSummary).Methods("GET", "HEAD")
+	router.HandleFunc("/{appid:[0-9]+}/player/{steamid}/stats/summary", statsSummary).Methods("GET", "HEAD")
+
+	// Single derived value from a small arithmetic expression over raw stat IDs
+	statExpression := withApp(withTimeout(handler.config.RequestTimeout, "player_stat_expression", withMsgpack(handler.GetPlayerStatExpression)))
+	router.HandleFunc("/player/{steamid}/stat", statExpression).Methods("GET", "HEAD")
+	router.HandleFunc("/{appid:[0-9]+}/player/{steamid}/stat", statExpression).Methods("GET", "HEAD")
+
+	// Combined report for a survive-with-friends group of 2-4 players
+	router.HandleFunc("/squad/report",
+		withBodyLimit(handler.bodyLimit(maxSquadBodySize),
+			withTimeout(handler.config.RequestTimeout, "squad_report", handler.GetSquadReport))).Methods("POST")
+
+	// Summaries and stats for up to 100 players in one request
+	router.HandleFunc("/players",
+		withApp(withTimeout(handler.config.RequestTimeout, "players", withMsgpack(handler.GetPlayers)))).Methods("GET", "HEAD")
+
+	// Achievement and adept overlap between two players, with rarity-weighted bragging rights
+	router.HandleFunc("/compare/achievements",
+		withTimeout(handler.config.RequestTimeout, "compare_achievements", handler.CompareAchievements)).Methods("GET")
+
+	// Every achievement with its global rarity trend and sharp changes after balance patches
+	catalog := withApp(withTimeout(handler.config.RequestTimeout, "achievement_catalog", handler.GetAchievementCatalog))
+	router.HandleFunc("/achievements/catalog", catalog).Methods("GET", "HEAD")
+	router.HandleFunc("/{appid:[0-9]+}/achievements/catalog", catalog).Methods("GET", "HEAD")
+
+	// Translation coverage for community-contributed stat display names
+	router.HandleFunc("/stats/translations",
+		withTimeout(HealthCheckTimeout, "translation_coverage", handler.GetTranslationCoverage)).Methods("GET")
+
+	// Steam payload fields our response types don't model (STEAM_JSON_TELEMETRY)
+	router.HandleFunc("/steam/unknowns",
+		withTimeout(HealthCheckTimeout, "steam_unknown_fields", handler.GetSteamUnknowns)).Methods("GET")
+
+	// Name search over previously-served players
+	router.HandleFunc("/search",
+		withTimeout(handler.config.RequestTimeout, "player_search", handler.SearchPlayers)).Methods("GET")
+
+	// Grade distribution across served players, for "your grade vs population" context
+	router.HandleFunc("/context/grades",
+		withTimeout(HealthCheckTimeout, "grade_context", handler.GetGradeContext)).Methods("GET")
+
+	// Background refresh registrations; demo fixtures never change, so not in demo mode
+	if !handler.config.DemoMode {
+		router.HandleFunc("/track",
+			withTimeout(HealthCheckTimeout, "tracked_players", handler.ListTracked)).Methods("GET")
+		router.HandleFunc("/track/{steamid}",
+			withTimeout(handler.config.RequestTimeout, "track_player", handler.TrackPlayer)).Methods("POST")
+		router.HandleFunc("/track/{steamid}",
+			withTimeout(handler.config.RequestTimeout, "untrack_player", handler.UntrackPlayer)).Methods("DELETE")
+
+		// Named groups of tracked players with team dashboards
+		router.HandleFunc("/groups",
+			withTimeout(HealthCheckTimeout, "list_groups", handler.ListGroups)).Methods("GET")
+		router.HandleFunc("/groups",
+			withBodyLimit(handler.bodyLimit(maxGroupBodySize),
+				withTimeout(handler.config.RequestTimeout, "create_group", handler.CreateGroup))).Methods("POST")
+		router.HandleFunc("/groups/{id:[0-9a-f]+}",
+			withTimeout(HealthCheckTimeout, "get_group", handler.GetGroup)).Methods("GET")
+		router.HandleFunc("/groups/{id:[0-9a-f]+}",
+			withTimeout(HealthCheckTimeout, "delete_group", handler.DeleteGroup)).Methods("DELETE")
+		router.HandleFunc("/groups/{id:[0-9a-f]+}/stats",
+			withTimeout(handler.config.RequestTimeout, "group_stats", withMsgpack(handler.GetGroupStats))).Methods("GET", "HEAD")
+	}
+
+	// Profiles of linked Steam accounts; players prove they own each account
+	// by signing in through Steam OpenID, which needs the public URL to return to
+	if !handler.config.DemoMode && handler.config.PublicBaseURL != "" {
+		router.HandleFunc("/profile/link",
+			withBodyLimit(handler.bodyLimit(maxProfileLinkBodySize),
+				withTimeout(HealthCheckTimeout, "profile_link", handler.BeginProfileLink))).Methods("POST")
+		callback := router.HandleFunc("/profile/link/callback",
+			withTimeout(handler.config.RequestTimeout, "profile_link_callback", handler.CompleteProfileLink)).Methods("GET")
+		handler.linkCallback, _ = callback.GetPathTemplate()
+		router.HandleFunc("/profile/{id:[0-9a-f]+}",
+			withTimeout(handler.config.RequestTimeout, "profile", withMsgpack(handler.GetProfile))).Methods("GET", "HEAD")
+		router.HandleFunc("/profile/{id:[0-9a-f]+}/accounts/{steamid:[0-9]{17}}",
+			withTimeout(HealthCheckTimeout, "profile_unlink", handler.UnlinkProfileAccount)).Methods("DELETE")
+	}
+
+	// Opt-in: public Steam inventory (charms/outfits) per player
+	if handler.config.InventoryEnabled {
+		router.HandleFunc("/player/{steamid}/inventory",
+			withTimeout(handler.config.RequestTimeout, "player_inventory", withMsgpack(handler.GetPlayerInventory))).Methods("GET", "HEAD")
+	}
+
+	// Opt-in: achievement icons mirrored from the Steam CDN
+	if handler.config.IconMirrorEnabled {
+		router.HandleFunc("/assets/achievements/{file}",
+			withTimeout(handler.config.RequestTimeout, "achievement_icon", handler.GetAchievementIcon)).Methods("GET", "HEAD")
+	}
 
 	// Health endpoints
-	router.HandleFunc("/health", handler.HealthCheck).Methods("GET")
-	router.HandleFunc("/healthz", handler.HealthCheck).Methods("GET") // Kubernetes-style healthcheck
+	health := router.HandleFunc("/health", withTimeout(HealthCheckTimeout, "health_check", handler.HealthCheck)).Methods("GET")
+	router.HandleFunc("/healthz", withTimeout(HealthCheckTimeout, "health_check", handler.HealthCheck)).Methods("GET") // Kubernetes-style healthcheck
+	router.HandleFunc("/status", withTimeout(HealthCheckTimeout, "status", handler.Status)).Methods("GET")
+	router.HandleFunc("/metrics", handler.Metrics).Methods("GET") // OpenMetrics; not timed so scrapes don't observe themselves
+
+	// Feature discovery for generic clients; public like /status
+	router.HandleFunc("/capabilities",
+		withTimeout(HealthCheckTimeout, "capabilities", handler.GetCapabilities)).Methods("GET")
+
+	// Canned player responses for frontend work; never reach Steam
+	if handler.config.SandboxEnabled {
+		router.HandleFunc("/sandbox/player",
+			withTimeout(HealthCheckTimeout, "sandbox_scenarios", handler.ListSandboxScenarios)).Methods("GET")
+		router.HandleFunc("/sandbox/player/{scenario}",
+			withTimeout(handler.config.RequestTimeout, "sandbox_player", handler.GetSandboxPlayer)).Methods("GET", "HEAD")
+	}
+
+	// Admin endpoints exist only when an admin token is configured
+	if len(credentials) > 0 {
+		router.HandleFunc("/admin/notify/test",
+			withBodyLimit(handler.bodyLimit(0),
+				withTimeout(30*time.Second, "notification_test", handler.TestNotification))).Methods("POST")
+		router.HandleFunc("/admin/notify/dead-letters",
+			withTimeout(HealthCheckTimeout, "notification_dead_letters", handler.GetDeadLetters)).Methods("GET")
+		router.HandleFunc("/admin/notify/dead-letters",
+			withTimeout(HealthCheckTimeout, "notification_dead_letters_clear", handler.ClearDeadLetters)).Methods("DELETE")
+		router.HandleFunc("/admin/notify/dead-letters/replay",
+			withBodyLimit(handler.bodyLimit(0),
+				withTimeout(HealthCheckTimeout, "notification_dead_letters_replay", handler.ReplayDeadLetters))).Methods("POST")
+		router.HandleFunc("/admin/cache",
+			withTimeout(HealthCheckTimeout, "cache_eviction", handler.EvictCache)).Methods("DELETE")
+		router.HandleFunc("/admin/log-level",
+			withTimeout(HealthCheckTimeout, "log_level", handler.GetLogLevel)).Methods("GET")
+		router.HandleFunc("/admin/log-level",
+			withBodyLimit(handler.bodyLimit(0),
+				withTimeout(HealthCheckTimeout, "log_level_change", handler.SetLogLevel))).Methods("PUT")
+		router.HandleFunc("/admin/support/capture",
+			withTimeout(HealthCheckTimeout, "support_capture_start", handler.StartSupportCapture)).Methods("POST")
+		router.HandleFunc("/admin/support/capture",
+			withTimeout(HealthCheckTimeout, "support_capture_status", handler.GetSupportCapture)).Methods("GET")
+		router.HandleFunc("/admin/support/capture",
+			withTimeout(HealthCheckTimeout, "support_capture_stop", handler.StopSupportCapture)).Methods("DELETE")
+		router.HandleFunc("/admin/support/bundle",
+			withTimeout(30*time.Second, "support_bundle", handler.GetSupportBundle)).Methods("GET")
+	}
+
+	prefix := routePrefix(health, "/health")
+	router.Use(ScopeMiddleware(prefix, credentials))
+	if len(credentials) > 0 {
+		if err := checkRouteScopes(router, prefix); err != nil {
+			panic(err) // routeScopes is out of step with the routes above
+		}
+	}
+
+	handler.routes = listRoutes(router)
+	return handler
 }
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rgonzalez12/dbd-analytics/internal/demo"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// Sandbox scenarios: canned player responses for UI development
+const (
+	ScenarioHappy        = "happy"
+	ScenarioPrivate      = "private"
+	ScenarioRateLimited  = "rate-limited"
+	ScenarioPartial      = "partial"
+	ScenarioHugePayload  = "huge-payload"
+	defaultSandboxStats  = 5000
+	maxSandboxStats      = 20000
+	maxSandboxDelay      = 60 * time.Second
+	sandboxRetryAfterSec = 60
+)
+
+// sandboxScenarios describes each scenario for the listing endpoint
+var sandboxScenarios = []map[string]interface{}{
+	{"scenario": ScenarioHappy, "status": http.StatusOK, "description": "Complete survivor-main response"},
+	{"scenario": ScenarioPrivate, "status": http.StatusOK, "description": "Zeroed stats with achievements_state private"},
+	{"scenario": ScenarioRateLimited, "status": http.StatusTooManyRequests, "description": "Steam rate-limit error with Retry-After"},
+	{"scenario": ScenarioPartial, "status": http.StatusPartialContent, "description": "Killer-main stats with the achievements source failed"},
+	{"scenario": ScenarioHugePayload, "status": http.StatusOK, "description": "Survivor-main response padded to ?stats= entries (default 5000, at most 20000), streamed"},
+}
+
+// ListSandboxScenarios lists the scenarios /sandbox/player/{scenario} serves
+func (h *Handler) ListSandboxScenarios(w http.ResponseWriter, r *http.Request) {
+	writeJSONResponse(w, map[string]interface{}{
+		"scenarios": sandboxScenarios,
+		"params": map[string]string{
+			"delay": fmt.Sprintf("added latency before answering, e.g. 2s; at most %s, so delays past the route timeout exercise it", maxSandboxDelay),
+			"stats": "entry count for huge-payload",
+		},
+	})
+}
+
+// GetSandboxPlayer answers with a fixed response for a UI state, never
+// touching Steam, the cache or the player stores. Responses are identical on
+// every call, timestamps included, so they can back snapshot tests.
+func (h *Handler) GetSandboxPlayer(w http.ResponseWriter, r *http.Request) {
+	scenario := mux.Vars(r)["scenario"]
+	params := newQueryParams(r)
+	delay := params.duration("delay")
+	if delay > maxSandboxDelay {
+		params.fail("delay", fmt.Sprintf("delay must be at most %s", maxSandboxDelay), nil)
+	}
+	statCount := params.intRange("stats", defaultSandboxStats, 1, maxSandboxStats)
+	if err := params.err(); err != nil {
+		writeParamErrors(w, r, err)
+		return
+	}
+
+	known := false
+	for _, s := range sandboxScenarios {
+		known = known || s["scenario"] == scenario
+	}
+	if !known {
+		writeError(w, r, "UNKNOWN_SCENARIO", "Unknown sandbox scenario", http.StatusNotFound,
+			map[string]interface{}{"scenarios": sandboxScenarios}, nil)
+		return
+	}
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return // the route timeout answers
+		}
+	}
+	w.Header().Set("X-Sandbox-Scenario", scenario)
+
+	switch scenario {
+	case ScenarioRateLimited:
+		w.Header().Set("Retry-After", strconv.Itoa(sandboxRetryAfterSec))
+		writeErrorResponse(w, steam.NewRateLimitErrorWithRetryAfter(sandboxRetryAfterSec))
+	case ScenarioPrivate:
+		response, ok := sandboxFixture(w, r, "demo-private")
+		if !ok {
+			return
+		}
+		writeJSONResponse(w, response)
+	case ScenarioPartial:
+		response, ok := sandboxFixture(w, r, "demo-killer")
+		if !ok {
+			return
+		}
+		failure := "Steam achievements API unavailable: HTTP 503 Service Unavailable"
+		response.Achievements = nil
+		response.AchievementsState = models.AchievementsUnavailable
+		response.DataSources.Achievements = models.DataSourceInfo{
+			Success:   false,
+			Source:    "sandbox",
+			Error:     failure,
+			FetchedAt: response.DataSources.Achievements.FetchedAt,
+		}
+		writePartialDataResponse(w, response, []string{"Achievement data unavailable: " + failure})
+	case ScenarioHugePayload:
+		response, ok := sandboxFixture(w, r, "demo-survivor")
+		if !ok {
+			return
+		}
+		response.Stats = padStats(response.Stats, statCount)
+		streamJSONResponse(w, r, response)
+	default:
+		response, ok := sandboxFixture(w, r, "demo-survivor")
+		if !ok {
+			return
+		}
+		writeJSONResponse(w, response)
+	}
+}
+
+// sandboxFixture is a bundled demo player dressed as a complete sandbox
+// response; fixtures are embedded, so failing here is a build problem
+func sandboxFixture(w http.ResponseWriter, r *http.Request, vanity string) (models.PlayerStatsWithAchievements, bool) {
+	response, found := demo.Player(vanity)
+	if !found {
+		log.Error("Sandbox fixture missing", "vanity", vanity, "error", demo.LoadError())
+		writeError(w, r, "SANDBOX_UNAVAILABLE", "Sandbox fixtures failed to load", http.StatusInternalServerError, nil, nil)
+		return models.PlayerStatsWithAchievements{}, false
+	}
+
+	fetchedAt := response.LastUpdated
+	source := models.DataSourceInfo{Success: true, Source: "sandbox", FetchedAt: fetchedAt}
+	response.DataSources = models.DataSourceStatus{Stats: source, Achievements: source, StructuredStats: source}
+	response.AchievementsState = achievementsState(response.Achievements, nil)
+	if response.Achievements == nil {
+		response.AchievementsState = models.AchievementsPrivate
+		response.DataSources.Achievements = models.DataSourceInfo{
+			Success:   false,
+			Source:    "sandbox",
+			Error:     "Achievements unavailable (private profile)",
+			FetchedAt: fetchedAt,
+		}
+	}
+	response.Demo = false
+	response.APIProvider = "sandbox"
+	return *response, true
+}
+
+// padStats repeats stats until there are count entries; copies get numbered
+// ids so clients keying rows by id see distinct rows
+func padStats(stats *models.StatsData, count int) *models.StatsData {
+	if stats == nil || len(stats.Stats) == 0 {
+		return stats
+	}
+	padded := &models.StatsData{Stats: make([]interface{}, 0, count), Summary: stats.Summary}
+	for i := 0; len(padded.Stats) < count; i++ {
+		original := stats.Stats[i%len(stats.Stats)]
+		entry, ok := original.(map[string]interface{})
+		if !ok || i < len(stats.Stats) {
+			padded.Stats = append(padded.Stats, original)
+			continue
+		}
+		clone := make(map[string]interface{}, len(entry))
+		for k, v := range entry {
+			clone[k] = v
+		}
+		clone["id"] = fmt.Sprintf("%v_%d", entry["id"], i/len(stats.Stats))
+		padded.Stats = append(padded.Stats, clone)
+	}
+	return padded
+}
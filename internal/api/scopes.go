@@ -0,0 +1,229 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// Scopes an admin token can hold. ADMIN_TOKEN holds all of them.
+const (
+	ScopeNotifyTest  = "notify:test"
+	ScopeNotifyRead  = "notify:read"
+	ScopeNotifyWrite = "notify:write"
+	ScopeCacheEvict  = "cache:evict"
+	ScopeLogLevel    = "log:level"
+
+	// Not a route scope: lets a token set ?cache= on player requests
+	ScopeCacheControl = "cache:control"
+
+	// Captures hold recorded traffic, so they are separate from the rest
+	ScopeSupportCapture = "support:capture"
+)
+
+var knownScopes = []string{ScopeNotifyTest, ScopeNotifyRead, ScopeNotifyWrite, ScopeCacheEvict, ScopeLogLevel, ScopeSupportCapture, ScopeCacheControl}
+
+// routeScopes annotates every protected route, keyed "METHOD /path" with the
+// path template relative to the API root, with the scopes a token needs to
+// call it. Routes under /admin/ must all be listed; RegisterRoutes refuses to
+// start otherwise.
+var routeScopes = map[string][]string{
+	"POST /admin/notify/test":                {ScopeNotifyTest},
+	"GET /admin/notify/dead-letters":         {ScopeNotifyRead},
+	"DELETE /admin/notify/dead-letters":      {ScopeNotifyWrite},
+	"POST /admin/notify/dead-letters/replay": {ScopeNotifyWrite},
+	"DELETE /admin/cache":                    {ScopeCacheEvict},
+	"GET /admin/log-level":                   {ScopeLogLevel},
+	"PUT /admin/log-level":                   {ScopeLogLevel},
+	"POST /admin/support/capture":            {ScopeSupportCapture},
+	"GET /admin/support/capture":             {ScopeSupportCapture},
+	"DELETE /admin/support/capture":          {ScopeSupportCapture},
+	"GET /admin/support/bundle":              {ScopeSupportCapture},
+}
+
+const adminPathPrefix = "/admin/"
+
+// adminCredential is one accepted X-Admin-Token value and what it may do
+type adminCredential struct {
+	token  string
+	scopes map[string]bool
+}
+
+// loadAdminCredentials reads ADMIN_TOKEN, which holds every scope, and
+// ADMIN_SCOPED_TOKENS, comma-separated "token=scope scope" entries. Malformed
+// entries and unknown scopes are logged and skipped.
+func loadAdminCredentials() []adminCredential {
+	var credentials []adminCredential
+	if token := adminToken(); token != "" {
+		all := make(map[string]bool, len(knownScopes))
+		for _, scope := range knownScopes {
+			all[scope] = true
+		}
+		credentials = append(credentials, adminCredential{token: token, scopes: all})
+	}
+
+	known := make(map[string]bool, len(knownScopes))
+	for _, scope := range knownScopes {
+		known[scope] = true
+	}
+	for i, entry := range strings.Split(os.Getenv("ADMIN_SCOPED_TOKENS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		token, scopeList, ok := strings.Cut(entry, "=")
+		token = strings.TrimSpace(token)
+		if !ok || token == "" {
+			log.Warn("Ignoring malformed ADMIN_SCOPED_TOKENS entry", "entry", i)
+			continue
+		}
+		scopes := make(map[string]bool)
+		for _, scope := range strings.Fields(scopeList) {
+			if !known[scope] {
+				log.Warn("Ignoring unknown admin scope", "entry", i, "scope", scope, "known", knownScopes)
+				continue
+			}
+			scopes[scope] = true
+		}
+		if len(scopes) == 0 {
+			log.Warn("Ignoring ADMIN_SCOPED_TOKENS entry without valid scopes", "entry", i)
+			continue
+		}
+		credentials = append(credentials, adminCredential{token: token, scopes: scopes})
+	}
+	return credentials
+}
+
+// scopesFor returns the scopes held by the provided token. Every credential is
+// compared so the time taken doesn't reveal which one matched.
+func scopesFor(credentials []adminCredential, provided string) (map[string]bool, bool) {
+	var granted map[string]bool
+	for _, credential := range credentials {
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(credential.token)) == 1 {
+			granted = credential.scopes
+		}
+	}
+	return granted, granted != nil && provided != ""
+}
+
+// routePrefix is the part of route's path template above path, which is where
+// the router is mounted
+func routePrefix(route *mux.Route, path string) string {
+	template, _ := route.GetPathTemplate()
+	return strings.TrimSuffix(template, path)
+}
+
+// routeKey is the routeScopes key for the matched route, or "" when mux
+// matched none. HEAD is checked as GET.
+func routeKey(r *http.Request, prefix string) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	method := r.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	return method + " " + strings.TrimPrefix(template, prefix)
+}
+
+// ScopeMiddleware enforces routeScopes for the routes mounted under prefix.
+// Missing or unknown tokens get 401; tokens lacking a scope get 403 naming the
+// missing scopes. An /admin/ route without an annotation is refused outright.
+func ScopeMiddleware(prefix string, credentials []adminCredential) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := routeKey(r, prefix)
+			required, annotated := routeScopes[key]
+			_, path, _ := strings.Cut(key, " ")
+			if !annotated && !strings.HasPrefix(path, adminPathPrefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !annotated {
+				log.Error("Admin route has no scope annotation", "route", key)
+				writeError(w, r, "FORBIDDEN", "This route has no scope annotation", http.StatusForbidden, nil, nil)
+				return
+			}
+
+			granted, ok := scopesFor(credentials, r.Header.Get("X-Admin-Token"))
+			if !ok {
+				writeError(w, r, "UNAUTHORIZED", "Valid X-Admin-Token header required", http.StatusUnauthorized, nil, nil)
+				return
+			}
+			var missing []string
+			for _, scope := range required {
+				if !granted[scope] {
+					missing = append(missing, scope)
+				}
+			}
+			if len(missing) > 0 {
+				log.Warn("Admin request lacks scope",
+					"route", key,
+					"missing_scopes", missing,
+					"client_ip", getClientIP(r))
+				writeError(w, r, "INSUFFICIENT_SCOPE", "Token lacks the scopes this route requires", http.StatusForbidden,
+					map[string]interface{}{"missing_scopes": missing, "required_scopes": required},
+					nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// checkRouteScopes verifies routeScopes against the registered routes: every
+// method of every /admin/ route must be annotated, and every annotation must
+// name a registered route
+func checkRouteScopes(router *mux.Router, prefix string) error {
+	registered := make(map[string]bool)
+	var unannotated []string
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		path := strings.TrimPrefix(template, prefix)
+		methods, _ := route.GetMethods()
+		for _, method := range methods {
+			if method == http.MethodHead {
+				method = http.MethodGet
+			}
+			key := method + " " + path
+			registered[key] = true
+			if _, ok := routeScopes[key]; !ok && strings.HasPrefix(path, adminPathPrefix) {
+				unannotated = append(unannotated, key)
+			}
+		}
+		if len(methods) == 0 && strings.HasPrefix(path, adminPathPrefix) {
+			unannotated = append(unannotated, "* "+path)
+		}
+		return nil
+	})
+	if len(unannotated) > 0 {
+		sort.Strings(unannotated)
+		return fmt.Errorf("admin routes without scope annotations: %s", strings.Join(unannotated, ", "))
+	}
+
+	var stale []string
+	for key := range routeScopes {
+		if !registered[key] {
+			stale = append(stale, key)
+		}
+	}
+	if len(stale) > 0 {
+		sort.Strings(stale)
+		return fmt.Errorf("scope annotations for unregistered routes: %s", strings.Join(stale, ", "))
+	}
+	return nil
+}
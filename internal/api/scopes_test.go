@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+const testAdminToken = "test-admin-token"
+
+// newTestAPI mounts the API under /api as server.New does, with the admin
+// token and one token per scope configured so every admin route registers
+func newTestAPI(t *testing.T) (*mux.Router, string) {
+	t.Helper()
+	t.Setenv("STEAM_API_KEY", "") // demo mode: nothing reaches Steam
+	t.Setenv("SCHEMA_STORE_DIR", t.TempDir())
+	t.Setenv("ADMIN_TOKEN", testAdminToken)
+	scoped := make([]string, 0, len(knownScopes))
+	for _, scope := range knownScopes {
+		scoped = append(scoped, scopeToken(scope)+"="+scope)
+	}
+	t.Setenv("ADMIN_SCOPED_TOKENS", strings.Join(scoped, ","))
+
+	root := mux.NewRouter()
+	router := root.PathPrefix("/api").Subrouter()
+	handler := RegisterRoutes(router)
+	t.Cleanup(func() { handler.Close() })
+
+	health := router.Get("health")
+	prefix := "/api"
+	if health != nil {
+		prefix = routePrefix(health, "/health")
+	}
+	return root, prefix
+}
+
+func scopeToken(scope string) string {
+	return "token-" + strings.ReplaceAll(scope, ":", "-")
+}
+
+type protectedRoute struct {
+	method, path, key string
+}
+
+// protectedRoutes walks the registered routes for every method of every path
+// under /admin/, the routes ScopeMiddleware must guard
+func protectedRoutes(t *testing.T, root *mux.Router, prefix string) []protectedRoute {
+	t.Helper()
+	pathVar := regexp.MustCompile(`\{[^}]+\}`)
+	var routes []protectedRoute
+	root.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		path := strings.TrimPrefix(template, prefix)
+		if !strings.HasPrefix(path, adminPathPrefix) {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			t.Errorf("admin route %s accepts every method", path)
+			return nil
+		}
+		for _, method := range methods {
+			key := method + " " + path
+			if method == http.MethodHead {
+				key = http.MethodGet + " " + path
+			}
+			routes = append(routes, protectedRoute{
+				method: method,
+				path:   pathVar.ReplaceAllString(template, "x"),
+				key:    key,
+			})
+		}
+		return nil
+	})
+	return routes
+}
+
+func serveAdmin(root *mux.Router, route protectedRoute, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(route.method, route.path, strings.NewReader("{}"))
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("User-Agent", "scopes-test")
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("X-Admin-Token", token)
+	}
+	rec := httptest.NewRecorder()
+	root.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestRouteScopesCoverAdminRoutes requires routeScopes to annotate exactly
+// the registered admin routes
+func TestRouteScopesCoverAdminRoutes(t *testing.T) {
+	root, prefix := newTestAPI(t)
+
+	routes := protectedRoutes(t, root, prefix)
+	if len(routes) == 0 {
+		t.Fatal("no admin routes registered")
+	}
+	annotated := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		if _, ok := routeScopes[route.key]; !ok {
+			t.Errorf("%s has no routeScopes annotation", route.key)
+		}
+		annotated[route.key] = true
+	}
+	for key := range routeScopes {
+		if !annotated[key] {
+			t.Errorf("routeScopes annotates %s, which is not a registered admin route", key)
+		}
+	}
+}
+
+// TestScopeMiddlewareGuardsEveryAdminRoute calls every admin route without a
+// token, with a token lacking its scope and with one holding it
+func TestScopeMiddlewareGuardsEveryAdminRoute(t *testing.T) {
+	root, prefix := newTestAPI(t)
+
+	for _, route := range protectedRoutes(t, root, prefix) {
+		required := routeScopes[route.key]
+		t.Run(route.key, func(t *testing.T) {
+			if rec := serveAdmin(root, route, ""); rec.Code != http.StatusUnauthorized {
+				t.Errorf("without a token: status %d, want 401", rec.Code)
+			}
+			if rec := serveAdmin(root, route, "wrong-token"); rec.Code != http.StatusUnauthorized {
+				t.Errorf("with an unknown token: status %d, want 401", rec.Code)
+			}
+
+			for _, scope := range knownScopes {
+				if scope == required[0] {
+					continue
+				}
+				rec := serveAdmin(root, route, scopeToken(scope))
+				if rec.Code != http.StatusForbidden {
+					t.Errorf("with only %s: status %d, want 403", scope, rec.Code)
+					continue
+				}
+				var body struct {
+					Details struct {
+						MissingScopes []string `json:"missing_scopes"`
+					} `json:"details"`
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+					t.Fatalf("403 body: %v", err)
+				}
+				if missing := body.Details.MissingScopes; len(missing) == 0 || missing[0] != required[0] {
+					t.Errorf("with only %s: missing_scopes %v, want %v", scope, missing, required)
+				}
+				break
+			}
+
+			for _, token := range []string{scopeToken(required[0]), testAdminToken} {
+				if rec := serveAdmin(root, route, token); rec.Code == http.StatusUnauthorized || rec.Code == http.StatusForbidden {
+					t.Errorf("with token %s: status %d, want authorized", token, rec.Code)
+				}
+			}
+		})
+	}
+}
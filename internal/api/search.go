@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// SearchPlayers fuzzy-matches persona names of players this service has served
+// before. Steam has no public name search, so unseen players will not appear.
+func (h *Handler) SearchPlayers(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	params := newQueryParams(r)
+	name := params.string("name")
+	if length := utf8.RuneCountInString(name); length < 2 || length > 64 {
+		params.fail("name", "name must be between 2 and 64 characters", nil)
+	}
+	limit := params.intRange("limit", 20, 1, 50)
+	offset := params.offset()
+	if err := params.err(); err != nil {
+		writeParamErrors(w, r, err)
+		return
+	}
+
+	results, total := h.players.Search(name, limit, offset)
+
+	log.Info("Player search completed",
+		"query", name,
+		"matches", total,
+		"returned", len(results),
+		"offset", offset,
+		"duration", time.Since(start))
+
+	response := map[string]interface{}{
+		"query":   name,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+		"results": results,
+	}
+	if next := offset + len(results); next < total {
+		response["next_offset"] = next
+	}
+
+	streamJSONResponse(w, r, response)
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+var snapshotsServed = metrics.NewCounter("dbd_combined_snapshots_served",
+	"Combined player requests answered from a persisted snapshot on a cache miss.")
+
+// serveSnapshot answers a combined cache miss from the player's persisted
+// snapshot, marked stale, and starts a background refresh. It reports whether
+// a response was written.
+func (h *Handler) serveSnapshot(w http.ResponseWriter, r *http.Request, steamID string, resolvedAs steam.IDType, lang string, format steam.StatFormatter) bool {
+	// Without a cache every request would miss and only ever see the snapshot
+	if h.cacheManager() == nil {
+		return false
+	}
+	response, storedAt, ok := h.loadSnapshot(steamID)
+	if !ok {
+		return false
+	}
+	markStale(&response, time.Since(storedAt))
+	response.CacheHit = false
+
+	snapshotsServed.Add(1)
+	log.Info("Serving persisted snapshot while refreshing",
+		"steam_id", steamID,
+		"stored_at", storedAt)
+	h.refreshInBackground(steamID)
+	h.writeCombinedResponse(w, r, steamID, resolvedAs, lang, format, response, nil)
+	return true
+}
+
+// loadSnapshot returns steamID's persisted snapshot, with every source
+// attributed to the store, unless it is missing, too old or disabled
+func (h *Handler) loadSnapshot(steamID string) (models.PlayerStatsWithAchievements, time.Time, bool) {
+	if h.config.SnapshotMaxAgeHours == 0 {
+		return models.PlayerStatsWithAchievements{}, time.Time{}, false
+	}
+	response, storedAt, ok := h.snapshots.Load(steamID, time.Duration(h.config.SnapshotMaxAgeHours)*time.Hour)
+	if !ok {
+		return response, storedAt, false
+	}
+
+	for _, source := range dataSources(&response) {
+		source.Source = "store"
+	}
+	if response.AchievementsState == "" { // saved before the field existed
+		if response.DataSources.Achievements.Success {
+			response.AchievementsState = achievementsState(response.Achievements, nil)
+		} else {
+			response.Achievements = nil
+			response.AchievementsState = models.AchievementsUnavailable
+		}
+	}
+	return response, storedAt, true
+}
+
+// markStale flags every source of a response served in place of live data
+func markStale(response *models.PlayerStatsWithAchievements, age time.Duration) {
+	for _, source := range dataSources(response) {
+		source.Stale = true
+		source.StaleAgeSeconds = int64(age.Seconds())
+	}
+}
+
+func dataSources(response *models.PlayerStatsWithAchievements) []*models.DataSourceInfo {
+	return []*models.DataSourceInfo{
+		&response.DataSources.Stats,
+		&response.DataSources.Achievements,
+		&response.DataSources.StructuredStats,
+	}
+}
+
+// refreshInBackground assembles and caches a live combined response for
+// steamID. It leads the coalescer flight, so requests arriving meanwhile wait
+// on it, and does nothing when another request is already assembling one.
+func (h *Handler) refreshInBackground(steamID string) {
+	flight, leader := h.combined.join(steamID)
+	if !leader {
+		return
+	}
+
+	go func() {
+		var (
+			response models.PlayerStatsWithAchievements
+			warnings []string
+			ok       bool
+		)
+		defer func() {
+			h.combined.finish(steamID, flight, response, warnings, ok)
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), h.config.OverallTimeout)
+		defer cancel()
+
+		var err error
+		response, warnings, err = h.assembleCombined(ctx, steamID, steamID, time.Now(), log.PlayerContext(steamID))
+		if err != nil {
+			log.Warn("Background refresh after snapshot failed", "steam_id", steamID, "error", err)
+			return
+		}
+		ok = true
+		h.publishStatsChanged(steamID, response)
+		// Keep the snapshot current even if the player is no longer tracked
+		// (registrations don't survive a restart); it expires by age
+		h.persistSnapshot(steamID, response)
+	}()
+}
+
+// persistSnapshot saves a live combined response for cold starts. Responses
+// carrying circuit-breaker fallback data are skipped, as they aren't cached.
+func (h *Handler) persistSnapshot(steamID string, response models.PlayerStatsWithAchievements) {
+	if !h.snapshots.Enabled() || response.DataSources.Achievements.Stale {
+		return
+	}
+	if err := h.snapshots.Save(steamID, response); err != nil {
+		log.Warn("Failed to persist player snapshot", "steam_id", steamID, "error", err)
+	}
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/demo"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+const (
+	minSquadSize     = 2
+	maxSquadSize     = 4
+	maxSquadBodySize = 4 << 10 // enforced by the route's body limit
+)
+
+type squadReportRequest struct {
+	SteamIDs []string `json:"steam_ids"`
+}
+
+// GetSquadReport combines two to four players into one SWF squad report. Body:
+// {"steam_ids": [...]}, each a SteamID64, vanity name or profile URL. Every
+// member's stats are required; achievements and raw counters degrade to warnings.
+func (h *Handler) GetSquadReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	start := time.Now()
+
+	var body squadReportRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		writeValidationError(w, r, "Request body must be JSON of the form {\"steam_ids\": [...]}", "body")
+		return
+	}
+	if len(body.SteamIDs) < minSquadSize || len(body.SteamIDs) > maxSquadSize {
+		writeValidationError(w, r, fmt.Sprintf("steam_ids must list between %d and %d players", minSquadSize, maxSquadSize), "steam_ids")
+		return
+	}
+
+	ids := make([]string, len(body.SteamIDs))
+	idTypes := make([]steam.IDType, len(body.SteamIDs))
+	for i, raw := range body.SteamIDs {
+		id, idType, _, err := parsePlayerID(strings.TrimSpace(raw), steam.IDTypeAuto)
+		if err != nil {
+			writeValidationError(w, r, err.Message, fmt.Sprintf("steam_ids[%d]", i))
+			return
+		}
+		ids[i], idTypes[i] = id, idType
+	}
+
+	if h.config.DemoMode {
+		h.serveDemoSquad(w, r, ids)
+		return
+	}
+
+	// Phase 1: resolve vanity names so duplicates can be caught before fetching
+	resolved := make([]string, len(ids))
+	resolveErrs := make([]*steam.APIError, len(ids))
+	group := h.workers.Group(ctx)
+	for i := range ids {
+		group.Go(fmt.Sprintf("resolve_%d", i), func(taskCtx context.Context) error {
+			resolved[i], _, resolveErrs[i] = h.steamClient.ResolveSteamIDAs(taskCtx, ids[i], idTypes[i])
+			if resolveErrs[i] != nil {
+				return resolveErrs[i]
+			}
+			return nil
+		})
+	}
+	if group.Wait(); ctx.Err() != nil {
+		writeTimeoutError(w, r, "squad_report")
+		return
+	}
+
+	seen := make(map[string]int, len(resolved))
+	for i, id := range resolved {
+		if resolveErrs[i] != nil {
+			writeErrorResponse(w, resolveErrs[i])
+			return
+		}
+		if first, dup := seen[id]; dup {
+			writeValidationError(w, r, fmt.Sprintf("steam_ids[%d] and steam_ids[%d] are the same player", first, i), "steam_ids")
+			return
+		}
+		seen[id] = i
+	}
+
+	// Phase 2: every member's sources in one fan-out on the shared pool
+	type memberResult struct {
+		stats        models.PlayerStats
+		statsErr     error
+		achievements *models.AchievementData
+		achErr       error
+		statValues   map[string]float64
+		valuesErr    *steam.APIError
+	}
+	results := make([]memberResult, len(resolved))
+	group = h.workers.Group(ctx)
+	for i, steamID := range resolved {
+		group.Go(fmt.Sprintf("stats_%d", i), func(taskCtx context.Context) error {
+			results[i].stats, _, results[i].statsErr = h.fetchPlayerStatsWithSource(taskCtx, steamID)
+			return results[i].statsErr
+		})
+		group.Go(fmt.Sprintf("achievements_%d", i), func(taskCtx context.Context) error {
+			results[i].achievements, _, results[i].achErr = h.fetchPlayerAchievementsWithSource(taskCtx, steamID)
+			return results[i].achErr
+		})
+		group.Go(fmt.Sprintf("stat_values_%d", i), func(taskCtx context.Context) error {
+			var raw *steam.SteamPlayerstats
+			if h.cacheManager() != nil {
+				raw, results[i].valuesErr = h.steamClient.GetUserStatsForGameCached(taskCtx, steamID, 381210, h.cacheManager().GetCache())
+			} else {
+				raw, results[i].valuesErr = h.steamClient.GetUserStatsForGame(taskCtx, steamID, 381210)
+			}
+			if results[i].valuesErr != nil {
+				return results[i].valuesErr
+			}
+			results[i].statValues = make(map[string]float64, len(raw.Stats))
+			for _, stat := range raw.Stats {
+				results[i].statValues[stat.Name] = stat.Value
+			}
+			return nil
+		})
+	}
+	if err := group.Wait(); ctx.Err() != nil {
+		writeTimeoutError(w, r, "squad_report")
+		return
+	} else if err != nil {
+		log.Debug("Squad fetch completed with source errors", "errors", err.Error())
+	}
+
+	members := make([]steam.SquadMemberData, len(results))
+	var warnings []string
+	for i, result := range results {
+		if result.statsErr != nil {
+			log.Warn("Squad report failed: member stats unavailable",
+				"steam_id", resolved[i],
+				"error", result.statsErr,
+				"error_type", classifyError(result.statsErr))
+			var steamErr *steam.APIError
+			if errors.As(result.statsErr, &steamErr) {
+				writeErrorResponse(w, steamErr)
+				return
+			}
+			writeErrorResponse(w, steam.NewInternalError(result.statsErr))
+			return
+		}
+		members[i] = steam.SquadMemberData{
+			Stats:        result.stats,
+			Achievements: result.achievements,
+			StatValues:   result.statValues,
+		}
+		if result.achErr != nil {
+			warnings = append(warnings, fmt.Sprintf("Achievements unavailable for %s: %s", resolved[i], classifyError(result.achErr)))
+		}
+		if result.valuesErr != nil {
+			warnings = append(warnings, fmt.Sprintf("Detailed stat counters unavailable for %s; style analytics are partial", resolved[i]))
+		}
+	}
+
+	report := steam.BuildSquadReport(members)
+	report.Warnings = warnings
+
+	log.Info("Squad report generated",
+		"members", len(report.Members),
+		"compared_achievements", report.SharedAchievements.ComparedMembers,
+		"warnings", len(warnings),
+		"duration", time.Since(start))
+
+	streamJSONResponse(w, r, report)
+}
+
+// serveDemoSquad builds the report from bundled fixtures when demo mode is active
+func (h *Handler) serveDemoSquad(w http.ResponseWriter, r *http.Request, ids []string) {
+	members := make([]steam.SquadMemberData, len(ids))
+	seen := make(map[string]int, len(ids))
+	for i, id := range ids {
+		player, found := demo.Player(id)
+		if !found {
+			writeError(w, r, "DEMO_PLAYER_NOT_FOUND",
+				"Demo mode is active; only bundled demo players are available",
+				http.StatusNotFound,
+				map[string]interface{}{"demo_players": demo.Players(), "field": fmt.Sprintf("steam_ids[%d]", i)},
+				nil)
+			return
+		}
+		if first, dup := seen[player.SteamID]; dup {
+			writeValidationError(w, r, fmt.Sprintf("steam_ids[%d] and steam_ids[%d] are the same player", first, i), "steam_ids")
+			return
+		}
+		seen[player.SteamID] = i
+		members[i] = steam.SquadMemberData{
+			Stats:        player.PlayerStats,
+			Achievements: player.Achievements,
+			StatValues:   statValuesFromStatsData(player.Stats),
+		}
+	}
+
+	report := steam.BuildSquadReport(members)
+	report.Demo = true
+	report.GeneratedAt = demo.LoadedAt()
+	w.Header().Set("X-Demo-Mode", "true")
+	streamJSONResponse(w, r, report)
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/demo"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// GetPlayerStatExpression evaluates ?expr= (e.g. DBD_Escape/DBD_MatchesPlayed)
+// over a player's raw stat counters so widgets can fetch one derived number
+// instead of the full payload. A literal + must be sent as %2B in the query.
+func (h *Handler) GetPlayerStatExpression(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	start := time.Now()
+	steamID, idType, invalidField, validationErr := playerIDFromRequest(r)
+
+	requestLogger := log.HTTPRequestContext(r.Method, r.URL.Path, steamID, r.RemoteAddr)
+
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr.Message, invalidField)
+		return
+	}
+
+	params := newQueryParams(r)
+	expr, err := steam.ParseStatExpr(params.values.Get("expr"))
+	if err != nil {
+		params.fail("expr", "Invalid expr: "+err.Error(), nil)
+	}
+	if err := params.err(); err != nil {
+		writeParamErrors(w, r, err)
+		return
+	}
+
+	if h.config.DemoMode {
+		player, found := demo.Player(steamID)
+		if !found {
+			writeError(w, r, "DEMO_PLAYER_NOT_FOUND",
+				"Demo mode is active; only bundled demo players are available",
+				http.StatusNotFound,
+				map[string]interface{}{"demo_players": demo.Players()},
+				nil)
+			return
+		}
+		lastUpdated := demo.LoadedAt()
+		w.Header().Set("X-Demo-Mode", "true")
+		setLastModified(w, lastUpdated)
+		writeJSONResponse(w, statExpressionResponse(player.SteamID, expr, statValuesFromStatsData(player.Stats), lastUpdated))
+		return
+	}
+
+	resolvedSteamID, resolvedAs, resolveErr := h.steamClient.ResolveSteamIDAs(ctx, steamID, idType)
+	if resolveErr != nil {
+		writeErrorResponse(w, resolveErr)
+		return
+	}
+	if !h.checkVanityBinding(w, r, steamID, resolvedSteamID, resolvedAs) {
+		return
+	}
+	w.Header().Set("X-Resolved-As", string(resolvedAs))
+
+	var rawStats *steam.SteamPlayerstats
+	var statsErr *steam.APIError
+	if h.cacheManager() != nil {
+		rawStats, statsErr = h.steamClient.GetUserStatsForGameCached(ctx, resolvedSteamID, steam.AppFromContext(ctx).NumericID(), h.cacheManager().GetCache())
+	} else {
+		rawStats, statsErr = h.steamClient.GetUserStatsForGame(ctx, resolvedSteamID, steam.AppFromContext(ctx).NumericID())
+	}
+	if statsErr != nil {
+		requestLogger.Warn("Failed to fetch player stats for expression",
+			"error", statsErr.Message,
+			"error_type", string(statsErr.Type),
+			"duration", time.Since(start))
+		writeErrorResponse(w, statsErr)
+		return
+	}
+
+	values := make(map[string]float64, len(rawStats.Stats))
+	for _, stat := range rawStats.Stats {
+		values[stat.Name] = stat.Value
+		// Renamed IDs still resolve, to the merged value
+		for _, source := range stat.Sources {
+			values[source.ID] = stat.Value
+		}
+	}
+	response := statExpressionResponse(resolvedSteamID, expr, values, time.Now().UTC())
+
+	requestLogger.Info("Stat expression evaluated",
+		"expr", expr.String(),
+		"stats_referenced", len(expr.Stats()),
+		"undefined", response["undefined"],
+		"duration", time.Since(start))
+
+	writeJSONResponse(w, response)
+}
+
+// statExpressionResponse shapes an evaluation; value is null when a divisor was
+// zero or the result overflowed, neither of which JSON can encode
+func statExpressionResponse(steamID string, expr *steam.StatExpr, values map[string]float64, evaluatedAt time.Time) map[string]interface{} {
+	result := expr.Eval(values)
+	if math.IsInf(result.Value, 0) || math.IsNaN(result.Value) {
+		result.Undefined = true
+	}
+
+	var value interface{} = result.Value
+	if result.Undefined {
+		value = nil
+	}
+	return map[string]interface{}{
+		"steam_id":      steamID,
+		"expr":          expr.String(),
+		"value":         value,
+		"undefined":     result.Undefined,
+		"stats":         expr.Stats(),
+		"missing_stats": result.Missing,
+		"evaluated_at":  evaluatedAt,
+	}
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// TestStatExpressionOverflowIsUndefined requires a result too large for a
+// float64 to come back as null instead of failing to encode
+func TestStatExpressionOverflowIsUndefined(t *testing.T) {
+	expr, err := steam.ParseStatExpr("DBD_BloodwebPoints * DBD_BloodwebPoints * DBD_BloodwebPoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := statExpressionResponse(benchSteamID, expr, map[string]float64{"DBD_BloodwebPoints": 1e200}, time.Now().UTC())
+
+	if response["value"] != nil || response["undefined"] != true {
+		t.Errorf("value %v, undefined %v; want null and true", response["value"], response["undefined"])
+	}
+	if _, err := json.Marshal(response); err != nil {
+		t.Errorf("response does not encode: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/notify"
+)
+
+// publishStatsChanged compares freshly fetched stats with the player's last
+// snapshot and publishes a stats_changed recap when anything moved, reporting
+// whether it did. The first fetch of a player only records the baseline.
+func (h *Handler) publishStatsChanged(steamID string, response models.PlayerStatsWithAchievements) bool {
+	now := time.Now().UTC()
+	snapshot := models.StatsSnapshot{
+		Escapes:           response.Escapes,
+		SacrificedCampers: response.SacrificedCampers,
+		KilledCampers:     response.KilledCampers,
+		KillerPips:        response.KillerPips,
+		SurvivorPips:      response.SurvivorPips,
+		TakenAt:           now,
+	}
+	grades := currentGrades(response.Stats, now)
+	if grades.Killer != nil {
+		snapshot.KillerGrade = grades.Killer.Grade
+	}
+	if grades.Survivor != nil {
+		snapshot.SurvivorGrade = grades.Survivor.Grade
+	}
+
+	previous := h.players.ObserveSnapshot(steamID, snapshot)
+	if previous == nil {
+		return false
+	}
+	delta := snapshot.Diff(*previous)
+	if delta.Empty() {
+		return false
+	}
+	if h.notifier == nil {
+		return true
+	}
+
+	h.notifier.Publish(notify.Event{
+		Type:     notify.EventStatsChanged,
+		Severity: notify.SeverityInfo,
+		Title:    "Session recap: " + response.DisplayName,
+		Message:  recapMessage(delta),
+		Subject:  steamID,
+		Fields:   deltaFields(steamID, delta),
+	})
+	return true
+}
+
+// deltaFields flattens a delta so chat channels list one change per line and
+// webhook consumers get the same keys as models.StatsDelta
+func deltaFields(steamID string, delta models.StatsDelta) map[string]interface{} {
+	fields := map[string]interface{}{
+		"steam_id": steamID,
+		"since":    delta.Since.Format(time.RFC3339),
+	}
+	for key, n := range map[string]int{
+		"new_escapes":    delta.NewEscapes,
+		"new_sacrifices": delta.NewSacrifices,
+		"new_kills":      delta.NewKills,
+		"killer_pips":    delta.KillerPips,
+		"survivor_pips":  delta.SurvivorPips,
+	} {
+		if n > 0 {
+			fields[key] = n
+		}
+	}
+	if change := delta.KillerGrade; change != nil {
+		fields["killer_grade"] = change.To
+	}
+	if change := delta.SurvivorGrade; change != nil {
+		fields["survivor_grade"] = change.To
+	}
+	return fields
+}
+
+// recapMessage summarises a delta in one line for chat channels
+func recapMessage(delta models.StatsDelta) string {
+	var parts []string
+	for _, counter := range []struct {
+		n     int
+		label string
+	}{
+		{delta.NewEscapes, "escapes"},
+		{delta.NewSacrifices, "sacrifices"},
+		{delta.NewKills, "kills"},
+		{delta.KillerPips, "killer pips"},
+		{delta.SurvivorPips, "survivor pips"},
+	} {
+		if counter.n > 0 {
+			parts = append(parts, fmt.Sprintf("+%d %s", counter.n, counter.label))
+		}
+	}
+	if change := delta.KillerGrade; change != nil {
+		parts = append(parts, gradeChangeText("Killer", change))
+	}
+	if change := delta.SurvivorGrade; change != nil {
+		parts = append(parts, gradeChangeText("Survivor", change))
+	}
+	return strings.Join(parts, ", ") + " since " + delta.Since.Format(time.RFC1123)
+}
+
+func gradeChangeText(role string, change *models.GradeChange) string {
+	if change.From == "" {
+		return role + " grade " + change.To
+	}
+	return role + " grade " + change.From + " → " + change.To
+}
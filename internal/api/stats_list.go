@@ -0,0 +1,198 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/demo"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// GetPlayerStatsList serves the mapped stat list filtered, sorted and paged
+// server-side, e.g. ?category=killer&value_type=count&sort=value_desc&limit=10,
+// so small widgets don't download all ~200 stats.
+func (h *Handler) GetPlayerStatsList(w http.ResponseWriter, r *http.Request) {
+	query, locale, err := statListQueryFromRequest(r)
+	if err != nil {
+		writeParamErrors(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Language", locale.Lang)
+
+	steamID, statsData, lastUpdated, ok := h.structuredStatsForRequest(w, r, "Stat list")
+	if !ok {
+		return
+	}
+	streamJSONResponse(w, r, statListResponse(steamID, localizeStats(statsData, locale.Lang, locale.Format), query, lastUpdated))
+}
+
+// GetPlayerStatsSummary aggregates the mapped stats server-side, e.g.
+// ?group_by=category for per-category totals broken down by value type, so
+// dashboards can render totals without the stat list. Counts, floats and
+// durations are summed; grades and levels only report their range.
+func (h *Handler) GetPlayerStatsSummary(w http.ResponseWriter, r *http.Request) {
+	params := newQueryParams(r)
+	groupBy := params.enum("group_by", steam.StatGroupings, "category")
+	query := steam.StatListQuery{
+		Category:  params.enum("category", steam.StatCategories, ""),
+		ValueType: params.enum("value_type", steam.StatValueTypes, ""),
+	}
+	if err := params.err(); err != nil {
+		writeParamErrors(w, r, err)
+		return
+	}
+
+	steamID, statsData, lastUpdated, ok := h.structuredStatsForRequest(w, r, "Stat summary")
+	if !ok {
+		return
+	}
+	stats, total := steam.FilterStats(statsFromStatsData(statsData), query)
+	response := map[string]interface{}{
+		"steam_id":     steamID,
+		"group_by":     groupBy,
+		"total":        total,
+		"last_updated": lastUpdated,
+	}
+	if groupBy == "value_type" {
+		response["groups"] = steam.AggregateStatsByValueType(stats)
+	} else {
+		response["groups"] = steam.GroupStatsByCategory(stats)
+	}
+	writeJSONResponse(w, response)
+}
+
+// structuredStatsForRequest resolves the request's player and returns their
+// mapped stats, or the bundled ones in demo mode, with the resolved SteamID64
+// and when the stats were fetched. On failure the error response is written
+// and ok is false.
+func (h *Handler) structuredStatsForRequest(w http.ResponseWriter, r *http.Request, what string) (steamID string, data *models.StatsData, lastUpdated time.Time, ok bool) {
+	ctx := r.Context()
+	start := time.Now()
+	steamID, idType, invalidField, validationErr := playerIDFromRequest(r)
+
+	requestLogger := log.HTTPRequestContext(r.Method, r.URL.Path, steamID, r.RemoteAddr)
+
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr.Message, invalidField)
+		return "", nil, time.Time{}, false
+	}
+
+	if h.config.DemoMode {
+		player, found := demo.Player(steamID)
+		if !found {
+			writeError(w, r, "DEMO_PLAYER_NOT_FOUND",
+				"Demo mode is active; only bundled demo players are available",
+				http.StatusNotFound,
+				map[string]interface{}{"demo_players": demo.Players()},
+				nil)
+			return "", nil, time.Time{}, false
+		}
+		lastUpdated := demo.LoadedAt()
+		w.Header().Set("X-Demo-Mode", "true")
+		setLastModified(w, lastUpdated)
+		return player.SteamID, player.Stats, lastUpdated, true
+	}
+
+	resolvedSteamID, resolvedAs, found, resolveErr := h.resolvePlayerID(ctx, steamID, idType)
+	if !found {
+		writeNotCached(w, r, steamID)
+		return "", nil, time.Time{}, false
+	}
+	if resolveErr != nil {
+		writeErrorResponse(w, resolveErr)
+		return "", nil, time.Time{}, false
+	}
+	if !h.checkVanityBinding(w, r, steamID, resolvedSteamID, resolvedAs) {
+		return "", nil, time.Time{}, false
+	}
+	w.Header().Set("X-Resolved-As", string(resolvedAs))
+
+	statsData, source, err := h.fetchPlayerStructuredStatsWithSource(ctx, resolvedSteamID)
+	if err != nil {
+		requestLogger.Warn("Failed to fetch structured stats",
+			"for", what,
+			"error", err,
+			"error_type", classifyError(err),
+			"duration", time.Since(start))
+		if errors.Is(err, cache.ErrNotCached) {
+			writeNotCached(w, r, steamID)
+			return "", nil, time.Time{}, false
+		}
+		var steamErr *steam.APIError
+		if errors.As(err, &steamErr) {
+			writeErrorResponse(w, steamErr)
+			return "", nil, time.Time{}, false
+		}
+		writeErrorResponse(w, steam.NewInternalError(err))
+		return "", nil, time.Time{}, false
+	}
+	requestLogger.Info(what+" served",
+		"source", source,
+		"duration", time.Since(start))
+	return resolvedSteamID, statsData, time.Now().UTC(), true
+}
+
+const (
+	defaultStatListLimit = 50
+	maxStatListLimit     = 500
+)
+
+// statListQueryFromRequest parses the list, lang and locale parameters
+func statListQueryFromRequest(r *http.Request) (steam.StatListQuery, localeOptions, error) {
+	params := newQueryParams(r)
+	query := steam.StatListQuery{
+		Category:  params.enum("category", steam.StatCategories, ""),
+		ValueType: params.enum("value_type", steam.StatValueTypes, ""),
+		Sort:      params.enum("sort", steam.StatSorts, ""),
+		Limit:     params.intRange("limit", defaultStatListLimit, 1, maxStatListLimit),
+		Offset:    params.offset(),
+	}
+	return query, params.localeOptions(), params.err()
+}
+
+func statListResponse(steamID string, data *models.StatsData, query steam.StatListQuery, lastUpdated time.Time) map[string]interface{} {
+	page, total := steam.FilterStats(statsFromStatsData(data), query)
+
+	response := map[string]interface{}{
+		"steam_id":     steamID,
+		"total":        total,
+		"limit":        query.Limit,
+		"offset":       query.Offset,
+		"stats":        page,
+		"last_updated": lastUpdated,
+	}
+	if next := query.Offset + len(page); next < total {
+		response["next_offset"] = next
+	}
+	return response
+}
+
+// statsFromStatsData recovers typed stats from structured stats, which hold
+// steam.Stat values when built live and generic maps when decoded from fixtures
+func statsFromStatsData(data *models.StatsData) []steam.Stat {
+	if data == nil {
+		return nil
+	}
+	stats := make([]steam.Stat, 0, len(data.Stats))
+	for _, raw := range data.Stats {
+		switch stat := raw.(type) {
+		case steam.Stat:
+			stats = append(stats, stat)
+		case map[string]interface{}:
+			encoded, err := json.Marshal(stat)
+			if err != nil {
+				continue
+			}
+			var decoded steam.Stat
+			if err := json.Unmarshal(encoded, &decoded); err == nil {
+				stats = append(stats, decoded)
+			}
+		}
+	}
+	return stats
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// fakeSteam answers the Steam endpoints the player handlers call, each after
+// delay, and counts the calls it serves
+type fakeSteam struct {
+	*httptest.Server
+	delay time.Duration
+	calls atomic.Int64
+}
+
+// newFakeSteam starts a fake Steam API and points clients created afterwards
+// at it. Schemas aren't persisted, so runs never write outside the test.
+func newFakeSteam(tb testing.TB, delay time.Duration) *fakeSteam {
+	tb.Helper()
+	fake := &fakeSteam{delay: delay}
+	fake.Server = httptest.NewServer(http.HandlerFunc(fake.serve))
+	tb.Cleanup(fake.Close)
+
+	tb.Setenv("STEAM_API_KEY", "test-key")
+	tb.Setenv("STEAM_API_BASE_URLS", fake.URL)
+	tb.Setenv("SCHEMA_STORE_DIR", tb.TempDir()) // never the developer's data/
+	return fake
+}
+
+// newClient returns a client for the fake, closed when the test ends
+func (f *fakeSteam) newClient(tb testing.TB) *steam.Client {
+	client := steam.NewClient()
+	tb.Cleanup(client.Close)
+	return client
+}
+
+func (f *fakeSteam) serve(w http.ResponseWriter, r *http.Request) {
+	f.calls.Add(1)
+	select {
+	case <-time.After(f.delay):
+	case <-r.Context().Done():
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/ISteamUser/GetPlayerSummaries/v0002/":
+		players := make([]string, 0)
+		for _, id := range strings.Split(r.URL.Query().Get("steamids"), ",") {
+			players = append(players, fmt.Sprintf(`{"steamid":%q,"personaname":"player %s","avatarfull":"https://example.com/%s.jpg"}`, id, id[len(id)-4:], id))
+		}
+		fmt.Fprintf(w, `{"response":{"players":[%s]}}`, strings.Join(players, ","))
+	case "/ISteamUserStats/GetUserStatsForGame/v2/":
+		fmt.Fprintf(w, `{"playerstats":{"steamID":%q,"gameName":"Dead by Daylight","stats":[{"name":"DBD_BloodwebPoints","value":1000000},{"name":"DBD_KillerSkulls","value":12}],"achievements":[]}}`,
+			r.URL.Query().Get("steamid"))
+	default:
+		http.NotFound(w, r)
+	}
+}
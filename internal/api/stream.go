@@ -0,0 +1,107 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// streamChunkSize is how much encoded JSON collects before it is flushed to
+// the client. A slow reader blocks the encoder at each flush instead of the
+// response piling up in memory.
+const streamChunkSize = 32 << 10
+
+var (
+	streamedResponses = metrics.NewCounter("dbd_json_streamed_responses",
+		"JSON responses encoded straight to the client.")
+	streamedBytes = metrics.NewCounter("dbd_json_streamed_bytes",
+		"Bytes of JSON encoded straight to the client.")
+	bufferedBytes = metrics.NewCounter("dbd_json_buffered_bytes",
+		"Bytes of JSON marshalled in full before being written.")
+)
+
+// streamStarter is implemented by response writers that buffer by default but
+// can switch to writing through to the client, such as the route timeout's
+type streamStarter interface {
+	// startStream sends the headers with status and returns the writer for
+	// the body, or false when the response can no longer be streamed
+	startStream(status int) (io.Writer, bool)
+}
+
+// streamJSONResponse writes data as a 200 JSON response without holding the
+// whole body in memory twice. Responses that need an ETag (Last-Modified is
+// set) and writers that must see the full body, such as the MessagePack
+// transcoder, get the buffered writeJSONResponse instead.
+func streamJSONResponse(w http.ResponseWriter, r *http.Request, data interface{}) {
+	starter, ok := w.(streamStarter)
+	if !ok || w.Header().Get("Last-Modified") != "" {
+		writeJSONResponse(w, data)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Expires", "0")
+
+	if r.Method == http.MethodHead {
+		starter.startStream(http.StatusOK)
+		return
+	}
+
+	// The stream starts on the first write, which json.Encoder only makes once
+	// the value has encoded, so an unencodable value can still get a 500
+	stream := &lazyStream{starter: starter}
+	buffered := bufio.NewWriterSize(stream, streamChunkSize)
+	err := json.NewEncoder(buffered).Encode(data)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if stream.started {
+		streamedResponses.Add(1)
+		streamedBytes.Add(stream.n)
+	}
+
+	switch {
+	case err != nil && !stream.started:
+		log.Error("Failed to encode streamed JSON response", "error", err.Error())
+		writeErrorResponse(w, steam.NewInternalError(err))
+	case err != nil:
+		log.Debug("Streamed JSON response cut short",
+			"error", err.Error(),
+			"bytes_written", stream.n)
+	default:
+		log.Info("successful_response_sent",
+			"status_code", http.StatusOK,
+			"response_size", stream.n,
+			"content_type", "application/json",
+			"streamed", true)
+	}
+}
+
+// lazyStream starts the response stream on its first write and counts the
+// bytes that go through
+type lazyStream struct {
+	starter streamStarter
+	body    io.Writer
+	started bool
+	n       int64
+}
+
+func (s *lazyStream) Write(p []byte) (int, error) {
+	if !s.started {
+		body, ok := s.starter.startStream(http.StatusOK)
+		if !ok {
+			return 0, http.ErrHandlerTimeout
+		}
+		s.body, s.started = body, true
+	}
+	n, err := s.body.Write(p)
+	s.n += int64(n)
+	return n, err
+}
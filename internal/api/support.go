@@ -0,0 +1,386 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/buildinfo"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+const (
+	defaultCaptureCount = 50
+	maxCaptureCount     = 200
+	captureBodyLimit    = 64 << 10 // bytes kept per request or response body
+	supportPathPrefix   = "/admin/support/"
+	redacted            = "[REDACTED]"
+)
+
+// captureHeaders never leave the process unredacted
+var captureHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Admin-Token":       true,
+	"X-Api-Key":           true,
+	"X-Profile-Token":     true,
+}
+
+// secretJSONField matches JSON string fields named like credentials, such as
+// the profile_token a profile link hands out
+var secretJSONField = regexp.MustCompile(`("[A-Za-z_]*(?:token|secret|password)"\s*:\s*")[^"]*"`)
+
+// capturedExchange is one recorded request and the response it got
+type capturedExchange struct {
+	RequestID             string      `json:"request_id,omitempty"`
+	At                    time.Time   `json:"at"`
+	DurationMs            float64     `json:"duration_ms"`
+	Method                string      `json:"method"`
+	Path                  string      `json:"path"`
+	Query                 string      `json:"query,omitempty"`
+	RequestHeaders        http.Header `json:"request_headers"`
+	RequestBody           string      `json:"request_body,omitempty"`
+	RequestBodyTruncated  bool        `json:"request_body_truncated,omitempty"`
+	Status                int         `json:"status"`
+	ResponseHeaders       http.Header `json:"response_headers"`
+	ResponseBody          string      `json:"response_body,omitempty"`
+	ResponseBodyTruncated bool        `json:"response_body_truncated,omitempty"`
+}
+
+// supportCapture records the next N requests and responses once an admin arms
+// it, for a support bundle. Credentials in headers, query parameters and any
+// configured secret value are redacted before anything is stored.
+type supportCapture struct {
+	mu        sync.Mutex
+	requested int
+	remaining int
+	startedAt time.Time
+	exchanges []capturedExchange
+	redact    *strings.Replacer
+}
+
+func newSupportCapture(credentials []adminCredential) *supportCapture {
+	return &supportCapture{redact: secretRedactor(credentials)}
+}
+
+// secretRedactor replaces every configured secret: variables whose names mark
+// them as keys, tokens, secrets, passwords or webhook URLs, and admin tokens
+func secretRedactor(credentials []adminCredential) *strings.Replacer {
+	var secrets []string
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		upper := strings.ToUpper(name)
+		for _, marker := range []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "WEBHOOK_URL"} {
+			if strings.Contains(upper, marker) {
+				secrets = append(secrets, value)
+				break
+			}
+		}
+	}
+	for _, credential := range credentials {
+		secrets = append(secrets, credential.token)
+	}
+
+	// Longest first so a secret containing another is replaced whole; very
+	// short values would redact ordinary text
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	var pairs []string
+	seen := make(map[string]bool)
+	for _, secret := range secrets {
+		if len(secret) < 6 || seen[secret] {
+			continue
+		}
+		seen[secret] = true
+		pairs = append(pairs, secret, redacted)
+		if escaped, err := json.Marshal(secret); err == nil {
+			if inner := string(escaped[1 : len(escaped)-1]); inner != secret {
+				pairs = append(pairs, inner, redacted) // as it appears inside JSON
+			}
+		}
+		if escaped := url.QueryEscape(secret); escaped != secret {
+			pairs = append(pairs, escaped, redacted)
+		}
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// start arms the capture for the next count requests, dropping earlier ones
+func (c *supportCapture) start(count int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requested = count
+	c.remaining = count
+	c.startedAt = time.Now().UTC()
+	c.exchanges = nil
+}
+
+// stop disarms the capture and discards what it recorded
+func (c *supportCapture) stop() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	discarded := len(c.exchanges)
+	c.requested, c.remaining = 0, 0
+	c.exchanges = nil
+	return discarded
+}
+
+// claim reserves a slot for one request, so concurrent requests never record
+// more than were asked for
+func (c *supportCapture) claim() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.remaining <= 0 {
+		return false
+	}
+	c.remaining--
+	return true
+}
+
+func (c *supportCapture) add(exchange capturedExchange) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.requested == 0 {
+		return // stopped while the request ran
+	}
+	c.exchanges = append(c.exchanges, exchange)
+	if len(c.exchanges) == c.requested {
+		log.Info("Support capture complete", "requests", c.requested)
+	}
+}
+
+func (c *supportCapture) status() map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return map[string]interface{}{
+		"active":     c.remaining > 0,
+		"requested":  c.requested,
+		"remaining":  c.remaining,
+		"recorded":   len(c.exchanges),
+		"started_at": c.startedAt,
+	}
+}
+
+func (c *supportCapture) recorded() []capturedExchange {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]capturedExchange(nil), c.exchanges...)
+}
+
+// redactHeaders copies header with credentials and secret values masked
+func (c *supportCapture) redactHeaders(header http.Header) http.Header {
+	out := make(http.Header, len(header))
+	for name, values := range header {
+		if captureHeaders[http.CanonicalHeaderKey(name)] {
+			out[name] = []string{redacted}
+			continue
+		}
+		masked := make([]string, len(values))
+		for i, value := range values {
+			masked[i] = c.redact.Replace(value)
+		}
+		out[name] = masked
+	}
+	return out
+}
+
+// redactQuery masks parameters named like credentials and any secret value
+func (c *supportCapture) redactQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	masked := make(url.Values, len(query))
+	for name, values := range query {
+		lower := strings.ToLower(name)
+		if strings.Contains(lower, "key") || strings.Contains(lower, "token") ||
+			strings.Contains(lower, "secret") || strings.Contains(lower, "password") {
+			masked[name] = []string{redacted}
+			continue
+		}
+		masked[name] = values
+	}
+	return c.redact.Replace(masked.Encode())
+}
+
+// body renders a captured body as text; binary bodies such as MessagePack are
+// only described
+func (c *supportCapture) body(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	if !utf8.Valid(data) {
+		return fmt.Sprintf("[%d bytes of binary data]", len(data))
+	}
+	return secretJSONField.ReplaceAllString(c.redact.Replace(string(data)), "${1}"+redacted+`"`)
+}
+
+// captureWriter passes the response through while keeping its status, and the
+// start of its body
+type captureWriter struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (cw *captureWriter) WriteHeader(statusCode int) {
+	if cw.status == 0 {
+		cw.status = statusCode
+	}
+	cw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (cw *captureWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	if room := captureBodyLimit - cw.body.Len(); room > 0 {
+		if len(p) > room {
+			cw.body.Write(p[:room])
+			cw.truncated = true
+		} else {
+			cw.body.Write(p)
+		}
+	} else if len(p) > 0 {
+		cw.truncated = true
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the connection for streaming
+func (cw *captureWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// middleware records requests while the capture is armed. It runs outermost
+// so recovered panics and every other middleware's answer are seen; the
+// support endpoints themselves are never recorded.
+func (c *supportCapture) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, supportPathPrefix) || !c.claim() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		exchange := capturedExchange{
+			At:             start.UTC(),
+			Method:         r.Method,
+			Path:           c.redact.Replace(r.URL.Path),
+			Query:          c.redactQuery(r.URL.Query()),
+			RequestHeaders: c.redactHeaders(r.Header),
+		}
+
+		// Keep the start of the body and hand the handler all of it
+		if r.Body != nil && r.Body != http.NoBody {
+			head, _ := io.ReadAll(io.LimitReader(r.Body, captureBodyLimit+1))
+			if len(head) > captureBodyLimit {
+				exchange.RequestBodyTruncated = true
+				exchange.RequestBody = c.body(head[:captureBodyLimit])
+			} else {
+				exchange.RequestBody = c.body(head)
+			}
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+		}
+
+		cw := &captureWriter{ResponseWriter: w}
+		defer func() {
+			exchange.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+			exchange.Status = cw.status
+			if exchange.Status == 0 {
+				exchange.Status = http.StatusOK
+			}
+			exchange.ResponseHeaders = c.redactHeaders(w.Header())
+			exchange.RequestID = w.Header().Get("X-Request-ID")
+			exchange.ResponseBody = c.body(cw.body.Bytes())
+			exchange.ResponseBodyTruncated = cw.truncated
+			c.add(exchange)
+		}()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// StartSupportCapture arms recording of the next ?count= requests (default 50,
+// at most 200), replacing any earlier capture
+func (h *Handler) StartSupportCapture(w http.ResponseWriter, r *http.Request) {
+	params := newQueryParams(r)
+	count := params.intRange("count", defaultCaptureCount, 1, maxCaptureCount)
+	if err := params.err(); err != nil {
+		writeParamErrors(w, r, err)
+		return
+	}
+	h.capture.start(count)
+	log.Info("Support capture started", "requests", count, "client_ip", getClientIP(r))
+	writeJSONResponseWithStatus(w, h.capture.status(), http.StatusAccepted)
+}
+
+// GetSupportCapture reports whether a capture is running and how far along it is
+func (h *Handler) GetSupportCapture(w http.ResponseWriter, r *http.Request) {
+	writeJSONResponse(w, h.capture.status())
+}
+
+// StopSupportCapture disarms the capture and discards what it recorded
+func (h *Handler) StopSupportCapture(w http.ResponseWriter, r *http.Request) {
+	discarded := h.capture.stop()
+	log.Info("Support capture stopped", "discarded", discarded)
+	writeJSONResponse(w, map[string]interface{}{"stopped": true, "discarded": discarded})
+}
+
+// GetSupportBundle downloads the recorded requests with a snapshot of the
+// configuration, cache statistics, circuit breaker and upstream state. Secret
+// values are redacted from the whole document.
+func (h *Handler) GetSupportBundle(w http.ResponseWriter, r *http.Request) {
+	bundle := map[string]interface{}{
+		"generated_at":   time.Now().UTC(),
+		"build":          buildinfo.Get(),
+		"uptime_seconds": int64(buildinfo.Uptime().Seconds()),
+		"goroutines":     runtime.NumGoroutine(),
+		"config":         h.config,
+		"feature_flags":  h.flags.Snapshot(),
+		"worker_pool":    h.workers.Stats(),
+		"cache_mode":     h.cacheInit.status(),
+		"steam": map[string]interface{}{
+			"hosts": h.steamClient.HostStatus(),
+			"quota": h.steamClient.Quota().Status(),
+		},
+		"capture":  h.capture.status(),
+		"requests": h.capture.recorded(),
+	}
+	if manager := h.cacheManager(); manager != nil {
+		bundle["cache"] = manager.GetCacheStatus()
+		if cb := manager.GetCircuitBreaker(); cb != nil {
+			bundle["circuit_breaker"] = cb.GetDetailedStatus()
+		}
+	}
+	if h.notifier != nil {
+		bundle["notification_channels"] = h.notifier.Health()
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		writeError(w, r, "BUNDLE_FAILED", "Failed to encode the support bundle", http.StatusInternalServerError, nil, nil)
+		return
+	}
+	data = []byte(h.capture.redact.Replace(string(data)))
+
+	filename := "support-bundle-" + time.Now().UTC().Format("20060102T150405Z") + ".json"
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(data)
+}
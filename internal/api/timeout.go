@@ -0,0 +1,201 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+)
+
+// HealthCheckTimeout bounds health endpoints, which should never block on upstreams
+const HealthCheckTimeout = 2 * time.Second
+
+// timeoutWriter buffers a handler's response so nothing reaches the client
+// until the handler finishes inside its deadline. A handler writing a large
+// body can instead stream it, giving up the timeout envelope once it starts.
+type timeoutWriter struct {
+	w           http.ResponseWriter
+	mu          sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	statusCode  int
+	wroteHeader bool
+	timedOut    bool
+	streaming   bool
+}
+
+func newTimeoutWriter(w http.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{w: w, header: make(http.Header)}
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.streaming {
+		n, err := tw.w.Write(p)
+		if err == nil {
+			// Unsupported only for writers that can't flush, which is not an error
+			http.NewResponseController(tw.w).Flush()
+		}
+		return n, err
+	}
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+	return tw.body.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(statusCode int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.writeHeaderLocked(statusCode)
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(statusCode int) {
+	tw.wroteHeader = true
+	tw.statusCode = statusCode
+}
+
+// startStream sends the headers now and writes the body straight through from
+// here on. It refuses once the deadline has passed or the handler has already
+// buffered a response.
+func (tw *timeoutWriter) startStream(status int) (io.Writer, bool) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.wroteHeader {
+		return nil, false
+	}
+	copyBufferedHeader(tw.w.Header(), tw.header)
+	tw.writeHeaderLocked(status)
+	tw.streaming = true
+	tw.w.WriteHeader(status)
+	return tw, true
+}
+
+// copyBufferedHeader moves a buffered handler's headers onto the real
+// response. Vary is merged, since middleware outside the route may already
+// have named request headers the response depends on.
+func copyBufferedHeader(dst, src http.Header) {
+	for key, values := range src {
+		if key == "Vary" {
+			dst[key] = append(dst[key], values...)
+			continue
+		}
+		dst[key] = values
+	}
+}
+
+type timeoutExtensionKey struct{}
+
+// withTimeoutExtension lets a request hold its route past the usual deadline
+// by however long extra says it asked to wait, e.g. for a long poll. It goes
+// outside withTimeout.
+func withTimeoutExtension(extra func(*http.Request) time.Duration, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if d := extra(r); d > 0 {
+			r = r.WithContext(context.WithValue(r.Context(), timeoutExtensionKey{}, d))
+		}
+		next(w, r)
+	}
+}
+
+// withTimeout enforces a hard deadline on a route. The handler runs with a
+// context that is cancelled at the deadline; if it has not finished by then the
+// standard timeout envelope is written and anything the handler writes later is discarded.
+func withTimeout(timeout time.Duration, operation string, next http.HandlerFunc) http.HandlerFunc {
+	traced := tracingEnabled()
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		traceID := ""
+		if traced {
+			traceID = traceIDFromRequest(r)
+		}
+
+		deadline := timeout
+		if extra, ok := r.Context().Value(timeoutExtensionKey{}).(time.Duration); ok {
+			deadline += extra
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), deadline)
+		defer cancel()
+		r = r.WithContext(ctx)
+
+		tw := newTimeoutWriter(w)
+		done := make(chan struct{})
+		panicChan := make(chan interface{}, 1)
+
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicChan <- p
+				}
+			}()
+			next(tw, r)
+			close(done)
+		}()
+
+		select {
+		case p := <-panicChan:
+			panic(p)
+
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			metrics.ObserveLatency(operation, time.Since(start), traceID)
+			if tw.streaming {
+				return
+			}
+
+			copyBufferedHeader(w.Header(), tw.header)
+			if !tw.wroteHeader {
+				tw.statusCode = http.StatusOK
+			}
+			writeBuffered(w, r, tw.statusCode, tw.body.Bytes())
+
+		case <-ctx.Done():
+			tw.mu.Lock()
+			tw.timedOut = true
+			streaming := tw.streaming
+			tw.mu.Unlock()
+
+			log.Warn("Route deadline exceeded",
+				"operation", operation,
+				"method", r.Method,
+				"path", r.URL.Path,
+				"timeout", deadline,
+				"cause", ctx.Err().Error(),
+				"streaming", streaming)
+
+			// A streamed response already has its status line out, so the
+			// client sees a truncated body; wait for the handler to stop
+			// writing to the connection before the server reuses it
+			if streaming {
+				select {
+				case <-done:
+				case p := <-panicChan:
+					panic(p)
+				}
+			} else {
+				writeTimeoutError(w, r, operation)
+			}
+			metrics.ObserveLatency(operation, time.Since(start), traceID)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// Phases of a combined player request reported by ?include=timing
+const (
+	phaseCache        = "cache"
+	phaseSteamSummary = "steam_summary"
+	phaseSteamStats   = "steam_stats"
+	phaseSteamAch     = "steam_ach"
+	phaseMapping      = "mapping"
+)
+
+// steamPhases sorts Steam endpoints into the phase their time counts toward;
+// ban and inventory lookups aren't part of the breakdown
+var steamPhases = []struct {
+	endpoint string
+	phase    string
+}{
+	{"GetPlayerSummaries", phaseSteamSummary},
+	{"ResolveVanityURL", phaseSteamSummary},
+	{"GetUserStatsForGame", phaseSteamStats},
+	{"GetSchemaForGame", phaseSteamStats},
+	{"GetPlayerAchievements", phaseSteamAch},
+	{"GetGlobalAchievementPercentages", phaseSteamAch},
+}
+
+// requestTiming sums how long a request spent in each phase. Worker-pool
+// tasks record into it concurrently.
+type requestTiming struct {
+	start  time.Time
+	mu     sync.Mutex
+	phases map[string]time.Duration
+}
+
+type requestTimingKey struct{}
+
+// withRequestTiming returns ctx carrying a recorder for a request that began
+// at start, which the Steam client reports its calls to
+func withRequestTiming(ctx context.Context, start time.Time) context.Context {
+	timing := &requestTiming{start: start, phases: make(map[string]time.Duration)}
+	ctx = context.WithValue(ctx, requestTimingKey{}, timing)
+	return steam.WithRequestObserver(ctx, timing.steamObserver(false))
+}
+
+// timingFromContext returns ctx's recorder, or nil when timing wasn't requested
+func timingFromContext(ctx context.Context) *requestTiming {
+	timing, _ := ctx.Value(requestTimingKey{}).(*requestTiming)
+	return timing
+}
+
+// observe adds the time since since to phase; a nil recorder ignores it
+func (t *requestTiming) observe(phase string, since time.Time) {
+	if t == nil {
+		return
+	}
+	elapsed := time.Since(since)
+	t.mu.Lock()
+	t.phases[phase] += elapsed
+	t.mu.Unlock()
+}
+
+// mapping returns ctx for a mapping section timed as phaseMapping: Steam calls
+// made inside it (schema or adept lookups on a cold cache) count toward their
+// own phase and are taken back out of mapping
+func (t *requestTiming) mapping(ctx context.Context) context.Context {
+	if t == nil {
+		return ctx
+	}
+	return steam.WithRequestObserver(ctx, t.steamObserver(true))
+}
+
+func (t *requestTiming) steamObserver(inMapping bool) func(steam.RequestInfo, steam.RequestResult) {
+	return func(info steam.RequestInfo, result steam.RequestResult) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		for _, candidate := range steamPhases {
+			if strings.Contains(info.Endpoint, candidate.endpoint) {
+				t.phases[candidate.phase] += result.Duration
+				break
+			}
+		}
+		if inMapping {
+			t.phases[phaseMapping] -= result.Duration
+		}
+	}
+}
+
+// report returns the phases so far and the total since the request began
+func (t *requestTiming) report() *models.ResponseTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return &models.ResponseTiming{
+		CacheMs:        milliseconds(t.phases[phaseCache]),
+		SteamSummaryMs: milliseconds(t.phases[phaseSteamSummary]),
+		SteamStatsMs:   milliseconds(t.phases[phaseSteamStats]),
+		SteamAchMs:     milliseconds(t.phases[phaseSteamAch]),
+		MappingMs:      milliseconds(max(t.phases[phaseMapping], 0)), // parallel Steam calls can outweigh the section
+		TotalMs:        milliseconds(time.Since(t.start)),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
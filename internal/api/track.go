@@ -0,0 +1,338 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+	"github.com/rgonzalez12/dbd-analytics/internal/store"
+)
+
+const (
+	// trackTickInterval is how often the refresher looks for due players
+	trackTickInterval = time.Minute
+	// trackRefreshBatch caps refetches per tick so a large registry is spread out
+	trackRefreshBatch = 50
+	// trackSlowedBatch caps refetches per tick while Steam is degraded
+	trackSlowedBatch = 5
+)
+
+// Refresher paces. While Steam is degraded the refresher backs off so it
+// doesn't compete with user traffic for what capacity is left; players held
+// back stay due and are caught up stalest first once Steam recovers.
+const (
+	paceNormal = "normal"
+	paceSlowed = "slowed" // at most trackSlowedBatch refetches per tick
+	pacePaused = "paused" // no refetches until Steam recovers
+)
+
+var (
+	trackPaceLevel = metrics.NewGauge("dbd_track_refresh_pace", "Background refresher pace: 0 normal, 1 slowed, 2 paused.")
+	trackBacklog   = metrics.NewGauge("dbd_track_refresh_backlog", "Tracked players due for a refresh at the last tick.")
+	trackDeferred  = metrics.NewCounter("dbd_track_refresh_deferred", "Due tracked-player refreshes held back while Steam was degraded.")
+)
+
+// trackPacer remembers the refresher's pace so transitions are logged once
+// and the status endpoint can report it
+type trackPacer struct {
+	mu      sync.Mutex
+	pace    string
+	reason  string
+	since   time.Time
+	backlog int
+}
+
+func newTracked(config APIConfig) *store.Tracked {
+	return store.NewTracked(store.TrackedConfig{
+		MaxPerOwner:   config.TrackMaxPerKey,
+		MaxTotal:      config.TrackMaxTotal,
+		InactiveAfter: time.Duration(config.TrackInactiveDays) * 24 * time.Hour,
+	})
+}
+
+// trackOwner identifies the caller for per-key limits: a digest of the API key
+// (never the key itself), or the client IP for anonymous callers. Only the
+// configured key counts; an unchecked header would let a caller mint a fresh
+// owner per request and sidestep the limit.
+func trackOwner(r *http.Request) string {
+	if hasValidAPIKey(r) {
+		sum := sha256.Sum256([]byte(r.Header.Get("X-API-Key")))
+		return "key:" + hex.EncodeToString(sum[:6])
+	}
+	return "ip:" + getClientIP(r)
+}
+
+// TrackPlayer registers a player for background refreshes, which publish
+// stats_changed notifications when their counters move
+func (h *Handler) TrackPlayer(w http.ResponseWriter, r *http.Request) {
+	steamID, ok := h.resolveTrackTarget(w, r)
+	if !ok {
+		return
+	}
+
+	tracked, created, err := h.tracked.Track(trackOwner(r), steamID)
+	if err != nil {
+		scope, limit := "client", h.config.TrackMaxPerKey
+		if errors.Is(err, store.ErrGlobalTrackLimit) {
+			scope, limit = "service", h.config.TrackMaxTotal
+		}
+		writeError(w, r, "TRACK_LIMIT_REACHED", err.Error(), http.StatusForbidden,
+			map[string]interface{}{"scope": scope, "limit": limit},
+			nil)
+		return
+	}
+
+	log.Info("Player tracked", "steam_id", steamID, "created", created, "owners", tracked.Owners)
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	writeJSONResponseWithStatus(w, tracked, status)
+}
+
+// UntrackPlayer removes the caller's registration for a player
+func (h *Handler) UntrackPlayer(w http.ResponseWriter, r *http.Request) {
+	steamID, ok := h.resolveTrackTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.tracked.Untrack(trackOwner(r), steamID) {
+		writeError(w, r, "NOT_TRACKED", "This player is not tracked by your client", http.StatusNotFound,
+			map[string]interface{}{"steam_id": steamID},
+			nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListTracked returns the caller's registrations with refresh metadata
+func (h *Handler) ListTracked(w http.ResponseWriter, r *http.Request) {
+	tracked := h.tracked.List(trackOwner(r))
+	streamJSONResponse(w, r, map[string]interface{}{
+		"tracked":       tracked,
+		"count":         len(tracked),
+		"total_tracked": h.tracked.Count(),
+		"limits": map[string]interface{}{
+			"per_key":         h.config.TrackMaxPerKey,
+			"total":           h.config.TrackMaxTotal,
+			"inactive_days":   h.config.TrackInactiveDays,
+			"refresh_minutes": h.config.TrackRefreshMinutes,
+		},
+	})
+}
+
+// resolveTrackTarget validates {steamid} and resolves vanity names so one
+// player is tracked under one key however it was addressed
+func (h *Handler) resolveTrackTarget(w http.ResponseWriter, r *http.Request) (string, bool) {
+	steamID, idType, invalidField, validationErr := playerIDFromRequest(r)
+	if validationErr != nil {
+		writeValidationError(w, r, validationErr.Message, invalidField)
+		return "", false
+	}
+	resolved, _, resolveErr := h.steamClient.ResolveSteamIDAs(r.Context(), steamID, idType)
+	if resolveErr != nil {
+		writeErrorResponse(w, resolveErr)
+		return "", false
+	}
+	return resolved, true
+}
+
+// startTrackRefresher refetches tracked players every TrackRefreshMinutes and
+// drops those inactive for TrackInactiveDays
+func (h *Handler) startTrackRefresher() {
+	if h.config.DemoMode {
+		return
+	}
+	interval := time.Duration(h.config.TrackRefreshMinutes) * time.Minute
+	stop := make(chan struct{})
+	ticker := time.NewTicker(trackTickInterval)
+	h.stopTracking = func() {
+		ticker.Stop()
+		close(stop)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				h.refreshTracked(interval)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+func (h *Handler) refreshTracked(interval time.Duration) {
+	if expired := h.tracked.ExpireInactive(); len(expired) > 0 {
+		log.Info("Untracked inactive players",
+			"count", len(expired),
+			"inactive_days", h.config.TrackInactiveDays)
+	}
+
+	due := h.tracked.Due(interval)
+	pace, reason := h.steamPace()
+	refreshed := 0
+	for _, steamID := range due {
+		if refreshed >= paceBatch(pace) {
+			break
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), SteamAPITimeout)
+		changed, err := h.refreshTrackedPlayer(ctx, steamID)
+		cancel()
+		h.tracked.MarkRefreshed(steamID, changed, err)
+		refreshed++
+		if err != nil {
+			log.Warn("Tracked player refresh failed", "steam_id", steamID, "error", err)
+		}
+		// Re-judged after every refetch so a batch stops as soon as Steam degrades
+		pace, reason = h.steamPace()
+	}
+
+	held := len(due) - refreshed
+	h.trackPace.observe(pace, reason, held)
+	if pace != paceNormal && held > 0 {
+		trackDeferred.Add(int64(held))
+	}
+}
+
+// paceBatch is how many players one tick may refetch at pace
+func paceBatch(pace string) int {
+	switch pace {
+	case pacePaused:
+		return 0
+	case paceSlowed:
+		return trackSlowedBatch
+	}
+	return trackRefreshBatch
+}
+
+// steamPace judges how hard background work may lean on Steam right now and
+// why: paused while the circuit breaker is open, the daily quota is used up
+// or every Steam host is down; slowed while the breaker is testing recovery,
+// the quota is past its soft limit or some hosts are down
+func (h *Handler) steamPace() (string, string) {
+	slowed := ""
+	if manager := h.cacheManager(); manager != nil {
+		if cb := manager.GetCircuitBreaker(); cb != nil {
+			switch cb.GetState() {
+			case cache.CircuitOpen:
+				return pacePaused, "circuit_open"
+			case cache.CircuitHalfOpen:
+				slowed = "circuit_half_open"
+			}
+		}
+	}
+
+	switch h.steamClient.Quota().Level() {
+	case steam.QuotaCritical:
+		return pacePaused, "quota_critical"
+	case steam.QuotaElevated:
+		if slowed == "" {
+			slowed = "quota_elevated"
+		}
+	}
+
+	hosts := h.steamClient.HostStatus()
+	healthy := 0
+	for _, host := range hosts {
+		if host.Healthy {
+			healthy++
+		}
+	}
+	switch {
+	case healthy == 0:
+		return pacePaused, "steam_down"
+	case healthy < len(hosts) && slowed == "":
+		slowed = "hosts_degraded"
+	}
+
+	if slowed != "" {
+		return paceSlowed, slowed
+	}
+	return paceNormal, ""
+}
+
+// observe records the pace a tick ended at and how many due players it left,
+// logging when the pace changes
+func (p *trackPacer) observe(pace, reason string, backlog int) {
+	p.mu.Lock()
+	previous, previousReason := p.pace, p.reason
+	if pace != previous {
+		p.since = time.Now().UTC()
+	}
+	p.pace, p.reason, p.backlog = pace, reason, backlog
+	p.mu.Unlock()
+
+	trackBacklog.Set(int64(backlog))
+	switch pace {
+	case pacePaused:
+		trackPaceLevel.Set(2)
+	case paceSlowed:
+		trackPaceLevel.Set(1)
+	default:
+		trackPaceLevel.Set(0)
+	}
+
+	switch {
+	case pace == previous && reason == previousReason:
+	case pace == paceNormal && previous != "":
+		log.Info("Tracked player refreshes resumed; catching up stalest first",
+			"previous_pace", previous,
+			"backlog", backlog)
+	case pace != paceNormal:
+		log.Warn("Tracked player refreshes backing off while Steam is degraded",
+			"pace", pace,
+			"reason", reason,
+			"backlog", backlog)
+	}
+}
+
+// Status reports the refresher's pace for the status endpoint
+func (p *trackPacer) Status() map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pace == "" {
+		return map[string]interface{}{"pace": paceNormal, "backlog": 0}
+	}
+	status := map[string]interface{}{
+		"pace":    p.pace,
+		"since":   p.since,
+		"backlog": p.backlog,
+	}
+	if p.reason != "" {
+		status["reason"] = p.reason
+	}
+	return status
+}
+
+// refreshTrackedPlayer refetches stats through the usual cached fetchers and
+// reports whether they changed since the last snapshot
+func (h *Handler) refreshTrackedPlayer(ctx context.Context, steamID string) (bool, error) {
+	stats, _, err := h.fetchPlayerStatsWithSource(ctx, steamID)
+	if err != nil {
+		return false, err
+	}
+	// Grades come from structured stats; a snapshot without them would later
+	// read as a grade change, so skip the comparison instead
+	structured, _, err := h.fetchPlayerStructuredStatsWithSource(ctx, steamID)
+	if err != nil {
+		return false, err
+	}
+
+	h.players.Record(steamID, stats.DisplayName, stats.Avatar)
+	return h.publishStatsChanged(steamID, models.PlayerStatsWithAchievements{
+		PlayerStats: stats,
+		Stats:       structured,
+	}), nil
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// localizeStats returns a copy of structured stats with display names translated
+// and Formatted values rendered for the requested locale.
+// The input may be shared with the cache and is never modified.
+func localizeStats(data *models.StatsData, lang string, format steam.StatFormatter) *models.StatsData {
+	if data == nil || (lang == steam.DefaultLanguage && format.Locale() == steam.DefaultLocale) {
+		return data
+	}
+
+	localized := &models.StatsData{
+		Stats:   make([]interface{}, len(data.Stats)),
+		Summary: data.Summary,
+	}
+	for i, raw := range data.Stats {
+		switch stat := raw.(type) {
+		case steam.Stat:
+			stat.DisplayName = steam.LocalizedDisplayName(lang, stat.ID, stat.DisplayName)
+			stat.Formatted = steam.FormatStatValue(format, stat.Value, stat.ValueType, stat.ID)
+			localized.Stats[i] = stat
+		case map[string]interface{}:
+			id, _ := stat["id"].(string)
+			name, _ := stat["display_name"].(string)
+			copied := make(map[string]interface{}, len(stat))
+			for k, v := range stat {
+				copied[k] = v
+			}
+			copied["display_name"] = steam.LocalizedDisplayName(lang, id, name)
+			if value, ok := stat["value"].(float64); ok {
+				valueType, _ := stat["value_type"].(string)
+				copied["formatted"] = steam.FormatStatValue(format, value, valueType, id)
+			}
+			localized.Stats[i] = copied
+		default:
+			localized.Stats[i] = raw
+		}
+	}
+	return localized
+}
+
+// GetTranslationCoverage reports which alias display names each bundled language is missing
+func (h *Handler) GetTranslationCoverage(w http.ResponseWriter, r *http.Request) {
+	streamJSONResponse(w, r, map[string]interface{}{
+		"default_language": steam.DefaultLanguage,
+		"languages":        steam.Languages(),
+		"default_locale":   steam.DefaultLocale,
+		"locales":          steam.Locales(),
+		"coverage":         steam.TranslationCoverageReport(),
+	})
+}
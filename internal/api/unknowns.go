@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// GetSteamUnknowns lists fields Steam has sent that our response types don't
+// model, as early warning of API shape changes, and Adept achievements no
+// registry classifies yet
+func (h *Handler) GetSteamUnknowns(w http.ResponseWriter, r *http.Request) {
+	fields, dropped := steam.UnknownFields()
+	streamJSONResponse(w, r, map[string]interface{}{
+		"telemetry_enabled": steam.ShapeTelemetryEnabled(),
+		"count":             len(fields),
+		"not_recorded":      dropped,
+		"fields":            fields,
+		"adepts": map[string]interface{}{
+			"strict":       steam.StrictAdepts(),
+			"achievements": steam.UnknownAdepts(),
+		},
+	})
+}
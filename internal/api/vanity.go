@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// vanityConfirmParam names the account a client accepts a changed vanity for
+const vanityConfirmParam = "vanity_confirm"
+
+// checkVanityBinding compares a vanity resolution with the account the vanity
+// was last bound to. A vanity that changed hands answers 300 listing both
+// accounts, and keeps doing so until the client repeats the request with
+// ?vanity_confirm=<new SteamID64>, which moves the binding. It returns false
+// when it has written the response.
+func (h *Handler) checkVanityBinding(w http.ResponseWriter, r *http.Request, vanity, resolvedSteamID string, resolvedAs steam.IDType) bool {
+	if resolvedAs != steam.IDTypeVanity {
+		return true
+	}
+	previous, bound := h.players.VanityOwner(vanity)
+	if !bound || previous.SteamID == resolvedSteamID {
+		return true
+	}
+
+	if r.URL.Query().Get(vanityConfirmParam) == resolvedSteamID {
+		h.players.BindVanity(vanity, resolvedSteamID)
+		log.Info("Vanity rebound on confirmation",
+			"vanity", vanity,
+			"previous_steam_id", previous.SteamID,
+			"steam_id", resolvedSteamID)
+		return true
+	}
+
+	log.Warn("Vanity resolves to a different account than before",
+		"vanity", vanity,
+		"previous_steam_id", previous.SteamID,
+		"steam_id", resolvedSteamID,
+		"path", r.URL.Path)
+
+	lastSeen := previous.LastSeen
+	choices := []models.VanityChoice{{
+		Relation:    "previously_known",
+		SteamID:     previous.SteamID,
+		DisplayName: previous.PersonaName,
+		LastSeen:    &lastSeen,
+		Href:        vanityChoiceHref(r, previous.SteamID),
+	}}
+	current := models.VanityChoice{
+		Relation:    "newly_resolved",
+		SteamID:     resolvedSteamID,
+		Href:        vanityChoiceHref(r, resolvedSteamID),
+		ConfirmHref: vanityConfirmHref(r, resolvedSteamID),
+	}
+	if known, ok := h.players.Get(resolvedSteamID); ok {
+		current.DisplayName = known.PersonaName
+		seen := known.LastSeen
+		current.LastSeen = &seen
+	}
+	choices = append(choices, current)
+
+	w.Header().Set("Location", current.Href)
+	writeJSONResponseWithStatus(w, models.VanityDisambiguation{
+		Vanity:  vanity,
+		Message: "This vanity name now belongs to a different Steam account than it did when last served",
+		Choices: choices,
+	}, http.StatusMultipleChoices)
+	return false
+}
+
+// vanityChoiceHref is the request's URL addressing steamID instead of the
+// vanity name (or profile URL) it was made with
+func vanityChoiceHref(r *http.Request, steamID string) string {
+	path := strings.Replace(r.URL.EscapedPath(), "/"+mux.Vars(r)["steamid"], "/"+steamID, 1)
+	query := r.URL.Query()
+	query.Del(vanityConfirmParam)
+	query.Del("id_type")
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+// vanityConfirmHref is the request's URL with ?vanity_confirm=steamID
+func vanityConfirmHref(r *http.Request, steamID string) string {
+	u := *r.URL
+	query := u.Query()
+	query.Set(vanityConfirmParam, steamID)
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
+
+// bindRequestVanity records the binding once a vanity request has been served
+func (h *Handler) bindRequestVanity(r *http.Request, steamID string, resolvedAs steam.IDType) {
+	if resolvedAs != steam.IDTypeVanity {
+		return
+	}
+	if vanity, _, _, err := playerIDFromRequest(r); err == nil {
+		h.players.BindVanity(vanity, steamID)
+	}
+}
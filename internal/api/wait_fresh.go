@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+	"github.com/rgonzalez12/dbd-analytics/internal/steam"
+)
+
+// waitForFreshMinAge is how recent a cached response must be for a long poll
+// to answer with it at once rather than refresh
+const waitForFreshMinAge = 30 * time.Second
+
+var (
+	waitForFreshRefreshed = metrics.NewCounter("dbd_wait_for_fresh_refreshed",
+		"Long-poll player requests answered with data refreshed while they waited.")
+	waitForFreshStale = metrics.NewCounter("dbd_wait_for_fresh_stale",
+		"Long-poll player requests answered with stale data because the refresh failed or outlasted the wait.")
+)
+
+// refreshOutcome is how a combined refresh ended
+type refreshOutcome struct {
+	response models.PlayerStatsWithAchievements
+	warnings []string
+	ok       bool
+}
+
+// waitForFreshBudget is how long a player request asked to wait, so its route
+// deadline can be extended by as much
+func (h *Handler) waitForFreshBudget(r *http.Request) time.Duration {
+	opts, err := playerOptionsFromRequest(r, h.config.MaxWaitForFresh)
+	if err != nil {
+		return 0
+	}
+	return opts.WaitForFresh
+}
+
+// serveWaitForFresh answers ?wait_for_fresh. A recent cached response is
+// served at once; otherwise a refresh starts and the request holds until it
+// lands or the wait runs out, then gets the refreshed data or the older copy
+// marked stale. It reports false, leaving the request to the regular path,
+// when there is no older copy to fall back on and the refresh failed or can't
+// run.
+func (h *Handler) serveWaitForFresh(w http.ResponseWriter, r *http.Request, steamID string, resolvedAs steam.IDType, opts playerOptions, requestLogger *slog.Logger) bool {
+	start := time.Now()
+	current, age, have := h.latestCombined(steamID)
+	if have && age < waitForFreshMinAge {
+		w.Header().Set("X-Data-Freshness", "fresh")
+		h.writeCombinedResponse(w, r, steamID, resolvedAs, opts.Lang, opts.Format, current, nil)
+		return true
+	}
+
+	serveStale := func(reason string) {
+		waitForFreshStale.Add(1)
+		requestLogger.Info("Long poll answered with stale data",
+			"reason", reason,
+			"age", age.Round(time.Second),
+			"waited", time.Since(start))
+		markStale(&current, age)
+		w.Header().Set("X-Data-Freshness", "stale")
+		h.writeCombinedResponse(w, r, steamID, resolvedAs, opts.Lang, opts.Format, current, nil)
+	}
+
+	// Refreshes count as cache bypasses against the daily Steam budget
+	if !h.steamClient.Quota().AllowFresh() {
+		if !have {
+			return false
+		}
+		serveStale("quota_pressure")
+		return true
+	}
+
+	refreshed := h.refreshCombined(steamID)
+	budget := time.NewTimer(opts.WaitForFresh)
+	defer budget.Stop()
+	budgetC := budget.C
+	for {
+		select {
+		case outcome := <-refreshed:
+			if outcome.ok {
+				waitForFreshRefreshed.Add(1)
+				requestLogger.Info("Long poll answered with refreshed data", "waited", time.Since(start))
+				w.Header().Set("X-Data-Freshness", "fresh")
+				h.writeCombinedResponse(w, r, steamID, resolvedAs, opts.Lang, opts.Format, outcome.response, outcome.warnings)
+				return true
+			}
+			if !have {
+				return false // the regular path fetches again and reports the failure
+			}
+			serveStale("refresh_failed")
+			return true
+		case <-budgetC:
+			if have {
+				serveStale("wait_exceeded")
+				return true
+			}
+			budgetC = nil // nothing older to offer, so keep waiting out the route deadline
+		case <-r.Context().Done():
+			writeTimeoutError(w, r, "player_stats_with_achievements")
+			return true
+		}
+	}
+}
+
+// latestCombined returns the newest combined response held for steamID, from
+// the cache or else a persisted snapshot, with its age
+func (h *Handler) latestCombined(steamID string) (models.PlayerStatsWithAchievements, time.Duration, bool) {
+	if response, found := cache.Get(h.sharedCache(), playerCombinedCache.Key(steamID)); found {
+		return response, time.Since(response.DataSources.Stats.FetchedAt), true
+	}
+	if response, storedAt, ok := h.loadSnapshot(steamID); ok {
+		return response, time.Since(storedAt), true
+	}
+	return models.PlayerStatsWithAchievements{}, 0, false
+}
+
+// refreshCombined assembles and caches a live combined response for steamID
+// in the background and delivers the outcome on the returned channel. When an
+// identical request is already assembling one, that outcome is shared instead.
+func (h *Handler) refreshCombined(steamID string) <-chan refreshOutcome {
+	outcome := make(chan refreshOutcome, 1)
+
+	flight, leader := h.combined.join(steamID)
+	if !leader {
+		go func() {
+			<-flight.done
+			outcome <- refreshOutcome{response: flight.response, warnings: flight.warnings, ok: flight.ok}
+		}()
+		return outcome
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.config.OverallTimeout)
+
+	// The per-source layers would otherwise hand the assembly the same old data
+	h.evictPlayerSources(ctx, steamID)
+	go func() {
+		defer cancel()
+
+		response, warnings, err := h.assembleCombined(ctx, steamID, steamID, time.Now(), log.PlayerContext(steamID))
+		h.combined.finish(steamID, flight, response, warnings, err == nil)
+		if err != nil {
+			log.Warn("Long-poll refresh failed", "steam_id", steamID, "error", err)
+			outcome <- refreshOutcome{}
+			return
+		}
+		h.publishStatsChanged(steamID, response)
+		if h.tracked.Has(steamID) {
+			h.persistSnapshot(steamID, response)
+		}
+		outcome <- refreshOutcome{response: response, warnings: warnings, ok: true}
+	}()
+	return outcome
+}
@@ -0,0 +1,79 @@
+// Package buildinfo exposes version metadata injected at link time:
+//
+//	go build -ldflags "-X github.com/rgonzalez12/dbd-analytics/internal/buildinfo.Version=1.2.0 \
+//	  -X github.com/rgonzalez12/dbd-analytics/internal/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/rgonzalez12/dbd-analytics/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/app
+//
+// A plain go build inside the git checkout still identifies itself: the commit
+// and its time come from the VCS stamp the Go toolchain embeds.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Set via -ldflags -X; defaults identify local development builds
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+var startTime = time.Now()
+
+// modified reports uncommitted changes in a VCS-stamped build
+var modified bool
+
+func init() {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if Commit == "unknown" && len(setting.Value) >= 7 {
+				Commit = setting.Value[:7]
+			}
+		case "vcs.time":
+			// The commit time; ldflags builds report when the binary was linked
+			if BuildTime == "unknown" {
+				BuildTime = setting.Value
+			}
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+}
+
+// Info is the build metadata reported by status endpoints
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	Modified  bool   `json:"modified,omitempty"` // built from a dirty working tree
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build metadata for this binary
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		Modified:  modified,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// StartTime reports when the process started
+func StartTime() time.Time {
+	return startTime
+}
+
+// Uptime reports how long the process has been running
+func Uptime() time.Duration {
+	return time.Since(startTime)
+}
@@ -0,0 +1,286 @@
+package cache
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	internalLog "github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// AdaptiveTTLConfig bounds how far the controller may stretch or shrink configured TTLs
+type AdaptiveTTLConfig struct {
+	Enabled              bool          `json:"enabled"`
+	MinFactor            float64       `json:"min_factor"`             // lowest multiplier applied to a base TTL
+	MaxFactor            float64       `json:"max_factor"`             // highest multiplier applied to a base TTL
+	Step                 float64       `json:"step"`                   // relative change per adjustment
+	TargetHitRate        float64       `json:"target_hit_rate"`        // below this, TTLs are lengthened
+	UpstreamErrorBackoff float64       `json:"upstream_error_backoff"` // upstream error rate that triggers backoff
+	MinSamples           int64         `json:"min_samples"`            // lookups needed before a prefix is tuned
+	Interval             time.Duration `json:"interval"`
+}
+
+// GetAdaptiveTTLConfigFromEnv loads adaptive TTL settings; the controller is off unless CACHE_ADAPTIVE_TTL_ENABLED=true
+func GetAdaptiveTTLConfigFromEnv() AdaptiveTTLConfig {
+	config := AdaptiveTTLConfig{
+		Enabled:              os.Getenv("CACHE_ADAPTIVE_TTL_ENABLED") == "true",
+		MinFactor:            getEnvFloat("CACHE_ADAPTIVE_TTL_MIN_FACTOR", 0.5),
+		MaxFactor:            getEnvFloat("CACHE_ADAPTIVE_TTL_MAX_FACTOR", 4.0),
+		Step:                 0.25,
+		TargetHitRate:        getEnvFloat("CACHE_ADAPTIVE_TTL_TARGET_HIT_RATE", 0.6),
+		UpstreamErrorBackoff: 0.2,
+		MinSamples:           20,
+		Interval:             getEnvDuration("CACHE_ADAPTIVE_TTL_INTERVAL", time.Minute),
+	}
+
+	if config.MinFactor <= 0 || config.MinFactor > 1 {
+		config.MinFactor = 0.5
+	}
+	if config.MaxFactor < 1 {
+		config.MaxFactor = 4.0
+	}
+	if config.Interval < time.Second {
+		config.Interval = time.Minute
+	}
+
+	return config
+}
+
+// getEnvFloat parses a float from environment variable with fallback
+func getEnvFloat(envKey string, fallback float64) float64 {
+	if value := os.Getenv(envKey); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+		internalLog.Warn("Invalid float in environment variable",
+			"env_key", envKey,
+			"value", value,
+			"fallback", fallback)
+	}
+	return fallback
+}
+
+// prefixWindow accumulates lookups for one key prefix between adjustments
+type prefixWindow struct {
+	hits        int64
+	misses      int64
+	factor      float64
+	lastHitRate float64
+	lastReason  string
+	adjustments int64
+	lastChanged time.Time
+}
+
+// AdaptiveTTL scales per-prefix TTLs from observed hit rates and upstream health.
+// Low hit rates and upstream trouble lengthen TTLs (fewer refreshes); healthy,
+// well-hit prefixes drift back toward, and below, their configured TTL for freshness.
+type AdaptiveTTL struct {
+	mu               sync.Mutex
+	config           AdaptiveTTLConfig
+	prefixes         map[string]*prefixWindow
+	upstreamOK       int64
+	upstreamFailed   int64
+	lastUpstreamRate float64
+	circuitBreaker   *CircuitBreaker
+	stopCh           chan struct{}
+}
+
+// NewAdaptiveTTL starts the adjustment loop; circuitBreaker may be nil
+func NewAdaptiveTTL(config AdaptiveTTLConfig, circuitBreaker *CircuitBreaker) *AdaptiveTTL {
+	a := &AdaptiveTTL{
+		config:         config,
+		prefixes:       make(map[string]*prefixWindow),
+		circuitBreaker: circuitBreaker,
+		stopCh:         make(chan struct{}),
+	}
+
+	internalLog.Info("Adaptive TTL controller enabled",
+		"min_factor", config.MinFactor,
+		"max_factor", config.MaxFactor,
+		"target_hit_rate", config.TargetHitRate,
+		"interval", config.Interval)
+
+	go a.loop()
+	return a
+}
+
+func keyPrefix(key string) string {
+	prefix, _, _ := strings.Cut(key, ":")
+	return prefix
+}
+
+func (a *AdaptiveTTL) window(prefix string) *prefixWindow {
+	w, ok := a.prefixes[prefix]
+	if !ok {
+		w = &prefixWindow{factor: 1.0}
+		a.prefixes[prefix] = w
+	}
+	return w
+}
+
+// Observe records a cache lookup outcome for the key's prefix
+func (a *AdaptiveTTL) Observe(key string, hit bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	w := a.window(keyPrefix(key))
+	if hit {
+		w.hits++
+	} else {
+		w.misses++
+	}
+}
+
+// RecordUpstream records the outcome of an upstream (Steam) fetch
+func (a *AdaptiveTTL) RecordUpstream(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err != nil {
+		a.upstreamFailed++
+	} else {
+		a.upstreamOK++
+	}
+}
+
+// TTL returns the tuned TTL for a prefix
+func (a *AdaptiveTTL) TTL(prefix string, base time.Duration) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	w, ok := a.prefixes[prefix]
+	if !ok {
+		return base
+	}
+	return time.Duration(float64(base) * w.factor)
+}
+
+func (a *AdaptiveTTL) loop() {
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.adjust()
+		case <-a.stopCh:
+			return
+		}
+	}
+}
+
+func (a *AdaptiveTTL) adjust() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	upstreamTotal := a.upstreamOK + a.upstreamFailed
+	upstreamErrorRate := 0.0
+	if upstreamTotal > 0 {
+		upstreamErrorRate = float64(a.upstreamFailed) / float64(upstreamTotal)
+	}
+	a.lastUpstreamRate = upstreamErrorRate
+	a.upstreamOK, a.upstreamFailed = 0, 0
+
+	circuitOpen := a.circuitBreaker != nil && a.circuitBreaker.GetState() == CircuitOpen
+	upstreamTrouble := circuitOpen || (upstreamTotal > 0 && upstreamErrorRate >= a.config.UpstreamErrorBackoff)
+
+	for prefix, w := range a.prefixes {
+		samples := w.hits + w.misses
+		if samples < a.config.MinSamples && !upstreamTrouble {
+			continue
+		}
+
+		hitRate := 0.0
+		if samples > 0 {
+			hitRate = float64(w.hits) / float64(samples)
+		}
+		w.lastHitRate = hitRate
+		w.hits, w.misses = 0, 0
+
+		oldFactor := w.factor
+		reason := ""
+		switch {
+		case upstreamTrouble:
+			w.factor *= 1 + a.config.Step
+			reason = "upstream_errors"
+		case hitRate < a.config.TargetHitRate:
+			w.factor *= 1 + a.config.Step
+			reason = "low_hit_rate"
+		default:
+			w.factor /= 1 + a.config.Step
+			reason = "healthy_hit_rate"
+		}
+
+		if w.factor > a.config.MaxFactor {
+			w.factor = a.config.MaxFactor
+		}
+		if w.factor < a.config.MinFactor {
+			w.factor = a.config.MinFactor
+		}
+
+		if w.factor == oldFactor {
+			continue
+		}
+
+		w.adjustments++
+		w.lastReason = reason
+		w.lastChanged = time.Now()
+
+		internalLog.Info("Adaptive TTL adjusted",
+			"prefix", prefix,
+			"old_factor", oldFactor,
+			"new_factor", w.factor,
+			"reason", reason,
+			"hit_rate", hitRate,
+			"samples", samples,
+			"upstream_error_rate", upstreamErrorRate,
+			"circuit_open", circuitOpen)
+	}
+}
+
+// Status returns per-prefix factors and the inputs behind the last decisions
+func (a *AdaptiveTTL) Status() map[string]interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	prefixes := make(map[string]interface{}, len(a.prefixes))
+	for prefix, w := range a.prefixes {
+		prefixes[prefix] = map[string]interface{}{
+			"factor":        w.factor,
+			"last_hit_rate": w.lastHitRate,
+			"last_reason":   w.lastReason,
+			"adjustments":   w.adjustments,
+			"last_changed":  w.lastChanged,
+		}
+	}
+
+	return map[string]interface{}{
+		"enabled":                  true,
+		"config":                   a.config,
+		"last_upstream_error_rate": a.lastUpstreamRate,
+		"prefixes":                 prefixes,
+	}
+}
+
+// Close stops the adjustment loop
+func (a *AdaptiveTTL) Close() {
+	select {
+	case <-a.stopCh:
+	default:
+		close(a.stopCh)
+	}
+}
+
+// observedCache reports Get outcomes to the adaptive controller
+type observedCache struct {
+	Cache
+	adaptive *AdaptiveTTL
+}
+
+func (c *observedCache) Get(key string) (interface{}, bool) {
+	value, found := c.Cache.Get(key)
+	c.adaptive.Observe(key, found)
+	return value, found
+}
@@ -0,0 +1,317 @@
+package cache
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	internalLog "github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// Auto-tuning modes. In recommend mode the tuner only reports what it would
+// change; in apply mode it makes the change and records it in the audit log.
+const (
+	AutoTuneOff       = "off"
+	AutoTuneRecommend = "recommend"
+	AutoTuneApply     = "apply"
+)
+
+// Recommendation actions
+const (
+	ActionRaiseCapacity = "raise_capacity"
+	ActionExtendTTL     = "extend_ttl"
+)
+
+const (
+	autoTuneStep        = 0.25 // relative change per adjustment
+	autoTuneMinSamples  = 100  // lookups in a window before it is judged
+	autoTuneAuditSize   = 50
+	maxMemoryCacheItems = 100000 // NewMemoryCache's own cap
+)
+
+// AutoTuneConfig bounds what the tuner may change to hold the hit-rate SLO
+type AutoTuneConfig struct {
+	Mode              string        `json:"mode"`
+	HitRateSLO        float64       `json:"hit_rate_slo"`        // 0-1; windows below it are tuned
+	MemoryBudgetBytes int64         `json:"memory_budget_bytes"` // capacity is never raised past this
+	MaxEntries        int           `json:"max_entries"`
+	MaxTTLFactor      float64       `json:"max_ttl_factor"` // highest multiplier applied to every TTL
+	Interval          time.Duration `json:"interval"`
+}
+
+// GetAutoTuneConfigFromEnv loads the tuner settings; it only recommends unless CACHE_AUTOTUNE_MODE=apply
+func GetAutoTuneConfigFromEnv() AutoTuneConfig {
+	config := AutoTuneConfig{
+		Mode:              os.Getenv("CACHE_AUTOTUNE_MODE"),
+		HitRateSLO:        getEnvFloat("CACHE_HIT_RATE_SLO", 0.7),
+		MemoryBudgetBytes: int64(getEnvFloat("CACHE_AUTOTUNE_MEMORY_BUDGET_MB", 256) * 1024 * 1024),
+		MaxEntries:        maxMemoryCacheItems,
+		MaxTTLFactor:      getEnvFloat("CACHE_AUTOTUNE_MAX_TTL_FACTOR", 2.0),
+		Interval:          getEnvDuration("CACHE_AUTOTUNE_INTERVAL", 5*time.Minute),
+	}
+	if value := os.Getenv("CACHE_AUTOTUNE_MAX_ENTRIES"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 && parsed < maxMemoryCacheItems {
+			config.MaxEntries = parsed
+		}
+	}
+
+	switch config.Mode {
+	case AutoTuneOff, AutoTuneRecommend, AutoTuneApply:
+	case "":
+		config.Mode = AutoTuneRecommend
+	default:
+		internalLog.Warn("Invalid CACHE_AUTOTUNE_MODE, only recommending",
+			"value", config.Mode,
+			"allowed", []string{AutoTuneOff, AutoTuneRecommend, AutoTuneApply})
+		config.Mode = AutoTuneRecommend
+	}
+	if config.HitRateSLO <= 0 || config.HitRateSLO >= 1 {
+		config.HitRateSLO = 0.7
+	}
+	if config.MemoryBudgetBytes <= 0 {
+		config.MemoryBudgetBytes = 256 * 1024 * 1024
+	}
+	if config.MaxTTLFactor < 1 {
+		config.MaxTTLFactor = 2.0
+	}
+	if config.Interval < time.Second {
+		config.Interval = 5 * time.Minute
+	}
+	return config
+}
+
+// Recommendation is one change the tuner judged would help the hit rate
+type Recommendation struct {
+	Action  string  `json:"action"`
+	From    float64 `json:"from"`
+	To      float64 `json:"to"`
+	Reason  string  `json:"reason"`
+	Applied bool    `json:"applied"`
+	Blocked string  `json:"blocked,omitempty"` // why a needed change stayed within bounds
+}
+
+// TuningAdjustment is an audit log entry for a change the tuner applied
+type TuningAdjustment struct {
+	At      time.Time `json:"at"`
+	Action  string    `json:"action"`
+	From    float64   `json:"from"`
+	To      float64   `json:"to"`
+	Reason  string    `json:"reason"`
+	HitRate float64   `json:"hit_rate"`
+}
+
+// AutoTuner watches a memory cache's hit rate against the SLO. Misses driven
+// by LRU evictions call for more capacity, misses from expiry for longer TTLs;
+// both are bounded by the configured memory budget and TTL factor.
+type AutoTuner struct {
+	mu     sync.Mutex
+	config AutoTuneConfig
+	cache  *MemoryCache
+
+	last            CacheStats // counters at the previous evaluation
+	lastHitRate     float64
+	lastEvaluated   time.Time
+	ttlFactor       float64
+	recommendations []Recommendation
+	audit           []TuningAdjustment
+	stopCh          chan struct{}
+}
+
+// NewAutoTuner starts the evaluation loop for cache
+func NewAutoTuner(config AutoTuneConfig, cache *MemoryCache) *AutoTuner {
+	t := &AutoTuner{
+		config:    config,
+		cache:     cache,
+		last:      cache.Stats(),
+		ttlFactor: 1.0,
+		stopCh:    make(chan struct{}),
+	}
+
+	internalLog.Info("Cache auto-tuner started",
+		"mode", config.Mode,
+		"hit_rate_slo", config.HitRateSLO,
+		"memory_budget_bytes", config.MemoryBudgetBytes,
+		"max_ttl_factor", config.MaxTTLFactor,
+		"interval", config.Interval)
+
+	go t.loop()
+	return t
+}
+
+func (t *AutoTuner) loop() {
+	ticker := time.NewTicker(t.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.evaluate()
+		case <-t.stopCh:
+			return
+		}
+	}
+}
+
+// evaluate judges the window since the last call and, in apply mode, applies
+// what it recommends
+func (t *AutoTuner) evaluate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := t.cache.Stats()
+	hits := stats.Hits - t.last.Hits
+	misses := stats.Misses - t.last.Misses
+	lruEvictions := stats.LRUEvictions - t.last.LRUEvictions
+	expired := stats.ExpiredKeys - t.last.ExpiredKeys
+	t.last = stats
+	t.lastEvaluated = time.Now()
+
+	if hits+misses < autoTuneMinSamples {
+		return
+	}
+	hitRate := float64(hits) / float64(hits+misses)
+	t.lastHitRate = hitRate
+	t.recommendations = nil
+	if hitRate >= t.config.HitRateSLO {
+		return
+	}
+
+	var rec Recommendation
+	if lruEvictions > expired {
+		rec = t.capacityRecommendation(stats)
+	} else {
+		rec = t.ttlRecommendation()
+	}
+	if rec.Blocked == "" && t.config.Mode == AutoTuneApply {
+		t.applyLocked(&rec, hitRate)
+	}
+	t.recommendations = []Recommendation{rec}
+
+	internalLog.Info("Cache hit rate below SLO",
+		"hit_rate", hitRate,
+		"hit_rate_slo", t.config.HitRateSLO,
+		"lru_evictions", lruEvictions,
+		"expired_keys", expired,
+		"action", rec.Action,
+		"to", rec.To,
+		"applied", rec.Applied,
+		"blocked", rec.Blocked)
+}
+
+// capacityRecommendation raises max entries by a step, as far as the memory
+// budget allows at the current average entry size
+func (t *AutoTuner) capacityRecommendation(stats CacheStats) Recommendation {
+	current := t.cache.MaxEntries()
+	rec := Recommendation{
+		Action: ActionRaiseCapacity,
+		From:   float64(current),
+		Reason: "lru_evictions",
+	}
+
+	limit := t.config.MaxEntries
+	if stats.AverageKeySize > 0 {
+		if fit := int(t.config.MemoryBudgetBytes / stats.AverageKeySize); fit < limit {
+			limit = fit
+		}
+	}
+	target := int(float64(current) * (1 + autoTuneStep))
+	if target == current {
+		target++
+	}
+	if target > limit {
+		target = limit
+	}
+	if target <= current {
+		rec.To = rec.From
+		rec.Blocked = "memory_budget"
+		if limit == t.config.MaxEntries {
+			rec.Blocked = "max_entries"
+		}
+		return rec
+	}
+	rec.To = float64(target)
+	return rec
+}
+
+// ttlRecommendation lengthens every TTL by a step, up to the max factor
+func (t *AutoTuner) ttlRecommendation() Recommendation {
+	rec := Recommendation{
+		Action: ActionExtendTTL,
+		From:   t.ttlFactor,
+		Reason: "expirations",
+	}
+	target := t.ttlFactor * (1 + autoTuneStep)
+	if target > t.config.MaxTTLFactor {
+		target = t.config.MaxTTLFactor
+	}
+	if target <= t.ttlFactor {
+		rec.To = rec.From
+		rec.Blocked = "max_ttl_factor"
+		return rec
+	}
+	rec.To = target
+	return rec
+}
+
+func (t *AutoTuner) applyLocked(rec *Recommendation, hitRate float64) {
+	switch rec.Action {
+	case ActionRaiseCapacity:
+		t.cache.SetMaxEntries(int(rec.To))
+	case ActionExtendTTL:
+		t.ttlFactor = rec.To
+	}
+	rec.Applied = true
+
+	adjustment := TuningAdjustment{
+		At:      time.Now().UTC(),
+		Action:  rec.Action,
+		From:    rec.From,
+		To:      rec.To,
+		Reason:  rec.Reason,
+		HitRate: hitRate,
+	}
+	t.audit = append(t.audit, adjustment)
+	if over := len(t.audit) - autoTuneAuditSize; over > 0 {
+		t.audit = append(t.audit[:0:0], t.audit[over:]...)
+	}
+	internalLog.Info("Cache auto-tune adjustment applied",
+		"audit", true,
+		"action", adjustment.Action,
+		"from", adjustment.From,
+		"to", adjustment.To,
+		"reason", adjustment.Reason,
+		"hit_rate", hitRate)
+}
+
+// TTLFactor is the multiplier the tuner has applied to every TTL
+func (t *AutoTuner) TTLFactor() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.ttlFactor
+}
+
+// Status returns the SLO, the latest recommendations and the audit log
+func (t *AutoTuner) Status() map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return map[string]interface{}{
+		"config":          t.config,
+		"max_entries":     t.cache.MaxEntries(),
+		"ttl_factor":      t.ttlFactor,
+		"last_hit_rate":   t.lastHitRate,
+		"last_evaluated":  t.lastEvaluated,
+		"recommendations": append([]Recommendation{}, t.recommendations...),
+		"audit":           append([]TuningAdjustment{}, t.audit...),
+	}
+}
+
+// Close stops the evaluation loop
+func (t *AutoTuner) Close() {
+	select {
+	case <-t.stopCh:
+	default:
+		close(t.stopCh)
+	}
+}
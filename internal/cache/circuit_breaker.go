@@ -2,10 +2,12 @@ package cache
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/notify"
 )
 
 // CircuitState represents the current state of the circuit breaker
@@ -17,6 +19,10 @@ const (
 	CircuitHalfOpen                     // Testing if service recovered
 )
 
+// ErrCircuitOpen is returned, possibly wrapped, for calls refused while the
+// breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
 // CircuitBreakerConfig defines circuit breaker behavior
 type CircuitBreakerConfig struct {
 	MaxFailures            int           `json:"max_failures"`
@@ -83,6 +89,19 @@ func (cb *CircuitBreaker) Execute(fn func() (interface{}, error)) (interface{},
 }
 
 func (cb *CircuitBreaker) ExecuteWithStaleCache(key string, fn func() (interface{}, error)) (interface{}, error) {
+	result, _, err := cb.ExecuteWithStaleCacheInfo(key, fn)
+	return result, err
+}
+
+// StaleInfo says whether a result came from the fallback cache instead of fn
+type StaleInfo struct {
+	Served bool
+	Age    time.Duration // time since the stale entry was stored
+}
+
+// ExecuteWithStaleCacheInfo is ExecuteWithStaleCache that also reports when the
+// result is a stale fallback entry, so callers can tell users the data may be old
+func (cb *CircuitBreaker) ExecuteWithStaleCacheInfo(key string, fn func() (interface{}, error)) (interface{}, StaleInfo, error) {
 	result, err := cb.executeWithOptions(fn, false)
 	if err != nil {
 		log.Warn("Circuit breaker triggered for key",
@@ -92,18 +111,19 @@ func (cb *CircuitBreaker) ExecuteWithStaleCache(key string, fn func() (interface
 			"failure_count", cb.failures,
 			"last_failure", cb.lastFailureTime)
 
-		if staleData, exists := cb.getStaleData(key); exists {
+		if staleData, age, exists := cb.getStaleData(key); exists {
 			log.Info("Serving stale data from fallback cache",
 				"key", key,
+				"stale_age", age.Round(time.Second),
 				"circuit_state", cb.getStateString())
-			return staleData, nil
+			return staleData, StaleInfo{Served: true, Age: age}, nil
 		}
 
 		log.Warn("No stale data available for key",
 			"key", key,
 			"circuit_state", cb.getStateString())
 	}
-	return result, err
+	return result, StaleInfo{}, err
 }
 
 // executeWithOptions is the internal execution method
@@ -131,7 +151,7 @@ func (cb *CircuitBreaker) executeWithOptions(fn func() (interface{}, error), use
 			if useGenericFallback {
 				return cb.getFallbackData()
 			} else {
-				return nil, errors.New("circuit breaker open")
+				return nil, ErrCircuitOpen
 			}
 		}
 
@@ -236,6 +256,15 @@ func (cb *CircuitBreaker) openCircuit() {
 			"failure_rate", cb.getFailureRate(),
 			"failures", cb.failures,
 			"total_requests", len(cb.requestHistory))
+
+		notify.Publish(notify.EventCircuitOpen, notify.SeverityCritical,
+			"Steam API circuit breaker opened",
+			"Upstream failure rate exceeded the threshold; serving cached data where possible",
+			map[string]interface{}{
+				"failure_rate":  cb.getFailureRate(),
+				"failures":      cb.failures,
+				"reset_timeout": cb.config.ResetTimeout.String(),
+			})
 	}
 }
 
@@ -280,7 +309,7 @@ func (cb *CircuitBreaker) handleSuccess() {
 // getFallbackData returns cached fallback data
 func (cb *CircuitBreaker) getFallbackData() (interface{}, error) {
 	if cb.fallbackCache == nil {
-		return nil, errors.New("circuit breaker open and no fallback cache available")
+		return nil, fmt.Errorf("%w and no fallback cache available", ErrCircuitOpen)
 	}
 
 	// Simplified fallback response
@@ -291,10 +320,11 @@ func (cb *CircuitBreaker) getFallbackData() (interface{}, error) {
 	}, nil
 }
 
-// getStaleData attempts to retrieve stale data from fallback cache
-func (cb *CircuitBreaker) getStaleData(key string) (interface{}, bool) {
+// getStaleData attempts to retrieve stale data from fallback cache, along with
+// how long ago it was stored
+func (cb *CircuitBreaker) getStaleData(key string) (interface{}, time.Duration, bool) {
 	if cb.fallbackCache == nil {
-		return nil, false
+		return nil, 0, false
 	}
 
 	// Try to get data even if expired
@@ -304,12 +334,12 @@ func (cb *CircuitBreaker) getStaleData(key string) (interface{}, bool) {
 
 		if entry, exists := memCache.data[key]; exists {
 			// Return stale data regardless of expiration
-			entry.AccessedAt = time.Now() // Update access time
-			return entry.Value, true
+			entry.UpdateAccess()
+			return entry.Value, time.Since(entry.StoredAt), true
 		}
 	}
 
-	return nil, false
+	return nil, 0, false
 }
 
 // GetState returns the current circuit breaker state
@@ -0,0 +1,425 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CounterStore keeps sliding-window counters shared by every replica, so
+// limits enforced against them hold cluster-wide rather than per instance
+type CounterStore interface {
+	// Take adds n to key's count unless that would take the estimate over the
+	// last window past limit (0 means no limit). n 0 reads the count.
+	Take(ctx context.Context, key string, n, limit int64, window time.Duration) (WindowCount, error)
+	// Ping verifies the backend is reachable
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// WindowCount is a counter after Take
+type WindowCount struct {
+	Allowed bool
+	Count   int64     // sliding-window estimate, including n when allowed
+	Reset   time.Time // when the current fixed window ends
+}
+
+// CounterConfig selects the shared counter backend; an empty backend keeps
+// rate limits and the Steam quota per instance
+type CounterConfig struct {
+	Backend       string `json:"backend"` // "" | "redis"
+	RedisAddr     string `json:"redis_addr"`
+	RedisPassword string `json:"-"`
+	Prefix        string `json:"prefix"`
+}
+
+// GetCounterConfigFromEnv reads SHARED_COUNTERS_* variables. The Redis address
+// and password default to the cache invalidation ones, which usually name the
+// same server.
+func GetCounterConfigFromEnv() CounterConfig {
+	invalidation := GetInvalidationConfigFromEnv()
+	config := CounterConfig{
+		Backend:       strings.ToLower(strings.TrimSpace(os.Getenv("SHARED_COUNTERS_BACKEND"))),
+		RedisAddr:     os.Getenv("SHARED_COUNTERS_REDIS_ADDR"),
+		RedisPassword: os.Getenv("SHARED_COUNTERS_REDIS_PASSWORD"),
+		Prefix:        os.Getenv("SHARED_COUNTERS_PREFIX"),
+	}
+	if config.RedisAddr == "" {
+		config.RedisAddr = invalidation.RedisAddr
+		if config.RedisPassword == "" {
+			config.RedisPassword = invalidation.RedisPassword
+		}
+	}
+	if config.Prefix == "" {
+		config.Prefix = "dbd-analytics:counter:"
+	}
+	return config
+}
+
+// NewCounterStore opens the configured backend, or returns nil when counters
+// stay local
+func NewCounterStore(config CounterConfig) (CounterStore, error) {
+	switch config.Backend {
+	case "":
+		return nil, nil
+	case "redis":
+		return NewRedisCounterStore(config.RedisAddr, config.RedisPassword, config.Prefix, DefaultConfig().Redis), nil
+	}
+	return nil, fmt.Errorf("unknown shared counter backend %q; use redis", config.Backend)
+}
+
+// slidingWindowScript estimates a sliding window from two fixed windows: the
+// current count plus the previous one weighted by how much of it still
+// overlaps the window. It checks and increments atomically.
+//
+// KEYS: current window, previous window. ARGV: n, limit, previous weight in
+// thousandths, expiry in milliseconds.
+const slidingWindowScript = `
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local estimate = math.floor(previous * tonumber(ARGV[3]) / 1000) + current
+local limit = tonumber(ARGV[2])
+local n = tonumber(ARGV[1])
+if limit > 0 and estimate + math.max(n, 1) > limit then
+  return {0, estimate}
+end
+if n > 0 then
+  redis.call('INCRBY', KEYS[1], n)
+  redis.call('PEXPIRE', KEYS[1], ARGV[4])
+  estimate = estimate + n
+end
+return {1, estimate}
+`
+
+// RedisCounterStore keeps counters in Redis, speaking RESP directly like the
+// invalidation bus. Windows are aligned to each replica's clock, so replicas
+// should run NTP.
+//
+// addr may be a standalone server or any node of a Redis Cluster: MOVED and
+// ASK redirects are followed, and the slot owners they reveal are remembered
+// so later commands go straight to the right node. Both windows of a counter
+// share a hash tag, so the script never touches keys in two slots.
+//
+// Each node gets a small pool of connections. A command that finds its
+// node's pool exhausted waits only briefly before returning ErrCountersBusy,
+// so callers count locally rather than queue behind a slow Redis.
+type RedisCounterStore struct {
+	addr     string
+	password string
+	prefix   string
+	config   RedisConfig
+
+	mu     sync.Mutex
+	pools  map[string]*redisPool // connection pools by address
+	slots  map[int]string        // slot owners learned from MOVED replies
+	closed bool
+}
+
+type redisNode struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// redisPool holds one node's connections. open has a token per connection,
+// idle or checked out, so at most counterPoolSize are ever open.
+type redisPool struct {
+	idle chan *redisNode
+	open chan struct{}
+}
+
+const (
+	// maxRedirects bounds MOVED/ASK hops for one command, e.g. during resharding
+	maxRedirects = 5
+	// counterPoolSize caps the connections to each node
+	counterPoolSize = 8
+	// counterPoolWait is how long a command waits for a connection when the
+	// pool is exhausted, before the caller falls back to local counting
+	counterPoolWait = 20 * time.Millisecond
+)
+
+// ErrCountersBusy is returned when every pooled connection to a node is in
+// use; callers count locally for that call
+var ErrCountersBusy = errors.New("redis counters busy")
+
+// NewRedisCounterStore creates a store; connections are opened lazily
+func NewRedisCounterStore(addr, password, prefix string, config RedisConfig) *RedisCounterStore {
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+	if config.ReadTimeout <= 0 {
+		config.ReadTimeout = 3 * time.Second
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 3 * time.Second
+	}
+	return &RedisCounterStore{
+		addr:     addr,
+		password: password,
+		prefix:   prefix,
+		config:   config,
+		pools:    make(map[string]*redisPool),
+		slots:    make(map[int]string),
+	}
+}
+
+// Take implements CounterStore
+func (s *RedisCounterStore) Take(ctx context.Context, key string, n, limit int64, window time.Duration) (WindowCount, error) {
+	if window <= 0 {
+		return WindowCount{}, errors.New("counter window must be positive")
+	}
+	now := time.Now()
+	index := now.UnixNano() / int64(window)
+	windowStart := time.Unix(0, index*int64(window))
+	overlap := 1 - float64(now.Sub(windowStart))/float64(window)
+
+	tagged := s.prefix + HashTag(key) + ":"
+	current := tagged + strconv.FormatInt(index, 10)
+	previous := tagged + strconv.FormatInt(index-1, 10)
+	reply, err := s.do(ctx, KeySlot(current), "EVAL", slidingWindowScript, "2", current, previous,
+		strconv.FormatInt(n, 10),
+		strconv.FormatInt(limit, 10),
+		strconv.Itoa(int(overlap*1000)),
+		strconv.FormatInt((2*window).Milliseconds(), 10))
+	if err != nil {
+		return WindowCount{}, err
+	}
+	parts, ok := reply.([]interface{})
+	if !ok || len(parts) != 2 {
+		return WindowCount{}, fmt.Errorf("redis: unexpected counter reply %v", reply)
+	}
+	allowed, _ := parts[0].(int64)
+	count, _ := parts[1].(int64)
+	return WindowCount{Allowed: allowed == 1, Count: count, Reset: windowStart.Add(window)}, nil
+}
+
+// Ping implements CounterStore
+func (s *RedisCounterStore) Ping(ctx context.Context) error {
+	if _, err := s.do(ctx, -1, "PING"); err != nil {
+		return fmt.Errorf("redis ping: %w", err)
+	}
+	return nil
+}
+
+// Close drops every idle connection; those checked out are closed as they
+// are returned
+func (s *RedisCounterStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	var firstErr error
+	for addr, pool := range s.pools {
+		for drained := false; !drained; {
+			select {
+			case node := <-pool.idle:
+				if err := node.conn.Close(); err != nil && firstErr == nil {
+					firstErr = err
+				}
+			default:
+				drained = true
+			}
+		}
+		delete(s.pools, addr)
+	}
+	return firstErr
+}
+
+// do sends one command for slot (-1 for none) to the node owning it and
+// returns its reply, following cluster redirects and retrying once on a
+// fresh connection if a pooled one went stale. s.mu only guards the slot
+// map and pools; dials and round trips run outside it.
+func (s *RedisCounterStore) do(ctx context.Context, slot int, args ...string) (interface{}, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, errors.New("counter store closed")
+	}
+	addr := s.addr
+	if owner, ok := s.slots[slot]; ok {
+		addr = owner
+	}
+	s.mu.Unlock()
+
+	asking := false
+	reconnected := false
+	for hop := 0; hop <= maxRedirects; hop++ {
+		node, err := s.acquire(ctx, addr)
+		if err != nil && addr != s.addr && !errors.Is(err, ErrCountersBusy) && ctx.Err() == nil {
+			// A remembered owner may have failed over; ask the seed node again
+			s.setOwner(slot, "")
+			addr, asking = s.addr, false
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if asking {
+			if _, err := s.roundTrip(ctx, node, "ASKING"); err != nil {
+				s.release(addr, node, !isErrorReply(err) || ctx.Err() != nil)
+				return nil, err
+			}
+			asking = false
+		}
+		reply, err := s.roundTrip(ctx, node, args...)
+		// A cancelled ctx may have cut the connection's deadline short
+		s.release(addr, node, (err != nil && !isErrorReply(err)) || ctx.Err() != nil)
+		if err == nil {
+			return reply, nil
+		}
+
+		redirect, target, isRedirect := parseRedirect(err)
+		switch {
+		case isRedirect && redirect == "MOVED":
+			s.setOwner(slot, target)
+			addr = target
+		case isRedirect && redirect == "ASK":
+			addr, asking = target, true // migrating; the slot map stays as is
+		case isErrorReply(err):
+			return nil, err // an error reply; the connection is fine
+		case !reconnected && ctx.Err() == nil:
+			reconnected = true
+		default:
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("redis: more than %d cluster redirects", maxRedirects)
+}
+
+// isErrorReply reports an error Redis replied with, as opposed to a failed
+// connection
+func isErrorReply(err error) bool {
+	return strings.HasPrefix(err.Error(), "redis: ")
+}
+
+// parseRedirect reads "MOVED <slot> <addr>" and "ASK <slot> <addr>" replies
+func parseRedirect(err error) (string, string, bool) {
+	fields := strings.Fields(strings.TrimPrefix(err.Error(), "redis: "))
+	if len(fields) != 3 || (fields[0] != "MOVED" && fields[0] != "ASK") {
+		return "", "", false
+	}
+	return fields[0], fields[2], true
+}
+
+// setOwner remembers the node owning slot, or forgets it when addr is empty
+func (s *RedisCounterStore) setOwner(slot int, addr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if addr == "" {
+		delete(s.slots, slot)
+	} else {
+		s.slots[slot] = addr
+	}
+}
+
+func (s *RedisCounterStore) pool(addr string) (*redisPool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, errors.New("counter store closed")
+	}
+	pool, ok := s.pools[addr]
+	if !ok {
+		pool = &redisPool{
+			idle: make(chan *redisNode, counterPoolSize),
+			open: make(chan struct{}, counterPoolSize),
+		}
+		s.pools[addr] = pool
+	}
+	return pool, nil
+}
+
+// acquire takes an idle connection to addr or dials a new one while the pool
+// has room. When it has none it waits up to counterPoolWait, bounded by ctx,
+// for one to come back.
+func (s *RedisCounterStore) acquire(ctx context.Context, addr string) (*redisNode, error) {
+	pool, err := s.pool(addr)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case node := <-pool.idle:
+		return node, nil
+	case pool.open <- struct{}{}:
+		return s.dial(ctx, pool, addr)
+	default:
+	}
+
+	wait := time.NewTimer(counterPoolWait)
+	defer wait.Stop()
+	select {
+	case node := <-pool.idle:
+		return node, nil
+	case pool.open <- struct{}{}:
+		return s.dial(ctx, pool, addr)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-wait.C:
+		return nil, ErrCountersBusy
+	}
+}
+
+// dial opens a connection for a pool slot already reserved in pool.open,
+// giving the slot back if it fails
+func (s *RedisCounterStore) dial(ctx context.Context, pool *redisPool, addr string) (*redisNode, error) {
+	dialer := net.Dialer{Timeout: s.config.DialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		<-pool.open
+		return nil, fmt.Errorf("redis dial %s: %w", addr, err)
+	}
+	node := &redisNode{conn: conn, reader: bufio.NewReader(conn)}
+	if s.password != "" {
+		if _, err := s.roundTrip(ctx, node, "AUTH", s.password); err != nil {
+			conn.Close()
+			<-pool.open
+			return nil, fmt.Errorf("redis auth: %w", err)
+		}
+	}
+	return node, nil
+}
+
+// release returns node to addr's pool, or closes it when broken or the
+// store has closed
+func (s *RedisCounterStore) release(addr string, node *redisNode, broken bool) {
+	s.mu.Lock()
+	pool, ok := s.pools[addr]
+	closed := s.closed
+	s.mu.Unlock()
+	if !ok {
+		node.conn.Close() // the store closed and dropped the pool
+		return
+	}
+	if broken || closed {
+		node.conn.Close()
+		<-pool.open
+		return
+	}
+	pool.idle <- node // never blocks: idle holds at most one per open token
+}
+
+func (s *RedisCounterStore) roundTrip(ctx context.Context, node *redisNode, args ...string) (interface{}, error) {
+	// Cancelling ctx interrupts a blocked read or write; the connection is
+	// then discarded as broken
+	stop := context.AfterFunc(ctx, func() { node.conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	writeTimeout := s.config.WriteTimeout
+	if d, ok := ctx.Deadline(); ok {
+		writeTimeout = min(writeTimeout, time.Until(d))
+	}
+	if err := writeCommand(node.conn, writeTimeout, args...); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(s.config.ReadTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	node.conn.SetReadDeadline(deadline)
+	return readReply(node.reader)
+}
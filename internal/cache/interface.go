@@ -1,11 +1,16 @@
 package cache
 
-import "time"
+import (
+	"sync/atomic"
+	"time"
+)
 
-// Cache defines the interface for the cache implementation
+// Cache is the single cache contract used across the service; packages that
+// accept a cache take this type rather than ad-hoc interfaces
 type Cache interface {
 	Set(key string, value interface{}, ttl time.Duration) error
 	Get(key string) (interface{}, bool)
+	StoredAt(key string) (time.Time, bool) // write time of key's live entry
 	Delete(key string) error
 	Clear() error
 	EvictExpired() int
@@ -27,6 +32,7 @@ type CacheStats struct {
 	AverageKeySize   int64     `json:"average_key_size"`
 	CorruptionEvents int64     `json:"corruption_events"`
 	RecoveryEvents   int64     `json:"recovery_events"`
+	OrphansRemoved   int64     `json:"orphans_removed"` // entries under unregistered key prefixes
 	LastHitTime      time.Time `json:"last_hit_time"`
 	LastMissTime     time.Time `json:"last_miss_time"`
 	UptimeSeconds    int64     `json:"uptime_seconds"`
@@ -36,8 +42,13 @@ type CacheStats struct {
 type CacheEntry struct {
 	Value      interface{} `json:"value"`
 	ExpiresAt  time.Time   `json:"expires_at"`
-	AccessedAt time.Time   `json:"accessed_at"`
+	AccessedAt time.Time   `json:"accessed_at"` // when stored; LastAccess includes later reads
+	StoredAt   time.Time   `json:"stored_at"`
 	Size       int64       `json:"size"`
+
+	// lastRead is the latest read in Unix nanoseconds; it is atomic so reads
+	// can record themselves under a shared lock
+	lastRead atomic.Int64
 }
 
 // IsExpired checks if the cache entry has expired
@@ -45,7 +56,15 @@ func (e *CacheEntry) IsExpired() bool {
 	return time.Now().After(e.ExpiresAt)
 }
 
-// UpdateAccess updates the last accessed timestamp for tracking
+// UpdateAccess records a read for LRU tracking; safe under a read lock
 func (e *CacheEntry) UpdateAccess() {
-	e.AccessedAt = time.Now()
+	e.lastRead.Store(time.Now().UnixNano())
+}
+
+// LastAccess returns when the entry was last read, or stored if never read
+func (e *CacheEntry) LastAccess() time.Time {
+	if nanos := e.lastRead.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return e.AccessedAt
 }
@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// Invalidation is a cache eviction broadcast to every replica sharing a bus
+type Invalidation struct {
+	Origin string `json:"origin"` // instance that performed the eviction; replicas skip their own
+	Key    string `json:"key,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+	All    bool   `json:"all,omitempty"`
+}
+
+// InvalidationBus carries invalidations between instances with local caches
+type InvalidationBus interface {
+	Publish(ctx context.Context, inv Invalidation) error
+	// Subscribe delivers invalidations until ctx is cancelled, reconnecting as needed
+	Subscribe(ctx context.Context, handle func(Invalidation))
+	Close() error
+}
+
+// InvalidationConfig selects the invalidation bus; an empty backend keeps caches local
+type InvalidationConfig struct {
+	Backend       string `json:"backend"` // "" | "redis"
+	RedisAddr     string `json:"redis_addr"`
+	RedisPassword string `json:"-"`
+	Channel       string `json:"channel"`
+}
+
+// GetInvalidationConfigFromEnv reads CACHE_INVALIDATION_* variables
+func GetInvalidationConfigFromEnv() InvalidationConfig {
+	config := InvalidationConfig{
+		Backend:       strings.ToLower(strings.TrimSpace(os.Getenv("CACHE_INVALIDATION_BACKEND"))),
+		RedisAddr:     os.Getenv("CACHE_INVALIDATION_REDIS_ADDR"),
+		RedisPassword: os.Getenv("CACHE_INVALIDATION_REDIS_PASSWORD"),
+		Channel:       os.Getenv("CACHE_INVALIDATION_CHANNEL"),
+	}
+	if config.RedisAddr == "" {
+		config.RedisAddr = "localhost:6379"
+	}
+	if config.Channel == "" {
+		config.Channel = "dbd-analytics:cache-invalidation"
+	}
+	return config
+}
+
+// invalidationStats counts bus traffic for the cache status endpoint
+type invalidationStats struct {
+	published     atomic.Int64
+	publishErrors atomic.Int64
+	received      atomic.Int64
+	applied       atomic.Int64
+}
+
+// startInvalidation connects the manager to the configured bus
+func (m *Manager) startInvalidation(config InvalidationConfig) {
+	switch config.Backend {
+	case "":
+		return
+	case "redis":
+		m.bus = NewRedisInvalidationBus(config.RedisAddr, config.RedisPassword, config.Channel, m.config.Redis)
+	default:
+		log.Warn("Unknown cache invalidation backend, invalidations stay local", "backend", config.Backend)
+		return
+	}
+
+	m.instanceID = newInstanceID()
+	m.invalidationConfig = config
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.stopInvalidation = cancel
+	go m.bus.Subscribe(ctx, m.applyInvalidation)
+
+	log.Info("Cache invalidation broadcasting enabled",
+		"backend", config.Backend,
+		"channel", config.Channel,
+		"instance_id", m.instanceID)
+}
+
+// applyInvalidation evicts locally for an invalidation published by another replica
+func (m *Manager) applyInvalidation(inv Invalidation) {
+	m.invalidationStats.received.Add(1)
+	if inv.Origin == m.instanceID {
+		return
+	}
+	m.invalidationStats.applied.Add(1)
+
+	switch {
+	case inv.All:
+		_ = m.cache.Clear()
+	case inv.Prefix != "":
+		deletePrefix(m.cache, inv.Prefix)
+	case inv.Key != "":
+		_ = m.cache.Delete(inv.Key)
+	}
+	log.Debug("Applied remote cache invalidation",
+		"origin", inv.Origin,
+		"key", inv.Key,
+		"prefix", inv.Prefix,
+		"all", inv.All)
+}
+
+// publish broadcasts a local eviction; failures are logged, never returned, since
+// the local eviction already succeeded and TTLs bound staleness elsewhere
+func (m *Manager) publish(inv Invalidation) {
+	if m.bus == nil {
+		return
+	}
+	inv.Origin = m.instanceID
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := m.bus.Publish(ctx, inv); err != nil {
+		m.invalidationStats.publishErrors.Add(1)
+		log.Warn("Failed to broadcast cache invalidation",
+			"key", inv.Key,
+			"prefix", inv.Prefix,
+			"all", inv.All,
+			"error", err)
+		return
+	}
+	m.invalidationStats.published.Add(1)
+}
+
+// Invalidate deletes a key here and on every replica
+func (m *Manager) Invalidate(key string) error {
+	err := m.cache.Delete(key)
+	m.publish(Invalidation{Key: key})
+	return err
+}
+
+// InvalidatePrefix deletes every key in a namespace (e.g. "player_combined") here
+// and on every replica, returning the number of local entries removed
+func (m *Manager) InvalidatePrefix(prefix string) int {
+	removed := deletePrefix(m.cache, prefix)
+	m.publish(Invalidation{Prefix: prefix})
+	return removed
+}
+
+// InvalidationBackend names the cross-replica bus in use, or "" when caches stay local
+func (m *Manager) InvalidationBackend() string {
+	if m.bus == nil {
+		return ""
+	}
+	return m.invalidationConfig.Backend
+}
+
+// InvalidationStatus reports bus configuration and traffic counters
+func (m *Manager) InvalidationStatus() map[string]interface{} {
+	if m.bus == nil {
+		return map[string]interface{}{"enabled": false}
+	}
+	return map[string]interface{}{
+		"enabled":        true,
+		"backend":        m.invalidationConfig.Backend,
+		"channel":        m.invalidationConfig.Channel,
+		"instance_id":    m.instanceID,
+		"published":      m.invalidationStats.published.Load(),
+		"publish_errors": m.invalidationStats.publishErrors.Load(),
+		"received":       m.invalidationStats.received.Load(),
+		"applied":        m.invalidationStats.applied.Load(),
+	}
+}
+
+// deletePrefix removes keys equal to prefix or under "prefix:"
+func deletePrefix(c Cache, prefix string) int {
+	if mc, ok := c.(*MemoryCache); ok {
+		return mc.DeletePrefix(prefix)
+	}
+	return 0
+}
+
+func newInstanceID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	host, _ := os.Hostname()
+	if host == "" {
+		return hex.EncodeToString(buf)
+	}
+	return host + "-" + hex.EncodeToString(buf)
+}
+
+// broadcastCache propagates Delete and Clear to other replicas
+type broadcastCache struct {
+	Cache
+	manager *Manager
+}
+
+func (c *broadcastCache) Delete(key string) error {
+	err := c.Cache.Delete(key)
+	c.manager.publish(Invalidation{Key: key})
+	return err
+}
+
+func (c *broadcastCache) Clear() error {
+	err := c.Cache.Clear()
+	c.manager.publish(Invalidation{All: true})
+	return err
+}
@@ -1,5 +1,13 @@
 package cache
 
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
 // Cache key prefixes for different data types
 const (
 	// Player-specific cache keys
@@ -7,11 +15,156 @@ const (
 	PlayerSummaryPrefix      = "player_summary"
 	PlayerAchievementsPrefix = "player_achievements"
 	PlayerCombinedPrefix     = "player_combined"
+	PlayerInventoryPrefix    = "player_inventory"
+	StructuredStatsPrefix    = "structured_stats"
 
 	// Steam API cache keys
-	SteamAPIPrefix = "steam_api"
+	SteamAPIPrefix  = "steam_api"
+	UserStatsPrefix = "user_stats" // raw GetUserStatsForGame payloads
 
 	// Achievement system cache keys
 	AdeptMapPrefix          = "adept_map_v1"       // bump version if format changes
 	GlobalPercentagesPrefix = "global_percentages" // global achievement percentages
+	SchemaPrefix            = "schema_v1"          // GetSchemaForGame payload; bump version if format changes
+)
+
+// knownPrefixes are the namespaces this build writes. The cleanup worker drops
+// entries under anything else, which is what keys left behind by a renamed or
+// re-versioned prefix look like (adept_map_v0 after the bump to v1).
+var (
+	knownPrefixesMu sync.RWMutex
+	knownPrefixes   = map[string]bool{
+		PlayerStatsPrefix:        true,
+		PlayerSummaryPrefix:      true,
+		PlayerAchievementsPrefix: true,
+		PlayerCombinedPrefix:     true,
+		PlayerInventoryPrefix:    true,
+		StructuredStatsPrefix:    true,
+		SteamAPIPrefix:           true,
+		UserStatsPrefix:          true,
+		AdeptMapPrefix:           true,
+		GlobalPercentagesPrefix:  true,
+		SchemaPrefix:             true,
+	}
+)
+
+// playerPrefixes hold one player's data; a request's Policy applies to them
+// and not to game-wide entries such as the schema
+var playerPrefixes = map[string]bool{
+	PlayerStatsPrefix:        true,
+	PlayerSummaryPrefix:      true,
+	PlayerAchievementsPrefix: true,
+	PlayerCombinedPrefix:     true,
+	PlayerInventoryPrefix:    true,
+	StructuredStatsPrefix:    true,
+	UserStatsPrefix:          true,
+}
+
+// RegisterPrefix marks a namespace as valid so orphan sweeps keep its entries.
+// Anything writing keys under a prefix not declared above must register it.
+func RegisterPrefix(prefix string) {
+	knownPrefixesMu.Lock()
+	knownPrefixes[prefix] = true
+	knownPrefixesMu.Unlock()
+}
+
+// KnownPrefixes lists the registered namespaces
+func KnownPrefixes() []string {
+	knownPrefixesMu.RLock()
+	defer knownPrefixesMu.RUnlock()
+
+	prefixes := make([]string, 0, len(knownPrefixes))
+	for prefix := range knownPrefixes {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+func isKnownKey(key string) bool {
+	knownPrefixesMu.RLock()
+	defer knownPrefixesMu.RUnlock()
+	return knownPrefixes[keyPrefix(key)]
+}
+
+// Namespace binds a key prefix to the one type stored under it. Keys built
+// from it carry that type, so Get, Set and GetOrLoad can't read or write
+// anything else there.
+type Namespace[T any] struct {
+	prefix string
+}
+
+// Key is a cache key whose entry holds a T
+type Key[T any] string
+
+// namespaceTypes records the type each declared namespace stores
+var (
+	namespaceTypesMu sync.Mutex
+	namespaceTypes   = make(map[string]reflect.Type)
 )
+
+// NewNamespace declares that entries under prefix hold a T and registers the
+// prefix. Declaring a prefix twice with different types panics, so two
+// packages can't share a namespace for different values, as does a T with a
+// field that can never be serialized: cached types are plain data.
+func NewNamespace[T any](prefix string) Namespace[T] {
+	valueType := reflect.TypeFor[T]()
+	if bad := unencodablePath(valueType, "", map[reflect.Type]bool{}); bad != "" {
+		panic(fmt.Sprintf("cache: namespace %q holds %s, whose %s can't be serialized", prefix, valueType, bad))
+	}
+	namespaceTypesMu.Lock()
+	defer namespaceTypesMu.Unlock()
+	if declared, ok := namespaceTypes[prefix]; ok && declared != valueType {
+		panic(fmt.Sprintf("cache: namespace %q holds %s, not %s", prefix, declared, valueType))
+	}
+	namespaceTypes[prefix] = valueType
+	RegisterPrefix(prefix)
+	return Namespace[T]{prefix: prefix}
+}
+
+// Prefix returns the namespace's key prefix
+func (n Namespace[T]) Prefix() string {
+	return n.prefix
+}
+
+// Key returns the namespace's key for parts, as GenerateKey joins them
+func (n Namespace[T]) Key(parts ...string) Key[T] {
+	return Key[T](GenerateKey(n.prefix, parts...))
+}
+
+// HashTag wraps id in a Redis Cluster hash tag. Cluster slots a key by the
+// part inside its first {...}, so keys sharing a tag land on one slot and a
+// script may touch all of them.
+func HashTag(id string) string {
+	return "{" + id + "}"
+}
+
+// clusterSlots is the number of Redis Cluster hash slots
+const clusterSlots = 16384
+
+// KeySlot is the Redis Cluster slot of key: CRC16 of its hash tag when it
+// has a non-empty one, else of the whole key
+func KeySlot(key string) int {
+	if open := strings.IndexByte(key, '{'); open >= 0 {
+		if length := strings.IndexByte(key[open+1:], '}'); length > 0 {
+			key = key[open+1 : open+1+length]
+		}
+	}
+	return int(crc16(key) % clusterSlots)
+}
+
+// crc16 is CRC-16/XMODEM, the checksum Redis Cluster slots keys with
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
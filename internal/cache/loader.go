@@ -0,0 +1,197 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	internalLog "github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// SkipStore, returned by a GetOrLoad loader together with its value, hands
+// the value back without caching it: a stale fallback, or an empty upstream
+// answer that shouldn't be pinned for a whole TTL
+var SkipStore = errors.New("cache: value not stored")
+
+// ErrNotCached is what GetOrLoad returns on a miss under PolicyOnly
+var ErrNotCached = errors.New("cache: not cached")
+
+// Policy overrides how GetOrLoad treats per-player entries for one request,
+// for support and debugging; game-wide entries are always read through
+type Policy string
+
+const (
+	PolicyDefault Policy = ""
+	PolicyBypass  Policy = "bypass"  // skip the read; load and store, keeping the old entry if the load fails
+	PolicyRefresh Policy = "refresh" // drop the entry, then load and store
+	PolicyOnly    Policy = "only"    // read only; a miss is ErrNotCached and nothing is loaded
+)
+
+type policyKey struct{}
+
+// WithPolicy returns ctx carrying policy for the GetOrLoad calls made under it
+func WithPolicy(ctx context.Context, policy Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, policy)
+}
+
+// PolicyFromContext returns the policy ctx carries, PolicyDefault if none
+func PolicyFromContext(ctx context.Context) Policy {
+	policy, _ := ctx.Value(policyKey{}).(Policy)
+	return policy
+}
+
+// errLoadAborted is what callers sharing a load see when the loader panicked
+var errLoadAborted = errors.New("cache: shared load aborted")
+
+type loadCall struct {
+	done      chan struct{}
+	value     interface{}
+	err       error
+	cancelled bool // the loader's ctx ended, so err may not apply to other callers
+}
+
+// loads coalesces concurrent GetOrLoad misses by cache key. Keys alone are
+// enough: every Cache a Manager hands out wraps the same store.
+var loads = struct {
+	mu    sync.Mutex
+	calls map[string]*loadCall
+}{calls: make(map[string]*loadCall)}
+
+// Get returns the T cached under key. Typed keys keep other values out of a
+// namespace; an entry that isn't a T anyway, written through the untyped
+// Cache methods, is removed so the caller's reload replaces it.
+func Get[T any](c Cache, key Key[T]) (T, bool) {
+	var zero T
+	if c == nil {
+		return zero, false
+	}
+	cached, found := c.Get(string(key))
+	if !found {
+		return zero, false
+	}
+	value, ok := cached.(T)
+	if !ok {
+		internalLog.Warn("Invalid cache entry type, removing",
+			"cache_key", key,
+			"expected", reflect.TypeFor[T]().String(),
+			"actual", reflect.TypeOf(cached))
+		c.Delete(string(key))
+		return zero, false
+	}
+	return value, true
+}
+
+// Set caches value under key for ttl
+func Set[T any](c Cache, key Key[T], value T, ttl time.Duration) error {
+	return c.Set(string(key), value, ttl)
+}
+
+// StoredAt returns when the entry under key was written, for reporting how
+// old served data is
+func StoredAt[T any](c Cache, key Key[T]) (time.Time, bool) {
+	if c == nil {
+		return time.Time{}, false
+	}
+	return c.StoredAt(string(key))
+}
+
+// GetOrLoad returns the T cached under key, or runs load and caches what it
+// returns for ttl; hit reports which. Concurrent misses on a key share one
+// load. A caller whose ctx ends first stops waiting, and one handed the
+// loader's cancellation while its own ctx is live loads for itself. A nil
+// cache runs load directly. The Policy ctx carries changes this for
+// per-player keys; bypassing and refreshing loads are never shared. Go
+// methods can't take type parameters, so this is a function over
+// Manager.GetCache() rather than a Manager method.
+func GetOrLoad[T any](ctx context.Context, c Cache, key Key[T], ttl time.Duration, load func(context.Context) (T, error)) (value T, hit bool, err error) {
+	policy := PolicyDefault
+	if playerPrefixes[keyPrefix(string(key))] {
+		policy = PolicyFromContext(ctx)
+	}
+	if policy == PolicyOnly {
+		if value, ok := Get(c, key); ok {
+			return value, true, nil
+		}
+		return value, false, ErrNotCached
+	}
+
+	if c == nil {
+		value, err = load(ctx)
+		if errors.Is(err, SkipStore) {
+			err = nil
+		}
+		return value, false, err
+	}
+	switch policy {
+	case PolicyRefresh:
+		c.Delete(string(key))
+		fallthrough
+	case PolicyBypass:
+		value, err = load(ctx)
+		switch {
+		case errors.Is(err, SkipStore):
+			err = nil
+		case err == nil:
+			if setErr := Set(c, key, value, ttl); setErr != nil {
+				internalLog.Warn("Failed to cache loaded value", "cache_key", key, "error", setErr)
+			}
+		}
+		return value, false, err
+	}
+
+	if value, ok := Get(c, key); ok {
+		return value, true, nil
+	}
+
+	loads.mu.Lock()
+	call, joined := loads.calls[string(key)]
+	if !joined {
+		call = &loadCall{done: make(chan struct{}), err: errLoadAborted}
+		loads.calls[string(key)] = call
+	}
+	loads.mu.Unlock()
+
+	if joined {
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return value, false, ctx.Err()
+		}
+		if call.cancelled && ctx.Err() == nil {
+			return GetOrLoad(ctx, c, key, ttl, load)
+		}
+	} else {
+		runLoad(ctx, c, key, ttl, call, load)
+	}
+
+	if call.err != nil {
+		return value, false, call.err
+	}
+	value, _ = call.value.(T)
+	return value, false, nil
+}
+
+// runLoad runs load for call and stores its result before releasing waiters,
+// so a caller arriving after the release finds the value cached
+func runLoad[T any](ctx context.Context, c Cache, key Key[T], ttl time.Duration, call *loadCall, load func(context.Context) (T, error)) {
+	defer func() {
+		loads.mu.Lock()
+		delete(loads.calls, string(key))
+		loads.mu.Unlock()
+		close(call.done)
+	}()
+
+	value, err := load(ctx)
+	switch {
+	case errors.Is(err, SkipStore):
+		err = nil
+	case err == nil:
+		if setErr := Set(c, key, value, ttl); setErr != nil {
+			internalLog.Warn("Failed to cache loaded value", "cache_key", key, "error", setErr)
+		}
+	}
+	call.value, call.err = value, err
+	call.cancelled = err != nil && ctx.Err() != nil
+}
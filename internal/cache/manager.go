@@ -40,9 +40,10 @@ func DefaultConfig() Config {
 	return Config{
 		Type: MemoryCacheType,
 		Memory: MemoryCacheConfig{
-			MaxEntries:      1000,
-			DefaultTTL:      ttlConfig.DefaultTTL,
-			CleanupInterval: 30 * time.Second,
+			MaxEntries:          1000,
+			DefaultTTL:          ttlConfig.DefaultTTL,
+			CleanupInterval:     30 * time.Second,
+			ValidationBatchSize: defaultValidationBatchSize,
 		},
 		Redis: RedisConfig{
 			Host:         "localhost",
@@ -78,6 +79,7 @@ func DevelopmentConfig() Config {
 		PlayerSummary:      1 * time.Minute,
 		PlayerAchievements: 2 * time.Minute,
 		PlayerCombined:     1 * time.Minute,
+		PlayerInventory:    5 * time.Minute,
 		SteamAPI:           30 * time.Second,
 		DefaultTTL:         30 * time.Second,
 	}
@@ -89,11 +91,23 @@ type Manager struct {
 	config         Config
 	cache          Cache
 	circuitBreaker *CircuitBreaker
+	adaptive       *AdaptiveTTL // nil unless adaptive TTL tuning is enabled
+	tuner          *AutoTuner   // nil when auto-tuning is off or the cache isn't in memory
+	writes         *writePipeline
+	ttlScale       func() float64
+
+	// Cross-replica invalidation; bus is nil when caches stay local
+	bus                InvalidationBus
+	instanceID         string
+	invalidationConfig InvalidationConfig
+	invalidationStats  invalidationStats
+	stopInvalidation   func()
 }
 
 func NewManager(config Config) (*Manager, error) {
 	manager := &Manager{
 		config: config,
+		writes: newWritePipeline(),
 	}
 
 	cache, err := manager.createCache()
@@ -107,11 +121,61 @@ func NewManager(config Config) (*Manager, error) {
 	circuitConfig := DefaultCircuitBreakerConfig()
 	manager.circuitBreaker = NewCircuitBreaker(circuitConfig, cache)
 
+	if adaptiveConfig := GetAdaptiveTTLConfigFromEnv(); adaptiveConfig.Enabled {
+		manager.adaptive = NewAdaptiveTTL(adaptiveConfig, manager.circuitBreaker)
+	}
+
+	if memCache, ok := cache.(*MemoryCache); ok {
+		if tuneConfig := GetAutoTuneConfigFromEnv(); tuneConfig.Mode != AutoTuneOff {
+			manager.tuner = NewAutoTuner(tuneConfig, memCache)
+		}
+	}
+
+	manager.startInvalidation(GetInvalidationConfigFromEnv())
+
 	return manager, nil
 }
 
 func (m *Manager) GetCache() Cache {
-	return m.cache
+	var c Cache = &pipelinedCache{Cache: m.cache, writes: m.writes}
+	if m.adaptive != nil {
+		c = &observedCache{Cache: c, adaptive: m.adaptive}
+	}
+	if m.bus != nil {
+		c = &broadcastCache{Cache: c, manager: m}
+	}
+	return c
+}
+
+// TTLFor returns the TTL to use for a key prefix, tuned by the adaptive controller
+// when enabled, lengthened by the auto-tuner when it has applied a TTL bump and
+// stretched by the TTL scale (upstream quota pressure) when set
+func (m *Manager) TTLFor(prefix string, base time.Duration) time.Duration {
+	ttl := base
+	if m.adaptive != nil {
+		ttl = m.adaptive.TTL(prefix, base)
+	}
+	if m.tuner != nil {
+		ttl = time.Duration(float64(ttl) * m.tuner.TTLFactor())
+	}
+	if m.ttlScale != nil {
+		if scale := m.ttlScale(); scale > 1 {
+			ttl = time.Duration(float64(ttl) * scale)
+		}
+	}
+	return ttl
+}
+
+// SetTTLScale installs a multiplier applied to every TTL from TTLFor; values <= 1 are ignored
+func (m *Manager) SetTTLScale(scale func() float64) {
+	m.ttlScale = scale
+}
+
+// RecordUpstream feeds an upstream fetch outcome to the adaptive controller
+func (m *Manager) RecordUpstream(err error) {
+	if m.adaptive != nil {
+		m.adaptive.RecordUpstream(err)
+	}
 }
 
 // GetConfig returns the current cache configuration
@@ -143,13 +207,43 @@ func (m *Manager) GetCacheStatus() map[string]interface{} {
 	// Add cache-specific stats if available
 	if memCache, ok := m.cache.(*MemoryCache); ok {
 		status["cache_stats"] = memCache.GetStats()
+		status["draining"] = memCache.Draining()
 	}
 
+	if m.adaptive != nil {
+		status["adaptive_ttl"] = m.adaptive.Status()
+	}
+	if m.tuner != nil {
+		status["autotune"] = m.tuner.Status()
+	}
+
+	status["invalidation"] = m.InvalidationStatus()
+	status["write_errors"] = m.writes.Status()
+	status["known_prefixes"] = KnownPrefixes()
+
 	return status
 }
 
+// Drain stops cache writes ahead of Close so requests still in flight during
+// shutdown keep their cache reads without failing writes
+func (m *Manager) Drain() {
+	if memCache, ok := m.cache.(*MemoryCache); ok {
+		memCache.Drain()
+	}
+}
+
 // Close gracefully shuts down the cache
 func (m *Manager) Close() error {
+	if m.adaptive != nil {
+		m.adaptive.Close()
+	}
+	if m.tuner != nil {
+		m.tuner.Close()
+	}
+	if m.bus != nil {
+		m.stopInvalidation()
+		m.bus.Close()
+	}
 	if memCache, ok := m.cache.(*MemoryCache); ok {
 		memCache.Close()
 	}
@@ -191,6 +285,7 @@ type TTLConfig struct {
 	PlayerSummary      time.Duration `json:"player_summary_ttl"`
 	PlayerAchievements time.Duration `json:"player_achievements_ttl"`
 	PlayerCombined     time.Duration `json:"player_combined_ttl"`
+	PlayerInventory    time.Duration `json:"player_inventory_ttl"`
 	SteamAPI           time.Duration `json:"steam_api_ttl"`
 	DefaultTTL         time.Duration `json:"default_ttl"`
 }
@@ -204,6 +299,7 @@ func GetTTLFromEnv() TTLConfig {
 		PlayerSummary:      getEnvDuration("CACHE_PLAYER_SUMMARY_TTL", 10*time.Minute),
 		PlayerAchievements: getEnvDuration("CACHE_PLAYER_ACHIEVEMENTS_TTL", 2*time.Minute),
 		PlayerCombined:     getEnvDuration("CACHE_PLAYER_COMBINED_TTL", 10*time.Minute),
+		PlayerInventory:    getEnvDuration("CACHE_PLAYER_INVENTORY_TTL", 6*time.Hour),
 		SteamAPI:           getEnvDuration("CACHE_STEAM_API_TTL", 3*time.Minute),
 		DefaultTTL:         getEnvDuration("CACHE_DEFAULT_TTL", 3*time.Minute),
 	}
@@ -213,6 +309,7 @@ func GetTTLFromEnv() TTLConfig {
 		"player_summary_ttl", config.PlayerSummary,
 		"player_achievements_ttl", config.PlayerAchievements,
 		"player_combined_ttl", config.PlayerCombined,
+		"player_inventory_ttl", config.PlayerInventory,
 		"steam_api_ttl", config.SteamAPI,
 		"default_ttl", config.DefaultTTL,
 		"source_priority", "env_vars > hardcoded_defaults")
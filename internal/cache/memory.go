@@ -2,31 +2,42 @@ package cache
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+	"github.com/rgonzalez12/dbd-analytics/internal/notify"
 )
 
 type MemoryCache struct {
 	mu             sync.RWMutex
 	data           map[string]*CacheEntry
-	stats          CacheStats
+	stats          cacheCounters
 	maxEntries     int
 	defaultTTL     time.Duration
 	cleanupTicker  *time.Ticker
 	stopCleanup    chan struct{}
 	shutdownOnce   sync.Once
 	isShuttingDown bool
-	startTime      time.Time // Track cache initialization time for uptime
+	draining       atomic.Bool // set by Drain; writes are skipped until Close
+	startTime      time.Time   // Track cache initialization time for uptime
+
+	validationBatchSize int   // entries checked per lock slice during corruption detection
+	maxEntrySize        int64 // serialized bytes one entry may take
 }
 
 // MemoryCacheConfig holds configuration for in-memory cache
 type MemoryCacheConfig struct {
-	MaxEntries      int
-	DefaultTTL      time.Duration
-	CleanupInterval time.Duration
+	MaxEntries          int
+	DefaultTTL          time.Duration
+	CleanupInterval     time.Duration
+	ValidationBatchSize int   // entries per lock slice during corruption detection; 0 uses the default
+	MaxEntrySize        int64 // serialized bytes one entry may take; 0 uses the default
 }
 
 func NewMemoryCache(config MemoryCacheConfig) *MemoryCache {
@@ -34,9 +45,9 @@ func NewMemoryCache(config MemoryCacheConfig) *MemoryCache {
 		config.MaxEntries = 1000
 		log.Warn("Invalid MaxEntries, using default", "default", 1000)
 	}
-	if config.MaxEntries > 100000 {
-		config.MaxEntries = 100000
-		log.Warn("MaxEntries too large, capping at", "max", 100000)
+	if config.MaxEntries > maxMemoryCacheItems {
+		config.MaxEntries = maxMemoryCacheItems
+		log.Warn("MaxEntries too large, capping at", "max", maxMemoryCacheItems)
 	}
 	if config.DefaultTTL <= 0 {
 		config.DefaultTTL = 5 * time.Minute
@@ -55,13 +66,22 @@ func NewMemoryCache(config MemoryCacheConfig) *MemoryCache {
 		log.Warn("CleanupInterval too frequent, setting minimum", "min", config.CleanupInterval)
 	}
 
+	if config.ValidationBatchSize <= 0 {
+		config.ValidationBatchSize = defaultValidationBatchSize
+	}
+	if config.MaxEntrySize <= 0 {
+		config.MaxEntrySize = defaultMaxEntrySize
+	}
+
 	cache := &MemoryCache{
-		data:          make(map[string]*CacheEntry),
-		maxEntries:    config.MaxEntries,
-		defaultTTL:    config.DefaultTTL,
-		cleanupTicker: time.NewTicker(config.CleanupInterval),
-		stopCleanup:   make(chan struct{}),
-		startTime:     time.Now(),
+		data:                make(map[string]*CacheEntry),
+		maxEntries:          config.MaxEntries,
+		defaultTTL:          config.DefaultTTL,
+		cleanupTicker:       time.NewTicker(config.CleanupInterval),
+		stopCleanup:         make(chan struct{}),
+		startTime:           time.Now(),
+		validationBatchSize: config.ValidationBatchSize,
+		maxEntrySize:        config.MaxEntrySize,
 	}
 
 	go cache.cleanupWorker()
@@ -74,32 +94,46 @@ func NewMemoryCache(config MemoryCacheConfig) *MemoryCache {
 	return cache
 }
 
-// Set stores a value with TTL
+// Set stores a value with TTL. Failures are *WriteError so callers can tell a
+// closing cache from an oversized or unencodable value.
 func (mc *MemoryCache) Set(key string, value interface{}, ttl time.Duration) error {
 	if key == "" {
-		return fmt.Errorf("cache key cannot be empty")
+		return &WriteError{Kind: WriteErrInvalid, Key: key, Err: errors.New("cache key cannot be empty")}
 	}
 	if value == nil {
-		return fmt.Errorf("cache value cannot be nil")
+		return &WriteError{Kind: WriteErrInvalid, Key: key, Err: errors.New("cache value cannot be nil")}
 	}
 	if ttl <= 0 {
 		ttl = mc.defaultTTL
 	}
+	if mc.draining.Load() {
+		drainSkippedWrites.Add(1)
+		return nil
+	}
 
 	mc.mu.RLock()
 	if mc.isShuttingDown {
 		mc.mu.RUnlock()
-		return fmt.Errorf("cache is shutting down")
+		return &WriteError{Kind: WriteErrShutdown, Key: key, Err: errCacheShuttingDown}
 	}
 	mc.mu.RUnlock()
 
-	// Calculate size for memory tracking
-	size := mc.calculateSize(value)
+	// Calculate size for memory tracking; a value that cannot be encoded would
+	// only be dropped later by corruption detection, so it is refused up front
+	size, err := calculateSize(value)
+	if err != nil {
+		return &WriteError{Kind: WriteErrSerialization, Key: key, Err: err}
+	}
+	if size > mc.maxEntrySize {
+		return &WriteError{Kind: WriteErrSize, Key: key,
+			Err: fmt.Errorf("entry is %d bytes, limit is %d", size, mc.maxEntrySize)}
+	}
 
 	entry := &CacheEntry{
 		Value:      value,
 		ExpiresAt:  time.Now().Add(ttl),
 		AccessedAt: time.Now(),
+		StoredAt:   time.Now(),
 		Size:       size,
 	}
 
@@ -108,7 +142,7 @@ func (mc *MemoryCache) Set(key string, value interface{}, ttl time.Duration) err
 
 	// Double-check shutdown state under write lock
 	if mc.isShuttingDown {
-		return fmt.Errorf("cache is shutting down")
+		return &WriteError{Kind: WriteErrShutdown, Key: key, Err: errCacheShuttingDown}
 	}
 
 	existingEntry, isUpdate := mc.data[key]
@@ -119,12 +153,12 @@ func (mc *MemoryCache) Set(key string, value interface{}, ttl time.Duration) err
 
 	// If updating, subtract the old size from memory usage
 	if isUpdate {
-		mc.stats.MemoryUsage -= existingEntry.Size
+		mc.stats.memoryUsage.Add(-existingEntry.Size)
 	}
 
 	mc.data[key] = entry
-	mc.stats.MemoryUsage += size
-	mc.stats.SetsTotal++
+	mc.stats.memoryUsage.Add(size)
+	mc.stats.setsTotal.Add(1)
 
 	log.Debug("Cache entry set",
 		"key", key,
@@ -132,7 +166,7 @@ func (mc *MemoryCache) Set(key string, value interface{}, ttl time.Duration) err
 		"size_bytes", size,
 		"total_entries", len(mc.data),
 		"is_update", isUpdate,
-		"sets_total", mc.stats.SetsTotal)
+		"sets_total", mc.stats.setsTotal.Load())
 
 	return nil
 }
@@ -143,53 +177,76 @@ func (mc *MemoryCache) Get(key string) (interface{}, bool) {
 		return nil, false
 	}
 
-	mc.mu.Lock()
-	defer mc.mu.Unlock()
-
-	// Check shutdown state
+	// Hits only touch atomics, so concurrent Gets share the read lock
+	mc.mu.RLock()
 	if mc.isShuttingDown {
+		mc.mu.RUnlock()
 		log.Debug("Cache get during shutdown", "key", key)
 		return nil, false
 	}
-
 	entry, exists := mc.data[key]
+	now := time.Now()
+	if exists && now.Before(entry.ExpiresAt) {
+		entry.lastRead.Store(now.UnixNano())
+		value := entry.Value
+		mc.mu.RUnlock()
+
+		hits := mc.stats.hits.Add(1)
+		mc.stats.lastHit.Store(now.UnixNano())
+		log.Debug("Cache hit",
+			"key", key,
+			"age", now.Sub(entry.StoredAt),
+			"total_hits", hits)
+		return value, true
+	}
+	entries := len(mc.data)
+	mc.mu.RUnlock()
+
 	if !exists {
-		mc.stats.Misses++
-		mc.stats.LastMissTime = time.Now()
+		misses := mc.recordMiss()
 		log.Debug("Cache miss",
 			"key", key,
 			"reason", "key_not_found",
-			"total_entries", len(mc.data),
-			"miss_count", mc.stats.Misses)
+			"total_entries", entries,
+			"miss_count", misses)
 		return nil, false
 	}
 
-	// Check if entry has expired
-	if entry.IsExpired() {
+	// Removing the expired entry takes the write lock; a Set may have replaced
+	// it meanwhile, so only this exact entry is dropped
+	mc.mu.Lock()
+	if mc.data[key] == entry {
 		delete(mc.data, key)
-		mc.stats.MemoryUsage -= entry.Size
-		mc.stats.Misses++
-		mc.stats.Evictions++
-		mc.stats.ExpiredKeys++
-		mc.stats.LastMissTime = time.Now()
-		log.Debug("Cache miss",
-			"key", key,
-			"reason", "expired",
-			"expired_at", entry.ExpiresAt,
-			"age_seconds", time.Since(entry.ExpiresAt).Seconds())
-		return nil, false
+		mc.stats.memoryUsage.Add(-entry.Size)
+		mc.stats.evictions.Add(1)
+		mc.stats.expiredKeys.Add(1)
 	}
+	mc.mu.Unlock()
+	mc.recordMiss()
+	log.Debug("Cache miss",
+		"key", key,
+		"reason", "expired",
+		"expired_at", entry.ExpiresAt,
+		"age_seconds", time.Since(entry.ExpiresAt).Seconds())
+	return nil, false
+}
 
-	// Update access time for LRU tracking
-	entry.UpdateAccess()
-	mc.stats.Hits++
-	mc.stats.LastHitTime = time.Now()
+// StoredAt returns when key's live entry was written. It reads no value, so
+// it counts as neither a hit nor a miss.
+func (mc *MemoryCache) StoredAt(key string) (time.Time, bool) {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	entry, exists := mc.data[key]
+	if !exists || mc.isShuttingDown || !time.Now().Before(entry.ExpiresAt) {
+		return time.Time{}, false
+	}
+	return entry.StoredAt, true
+}
 
-	log.Debug("Cache hit",
-		"key", key,
-		"age", time.Since(entry.AccessedAt),
-		"total_hits", mc.stats.Hits)
-	return entry.Value, true
+// recordMiss counts a miss and returns the running total
+func (mc *MemoryCache) recordMiss() int64 {
+	mc.stats.lastMiss.Store(time.Now().UnixNano())
+	return mc.stats.misses.Add(1)
 }
 
 func (mc *MemoryCache) Delete(key string) error {
@@ -198,24 +255,43 @@ func (mc *MemoryCache) Delete(key string) error {
 
 	if entry, exists := mc.data[key]; exists {
 		delete(mc.data, key)
-		mc.stats.MemoryUsage -= entry.Size
-		mc.stats.DeletesTotal++
+		mc.stats.memoryUsage.Add(-entry.Size)
+		mc.stats.deletesTotal.Add(1)
 		log.Debug("Cache entry deleted",
 			"key", key,
 			"size_bytes", entry.Size,
-			"deletes_total", mc.stats.DeletesTotal)
+			"deletes_total", mc.stats.deletesTotal.Load())
 	}
 
 	return nil
 }
 
+// DeletePrefix removes every key equal to prefix or starting with "prefix:"
+func (mc *MemoryCache) DeletePrefix(prefix string) int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	removed := 0
+	for key, entry := range mc.data {
+		if key == prefix || strings.HasPrefix(key, prefix+":") {
+			delete(mc.data, key)
+			mc.stats.memoryUsage.Add(-entry.Size)
+			mc.stats.deletesTotal.Add(1)
+			removed++
+		}
+	}
+
+	log.Info("Cache prefix deleted", "prefix", prefix, "entries_removed", removed)
+	return removed
+}
+
 func (mc *MemoryCache) Clear() error {
 	mc.mu.Lock()
 	defer mc.mu.Unlock()
 
 	entryCount := len(mc.data)
 	mc.data = make(map[string]*CacheEntry)
-	mc.stats.MemoryUsage = 0
+	mc.stats.memoryUsage.Store(0)
 
 	log.Info("Cache cleared", "entries_removed", entryCount)
 	return nil
@@ -231,26 +307,9 @@ func (mc *MemoryCache) EvictExpired() int {
 
 // Stats returns cache performance metrics
 func (mc *MemoryCache) Stats() CacheStats {
-	mc.mu.RLock()
-	defer mc.mu.RUnlock()
-
-	// Create a copy of stats
-	stats := CacheStats{
-		Hits:             mc.stats.Hits,
-		Misses:           mc.stats.Misses,
-		Evictions:        mc.stats.Evictions,
-		Entries:          len(mc.data),
-		MemoryUsage:      mc.stats.MemoryUsage,
-		SetsTotal:        mc.stats.SetsTotal,
-		DeletesTotal:     mc.stats.DeletesTotal,
-		ExpiredKeys:      mc.stats.ExpiredKeys,
-		LRUEvictions:     mc.stats.LRUEvictions,
-		CorruptionEvents: mc.stats.CorruptionEvents,
-		RecoveryEvents:   mc.stats.RecoveryEvents,
-		LastHitTime:      mc.stats.LastHitTime,
-		LastMissTime:     mc.stats.LastMissTime,
-		UptimeSeconds:    int64(time.Since(mc.startTime).Seconds()),
-	}
+	stats := mc.stats.snapshot()
+	stats.Entries = mc.getCurrentEntryCount()
+	stats.UptimeSeconds = int64(time.Since(mc.startTime).Seconds())
 
 	// Calculate hit rate
 	totalRequests := stats.Hits + stats.Misses
@@ -266,6 +325,39 @@ func (mc *MemoryCache) Stats() CacheStats {
 	return stats
 }
 
+// MaxEntries is the current capacity
+func (mc *MemoryCache) MaxEntries() int {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return mc.maxEntries
+}
+
+// SetMaxEntries changes the capacity, within the same bounds as
+// NewMemoryCache. Lowering it evicts on later writes rather than at once.
+func (mc *MemoryCache) SetMaxEntries(maxEntries int) {
+	if maxEntries <= 0 || maxEntries > maxMemoryCacheItems {
+		return
+	}
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.maxEntries = maxEntries
+}
+
+// Drain readies the cache for Close while in-flight requests finish: reads
+// are still served, but writes are skipped without error since the entries
+// would be dropped with the cache anyway
+func (mc *MemoryCache) Drain() {
+	if mc.draining.CompareAndSwap(false, true) {
+		log.Info("Memory cache draining; writes are skipped until close",
+			"entries", mc.getCurrentEntryCount())
+	}
+}
+
+// Draining reports whether Drain has been called
+func (mc *MemoryCache) Draining() bool {
+	return mc.draining.Load()
+}
+
 // Close shuts down the cache and stops background workers
 func (mc *MemoryCache) Close() {
 	mc.shutdownOnce.Do(func() {
@@ -290,7 +382,9 @@ func (mc *MemoryCache) Close() {
 		mc.data = nil
 		mc.mu.Unlock()
 
-		log.Info("Memory cache closed", "final_entries", entryCount)
+		log.Info("Memory cache closed",
+			"final_entries", entryCount,
+			"writes_skipped_draining", drainSkippedWrites.Value())
 	})
 }
 
@@ -302,9 +396,9 @@ func (mc *MemoryCache) evictExpiredLocked() int {
 	for key, entry := range mc.data {
 		if now.After(entry.ExpiresAt) {
 			delete(mc.data, key)
-			mc.stats.MemoryUsage -= entry.Size
-			mc.stats.Evictions++
-			mc.stats.ExpiredKeys++
+			mc.stats.memoryUsage.Add(-entry.Size)
+			mc.stats.evictions.Add(1)
+			mc.stats.expiredKeys.Add(1)
 			evicted++
 		}
 	}
@@ -312,7 +406,7 @@ func (mc *MemoryCache) evictExpiredLocked() int {
 	if evicted > 0 {
 		log.Debug("Expired entries evicted",
 			"count", evicted,
-			"total_expired", mc.stats.ExpiredKeys)
+			"total_expired", mc.stats.expiredKeys.Load())
 	}
 
 	return evicted
@@ -335,9 +429,9 @@ func (mc *MemoryCache) evictLRU() {
 			continue
 		}
 
-		if first || entry.AccessedAt.Before(oldestTime) {
+		if accessed := entry.LastAccess(); first || accessed.Before(oldestTime) {
 			oldestKey = key
-			oldestTime = entry.AccessedAt
+			oldestTime = accessed
 			first = false
 		}
 	}
@@ -352,84 +446,177 @@ func (mc *MemoryCache) evictLRU() {
 	// Remove the oldest entry
 	if entry, exists := mc.data[oldestKey]; exists {
 		delete(mc.data, oldestKey)
-		mc.stats.MemoryUsage -= entry.Size
-		mc.stats.Evictions++
-		mc.stats.LRUEvictions++
+		mc.stats.memoryUsage.Add(-entry.Size)
+		mc.stats.evictions.Add(1)
+		mc.stats.lruEvictions.Add(1)
 
 		log.Debug("LRU eviction",
 			"key", oldestKey,
 			"age", time.Since(oldestTime),
 			"remaining_entries", len(mc.data),
 			"memory_freed", entry.Size,
-			"lru_evictions_total", mc.stats.LRUEvictions)
+			"lru_evictions_total", mc.stats.lruEvictions.Load())
 	}
 }
 
 // calculateSize estimates the memory size of a value in bytes
-func (mc *MemoryCache) calculateSize(value interface{}) int64 {
+func calculateSize(value interface{}) (int64, error) {
 	// JSON marshaling size estimation
 	data, err := json.Marshal(value)
 	if err != nil {
-		mc.stats.CorruptionEvents++
-		log.Error("Cache value serialization failed", "error", err.Error())
-		return 1024
+		return 0, err
 	}
 
-	return int64(len(data)) + 200
+	return int64(len(data)) + 200, nil
 }
 
-// detectAndRecover performs corruption detection and recovery
+// defaultMaxEntrySize keeps one runaway value from crowding out the rest of the cache
+const defaultMaxEntrySize = 8 << 20
+
+var errCacheShuttingDown = errors.New("cache is shutting down")
+
+var drainSkippedWrites = metrics.NewCounter("dbd_cache_writes_skipped_draining",
+	"Cache writes skipped because the cache was draining for shutdown.")
+
+// defaultValidationBatchSize bounds how many entries one validation slice holds the lock for
+const defaultValidationBatchSize = 100
+
+// detectAndRecover performs corruption detection and recovery. Keys are checked
+// in batches so the write lock is only held for one short slice at a time, and
+// the serialization check runs with no lock held at all; Get/Set interleave
+// freely between batches.
 func (mc *MemoryCache) detectAndRecover() int {
-	mc.mu.Lock()
-	defer mc.mu.Unlock()
+	mc.mu.RLock()
+	keys := make([]string, 0, len(mc.data))
+	for key := range mc.data {
+		keys = append(keys, key)
+	}
+	mc.mu.RUnlock()
 
 	corrupted := 0
+	for start := 0; start < len(keys); start += mc.validationBatchSize {
+		end := min(start+mc.validationBatchSize, len(keys))
+		corrupted += mc.validateBatch(keys[start:end])
+	}
+
+	if corrupted > 0 {
+		corruptionEvents := mc.stats.corruptionEvents.Add(int64(corrupted))
+		recoveryEvents := mc.stats.recoveryEvents.Add(1)
+		remaining := mc.getCurrentEntryCount()
+
+		log.Error("Cache corruption detected and recovered",
+			"corrupted_entries", corrupted,
+			"corruption_events_total", corruptionEvents,
+			"recovery_events_total", recoveryEvents,
+			"remaining_entries", remaining)
+
+		notify.Publish(notify.EventCacheCorruption, notify.SeverityWarning,
+			"Cache corruption detected",
+			fmt.Sprintf("%d corrupted cache entries were removed", corrupted),
+			map[string]interface{}{
+				"corruption_events_total": corruptionEvents,
+				"remaining_entries":       remaining,
+			})
+	}
+
+	return corrupted
+}
+
+// validateBatch checks one slice of keys and removes the corrupted entries
+func (mc *MemoryCache) validateBatch(keys []string) int {
+	type candidate struct {
+		key   string
+		entry *CacheEntry
+	}
+
+	// Timestamp checks are cheap, so they run under the lock
 	now := time.Now()
+	corrupt := make(map[string]*CacheEntry)
+	candidates := make([]candidate, 0, len(keys))
 
-	for key, entry := range mc.data {
-		// Check for nil entries
-		if entry == nil {
-			delete(mc.data, key)
-			corrupted++
+	mc.mu.RLock()
+	for _, key := range keys {
+		entry, exists := mc.data[key]
+		if !exists {
 			continue
 		}
-
-		// Check for invalid timestamps
-		if entry.ExpiresAt.IsZero() || entry.AccessedAt.IsZero() {
-			delete(mc.data, key)
-			mc.stats.MemoryUsage -= entry.Size
-			corrupted++
+		if entry == nil ||
+			entry.ExpiresAt.IsZero() || entry.LastAccess().IsZero() ||
+			now.Sub(entry.LastAccess()) > 365*24*time.Hour {
+			corrupt[key] = entry
 			continue
 		}
+		candidates = append(candidates, candidate{key: key, entry: entry})
+	}
+	mc.mu.RUnlock()
 
-		// Check for very old access times
-		if now.Sub(entry.AccessedAt) > 365*24*time.Hour {
-			delete(mc.data, key)
-			mc.stats.MemoryUsage -= entry.Size
-			corrupted++
-			continue
+	// Values are never mutated after Set, so marshaling them needs no lock
+	for _, c := range candidates {
+		if _, err := json.Marshal(c.entry.Value); err != nil {
+			corrupt[c.key] = c.entry
 		}
+	}
 
-		if _, err := json.Marshal(entry.Value); err != nil {
-			delete(mc.data, key)
-			mc.stats.MemoryUsage -= entry.Size
-			corrupted++
+	if len(corrupt) == 0 {
+		return 0
+	}
+
+	removed := 0
+	mc.mu.Lock()
+	for key, entry := range corrupt {
+		// Skip keys rewritten since they were checked
+		if current, exists := mc.data[key]; !exists || current != entry {
 			continue
 		}
+		delete(mc.data, key)
+		if entry != nil {
+			mc.stats.memoryUsage.Add(-entry.Size)
+		}
+		removed++
 	}
+	mc.mu.Unlock()
+	return removed
+}
 
-	if corrupted > 0 {
-		mc.stats.CorruptionEvents += int64(corrupted)
-		mc.stats.RecoveryEvents++
+// sweepOrphans removes entries whose key prefix is not registered, such as keys
+// written by an older build before a prefix was renamed or versioned
+func (mc *MemoryCache) sweepOrphans() int {
+	mc.mu.RLock()
+	var orphans []string
+	for key := range mc.data {
+		if !isKnownKey(key) {
+			orphans = append(orphans, key)
+		}
+	}
+	mc.mu.RUnlock()
 
-		log.Error("Cache corruption detected and recovered",
-			"corrupted_entries", corrupted,
-			"corruption_events_total", mc.stats.CorruptionEvents,
-			"recovery_events_total", mc.stats.RecoveryEvents,
-			"remaining_entries", len(mc.data))
+	if len(orphans) == 0 {
+		return 0
 	}
 
-	return corrupted
+	removed := 0
+	byPrefix := make(map[string]int)
+	mc.mu.Lock()
+	for _, key := range orphans {
+		entry, exists := mc.data[key]
+		if !exists {
+			continue
+		}
+		delete(mc.data, key)
+		mc.stats.memoryUsage.Add(-entry.Size)
+		byPrefix[keyPrefix(key)]++
+		removed++
+	}
+	total := mc.stats.orphansRemoved.Add(int64(removed))
+	mc.mu.Unlock()
+
+	if removed > 0 {
+		log.Info("Removed cache entries under unregistered key prefixes",
+			"removed", removed,
+			"by_prefix", byPrefix,
+			"orphans_removed_total", total)
+	}
+	return removed
 }
 
 // cleanupWorker runs in a background goroutine to periodically clean expired entries
@@ -447,10 +634,11 @@ func (mc *MemoryCache) cleanupWorker() {
 			start := time.Now()
 			evicted := mc.EvictExpired()
 
-			// Perform corruption detection every 5th cleanup
+			// Perform corruption detection and the orphan sweep every 5th cleanup
 			corrupted := 0
 			if cleanupCount%5 == 0 {
 				corrupted = mc.detectAndRecover()
+				mc.sweepOrphans()
 			}
 
 			duration := time.Since(start)
@@ -522,32 +710,60 @@ func (mc *MemoryCache) getCurrentEntryCount() int {
 
 // GetStats returns a copy of the current cache statistics
 func (mc *MemoryCache) GetStats() CacheStats {
-	mc.mu.RLock()
-	defer mc.mu.RUnlock()
+	stats := mc.stats.snapshot()
+	stats.Entries = mc.getCurrentEntryCount()
+	stats.UptimeSeconds = int64(time.Since(mc.startTime).Seconds())
 
 	// Calculate hit rate
-	totalRequests := mc.stats.Hits + mc.stats.Misses
-	var hitRate float64
+	totalRequests := stats.Hits + stats.Misses
 	if totalRequests > 0 {
-		hitRate = float64(mc.stats.Hits) / float64(totalRequests)
+		stats.HitRate = float64(stats.Hits) / float64(totalRequests)
 	}
 
-	// Return a copy of the stats
+	return stats
+}
+
+// cacheCounters are a MemoryCache's running statistics. They are atomic so
+// Get can record hits and misses while holding only the read lock.
+type cacheCounters struct {
+	hits             atomic.Int64
+	misses           atomic.Int64
+	evictions        atomic.Int64
+	memoryUsage      atomic.Int64
+	setsTotal        atomic.Int64
+	deletesTotal     atomic.Int64
+	expiredKeys      atomic.Int64
+	lruEvictions     atomic.Int64
+	corruptionEvents atomic.Int64
+	recoveryEvents   atomic.Int64
+	orphansRemoved   atomic.Int64
+	lastHit          atomic.Int64 // Unix nanoseconds, 0 before the first hit
+	lastMiss         atomic.Int64 // Unix nanoseconds, 0 before the first miss
+}
+
+// snapshot copies the counters into a CacheStats. Counters are read one at a
+// time, so a snapshot taken under load may mix adjacent moments.
+func (c *cacheCounters) snapshot() CacheStats {
 	return CacheStats{
-		Hits:             mc.stats.Hits,
-		Misses:           mc.stats.Misses,
-		SetsTotal:        mc.stats.SetsTotal,
-		DeletesTotal:     mc.stats.DeletesTotal,
-		Evictions:        mc.stats.Evictions,
-		ExpiredKeys:      mc.stats.ExpiredKeys,
-		LRUEvictions:     mc.stats.LRUEvictions,
-		MemoryUsage:      mc.stats.MemoryUsage,
-		LastHitTime:      mc.stats.LastHitTime,
-		LastMissTime:     mc.stats.LastMissTime,
-		CorruptionEvents: mc.stats.CorruptionEvents,
-		RecoveryEvents:   mc.stats.RecoveryEvents,
-		Entries:          len(mc.data),
-		HitRate:          hitRate,
-		UptimeSeconds:    int64(time.Since(mc.startTime).Seconds()),
+		Hits:             c.hits.Load(),
+		Misses:           c.misses.Load(),
+		Evictions:        c.evictions.Load(),
+		MemoryUsage:      c.memoryUsage.Load(),
+		SetsTotal:        c.setsTotal.Load(),
+		DeletesTotal:     c.deletesTotal.Load(),
+		ExpiredKeys:      c.expiredKeys.Load(),
+		LRUEvictions:     c.lruEvictions.Load(),
+		CorruptionEvents: c.corruptionEvents.Load(),
+		RecoveryEvents:   c.recoveryEvents.Load(),
+		OrphansRemoved:   c.orphansRemoved.Load(),
+		LastHitTime:      unixNanoTime(c.lastHit.Load()),
+		LastMissTime:     unixNanoTime(c.lastMiss.Load()),
+	}
+}
+
+func unixNanoTime(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
 	}
+	return time.Unix(0, nanos)
 }
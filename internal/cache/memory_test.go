@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testStats stands in for a cached player payload: big enough that
+// marshaling every entry in one pass takes a noticeable time
+type testStats struct {
+	SteamID string
+	Values  []int64
+	Names   []string
+}
+
+func newTestCache(tb testing.TB, entries int) (*MemoryCache, []string) {
+	tb.Helper()
+	mc := NewMemoryCache(MemoryCacheConfig{
+		MaxEntries:      entries,
+		DefaultTTL:      time.Hour,
+		CleanupInterval: time.Hour, // validation runs only when a test calls it
+	})
+	tb.Cleanup(mc.Close)
+
+	keys := make([]string, entries)
+	for i := range keys {
+		keys[i] = GenerateKey(PlayerStatsPrefix, fmt.Sprintf("7656119%010d", i))
+		value := testStats{SteamID: keys[i], Values: make([]int64, 64), Names: make([]string, 16)}
+		for j := range value.Names {
+			value.Names[j] = fmt.Sprintf("stat_%d", j)
+		}
+		if err := mc.Set(keys[i], value, 0); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return mc, keys
+}
+
+// getLatencies times Gets spread over keys until stop is closed
+func getLatencies(mc *MemoryCache, keys []string, stop <-chan struct{}) []time.Duration {
+	var latencies []time.Duration
+	for i := 0; ; i++ {
+		select {
+		case <-stop:
+			return latencies
+		default:
+		}
+		start := time.Now()
+		mc.Get(keys[(i*7919)%len(keys)])
+		latencies = append(latencies, time.Since(start))
+	}
+}
+
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(latencies)
+	slices.Sort(sorted)
+	return sorted[int(float64(len(sorted)-1)*p)]
+}
+
+// TestGetLatencyDuringValidation checks that corruption detection over a
+// large cache doesn't stall reads: Get's p99 while it runs must stay far
+// below the time a whole-map pass takes, which a Get would wait out if
+// validation held the lock across it
+func TestGetLatencyDuringValidation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("fills a 50k entry cache")
+	}
+	mc, keys := newTestCache(t, 50000)
+
+	passStart := time.Now()
+	mc.detectAndRecover()
+	pass := time.Since(passStart)
+
+	stop := make(chan struct{})
+	var passes atomic.Int64
+	go func() {
+		defer close(stop)
+		for i := 0; i < 3; i++ {
+			mc.detectAndRecover()
+			passes.Add(1)
+		}
+	}()
+	latencies := getLatencies(mc, keys, stop)
+
+	p99 := percentile(latencies, 0.99)
+	t.Logf("validation pass %v over %d entries; %d Gets during %d passes, p50 %v, p99 %v, max %v",
+		pass, len(keys), len(latencies), passes.Load(),
+		percentile(latencies, 0.5), p99, percentile(latencies, 1))
+
+	if limit := pass / 10; p99 > limit && p99 > time.Millisecond {
+		t.Fatalf("Get p99 %v during validation exceeds a tenth of a validation pass (%v)", p99, limit)
+	}
+}
+
+// BenchmarkGetDuringValidation reports Get latency percentiles while
+// corruption detection sweeps the cache continuously
+func BenchmarkGetDuringValidation(b *testing.B) {
+	mc, keys := newTestCache(b, 50000)
+
+	stop := make(chan struct{})
+	validating := make(chan struct{})
+	go func() {
+		defer close(validating)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				mc.detectAndRecover()
+			}
+		}
+	}()
+
+	latencies := make([]time.Duration, b.N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := time.Now()
+		mc.Get(keys[(i*7919)%len(keys)])
+		latencies[i] = time.Since(start)
+	}
+	b.StopTimer()
+	close(stop)
+	<-validating
+
+	b.ReportMetric(float64(percentile(latencies, 0.5).Nanoseconds()), "p50-ns")
+	b.ReportMetric(float64(percentile(latencies, 0.99).Nanoseconds()), "p99-ns")
+}
+
+// The parallel benchmarks cover the paths contended under load: Get runs
+// under the read lock with atomic stats, so reads should scale with
+// GOMAXPROCS rather than queue on each other
+
+func BenchmarkGetParallel(b *testing.B) {
+	mc, keys := newTestCache(b, 10000)
+	var next atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(next.Add(1)) * 7919
+		for pb.Next() {
+			mc.Get(keys[i%len(keys)])
+			i++
+		}
+	})
+}
+
+func BenchmarkGetMissParallel(b *testing.B) {
+	mc, _ := newTestCache(b, 1000)
+	missing := GenerateKey(PlayerStatsPrefix, "missing")
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			mc.Get(missing)
+		}
+	})
+}
+
+// BenchmarkGetSetParallel mixes one Set per ten Gets, roughly a warm cache
+// refreshing entries as they expire
+func BenchmarkGetSetParallel(b *testing.B) {
+	mc, keys := newTestCache(b, 10000)
+	value := testStats{SteamID: "refreshed", Values: make([]int64, 64)}
+	var next atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(next.Add(1)) * 7919
+		for pb.Next() {
+			key := keys[i%len(keys)]
+			if i%10 == 0 {
+				mc.Set(key, value, 0)
+			} else {
+				mc.Get(key)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkStatsParallel(b *testing.B) {
+	mc, keys := newTestCache(b, 1000)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if i%100 == 0 {
+				mc.Stats()
+			} else {
+				mc.Get(keys[i%len(keys)])
+			}
+			i++
+		}
+	})
+}
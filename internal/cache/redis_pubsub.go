@@ -0,0 +1,254 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// RedisInvalidationBus broadcasts invalidations over Redis PUBLISH/SUBSCRIBE.
+// It speaks just enough RESP for pub/sub so no client library is required.
+type RedisInvalidationBus struct {
+	addr     string
+	password string
+	channel  string
+	config   RedisConfig
+
+	mu      sync.Mutex
+	pubConn net.Conn
+	pubRead *bufio.Reader
+	closed  bool
+}
+
+// NewRedisInvalidationBus creates a bus; connections are opened lazily
+func NewRedisInvalidationBus(addr, password, channel string, config RedisConfig) *RedisInvalidationBus {
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+	if config.ReadTimeout <= 0 {
+		config.ReadTimeout = 3 * time.Second
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 3 * time.Second
+	}
+	return &RedisInvalidationBus{addr: addr, password: password, channel: channel, config: config}
+}
+
+// Publish sends an invalidation, reconnecting once if the cached connection went stale
+func (b *RedisInvalidationBus) Publish(ctx context.Context, inv Invalidation) error {
+	payload, err := json.Marshal(inv)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return errors.New("invalidation bus closed")
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		if b.pubConn == nil {
+			conn, reader, err := b.dial(ctx)
+			if err != nil {
+				return err
+			}
+			b.pubConn, b.pubRead = conn, reader
+		}
+		if lastErr = b.command(ctx, b.pubConn, b.pubRead, "PUBLISH", b.channel, string(payload)); lastErr == nil {
+			return nil
+		}
+		b.pubConn.Close()
+		b.pubConn, b.pubRead = nil, nil
+	}
+	return lastErr
+}
+
+// Subscribe listens on the channel until ctx is cancelled, backing off between reconnects
+func (b *RedisInvalidationBus) Subscribe(ctx context.Context, handle func(Invalidation)) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		err := b.subscribeOnce(ctx, handle, func() { backoff = time.Second })
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warn("Cache invalidation subscription lost, reconnecting",
+			"addr", b.addr,
+			"channel", b.channel,
+			"retry_in", backoff,
+			"error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (b *RedisInvalidationBus) subscribeOnce(ctx context.Context, handle func(Invalidation), connected func()) error {
+	conn, reader, err := b.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Unblock the read loop on shutdown
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := writeCommand(conn, b.config.WriteTimeout, "SUBSCRIBE", b.channel); err != nil {
+		return err
+	}
+	connected()
+
+	for {
+		// Pub/sub connections idle indefinitely; only writes carry a deadline
+		conn.SetReadDeadline(time.Time{})
+		reply, err := readReply(reader)
+		if err != nil {
+			return err
+		}
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 3 {
+			continue
+		}
+		if kind, _ := parts[0].(string); kind != "message" {
+			continue
+		}
+		payload, _ := parts[2].(string)
+
+		var inv Invalidation
+		if err := json.Unmarshal([]byte(payload), &inv); err != nil {
+			log.Warn("Ignoring malformed cache invalidation", "error", err)
+			continue
+		}
+		handle(inv)
+	}
+}
+
+// Ping opens a fresh connection, authenticates and sends PING, so
+// configuration checks can verify the server before the bus is used
+func (b *RedisInvalidationBus) Ping(ctx context.Context) error {
+	conn, reader, err := b.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := b.command(ctx, conn, reader, "PING"); err != nil {
+		return fmt.Errorf("redis ping: %w", err)
+	}
+	return nil
+}
+
+// Close drops the publish connection; subscriptions stop with their context
+func (b *RedisInvalidationBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	if b.pubConn != nil {
+		err := b.pubConn.Close()
+		b.pubConn, b.pubRead = nil, nil
+		return err
+	}
+	return nil
+}
+
+func (b *RedisInvalidationBus) dial(ctx context.Context) (net.Conn, *bufio.Reader, error) {
+	dialer := net.Dialer{Timeout: b.config.DialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", b.addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("redis dial %s: %w", b.addr, err)
+	}
+	reader := bufio.NewReader(conn)
+	if b.password != "" {
+		if err := b.command(ctx, conn, reader, "AUTH", b.password); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("redis auth: %w", err)
+		}
+	}
+	return conn, reader, nil
+}
+
+// command sends a request and waits for its reply, surfacing Redis error replies
+func (b *RedisInvalidationBus) command(ctx context.Context, conn net.Conn, reader *bufio.Reader, args ...string) error {
+	if err := writeCommand(conn, b.config.WriteTimeout, args...); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(b.config.ReadTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetReadDeadline(deadline)
+	_, err := readReply(reader)
+	return err
+}
+
+func writeCommand(conn net.Conn, timeout time.Duration, args ...string) error {
+	var sb strings.Builder
+	sb.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		sb.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	conn.SetWriteDeadline(time.Now().Add(timeout))
+	_, err := io.WriteString(conn, sb.String())
+	return err
+}
+
+// readReply parses one RESP2 value: simple strings, errors, integers, bulk strings and arrays
+func readReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, errors.New("redis: " + line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+)
+
+// maxSanitizeDepth stops Sanitize on cyclic values, which can't encode anyway
+const maxSanitizeDepth = 64
+
+var sanitizedWrites = metrics.NewCounter("dbd_cache_values_sanitized",
+	"Cache values stored after stripping parts that could not be serialized.")
+
+// unencodablePath returns the first field path in t whose type can never be
+// serialized, or "" when every field can be. Fields tagged json:"-" and
+// interface{} fields, checked at write time instead, are skipped.
+func unencodablePath(t reflect.Type, path string, seen map[reflect.Type]bool) string {
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		if path == "" {
+			return t.String()
+		}
+		return path
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return unencodablePath(t.Elem(), path, seen)
+	case reflect.Map:
+		return unencodablePath(t.Elem(), path+"[]", seen)
+	case reflect.Struct:
+		if seen[t] {
+			return ""
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+			if bad := unencodablePath(field.Type, strings.TrimPrefix(path+"."+field.Name, "."), seen); bad != "" {
+				return bad
+			}
+		}
+	}
+	return ""
+}
+
+// Sanitize returns a copy of value, of the same type, with funcs, channels
+// and non-finite floats zeroed wherever they appear, and the paths it
+// changed. A value with nothing to strip comes back with no paths.
+//
+// NewNamespace already refuses types that can never be serialized, so this
+// only catches what slips through at write time: interface{} fields holding
+// a func or channel, and NaN or infinite floats. Such values are cached as
+// the sanitized copy rather than dropped.
+func Sanitize(value interface{}) (interface{}, []string) {
+	if value == nil {
+		return nil, nil
+	}
+	var stripped []string
+	clean := sanitizeValue(reflect.ValueOf(value), "", 0, &stripped)
+	if len(stripped) == 0 {
+		return value, nil
+	}
+	return clean.Interface(), stripped
+}
+
+func sanitizeValue(v reflect.Value, path string, depth int, stripped *[]string) reflect.Value {
+	at := path
+	if at == "" {
+		at = "."
+	}
+	if depth > maxSanitizeDepth {
+		*stripped = append(*stripped, at+" (too deep)")
+		return reflect.Zero(v.Type())
+	}
+
+	switch v.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		*stripped = append(*stripped, at)
+		return reflect.Zero(v.Type())
+
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			*stripped = append(*stripped, at)
+			return reflect.Zero(v.Type())
+		}
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		switch v.Elem().Kind() {
+		case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+			// A typed zero func still can't encode; leave the interface nil
+			*stripped = append(*stripped, at)
+			return out
+		}
+		out.Set(sanitizeValue(v.Elem(), path, depth+1, stripped))
+		return out
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(sanitizeValue(v.Elem(), path, depth+1, stripped))
+		return out
+
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+			out.Field(i).Set(sanitizeValue(v.Field(i), path+"."+field.Name, depth+1, stripped))
+		}
+		return out
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(sanitizeValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), depth+1, stripped))
+		}
+		return out
+
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(sanitizeValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), depth+1, stripped))
+		}
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), sanitizeValue(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), depth+1, stripped))
+		}
+		return out
+	}
+	return v
+}
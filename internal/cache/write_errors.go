@@ -0,0 +1,213 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+	"github.com/rgonzalez12/dbd-analytics/internal/notify"
+)
+
+// WriteErrorKind says why a cache Set failed
+type WriteErrorKind string
+
+const (
+	WriteErrInvalid       WriteErrorKind = "invalid"       // empty key or nil value; a caller bug
+	WriteErrShutdown      WriteErrorKind = "shutdown"      // cache is closing
+	WriteErrSize          WriteErrorKind = "size"          // value is larger than one entry may be
+	WriteErrSerialization WriteErrorKind = "serialization" // value cannot be encoded
+	WriteErrTransient     WriteErrorKind = "transient"     // anything uncategorized, e.g. a backend hiccup
+)
+
+var writeErrorKinds = []WriteErrorKind{WriteErrInvalid, WriteErrShutdown, WriteErrSize, WriteErrSerialization, WriteErrTransient}
+
+// Retryable reports whether another attempt could succeed
+func (k WriteErrorKind) Retryable() bool {
+	return k == WriteErrTransient
+}
+
+// WriteError is returned by cache Set implementations that know why they failed
+type WriteError struct {
+	Kind WriteErrorKind
+	Key  string
+	Err  error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("cache write failed (%s) for %q: %v", e.Kind, e.Key, e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// WriteErrorKindOf categorizes a Set error; errors without a category are transient
+func WriteErrorKindOf(err error) WriteErrorKind {
+	if err == nil {
+		return ""
+	}
+	var writeErr *WriteError
+	if errors.As(err, &writeErr) {
+		return writeErr.Kind
+	}
+	return WriteErrTransient
+}
+
+const (
+	writeRetryAttempts = 3
+	writeRetryBackoff  = 20 * time.Millisecond
+
+	// A key whose value fails to encode this many times in a row is reported as a
+	// corruption candidate: the producer is building a bad value, not a one-off
+	serializationCandidateThreshold = 3
+	maxTrackedSerializationKeys     = 256
+)
+
+var (
+	writeErrorCounters = map[WriteErrorKind]*metrics.Counter{
+		WriteErrInvalid:       metrics.NewCounter("dbd_cache_write_errors_invalid", "Cache writes rejected for an empty key or nil value."),
+		WriteErrShutdown:      metrics.NewCounter("dbd_cache_write_errors_shutdown", "Cache writes rejected because the cache was shutting down."),
+		WriteErrSize:          metrics.NewCounter("dbd_cache_write_errors_size", "Cache writes rejected for exceeding the per-entry size limit."),
+		WriteErrSerialization: metrics.NewCounter("dbd_cache_write_errors_serialization", "Cache writes whose value could not be serialized."),
+		WriteErrTransient:     metrics.NewCounter("dbd_cache_write_errors_transient", "Cache writes that still failed after retrying a transient error."),
+	}
+	writeRetriesTotal        = metrics.NewCounter("dbd_cache_write_retries", "Cache writes retried after a transient failure.")
+	corruptionCandidateTotal = metrics.NewCounter("dbd_cache_corruption_candidates", "Cache keys whose values repeatedly failed to serialize.")
+)
+
+// writePipeline tracks Set outcomes for every cache handed out by the manager
+type writePipeline struct {
+	mu                    sync.Mutex
+	failures              map[WriteErrorKind]int64
+	retries               int64
+	sanitized             int64 // values cached after Sanitize stripped them
+	candidates            int64
+	serializationFailures map[string]int // consecutive failures per key
+}
+
+func newWritePipeline() *writePipeline {
+	return &writePipeline{
+		failures:              make(map[WriteErrorKind]int64),
+		serializationFailures: make(map[string]int),
+	}
+}
+
+// set runs one write: transient failures are retried with a short backoff,
+// and a value that fails to serialize is retried once as a sanitized copy.
+// Everything else fails immediately since another attempt would fail the
+// same way.
+func (p *writePipeline) set(c Cache, key string, value interface{}, ttl time.Duration) error {
+	sanitized := false
+	for attempt := 1; ; attempt++ {
+		err := c.Set(key, value, ttl)
+		if err == nil {
+			p.succeeded(key)
+			return nil
+		}
+		kind := WriteErrorKindOf(err)
+		if kind == WriteErrSerialization && !sanitized {
+			sanitized = true
+			if clean, stripped := Sanitize(value); len(stripped) > 0 {
+				p.mu.Lock()
+				p.sanitized++
+				p.mu.Unlock()
+				sanitizedWrites.Add(1)
+				if len(stripped) > 10 {
+					stripped = append(stripped[:10:10], fmt.Sprintf("and %d more", len(stripped)-10))
+				}
+				log.Warn("Caching sanitized copy of a value that failed to serialize",
+					"key", key,
+					"stripped", stripped,
+					"error", err.Error())
+				value = clean
+				attempt--
+				continue
+			}
+		}
+		if !kind.Retryable() || attempt == writeRetryAttempts {
+			p.failed(key, kind, attempt, err)
+			return err
+		}
+		p.mu.Lock()
+		p.retries++
+		p.mu.Unlock()
+		writeRetriesTotal.Add(1)
+		time.Sleep(addJitter(time.Duration(attempt)*writeRetryBackoff, 0.5))
+	}
+}
+
+func (p *writePipeline) succeeded(key string) {
+	p.mu.Lock()
+	delete(p.serializationFailures, key)
+	p.mu.Unlock()
+}
+
+func (p *writePipeline) failed(key string, kind WriteErrorKind, attempts int, err error) {
+	writeErrorCounters[kind].Add(1)
+
+	p.mu.Lock()
+	p.failures[kind]++
+	streak := 0
+	if kind == WriteErrSerialization {
+		if _, tracked := p.serializationFailures[key]; tracked || len(p.serializationFailures) < maxTrackedSerializationKeys {
+			p.serializationFailures[key]++
+			streak = p.serializationFailures[key]
+		}
+	}
+	candidate := streak == serializationCandidateThreshold
+	if candidate {
+		p.candidates++
+	}
+	p.mu.Unlock()
+
+	log.Warn("Cache write failed",
+		"key", key,
+		"kind", string(kind),
+		"attempts", attempts,
+		"error", err.Error())
+
+	if candidate {
+		corruptionCandidateTotal.Add(1)
+		log.Error("Cache corruption candidate: value repeatedly fails to serialize",
+			"key", key,
+			"consecutive_failures", streak)
+		notify.Publish(notify.EventCacheCorruption, notify.SeverityWarning,
+			"Cache corruption candidate",
+			fmt.Sprintf("Values for %s failed to serialize %d times in a row", key, streak),
+			map[string]interface{}{
+				"key":                  key,
+				"consecutive_failures": streak,
+			})
+	}
+}
+
+// Status reports failure counts by kind for the status endpoint
+func (p *writePipeline) Status() map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	failures := make(map[string]int64, len(writeErrorKinds))
+	for _, kind := range writeErrorKinds {
+		failures[string(kind)] = p.failures[kind]
+	}
+	return map[string]interface{}{
+		"failures":              failures,
+		"retries":               p.retries,
+		"sanitized":             p.sanitized,
+		"corruption_candidates": p.candidates,
+		"failing_keys":          len(p.serializationFailures),
+	}
+}
+
+// pipelinedCache routes Set through the manager's write pipeline
+type pipelinedCache struct {
+	Cache
+	writes *writePipeline
+}
+
+func (c *pipelinedCache) Set(key string, value interface{}, ttl time.Duration) error {
+	return c.writes.set(c.Cache, key, value, ttl)
+}
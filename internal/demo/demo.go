@@ -0,0 +1,131 @@
+// Package demo serves bundled fixture players so the API can be exercised
+// without a Steam API key (frontend development, CI, offline demos).
+package demo
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+//go:embed fixtures/*.json
+var fixtureFS embed.FS
+
+// fixture is a bundled player response plus the vanity name it answers to
+type fixture struct {
+	Vanity string `json:"vanity"`
+	models.PlayerStatsWithAchievements
+}
+
+var (
+	loadOnce sync.Once
+	byID     map[string]fixture
+	byVanity map[string]string
+	loadErr  error
+	loadedAt time.Time
+)
+
+// Enabled reports whether demo mode is active. DEMO_MODE=true forces it on,
+// DEMO_MODE=false forces it off, and otherwise it turns on when STEAM_API_KEY is unset.
+func Enabled() bool {
+	if value := os.Getenv("DEMO_MODE"); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return os.Getenv("STEAM_API_KEY") == ""
+}
+
+func load() {
+	loadedAt = time.Now().UTC().Truncate(time.Second)
+	byID = make(map[string]fixture)
+	byVanity = make(map[string]string)
+
+	entries, err := fixtureFS.ReadDir("fixtures")
+	if err != nil {
+		loadErr = fmt.Errorf("failed to read demo fixtures: %w", err)
+		return
+	}
+
+	for _, entry := range entries {
+		data, err := fixtureFS.ReadFile("fixtures/" + entry.Name())
+		if err != nil {
+			loadErr = fmt.Errorf("failed to read demo fixture %s: %w", entry.Name(), err)
+			return
+		}
+
+		var f fixture
+		if err := json.Unmarshal(data, &f); err != nil {
+			loadErr = fmt.Errorf("failed to parse demo fixture %s: %w", entry.Name(), err)
+			return
+		}
+
+		byID[f.SteamID] = f
+		if f.Vanity != "" {
+			byVanity[strings.ToLower(f.Vanity)] = f.SteamID
+		}
+	}
+}
+
+// Player returns the fixture response for a Steam ID or vanity name
+func Player(steamIDOrVanity string) (*models.PlayerStatsWithAchievements, bool) {
+	loadOnce.Do(load)
+	if loadErr != nil {
+		return nil, false
+	}
+
+	steamID := steamIDOrVanity
+	if resolved, ok := byVanity[strings.ToLower(steamIDOrVanity)]; ok {
+		steamID = resolved
+	}
+
+	f, ok := byID[steamID]
+	if !ok {
+		return nil, false
+	}
+
+	// Copy so callers can decorate the response without touching the fixture
+	response := f.PlayerStatsWithAchievements
+	response.Demo = true
+	response.APIProvider = "demo"
+	return &response, true
+}
+
+// LoadedAt is when the fixtures were read; demo responses report it as their
+// fetch time so repeated requests validate as unchanged
+func LoadedAt() time.Time {
+	loadOnce.Do(load)
+	return loadedAt
+}
+
+// Players lists the Steam IDs and vanity names of all bundled fixtures
+func Players() []map[string]string {
+	loadOnce.Do(load)
+
+	players := make([]map[string]string, 0, len(byID))
+	for steamID, f := range byID {
+		players = append(players, map[string]string{
+			"steam_id":     steamID,
+			"vanity":       f.Vanity,
+			"display_name": f.DisplayName,
+		})
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i]["steam_id"] < players[j]["steam_id"]
+	})
+	return players
+}
+
+// LoadError reports whether the bundled fixtures failed to parse
+func LoadError() error {
+	loadOnce.Do(load)
+	return loadErr
+}
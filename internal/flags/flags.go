@@ -0,0 +1,279 @@
+// Package flags gates heavy response blocks so they can be rolled out to a
+// share of clients and switched off without a redeploy. Rules are read from a
+// JSON file that is re-read whenever it changes on disk.
+package flags
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// Known flags. Each defaults to fully enabled so an absent flags file keeps
+// the responses the API has always served.
+const (
+	Analytics   = "analytics"   // derived analytics block on player responses
+	Percentiles = "percentiles" // global achievement rarity percentages
+	Roadmap     = "roadmap"     // /player/{steamid}/roadmap endpoint
+)
+
+const defaultReloadInterval = 15 * time.Second
+
+// Rule decides who sees a flagged feature. Keys override the rollout for
+// specific API keys in either direction.
+type Rule struct {
+	Enabled bool            `json:"enabled"`
+	Percent *int            `json:"percent,omitempty"` // 0-100 share of clients while enabled; nil means all
+	Keys    map[string]bool `json:"keys,omitempty"`    // API key -> forced on/off
+}
+
+// Set is the live rule table. A nil *Set reports every known flag enabled.
+type Set struct {
+	mu       sync.RWMutex
+	rules    map[string]Rule
+	path     string
+	modTime  time.Time
+	loadedAt time.Time
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+func defaultRules() map[string]Rule {
+	return map[string]Rule{
+		Analytics:   {Enabled: true},
+		Percentiles: {Enabled: true},
+		Roadmap:     {Enabled: true},
+	}
+}
+
+// New builds a rule table from path, re-reading it every interval when its
+// modification time changes. An empty path serves the defaults only.
+func New(path string, interval time.Duration) (*Set, error) {
+	s := &Set{
+		rules:    defaultRules(),
+		path:     path,
+		loadedAt: time.Now(),
+		stopCh:   make(chan struct{}),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	if _, err := s.reload(); err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+	go s.watch(interval)
+	return s, nil
+}
+
+// FromEnv loads FEATURE_FLAGS_FILE, re-read every FEATURE_FLAGS_RELOAD_SECS.
+// A missing or malformed file falls back to the defaults rather than failing startup.
+func FromEnv() *Set {
+	interval := defaultReloadInterval
+	if raw := os.Getenv("FEATURE_FLAGS_RELOAD_SECS"); raw != "" {
+		if secs, err := strconv.Atoi(raw); err == nil && secs > 0 {
+			interval = time.Duration(secs) * time.Second
+		} else {
+			log.Warn("Invalid FEATURE_FLAGS_RELOAD_SECS, using default",
+				"value", raw,
+				"default", defaultReloadInterval)
+		}
+	}
+
+	s, err := New(os.Getenv("FEATURE_FLAGS_FILE"), interval)
+	if err != nil {
+		log.Error("Failed to load feature flags, serving defaults", "error", err)
+		s, _ = New("", 0)
+	}
+	return s
+}
+
+// Enabled reports whether a flag is on for a client. apiKey is matched against
+// per-key overrides; clientID (the API key or client IP) picks the client's
+// stable rollout bucket so the same client sees the same answer on every request.
+func (s *Set) Enabled(name, apiKey, clientID string) bool {
+	if s == nil {
+		_, known := defaultRules()[name]
+		return known
+	}
+
+	s.mu.RLock()
+	rule, ok := s.rules[name]
+	s.mu.RUnlock()
+	if !ok {
+		return false
+	}
+
+	if apiKey != "" {
+		if forced, found := rule.Keys[apiKey]; found {
+			return forced
+		}
+	}
+	if !rule.Enabled {
+		return false
+	}
+	if rule.Percent == nil || *rule.Percent >= 100 {
+		return true
+	}
+	return bucket(name, clientID) < *rule.Percent
+}
+
+// OffForAll reports whether a flag is off for every client: disabled or
+// rolled out to no one, with no API key forcing it on. Work that only feeds
+// the flagged block can then be skipped rather than stripped per client.
+func (s *Set) OffForAll(name string) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.RLock()
+	rule, ok := s.rules[name]
+	s.mu.RUnlock()
+	if !ok {
+		return true
+	}
+
+	for _, forced := range rule.Keys {
+		if forced {
+			return false
+		}
+	}
+	return !rule.Enabled || (rule.Percent != nil && *rule.Percent <= 0)
+}
+
+// Snapshot describes the current rules for status pages; override keys are
+// counted rather than listed so API keys are never echoed back.
+func (s *Set) Snapshot() map[string]interface{} {
+	if s == nil {
+		return map[string]interface{}{"source": "defaults"}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.rules))
+	for name := range s.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rules := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		rule := s.rules[name]
+		percent := 100
+		if rule.Percent != nil {
+			percent = *rule.Percent
+		}
+		rules[name] = map[string]interface{}{
+			"enabled":       rule.Enabled,
+			"percent":       percent,
+			"key_overrides": len(rule.Keys),
+		}
+	}
+
+	snapshot := map[string]interface{}{
+		"source":    "defaults",
+		"flags":     rules,
+		"loaded_at": s.loadedAt,
+	}
+	if s.path != "" {
+		snapshot["source"] = "file"
+	}
+	return snapshot
+}
+
+// Close stops watching the flags file
+func (s *Set) Close() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() { close(s.stopCh) })
+}
+
+func (s *Set) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			changed, err := s.reload()
+			if err != nil {
+				log.Warn("Failed to reload feature flags, keeping previous rules",
+					"path", s.path,
+					"error", err)
+				continue
+			}
+			if changed {
+				log.Info("Feature flags reloaded", "path", s.path)
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// reload re-reads the flags file when its modification time has moved. Flags
+// the file omits keep their defaults, so the file only needs to list changes.
+func (s *Set) reload() (bool, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, fmt.Errorf("stat feature flags file: %w", err)
+	}
+
+	s.mu.RLock()
+	unchanged := info.ModTime().Equal(s.modTime)
+	s.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return false, fmt.Errorf("read feature flags file: %w", err)
+	}
+
+	var parsed map[string]Rule
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return false, fmt.Errorf("parse feature flags file: %w", err)
+	}
+
+	rules := defaultRules()
+	for name, rule := range parsed {
+		if _, known := rules[name]; !known {
+			log.Warn("Ignoring unknown feature flag", "flag", name, "path", s.path)
+			continue
+		}
+		if rule.Percent != nil && (*rule.Percent < 0 || *rule.Percent > 100) {
+			return false, fmt.Errorf("flag %q: percent must be between 0 and 100, got %d", name, *rule.Percent)
+		}
+		rules[name] = rule
+	}
+
+	s.mu.Lock()
+	s.rules = rules
+	s.modTime = info.ModTime()
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return true, nil
+}
+
+// bucket maps a client to 0-99, salted by flag so rollouts are independent
+func bucket(name, clientID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(clientID))
+	return int(h.Sum32() % 100)
+}
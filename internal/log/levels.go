@@ -0,0 +1,230 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// levels holds the global level and per-module overrides. A module is the
+// last element of the logging package's import path: api, steam, cache, main.
+var levels = &levelState{}
+
+type levelState struct {
+	mu      sync.RWMutex
+	initial slog.Level
+	global  slog.Level
+	modules map[string]slog.Level
+
+	// floor is the lowest level anything logs at, so Enabled stays a cheap
+	// check before the caller's module is known
+	floor slog.Level
+
+	// revert undoes a timed change; each change replaces the previous timer
+	revert   *time.Timer
+	revertAt time.Time
+}
+
+func (s *levelState) init(level slog.Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.initial = level
+	s.global = level
+	s.modules = nil
+	s.stopRevertLocked()
+	s.refloorLocked()
+}
+
+func (s *levelState) refloorLocked() {
+	s.floor = s.global
+	for _, level := range s.modules {
+		if level < s.floor {
+			s.floor = level
+		}
+	}
+}
+
+func (s *levelState) stopRevertLocked() {
+	if s.revert != nil {
+		s.revert.Stop()
+		s.revert = nil
+	}
+	s.revertAt = time.Time{}
+}
+
+func (s *levelState) enabled(level slog.Level) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return level >= s.floor
+}
+
+func (s *levelState) levelFor(module string) slog.Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if level, ok := s.modules[module]; ok {
+		return level
+	}
+	return s.global
+}
+
+// LevelSettings is the current global level, the per-module overrides and,
+// after a timed change, when everything returns to the startup level
+type LevelSettings struct {
+	Global    string            `json:"global"`
+	Modules   map[string]string `json:"modules"`
+	Startup   string            `json:"startup"`
+	RevertsAt *time.Time        `json:"reverts_at,omitempty"`
+}
+
+// Levels reports the levels in effect
+func Levels() LevelSettings {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+	settings := LevelSettings{
+		Global:  levelName(levels.global),
+		Modules: make(map[string]string, len(levels.modules)),
+		Startup: levelName(levels.initial),
+	}
+	for module, level := range levels.modules {
+		settings.Modules[module] = levelName(level)
+	}
+	if !levels.revertAt.IsZero() {
+		at := levels.revertAt
+		settings.RevertsAt = &at
+	}
+	return settings
+}
+
+// SetLevel changes the global level, or one module's when module is set.
+// With a positive duration, every level returns to the startup level (LOG_LEVEL,
+// no overrides) once it elapses, so incident debugging can't be left on.
+func SetLevel(module string, level slog.Level, duration time.Duration) {
+	if Logger == nil {
+		Initialize()
+	}
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	if module == "" {
+		levels.global = level
+	} else {
+		if levels.modules == nil {
+			levels.modules = make(map[string]slog.Level)
+		}
+		levels.modules[module] = level
+	}
+	levels.refloorLocked()
+	levels.scheduleRevertLocked(duration)
+}
+
+// ResetLevel drops a module's override, or restores the startup level and
+// drops every override when module is empty
+func ResetLevel(module string) {
+	if Logger == nil {
+		Initialize()
+	}
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	if module == "" {
+		levels.global = levels.initial
+		levels.modules = nil
+		levels.stopRevertLocked()
+	} else {
+		delete(levels.modules, module)
+	}
+	levels.refloorLocked()
+}
+
+// ToggleDebug switches the global level between debug and the startup level,
+// for SIGUSR1, and returns the level now in effect. Module overrides are kept.
+func ToggleDebug() slog.Level {
+	if Logger == nil {
+		Initialize()
+	}
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	if levels.global == slog.LevelDebug {
+		levels.global = levels.initial
+		levels.stopRevertLocked()
+	} else {
+		levels.global = slog.LevelDebug
+	}
+	levels.refloorLocked()
+	return levels.global
+}
+
+func (s *levelState) scheduleRevertLocked(duration time.Duration) {
+	s.stopRevertLocked()
+	if duration <= 0 {
+		return
+	}
+	s.revertAt = time.Now().Add(duration)
+	var timer *time.Timer
+	timer = time.AfterFunc(duration, func() {
+		s.mu.Lock()
+		if s.revert != timer {
+			s.mu.Unlock()
+			return // replaced by a later change
+		}
+		s.global = s.initial
+		s.modules = nil
+		s.revert = nil
+		s.revertAt = time.Time{}
+		s.refloorLocked()
+		s.mu.Unlock()
+		Info("Log levels reverted to startup level", "level", levelName(s.initial))
+	})
+	s.revert = timer
+}
+
+// levelName is the lower-case name ParseLevel accepts
+func levelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// moduleHandler filters records by the level of the module that logged them
+type moduleHandler struct {
+	next slog.Handler
+}
+
+func (h moduleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return levels.enabled(level)
+}
+
+func (h moduleHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level < levels.levelFor(moduleOf(record.PC)) {
+		return nil
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h moduleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return moduleHandler{h.next.WithAttrs(attrs)}
+}
+
+func (h moduleHandler) WithGroup(name string) slog.Handler {
+	return moduleHandler{h.next.WithGroup(name)}
+}
+
+// pcModules caches moduleOf by program counter
+var pcModules sync.Map
+
+// moduleOf is the last import path element of the package containing pc
+func moduleOf(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	if module, ok := pcModules.Load(pc); ok {
+		return module.(string)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	name := frame.Function // e.g. github.com/x/y/internal/steam.(*Client).Get
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+	module, _, _ := strings.Cut(name, ".")
+	pcModules.Store(pc, module)
+	return module
+}
@@ -1,67 +1,86 @@
 package log
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"os"
+	"runtime"
 	"strings"
+	"time"
 )
 
 var Logger *slog.Logger
 
 func Initialize() {
-	logLevel := getLogLevel()
+	levels.init(getLogLevel())
 
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level:     logLevel,
+	// The JSON handler passes everything; moduleHandler applies the runtime levels
+	logger := slog.New(moduleHandler{slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level:     slog.LevelDebug,
 		AddSource: true,
-	}))
+	})})
 
 	Logger = logger
 	slog.SetDefault(logger)
 }
 
 func getLogLevel() slog.Level {
-	level := strings.ToLower(os.Getenv("LOG_LEVEL"))
-	switch level {
-	case "debug":
-		return slog.LevelDebug
-	case "info":
+	level, err := ParseLevel(os.Getenv("LOG_LEVEL"))
+	if err != nil {
 		return slog.LevelInfo
+	}
+	return level
+}
+
+// ParseLevel reads a level name: debug, info, warn (or warning) or error.
+// An empty name is info.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
 	case "warn", "warning":
-		return slog.LevelWarn
+		return slog.LevelWarn, nil
 	case "error":
-		return slog.LevelError
+		return slog.LevelError, nil
 	default:
-		return slog.LevelInfo
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q; use debug, info, warn or error", name)
 	}
 }
 
 func Info(msg string, args ...any) {
-	if Logger == nil {
-		Initialize()
-	}
-	Logger.Info(msg, args...)
+	logAt(slog.LevelInfo, msg, args)
 }
 
 func Warn(msg string, args ...any) {
-	if Logger == nil {
-		Initialize()
-	}
-	Logger.Warn(msg, args...)
+	logAt(slog.LevelWarn, msg, args)
 }
 
 func Error(msg string, args ...any) {
-	if Logger == nil {
-		Initialize()
-	}
-	Logger.Error(msg, args...)
+	logAt(slog.LevelError, msg, args)
 }
 
 func Debug(msg string, args ...any) {
+	logAt(slog.LevelDebug, msg, args)
+}
+
+// logAt logs with the PC of the wrapper's caller, so source and the module
+// levels see the package that logged rather than this one
+func logAt(level slog.Level, msg string, args []any) {
 	if Logger == nil {
 		Initialize()
 	}
-	Logger.Debug(msg, args...)
+	ctx := context.Background()
+	if !Logger.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip Callers, logAt and the exported wrapper
+	record := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	record.Add(args...)
+	_ = Logger.Handler().Handle(ctx, record)
 }
 
 func WithContext(args ...any) *slog.Logger {
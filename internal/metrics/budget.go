@@ -0,0 +1,128 @@
+// Package metrics keeps instance-level instability counters (recovered panics,
+// 5xx responses, upstream failures) over rolling windows for the status endpoint.
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// Signal is a kind of instability event
+type Signal int
+
+const (
+	Requests Signal = iota
+	ServerErrors
+	Panics
+	UpstreamFailures
+	signalCount
+)
+
+// bucketWidth is the resolution of the rolling windows; the ring covers 24h
+const (
+	bucketWidth = time.Minute
+	bucketCount = 24 * 60
+)
+
+type bucket struct {
+	minute int64 // unix minute this bucket currently holds
+	counts [signalCount]int64
+}
+
+// ErrorBudget counts signals per minute in a 24h ring so any window up to a day
+// can be summed without keeping individual events
+type ErrorBudget struct {
+	mu      sync.Mutex
+	buckets [bucketCount]bucket
+	totals  [signalCount]int64
+	now     func() time.Time
+}
+
+// NewErrorBudget creates an empty budget
+func NewErrorBudget() *ErrorBudget {
+	return &ErrorBudget{now: time.Now}
+}
+
+// Record adds one occurrence of a signal at the current time
+func (b *ErrorBudget) Record(signal Signal) {
+	minute := b.now().Unix() / int64(bucketWidth/time.Second)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	slot := &b.buckets[minute%bucketCount]
+	if slot.minute != minute {
+		*slot = bucket{minute: minute}
+	}
+	slot.counts[signal]++
+	b.totals[signal]++
+}
+
+// WindowCounts summarises one rolling window
+type WindowCounts struct {
+	Requests         int64   `json:"requests"`
+	ServerErrors     int64   `json:"server_errors"`
+	Panics           int64   `json:"panics"`
+	UpstreamFailures int64   `json:"upstream_failures"`
+	ServerErrorRate  float64 `json:"server_error_rate"` // server errors / requests, 0-1
+}
+
+// Window sums the last d (at most 24h, minute resolution)
+func (b *ErrorBudget) Window(d time.Duration) WindowCounts {
+	minutes := int64(d / bucketWidth)
+	if minutes > bucketCount {
+		minutes = bucketCount
+	}
+	current := b.now().Unix() / int64(bucketWidth/time.Second)
+
+	b.mu.Lock()
+	var counts [signalCount]int64
+	for _, slot := range b.buckets {
+		if slot.minute > current-minutes && slot.minute <= current {
+			for i := range counts {
+				counts[i] += slot.counts[i]
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	return newWindowCounts(counts)
+}
+
+// Snapshot returns the 1h and 24h windows plus lifetime totals
+func (b *ErrorBudget) Snapshot() map[string]WindowCounts {
+	b.mu.Lock()
+	totals := b.totals
+	b.mu.Unlock()
+
+	return map[string]WindowCounts{
+		"1h":       b.Window(time.Hour),
+		"24h":      b.Window(24 * time.Hour),
+		"lifetime": newWindowCounts(totals),
+	}
+}
+
+func newWindowCounts(counts [signalCount]int64) WindowCounts {
+	w := WindowCounts{
+		Requests:         counts[Requests],
+		ServerErrors:     counts[ServerErrors],
+		Panics:           counts[Panics],
+		UpstreamFailures: counts[UpstreamFailures],
+	}
+	if w.Requests > 0 {
+		w.ServerErrorRate = float64(w.ServerErrors) / float64(w.Requests)
+	}
+	return w
+}
+
+var defaultBudget = NewErrorBudget()
+
+// Default returns the process-wide budget
+func Default() *ErrorBudget {
+	return defaultBudget
+}
+
+// Record adds a signal to the process-wide budget
+func Record(signal Signal) {
+	defaultBudget.Record(signal)
+}
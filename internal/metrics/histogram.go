@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the request latency histogram
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Exemplar links one observation to the trace that produced it
+type Exemplar struct {
+	TraceID   string
+	Value     float64
+	Timestamp time.Time
+}
+
+type histogram struct {
+	counts    []uint64 // per bucket, plus +Inf at the end
+	exemplars []*Exemplar
+	sum       float64
+	count     uint64
+}
+
+// LatencyHistograms keeps one request latency histogram per route. Each bucket
+// remembers the most recent traced observation as its exemplar.
+type LatencyHistograms struct {
+	mu     sync.Mutex
+	routes map[string]*histogram
+}
+
+// NewLatencyHistograms creates an empty set
+func NewLatencyHistograms() *LatencyHistograms {
+	return &LatencyHistograms{routes: make(map[string]*histogram)}
+}
+
+// Observe records a request duration; traceID may be empty
+func (l *LatencyHistograms) Observe(route string, d time.Duration, traceID string) {
+	seconds := d.Seconds()
+	idx := sort.SearchFloat64s(latencyBuckets, seconds)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h := l.routes[route]
+	if h == nil {
+		h = &histogram{
+			counts:    make([]uint64, len(latencyBuckets)+1),
+			exemplars: make([]*Exemplar, len(latencyBuckets)+1),
+		}
+		l.routes[route] = h
+	}
+	h.counts[idx]++
+	h.sum += seconds
+	h.count++
+	if traceID != "" {
+		h.exemplars[idx] = &Exemplar{TraceID: traceID, Value: seconds, Timestamp: time.Now()}
+	}
+}
+
+// WriteOpenMetrics renders the histograms in OpenMetrics text format, with
+// exemplars on the buckets that have one
+func (l *LatencyHistograms) WriteOpenMetrics(w io.Writer) error {
+	const name = "dbd_http_request_duration_seconds"
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	routes := make([]string, 0, len(l.routes))
+	for route := range l.routes {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	if _, err := fmt.Fprintf(w, "# TYPE %s histogram\n# UNIT %s seconds\n# HELP %s Request latency by route.\n", name, name, name); err != nil {
+		return err
+	}
+	for _, route := range routes {
+		h := l.routes[route]
+		var cumulative uint64
+		for i, count := range h.counts {
+			cumulative += count
+			le := "+Inf"
+			if i < len(latencyBuckets) {
+				le = strconv.FormatFloat(latencyBuckets[i], 'f', -1, 64)
+			}
+			line := fmt.Sprintf("%s_bucket{route=%q,le=%q} %d", name, route, le, cumulative)
+			if ex := h.exemplars[i]; ex != nil {
+				line += fmt.Sprintf(" # {trace_id=%q} %s %.3f", ex.TraceID,
+					strconv.FormatFloat(ex.Value, 'f', -1, 64),
+					float64(ex.Timestamp.UnixMilli())/1000)
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_sum{route=%q} %s\n%s_count{route=%q} %d\n",
+			name, route, strconv.FormatFloat(h.sum, 'f', -1, 64), name, route, h.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var defaultLatency = NewLatencyHistograms()
+
+// ObserveLatency records a request duration in the process-wide histograms
+func ObserveLatency(route string, d time.Duration, traceID string) {
+	defaultLatency.Observe(route, d, traceID)
+}
+
+// WriteOpenMetrics renders the process-wide metrics, terminated by # EOF
+func WriteOpenMetrics(w io.Writer) error {
+	if err := defaultLatency.WriteOpenMetrics(w); err != nil {
+		return err
+	}
+	if err := writeRegistry(w); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "# EOF\n")
+	return err
+}
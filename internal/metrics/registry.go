@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Counter is a monotonically increasing process-wide count
+type Counter struct {
+	name  string
+	help  string
+	value atomic.Int64
+}
+
+// Add increases the counter by n
+func (c *Counter) Add(n int64) {
+	c.value.Add(n)
+}
+
+// Value returns the current count
+func (c *Counter) Value() int64 {
+	return c.value.Load()
+}
+
+// Gauge is a process-wide value that goes up and down
+type Gauge struct {
+	name  string
+	help  string
+	value atomic.Int64
+}
+
+// Add changes the gauge by n, which may be negative
+func (g *Gauge) Add(n int64) {
+	g.value.Add(n)
+}
+
+// Set replaces the gauge's value
+func (g *Gauge) Set(n int64) {
+	g.value.Store(n)
+}
+
+// Value returns the current level
+func (g *Gauge) Value() int64 {
+	return g.value.Load()
+}
+
+var registry struct {
+	mu       sync.Mutex
+	counters []*Counter
+	gauges   []*Gauge
+}
+
+// NewCounter registers a counter exported as <name>_total on the metrics endpoint
+func NewCounter(name, help string) *Counter {
+	c := &Counter{name: name, help: help}
+	registry.mu.Lock()
+	registry.counters = append(registry.counters, c)
+	registry.mu.Unlock()
+	return c
+}
+
+// NewGauge registers a gauge exported on the metrics endpoint
+func NewGauge(name, help string) *Gauge {
+	g := &Gauge{name: name, help: help}
+	registry.mu.Lock()
+	registry.gauges = append(registry.gauges, g)
+	registry.mu.Unlock()
+	return g
+}
+
+// writeRegistry renders registered counters and gauges plus the live goroutine count
+func writeRegistry(w io.Writer) error {
+	registry.mu.Lock()
+	counters := append([]*Counter(nil), registry.counters...)
+	gauges := append([]*Gauge(nil), registry.gauges...)
+	registry.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# TYPE dbd_goroutines gauge\n# HELP dbd_goroutines Goroutines currently running.\ndbd_goroutines %d\n",
+		runtime.NumGoroutine()); err != nil {
+		return err
+	}
+	for _, c := range counters {
+		if _, err := fmt.Fprintf(w, "# TYPE %s counter\n# HELP %s %s\n%s_total %d\n", c.name, c.name, c.help, c.name, c.Value()); err != nil {
+			return err
+		}
+	}
+	for _, g := range gauges {
+		if _, err := fmt.Fprintf(w, "# TYPE %s gauge\n# HELP %s %s\n%s %d\n", g.name, g.name, g.help, g.name, g.Value()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// RollingCounter counts events per minute over the last 24h
+type RollingCounter struct {
+	mu      sync.Mutex
+	minutes [bucketCount]int64 // unix minute each slot holds
+	counts  [bucketCount]int64
+	now     func() time.Time
+}
+
+// NewRollingCounter creates an empty counter
+func NewRollingCounter() *RollingCounter {
+	return &RollingCounter{now: time.Now}
+}
+
+// Add records n events at the current time
+func (c *RollingCounter) Add(n int64) {
+	minute := c.now().Unix() / int64(bucketWidth/time.Second)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	slot := minute % bucketCount
+	if c.minutes[slot] != minute {
+		c.minutes[slot] = minute
+		c.counts[slot] = 0
+	}
+	c.counts[slot] += n
+}
+
+// Sum returns the events recorded in the last d (at most 24h, minute resolution)
+func (c *RollingCounter) Sum(d time.Duration) int64 {
+	minutes := int64(d / bucketWidth)
+	if minutes > bucketCount {
+		minutes = bucketCount
+	}
+	current := c.now().Unix() / int64(bucketWidth/time.Second)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var total int64
+	for i, minute := range c.minutes {
+		if minute > current-minutes && minute <= current {
+			total += c.counts[i]
+		}
+	}
+	return total
+}
@@ -33,28 +33,81 @@ type AchievementSummary struct {
 	UnlockedCount     int      `json:"unlocked_count"`
 	SurvivorCount     int      `json:"survivor_count"`
 	KillerCount       int      `json:"killer_count"`
+	UnknownAdeptCount int      `json:"unknown_adept_count,omitempty"` // strict mode: adepts typed adept_unknown
 	GeneralCount      int      `json:"general_count"`
 	AdeptSurvivors    []string `json:"adept_survivors"`
 	AdeptKillers      []string `json:"adept_killers"`
 	CompletionRate    float64  `json:"completion_rate"`
+
+	// Where the achievement schema came from: "steam", "disk" (the last copy
+	// Steam served, during an outage) or "hardcoded" (known adepts only)
+	SchemaSource string `json:"schema_source,omitempty"`
 }
 
+// Values of PlayerStatsWithAchievements.AchievementsState
+const (
+	AchievementsOK          = "ok"          // fetched, at least one achievement unlocked
+	AchievementsEmpty       = "empty"       // fetched, nothing unlocked yet
+	AchievementsPrivate     = "private"     // the profile hides game details
+	AchievementsUnavailable = "unavailable" // Steam failed; may recover on a later request
+	AchievementsPending     = "pending"     // progressive responses: a later document carries them
+)
+
 // PlayerStatsWithAchievements represents the response with both stats and achievements
 type PlayerStatsWithAchievements struct {
 	PlayerStats
 
-	Achievements *AchievementData `json:"achievements,omitempty"`
+	// Achievements is omitted unless AchievementsState is ok or empty, so a
+	// missing object never reads as "no adepts"
+	Achievements      *AchievementData `json:"achievements,omitempty"`
+	AchievementsState string           `json:"achievements_state"`
 
 	// Structured stats data using schema as source of truth
 	Stats *StatsData `json:"stats,omitempty"`
 
+	// Limited-time event stats, moved out of Stats; ended events only with ?include=events
+	Events []EventStats `json:"events,omitempty"`
+
+	// All-time best grades from the player store; live grades reset monthly
+	PeakGrades *PeakGrades `json:"peak_grades,omitempty"`
+
+	// Derived analytics (hook rates, power specialization, play style)
+	Analytics *PlayerAnalytics `json:"analytics,omitempty"`
+
+	// Heuristic integrity review flags (opt-in per deployment)
+	IntegrityFlags []IntegrityFlag `json:"integrity_flags,omitempty"`
+
 	// Data source tracking
 	DataSources DataSourceStatus `json:"data_sources"`
 
+	// Demo marks fixture data served when no Steam API key is configured
+	Demo bool `json:"demo,omitempty"`
+
+	// ResolvedAs says whether the request identifier was used as a SteamID64 or resolved as a vanity URL
+	ResolvedAs string `json:"resolved_as,omitempty"`
+
 	APIProvider   string    `json:"api_provider"`
 	SchemaVersion string    `json:"schema_version"`
 	CacheHit      bool      `json:"cache_hit"`
 	LastUpdated   time.Time `json:"last_updated"`
+
+	// Seconds since the oldest source was fetched from Steam, also sent as the
+	// Age header; set per request and never cached
+	DataAgeSeconds int64 `json:"data_age_seconds"`
+
+	// Where this request's time went, with ?include=timing; never cached
+	Timing *ResponseTiming `json:"timing,omitempty"`
+}
+
+// ResponseTiming is a request's latency budget in milliseconds. The Steam
+// fetches run in parallel, so the phases can add up to more than TotalMs.
+type ResponseTiming struct {
+	CacheMs        float64 `json:"cache_ms"`
+	SteamSummaryMs float64 `json:"steam_summary_ms"`
+	SteamStatsMs   float64 `json:"steam_stats_ms"`
+	SteamAchMs     float64 `json:"steam_ach_ms"`
+	MappingMs      float64 `json:"mapping_ms"`
+	TotalMs        float64 `json:"total_ms"`
 }
 
 // StatsData represents structured player statistics
@@ -75,4 +128,8 @@ type DataSourceInfo struct {
 	Source    string    `json:"source"` // "cache" | "api" | "fallback"
 	Error     string    `json:"error,omitempty"`
 	FetchedAt time.Time `json:"fetched_at"`
+
+	// Set when Steam was failing and an expired cache entry was served instead
+	Stale           bool  `json:"stale,omitempty"`
+	StaleAgeSeconds int64 `json:"stale_age_seconds,omitempty"`
 }
@@ -0,0 +1,65 @@
+package models
+
+// PlayerAnalytics holds insights derived from raw stat counters. Ratios are 0
+// when their denominator is zero (e.g. a player who has never played killer).
+type PlayerAnalytics struct {
+	Killer   *KillerAnalytics   `json:"killer,omitempty"`
+	Survivor *SurvivorAnalytics `json:"survivor,omitempty"`
+}
+
+// KillerAnalytics describes hook rate, power specialization and end-game pressure
+type KillerAnalytics struct {
+	Eliminations        int     `json:"eliminations"`            // sacrifices + mori kills
+	HooksPerSacrifice   float64 `json:"hooks_per_sacrifice"`     // hooks / sacrifices; 3.0 means every hook state was used
+	HooksPerElimination float64 `json:"hooks_per_elimination"`   // hooks / eliminations
+	MoriShare           float64 `json:"mori_share"`              // mori kills / eliminations
+	HitsNearHookPerHook float64 `json:"hits_near_hook_per_hook"` // hits near hooks / hooks; high values suggest hook-proxy play
+
+	// BasementHookRatio is only present when the schema exposes basement hook counters
+	BasementHookRatio *float64 `json:"basement_hook_ratio,omitempty"`
+
+	Specializations []PowerSpecialization `json:"specializations"`
+	FourKPressure   FourKPressure         `json:"four_k_pressure"`
+}
+
+// PowerSpecialization measures how heavily a killer power stat features in a player's history
+type PowerSpecialization struct {
+	Power                  string  `json:"power"`
+	StatID                 string  `json:"stat_id"`
+	Count                  int     `json:"count"`
+	PerHundredEliminations float64 `json:"per_hundred_eliminations"`
+	Index                  float64 `json:"index"` // share of all tracked power events, 0-1
+}
+
+// FourKPressure estimates how often games end with every survivor eliminated
+type FourKPressure struct {
+	LobbyEquivalents float64 `json:"lobby_equivalents"` // eliminations / 4
+	PowerFourKs      int     `json:"power_four_ks"`     // 4Ks where every survivor was downed by the power
+	PerfectGames     int     `json:"perfect_games"`     // killer perfect-score games
+	PowerFourKRate   float64 `json:"power_four_k_rate"` // power 4Ks / lobby equivalents
+	PerfectGameRate  float64 `json:"perfect_game_rate"` // perfect games / lobby equivalents
+	PressureScore    float64 `json:"pressure_score"`    // 0-1 blend of the two rates
+	PressureRating   string  `json:"pressure_rating"`   // "low" | "moderate" | "high"
+}
+
+// SurvivorAnalytics profiles a survivor's altruism and how they tend to survive
+type SurvivorAnalytics struct {
+	Escapes             int     `json:"escapes"`
+	AltruisticActions   int     `json:"altruistic_actions"`    // unhooks/heals + post-exit saves
+	AltruismPerEscape   float64 `json:"altruism_per_escape"`   // altruistic actions / escapes
+	AltruismScore       float64 `json:"altruism_score"`        // actions / (actions + escapes), 0-1
+	AltruismRating      string  `json:"altruism_rating"`       // "selfish" | "balanced" | "altruist"
+	ObjectiveFocus      float64 `json:"objective_focus"`       // generators / (generators + heals), 0-1
+	MapObjectiveRepairs int     `json:"map_objective_repairs"` // map-specific generator stats (second floors etc.)
+
+	Stealth SurvivalIndicators `json:"stealth"`
+	Chase   SurvivalIndicators `json:"chase"`
+
+	PlayStyle string `json:"play_style"` // "stealth" | "chase" | "balanced" | "unknown"
+}
+
+// SurvivalIndicators are escape-share signals pointing at one survival style
+type SurvivalIndicators struct {
+	Index   float64            `json:"index"`   // mean of the signals, 0-1
+	Signals map[string]float64 `json:"signals"` // individual shares of total escapes
+}
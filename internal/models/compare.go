@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// AchievementComparison splits two players' unlocks into what both have and
+// what only one has, with rarity-weighted bragging rights
+type AchievementComparison struct {
+	Players        [2]ComparedPlayer  `json:"players"`
+	Achievements   AchievementOverlap `json:"achievements"`
+	Adepts         AdeptOverlap       `json:"adepts"`
+	BraggingRights BraggingRights     `json:"bragging_rights"`
+	Warnings       []string           `json:"warnings,omitempty"`
+	Demo           bool               `json:"demo,omitempty"`
+	GeneratedAt    time.Time          `json:"generated_at"`
+}
+
+// ComparedPlayer is one side of a comparison
+type ComparedPlayer struct {
+	SteamID        string  `json:"steam_id"`
+	DisplayName    string  `json:"display_name,omitempty"`
+	UnlockedCount  int     `json:"unlocked_count"`
+	AdeptCount     int     `json:"adept_count"`
+	RarityScore    float64 `json:"rarity_score"`    // points for every unlock
+	ExclusiveScore float64 `json:"exclusive_score"` // points for unlocks the other player lacks
+}
+
+// AchievementOverlap lists achievements by who has unlocked them, rarest first
+type AchievementOverlap struct {
+	Both  []ComparedAchievement `json:"both"`
+	OnlyA []ComparedAchievement `json:"only_a"`
+	OnlyB []ComparedAchievement `json:"only_b"`
+}
+
+type ComparedAchievement struct {
+	ID          string  `json:"id"`
+	DisplayName string  `json:"display_name"`
+	Type        string  `json:"type"`
+	Rarity      float64 `json:"rarity,omitempty"` // 0-100 global completion percentage
+	Points      float64 `json:"points"`
+}
+
+// AdeptOverlap lists adept characters by who has unlocked them
+type AdeptOverlap struct {
+	Survivors CharacterOverlap `json:"survivors"`
+	Killers   CharacterOverlap `json:"killers"`
+}
+
+type CharacterOverlap struct {
+	Both  []string `json:"both"`
+	OnlyA []string `json:"only_a"`
+	OnlyB []string `json:"only_b"`
+}
+
+// BraggingRights goes to the player whose exclusive unlocks are worth more
+type BraggingRights struct {
+	Winner string  `json:"winner,omitempty"` // Steam ID; empty on a tie
+	Margin float64 `json:"margin"`
+	Reason string  `json:"reason"`
+}
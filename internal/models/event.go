@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// EventStats groups the stats of one limited-time event in a player response
+type EventStats struct {
+	ID     string     `json:"id"`
+	Name   string     `json:"name"`
+	Starts time.Time  `json:"starts_at"`
+	Ends   *time.Time `json:"ends_at,omitempty"` // nil while the event has no announced end
+	Active bool       `json:"active"`
+
+	// Mapped stats counted by the event, in MapPlayerStats order
+	Stats []interface{} `json:"stats"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// PeakGrades is the best grade ever observed per role. Live grades reset every
+// month, so these are remembered by the player store across resets.
+type PeakGrades struct {
+	Killer   *GradePeak `json:"killer,omitempty"`
+	Survivor *GradePeak `json:"survivor,omitempty"`
+}
+
+type GradePeak struct {
+	Grade      string    `json:"grade"`       // e.g. "Iridescent I"
+	Rank       int       `json:"rank"`        // 0 (Ash IV) to 19 (Iridescent I)
+	AchievedAt time.Time `json:"achieved_at"` // first observation at this grade
+}
+
+// Better reports whether p is a higher grade than other; nil is lowest
+func (p *GradePeak) Better(other *GradePeak) bool {
+	return p != nil && (other == nil || p.Rank > other.Rank)
+}
+
+// gradeResetDay is the day of the month Dead by Daylight resets grades
+const gradeResetDay = 13
+
+// GradeSeason names the grade period t falls in as YYYY-MM, after the month
+// whose reset started it: 2026-10 runs from 13 October to 13 November (UTC)
+func GradeSeason(t time.Time) string {
+	t = t.UTC()
+	if t.Day() < gradeResetDay {
+		t = t.AddDate(0, 0, -gradeResetDay) // back into the previous month
+	}
+	return t.Format("2006-01")
+}
+
+// GradeSeasonBounds returns the [start, end) of a YYYY-MM grade season
+func GradeSeasonBounds(season string) (start, end time.Time, err error) {
+	month, err := time.Parse("2006-01", season)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	start = time.Date(month.Year(), month.Month(), gradeResetDay, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// GradeContext is the grade distribution across the players this API knows,
+// for showing one player's grade against the population
+type GradeContext struct {
+	Season      string            `json:"season,omitempty"` // empty: every player's latest grade, any season
+	Scope       string            `json:"scope"`            // "all" or "tracked"
+	Players     int               `json:"players"`          // players with a snapshot in range
+	Killer      GradeDistribution `json:"killer"`
+	Survivor    GradeDistribution `json:"survivor"`
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+type GradeDistribution struct {
+	Total  int           `json:"total"`
+	Median string        `json:"median,omitempty"`
+	Grades []GradeBucket `json:"grades"` // Ash IV first
+}
+
+type GradeBucket struct {
+	Grade        string  `json:"grade"`
+	Rank         int     `json:"rank"`
+	Count        int     `json:"count"`
+	Percent      float64 `json:"percent"`
+	BelowPercent float64 `json:"below_percent"` // share of players at a lower grade
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// GroupStats is a team dashboard for a named group of players
+type GroupStats struct {
+	ID           string                        `json:"id"`
+	Name         string                        `json:"name"`
+	Members      []GroupMember                 `json:"members"`
+	Aggregate    GroupAggregate                `json:"aggregate"`
+	Leaderboards map[string][]GroupLeaderboard `json:"leaderboards"` // metric -> members, best first
+	Warnings     []string                      `json:"warnings,omitempty"`
+	GeneratedAt  time.Time                     `json:"generated_at"`
+}
+
+// GroupMember is one player's line on the dashboard. Available is false when
+// their stats could not be fetched; such members are left out of the
+// aggregate and leaderboards.
+type GroupMember struct {
+	SteamID        string  `json:"steam_id"`
+	DisplayName    string  `json:"display_name,omitempty"`
+	Avatar         string  `json:"avatar,omitempty"`
+	Available      bool    `json:"available"`
+	Escapes        int     `json:"escapes"`
+	TotalMatches   int     `json:"total_matches"`
+	EscapeRate     float64 `json:"escape_rate"` // escapes / matches played in any role, 0-1
+	Sacrifices     int     `json:"sacrifices"`
+	Kills          int     `json:"kills"`
+	KillerGrade    string  `json:"killer_grade,omitempty"`
+	SurvivorGrade  string  `json:"survivor_grade,omitempty"`
+	KillerPips     int     `json:"killer_pips"`
+	SurvivorPips   int     `json:"survivor_pips"`
+	BloodwebPoints int     `json:"bloodweb_points"`
+	HoursPlayed    int     `json:"hours_played"`
+	AdeptsUnlocked int     `json:"adepts_unlocked"`
+}
+
+type GroupAggregate struct {
+	Members           int     `json:"members"`
+	AvailableMembers  int     `json:"available_members"`
+	Escapes           int     `json:"escapes"`
+	TotalMatches      int     `json:"total_matches"`
+	EscapeRate        float64 `json:"escape_rate"`         // pooled: Σ escapes / Σ matches
+	AverageEscapeRate float64 `json:"average_escape_rate"` // mean of member rates
+	Sacrifices        int     `json:"sacrifices"`
+	Kills             int     `json:"kills"`
+	BloodwebPoints    int     `json:"bloodweb_points"`
+	HoursPlayed       int     `json:"hours_played"`
+	TotalAdepts       int     `json:"total_adepts"`    // Σ member adepts
+	DistinctAdepts    int     `json:"distinct_adepts"` // characters adepted by at least one member
+}
+
+// GroupLeaderboard places one member on one metric; tied members share a rank
+type GroupLeaderboard struct {
+	Rank        int     `json:"rank"`
+	SteamID     string  `json:"steam_id"`
+	DisplayName string  `json:"display_name"`
+	Value       float64 `json:"value"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// IntegrityFlag is a heuristic marker raised when a player's stats look implausible.
+// Flags are advisory only and intended for manual review by tournament admins.
+type IntegrityFlag struct {
+	Code      string  `json:"code"`
+	Severity  string  `json:"severity"` // "low" | "medium" | "high"
+	Message   string  `json:"message"`
+	Observed  float64 `json:"observed,omitempty"`
+	Threshold float64 `json:"threshold,omitempty"`
+}
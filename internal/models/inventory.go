@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// PlayerInventory lists a player's public Steam inventory items for Dead by Daylight
+type PlayerInventory struct {
+	SteamID    string          `json:"steam_id"`
+	Items      []InventoryItem `json:"items"`
+	Summary    map[string]int  `json:"summary"` // category -> owned count
+	TotalCount int             `json:"total_count"`
+	Truncated  bool            `json:"truncated,omitempty"` // more items exist beyond the page cap
+
+	Demo        bool      `json:"demo,omitempty"`
+	CacheHit    bool      `json:"cache_hit"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+type InventoryItem struct {
+	AssetID    string `json:"asset_id"`
+	ClassID    string `json:"class_id"`
+	Name       string `json:"name"`
+	Type       string `json:"type,omitempty"` // item type line from Steam
+	Category   string `json:"category"`       // "charm" | "outfit" | "other"
+	Icon       string `json:"icon,omitempty"`
+	Amount     int    `json:"amount"`
+	Tradable   bool   `json:"tradable"`
+	Marketable bool   `json:"marketable"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// PlayerMaps groups a player's per-map stats by realm and map, with how
+// many of each map's stats the player has progressed
+type PlayerMaps struct {
+	SteamID     string `json:"steam_id"`
+	DisplayName string `json:"display_name"`
+	Avatar      string `json:"avatar,omitempty"`
+
+	Realms        []RealmStats `json:"realms"`
+	MapsTotal     int          `json:"maps_total"`
+	MapsCompleted int          `json:"maps_completed"` // maps with every stat above zero
+
+	DataSources    DataSourceStatus `json:"data_sources"`
+	Demo           bool             `json:"demo,omitempty"`
+	ResolvedAs     string           `json:"resolved_as,omitempty"`
+	LastUpdated    time.Time        `json:"last_updated"`
+	DataAgeSeconds int64            `json:"data_age_seconds"`
+	Timing         *ResponseTiming  `json:"timing,omitempty"`
+}
+
+// RealmStats is one realm's maps, ordered by their first stat
+type RealmStats struct {
+	ID         string     `json:"id"` // realm code in stat IDs, e.g. "Asy"
+	Name       string     `json:"name"`
+	Maps       []MapStats `json:"maps"`
+	Completion float64    `json:"completion"` // 0-100 share of the realm's stats above zero
+}
+
+// MapStats is one map's stats. Identified is false for maps the service
+// can't name yet, whose name is the code from the stat ID.
+type MapStats struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Identified bool    `json:"identified"`
+	Progressed int     `json:"progressed"` // stats above zero
+	Total      int     `json:"total"`
+	Completion float64 `json:"completion"` // 0-100 share of Total progressed
+	Completed  bool    `json:"completed"`
+
+	// Mapped stats counted on the map, in MapPlayerStats order
+	Stats []interface{} `json:"stats"`
+}
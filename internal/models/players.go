@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Per-player error categories in a multi-player lookup; stats failures use
+// the error classification of the single-player endpoints
+const (
+	BatchPlayerNotFound     = "not_found"           // Steam knows no account with this ID
+	BatchSummaryUnavailable = "summary_unavailable" // the batched summary call failed
+	BatchNotCached          = "not_cached"          // Steam is being spared and the cache doesn't hold the player
+)
+
+// PlayerSummary is a player's public Steam profile
+type PlayerSummary struct {
+	SteamID     string `json:"steam_id"`
+	DisplayName string `json:"display_name"`
+	Avatar      string `json:"avatar,omitempty"`
+	AvatarFull  string `json:"avatar_full,omitempty"`
+}
+
+// BatchPlayer is one requested player in a multi-player lookup. Summary is
+// nil when the account couldn't be found or summarised; Stats is nil when
+// they couldn't be fetched, typically for a private profile, and Error says
+// why.
+type BatchPlayer struct {
+	SteamID string         `json:"steam_id"`
+	Summary *PlayerSummary `json:"summary,omitempty"`
+	Stats   *PlayerStats   `json:"stats,omitempty"`
+	Source  string         `json:"source,omitempty"` // of stats: cache or api
+	Error   string         `json:"error,omitempty"`
+}
+
+// BatchPlayers answers a multi-player lookup, players in request order
+type BatchPlayers struct {
+	Players     []BatchPlayer `json:"players"`
+	Requested   int           `json:"requested"`
+	Found       int           `json:"found"`                // players with a summary
+	WithStats   int           `json:"with_stats"`           // players with stats
+	CacheOnly   string        `json:"cache_only,omitempty"` // why Steam wasn't called: circuit_open, quota_elevated or quota_critical
+	Demo        bool          `json:"demo,omitempty"`
+	GeneratedAt time.Time     `json:"generated_at"`
+}
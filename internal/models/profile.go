@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// ProfileStats combines the Steam accounts linked to one profile: each
+// account's headline stats, and totals across them as one player
+type ProfileStats struct {
+	ID          string           `json:"id"`
+	Accounts    []ProfileAccount `json:"accounts"`
+	Combined    ProfileCombined  `json:"combined"`
+	Warnings    []string         `json:"warnings,omitempty"`
+	GeneratedAt time.Time        `json:"generated_at"`
+}
+
+// ProfileAccount is one linked account's line, shaped like a group member's.
+// Available is false when its stats could not be fetched; such accounts are
+// left out of the combined totals.
+type ProfileAccount struct {
+	GroupMember
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+// ProfileCombined totals a profile's available accounts. Counters are summed;
+// grades are the best any account holds, and adepts count each character once.
+type ProfileCombined struct {
+	Accounts          int      `json:"accounts"`
+	AvailableAccounts int      `json:"available_accounts"`
+	Escapes           int      `json:"escapes"`
+	TotalMatches      int      `json:"total_matches"`
+	EscapeRate        float64  `json:"escape_rate"` // Σ escapes / Σ matches
+	Sacrifices        int      `json:"sacrifices"`
+	Kills             int      `json:"kills"`
+	KillerPips        int      `json:"killer_pips"`
+	SurvivorPips      int      `json:"survivor_pips"`
+	BloodwebPoints    int      `json:"bloodweb_points"`
+	HoursPlayed       int      `json:"hours_played"`
+	KillerGrade       string   `json:"killer_grade,omitempty"`
+	SurvivorGrade     string   `json:"survivor_grade,omitempty"`
+	AdeptSurvivors    []string `json:"adept_survivors"` // adepted on any account
+	AdeptKillers      []string `json:"adept_killers"`
+	AdeptsUnlocked    int      `json:"adepts_unlocked"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// ProgressiveStats is the first document of a progressive player response:
+// the combined response without achievements, whose state is pending
+type ProgressiveStats struct {
+	Part string `json:"part"` // "stats"
+	PlayerStatsWithAchievements
+}
+
+// ProgressiveAchievements is the second document of a progressive player
+// response, carrying what the first left out once achievements are in
+type ProgressiveAchievements struct {
+	Part              string           `json:"part"` // "achievements"
+	Achievements      *AchievementData `json:"achievements,omitempty"`
+	AchievementsState string           `json:"achievements_state"`
+	IntegrityFlags    []IntegrityFlag  `json:"integrity_flags,omitempty"`
+	DataSources       DataSourceStatus `json:"data_sources"`
+	Warnings          []string         `json:"warnings,omitempty"`
+	Timing            *ResponseTiming  `json:"timing,omitempty"`
+}
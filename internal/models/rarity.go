@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// RaritySnapshot is one day's global achievement percentages for a title
+type RaritySnapshot struct {
+	Date        string             `json:"date"` // YYYY-MM-DD, UTC
+	Percentages map[string]float64 `json:"percentages"`
+}
+
+// AchievementCatalog lists a title's achievements with their global rarity
+// and how it moved over the trend window
+type AchievementCatalog struct {
+	AppID        string               `json:"app_id"`
+	Achievements []CatalogAchievement `json:"achievements"`
+	Count        int                  `json:"count"`
+	History      RarityHistoryInfo    `json:"history"`
+	Warnings     []string             `json:"warnings,omitempty"`
+	Demo         bool                 `json:"demo,omitempty"`
+	GeneratedAt  time.Time            `json:"generated_at"`
+}
+
+// RarityHistoryInfo describes the snapshots trends were computed from
+type RarityHistoryInfo struct {
+	Snapshots  int    `json:"snapshots"`
+	Oldest     string `json:"oldest,omitempty"`
+	Newest     string `json:"newest,omitempty"`
+	WindowDays int    `json:"window_days"`
+	Persistent bool   `json:"persistent"`
+}
+
+// CatalogAchievement is one schema achievement with its rarity trend
+type CatalogAchievement struct {
+	ID          string       `json:"id"`
+	DisplayName string       `json:"display_name"`
+	Description string       `json:"description"`
+	Icon        string       `json:"icon,omitempty"`
+	Hidden      bool         `json:"hidden,omitempty"`
+	Character   string       `json:"character,omitempty"`
+	Type        string       `json:"type"`
+	Rarity      float64      `json:"rarity"` // 0-100 global completion percentage
+	Trend       *RarityTrend `json:"trend,omitempty"`
+}
+
+// Values of RarityTrend.Direction
+const (
+	RarityRarer      = "rarer"
+	RarityMoreCommon = "more_common"
+	RaritySteady     = "steady"
+)
+
+// RarityTrend compares an achievement's rarity with the snapshot at the start
+// of the window. Change is in percentage points, so -2 means 2% fewer players
+// have it than on Since.
+type RarityTrend struct {
+	Since       string             `json:"since"`
+	Previous    float64            `json:"previous"`
+	Change      float64            `json:"change"`
+	Direction   string             `json:"direction"`
+	SharpChange *RaritySharpChange `json:"sharp_change,omitempty"`
+}
+
+// RaritySharpChange is the largest day-over-day jump in the window, reported
+// when it is big enough to suggest a balance patch rather than drift
+type RaritySharpChange struct {
+	Date   string  `json:"date"` // first snapshot with the new rarity
+	From   float64 `json:"from"`
+	To     float64 `json:"to"`
+	Change float64 `json:"change"`
+}
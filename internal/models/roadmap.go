@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// AchievementRoadmap suggests which locked achievements to chase next, easiest first
+type AchievementRoadmap struct {
+	SteamID     string         `json:"steam_id"`
+	Entries     []RoadmapEntry `json:"entries"`
+	LockedCount int            `json:"locked_count"`
+	Demo        bool           `json:"demo,omitempty"`
+	LastUpdated time.Time      `json:"last_updated"`
+}
+
+type RoadmapEntry struct {
+	ID          string           `json:"id"`
+	DisplayName string           `json:"display_name"`
+	Description string           `json:"description,omitempty"`
+	Icon        string           `json:"icon,omitempty"`
+	Type        string           `json:"type"`
+	Character   string           `json:"character,omitempty"`
+	Rarity      float64          `json:"rarity"`       // 0-100 global completion percentage
+	EffortScore float64          `json:"effort_score"` // 0 (trivial) - 1 (hardest)
+	Progress    *RoadmapProgress `json:"progress,omitempty"`
+	Reason      string           `json:"reason"`
+}
+
+// RoadmapProgress links an achievement to the stat counter it is earned by
+type RoadmapProgress struct {
+	Stat    string  `json:"stat"`
+	Current float64 `json:"current"`
+	Target  float64 `json:"target"`
+	Percent float64 `json:"percent"`
+}
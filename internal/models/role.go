@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// PlayerRoleView is the killer or survivor half of a combined player
+// response, for UIs that only show one role
+type PlayerRoleView struct {
+	SteamID     string `json:"steam_id"`
+	DisplayName string `json:"display_name"`
+	Avatar      string `json:"avatar,omitempty"`
+	Role        string `json:"role"` // "killer" | "survivor"
+
+	Grade     string     `json:"grade,omitempty"` // current grade, e.g. "Iridescent I"
+	Pips      int        `json:"pips"`
+	PeakGrade *GradePeak `json:"peak_grade,omitempty"`
+
+	// Mapped stats in the role's category, in MapPlayerStats order
+	Stats []interface{} `json:"stats"`
+
+	// Adepts maps each of the role's characters to whether its adept is
+	// unlocked; omitted unless achievements_state is ok or empty
+	Adepts            map[string]bool `json:"adepts,omitempty"`
+	AdeptsUnlocked    int             `json:"adepts_unlocked"`
+	AchievementsState string          `json:"achievements_state"`
+
+	// Only the role's side is set
+	Analytics *PlayerAnalytics `json:"analytics,omitempty"`
+
+	DataSources    DataSourceStatus `json:"data_sources"`
+	Demo           bool             `json:"demo,omitempty"`
+	ResolvedAs     string           `json:"resolved_as,omitempty"`
+	LastUpdated    time.Time        `json:"last_updated"`
+	DataAgeSeconds int64            `json:"data_age_seconds"`
+	Timing         *ResponseTiming  `json:"timing,omitempty"`
+}
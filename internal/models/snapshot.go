@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// StatsSnapshot is the handful of counters a session recap compares. The
+// player store keeps the latest one per player so changes can be reported.
+type StatsSnapshot struct {
+	Escapes           int       `json:"escapes"`
+	SacrificedCampers int       `json:"sacrificed_campers"`
+	KilledCampers     int       `json:"killed_campers"`
+	KillerPips        int       `json:"killer_pips"`
+	SurvivorPips      int       `json:"survivor_pips"`
+	KillerGrade       string    `json:"killer_grade,omitempty"`
+	SurvivorGrade     string    `json:"survivor_grade,omitempty"`
+	TakenAt           time.Time `json:"taken_at"`
+}
+
+// StatsDelta is what moved between two snapshots. Counters that went down
+// (e.g. pips after the monthly reset) are reported as zero progress.
+type StatsDelta struct {
+	NewEscapes    int          `json:"new_escapes,omitempty"`
+	NewSacrifices int          `json:"new_sacrifices,omitempty"`
+	NewKills      int          `json:"new_kills,omitempty"`
+	KillerPips    int          `json:"killer_pips,omitempty"`
+	SurvivorPips  int          `json:"survivor_pips,omitempty"`
+	KillerGrade   *GradeChange `json:"killer_grade,omitempty"`
+	SurvivorGrade *GradeChange `json:"survivor_grade,omitempty"`
+	Since         time.Time    `json:"since"`
+}
+
+type GradeChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Diff returns the changes from previous to s
+func (s StatsSnapshot) Diff(previous StatsSnapshot) StatsDelta {
+	delta := StatsDelta{
+		NewEscapes:    gained(previous.Escapes, s.Escapes),
+		NewSacrifices: gained(previous.SacrificedCampers, s.SacrificedCampers),
+		NewKills:      gained(previous.KilledCampers, s.KilledCampers),
+		KillerPips:    gained(previous.KillerPips, s.KillerPips),
+		SurvivorPips:  gained(previous.SurvivorPips, s.SurvivorPips),
+		Since:         previous.TakenAt,
+	}
+	if s.KillerGrade != "" && s.KillerGrade != previous.KillerGrade {
+		delta.KillerGrade = &GradeChange{From: previous.KillerGrade, To: s.KillerGrade}
+	}
+	if s.SurvivorGrade != "" && s.SurvivorGrade != previous.SurvivorGrade {
+		delta.SurvivorGrade = &GradeChange{From: previous.SurvivorGrade, To: s.SurvivorGrade}
+	}
+	return delta
+}
+
+// Empty reports whether nothing changed
+func (d StatsDelta) Empty() bool {
+	return d.NewEscapes == 0 && d.NewSacrifices == 0 && d.NewKills == 0 &&
+		d.KillerPips == 0 && d.SurvivorPips == 0 &&
+		d.KillerGrade == nil && d.SurvivorGrade == nil
+}
+
+func gained(before, after int) int {
+	if after > before {
+		return after - before
+	}
+	return 0
+}
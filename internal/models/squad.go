@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// SquadReport sums up a survive-with-friends group of two to four players
+type SquadReport struct {
+	Members            []SquadMember     `json:"members"`
+	Aggregate          SquadAggregate    `json:"aggregate"`
+	StrongestRoles     []SquadRole       `json:"strongest_roles"`
+	SharedAchievements SquadAchievements `json:"shared_achievements"`
+	Warnings           []string          `json:"warnings,omitempty"`
+	Demo               bool              `json:"demo,omitempty"`
+	GeneratedAt        time.Time         `json:"generated_at"`
+}
+
+type SquadMember struct {
+	SteamID               string  `json:"steam_id"`
+	DisplayName           string  `json:"display_name"`
+	Avatar                string  `json:"avatar,omitempty"`
+	Escapes               int     `json:"escapes"`
+	TotalMatches          int     `json:"total_matches"`
+	EscapeRate            float64 `json:"escape_rate"` // escapes / matches played in any role, 0-1
+	AdeptsUnlocked        int     `json:"adepts_unlocked"`
+	MainRole              string  `json:"main_role"` // "killer" | "survivor" | "balanced", by grade pips
+	PlayStyle             string  `json:"play_style"`
+	AltruismRating        string  `json:"altruism_rating"`
+	AchievementsAvailable bool    `json:"achievements_available"` // false when the profile hides achievements
+}
+
+type SquadAggregate struct {
+	Escapes           int     `json:"escapes"`
+	TotalMatches      int     `json:"total_matches"`
+	EscapeRate        float64 `json:"escape_rate"`         // pooled: Σ escapes / Σ matches
+	AverageEscapeRate float64 `json:"average_escape_rate"` // mean of member rates
+	TotalAdepts       int     `json:"total_adepts"`        // Σ member adepts
+	DistinctAdepts    int     `json:"distinct_adepts"`     // characters adepted by at least one member
+}
+
+// SquadRole names the member who leads the squad on one dimension
+type SquadRole struct {
+	Role    string  `json:"role"`
+	SteamID string  `json:"steam_id"`
+	Value   float64 `json:"value"`
+}
+
+// SquadAchievements compares unlocks across members whose achievements are visible
+type SquadAchievements struct {
+	ComparedMembers int                `json:"compared_members"`
+	UnlockedByAll   []SquadAchievement `json:"unlocked_by_all"`
+	UnlockedByAny   int                `json:"unlocked_by_any"`
+	UnlockedByNone  int                `json:"unlocked_by_none"`
+}
+
+type SquadAchievement struct {
+	ID          string  `json:"id"`
+	DisplayName string  `json:"display_name"`
+	Rarity      float64 `json:"rarity,omitempty"`
+}
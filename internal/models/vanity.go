@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// VanityDisambiguation answers a vanity name that now resolves to a different
+// account than the one it was last seen on (300 Multiple Choices)
+type VanityDisambiguation struct {
+	Vanity  string         `json:"vanity"`
+	Message string         `json:"message"`
+	Choices []VanityChoice `json:"choices"`
+}
+
+// VanityChoice is one account a vanity name could mean
+type VanityChoice struct {
+	Relation    string     `json:"relation"` // "previously_known" | "newly_resolved"
+	SteamID     string     `json:"steam_id"`
+	DisplayName string     `json:"display_name,omitempty"`
+	LastSeen    *time.Time `json:"last_seen,omitempty"`
+	Href        string     `json:"href"`                   // the account by SteamID64
+	ConfirmHref string     `json:"confirm_href,omitempty"` // rebinds the vanity to this account
+}
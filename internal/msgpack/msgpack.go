@@ -0,0 +1,347 @@
+// Package msgpack transcodes JSON documents to MessagePack. Going through the
+// JSON encoding (rather than reflecting over Go values) means a MessagePack
+// response carries exactly the fields, names, order and omissions of the JSON
+// one; only the wire format differs.
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// ContentType is the media type negotiated via Accept
+const ContentType = "application/msgpack"
+
+// FromJSON converts one JSON document to MessagePack. Numbers without a
+// fraction or exponent become integers, everything else float64.
+func FromJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var out bytes.Buffer
+	if err := encodeValue(dec, &out); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("msgpack: trailing data after JSON document")
+	}
+	return out.Bytes(), nil
+}
+
+func encodeValue(dec *json.Decoder, out *bytes.Buffer) error {
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("msgpack: invalid JSON: %w", err)
+	}
+
+	switch value := token.(type) {
+	case nil:
+		out.WriteByte(0xc0)
+	case bool:
+		if value {
+			out.WriteByte(0xc3)
+		} else {
+			out.WriteByte(0xc2)
+		}
+	case json.Number:
+		return writeNumber(out, value)
+	case string:
+		writeString(out, value)
+	case json.Delim:
+		// Containers are prefixed with their length, so elements are encoded
+		// into a scratch buffer first
+		var elements bytes.Buffer
+		count := 0
+		for dec.More() {
+			if value == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return fmt.Errorf("msgpack: invalid JSON: %w", err)
+				}
+				writeString(&elements, key.(string))
+			}
+			if err := encodeValue(dec, &elements); err != nil {
+				return err
+			}
+			count++
+		}
+		if _, err := dec.Token(); err != nil { // closing delimiter
+			return fmt.Errorf("msgpack: invalid JSON: %w", err)
+		}
+		if value == '{' {
+			writeHeader(out, count, 0x80, 0xde, 0xdf)
+		} else {
+			writeHeader(out, count, 0x90, 0xdc, 0xdd)
+		}
+		out.Write(elements.Bytes())
+	}
+	return nil
+}
+
+func writeNumber(out *bytes.Buffer, number json.Number) error {
+	if n, err := strconv.ParseInt(string(number), 10, 64); err == nil {
+		writeInt(out, n)
+		return nil
+	}
+	if n, err := strconv.ParseUint(string(number), 10, 64); err == nil {
+		out.WriteByte(0xcf)
+		out.Write(binary.BigEndian.AppendUint64(nil, n))
+		return nil
+	}
+	f, err := number.Float64()
+	if err != nil {
+		return fmt.Errorf("msgpack: invalid number %q", number)
+	}
+	out.WriteByte(0xcb)
+	out.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+	return nil
+}
+
+// writeInt uses the smallest encoding that holds n
+func writeInt(out *bytes.Buffer, n int64) {
+	switch {
+	case n >= 0 && n <= 0x7f:
+		out.WriteByte(byte(n)) // positive fixint
+	case n >= -32 && n < 0:
+		out.WriteByte(byte(0xe0 | (n + 32))) // negative fixint
+	case n > 0 && n <= math.MaxUint8:
+		out.Write([]byte{0xcc, byte(n)})
+	case n > 0 && n <= math.MaxUint16:
+		out.WriteByte(0xcd)
+		out.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n > 0 && n <= math.MaxUint32:
+		out.WriteByte(0xce)
+		out.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	case n > 0:
+		out.WriteByte(0xcf)
+		out.Write(binary.BigEndian.AppendUint64(nil, uint64(n)))
+	case n >= math.MinInt8:
+		out.Write([]byte{0xd0, byte(n)})
+	case n >= math.MinInt16:
+		out.WriteByte(0xd1)
+		out.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n >= math.MinInt32:
+		out.WriteByte(0xd2)
+		out.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		out.WriteByte(0xd3)
+		out.Write(binary.BigEndian.AppendUint64(nil, uint64(n)))
+	}
+}
+
+func writeString(out *bytes.Buffer, s string) {
+	switch n := len(s); {
+	case n < 32:
+		out.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		out.Write([]byte{0xd9, byte(n)})
+	case n <= math.MaxUint16:
+		out.WriteByte(0xda)
+		out.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		out.WriteByte(0xdb)
+		out.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+	out.WriteString(s)
+}
+
+// writeHeader writes a map or array length using the fix, 16- or 32-bit form
+func writeHeader(out *bytes.Buffer, n int, fix, b16, b32 byte) {
+	switch {
+	case n < 16:
+		out.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		out.WriteByte(b16)
+		out.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		out.WriteByte(b32)
+		out.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+// ToJSON converts a MessagePack document back to compact JSON. It reads the
+// subset FromJSON produces (nil, bool, integers, floats, strings, arrays and
+// string-keyed maps) and is used to check that the two encodings agree.
+func ToJSON(data []byte) ([]byte, error) {
+	d := &decoder{data: data}
+	var out bytes.Buffer
+	if err := d.decodeValue(&out); err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, errors.New("msgpack: trailing data after document")
+	}
+	return out.Bytes(), nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+var errTruncated = errors.New("msgpack: truncated document")
+
+func (d *decoder) next(n int) ([]byte, error) {
+	if d.pos+n > len(d.data) {
+		return nil, errTruncated
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// uint reads an n-byte big-endian length or integer
+func (d *decoder) uint(n int) (uint64, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+func (d *decoder) decodeValue(out *bytes.Buffer) error {
+	head, err := d.next(1)
+	if err != nil {
+		return err
+	}
+	b := head[0]
+
+	switch {
+	case b <= 0x7f:
+		out.WriteString(strconv.Itoa(int(b)))
+		return nil
+	case b >= 0xe0:
+		out.WriteString(strconv.Itoa(int(int8(b))))
+		return nil
+	case b&0xe0 == 0xa0:
+		return d.decodeString(out, int(b&0x1f))
+	case b&0xf0 == 0x90:
+		return d.decodeArray(out, int(b&0x0f))
+	case b&0xf0 == 0x80:
+		return d.decodeMap(out, int(b&0x0f))
+	}
+
+	switch b {
+	case 0xc0:
+		out.WriteString("null")
+	case 0xc2:
+		out.WriteString("false")
+	case 0xc3:
+		out.WriteString("true")
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := d.uint(1 << (b - 0xcc))
+		if err != nil {
+			return err
+		}
+		out.WriteString(strconv.FormatUint(v, 10))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (b - 0xd0)
+		v, err := d.uint(size)
+		if err != nil {
+			return err
+		}
+		// Sign-extend from the encoded width
+		shift := 64 - 8*size
+		out.WriteString(strconv.FormatInt(int64(v<<shift)>>shift, 10))
+	case 0xca, 0xcb:
+		var f float64
+		if b == 0xca {
+			v, err := d.uint(4)
+			if err != nil {
+				return err
+			}
+			f = float64(math.Float32frombits(uint32(v)))
+		} else {
+			v, err := d.uint(8)
+			if err != nil {
+				return err
+			}
+			f = math.Float64frombits(v)
+		}
+		encoded, err := json.Marshal(f)
+		if err != nil {
+			return fmt.Errorf("msgpack: %w", err)
+		}
+		out.Write(encoded)
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (b - 0xd9))
+		if err != nil {
+			return err
+		}
+		return d.decodeString(out, int(n))
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (b - 0xdc))
+		if err != nil {
+			return err
+		}
+		return d.decodeArray(out, int(n))
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (b - 0xde))
+		if err != nil {
+			return err
+		}
+		return d.decodeMap(out, int(n))
+	default:
+		return fmt.Errorf("msgpack: unsupported type byte 0x%02x", b)
+	}
+	return nil
+}
+
+func (d *decoder) decodeString(out *bytes.Buffer, n int) error {
+	s, err := d.next(n)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(string(s))
+	if err != nil {
+		return fmt.Errorf("msgpack: %w", err)
+	}
+	out.Write(encoded)
+	return nil
+}
+
+func (d *decoder) decodeArray(out *bytes.Buffer, n int) error {
+	out.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		if err := d.decodeValue(out); err != nil {
+			return err
+		}
+	}
+	out.WriteByte(']')
+	return nil
+}
+
+func (d *decoder) decodeMap(out *bytes.Buffer, n int) error {
+	out.WriteByte('{')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		start := out.Len()
+		if err := d.decodeValue(out); err != nil {
+			return err
+		}
+		if out.Bytes()[start] != '"' {
+			return errors.New("msgpack: map key is not a string")
+		}
+		out.WriteByte(':')
+		if err := d.decodeValue(out); err != nil {
+			return err
+		}
+	}
+	out.WriteByte('}')
+	return nil
+}
@@ -0,0 +1,242 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// postJSON sends a JSON body and treats any non-2xx status as a failure
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	return postBody(ctx, client, url, body, nil)
+}
+
+// postBody sends an encoded JSON body with any extra headers
+func postBody(ctx context.Context, client *http.Client, url string, body []byte, headers http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// WebhookChannel posts the raw event as JSON to an arbitrary URL. With a
+// Secret, each request is signed so the receiver can verify it came from
+// this service: X-Signature-256 is "sha256=" followed by the hex HMAC-SHA256
+// of "<X-Signature-Timestamp>.<body>". X-Event-ID repeats across retries.
+type WebhookChannel struct {
+	URL    string
+	Secret string
+	client *http.Client
+}
+
+func NewWebhookChannel(url string) *WebhookChannel {
+	return &WebhookChannel{URL: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *WebhookChannel) Name() string { return "webhook" }
+
+func (c *WebhookChannel) Send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	headers := http.Header{}
+	headers.Set("X-Event-ID", event.ID)
+	if c.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(c.Secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+		headers.Set("X-Signature-Timestamp", timestamp)
+		headers.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	return postBody(ctx, c.client, c.URL, body, headers)
+}
+
+// SlackChannel posts to a Slack incoming webhook
+type SlackChannel struct {
+	WebhookURL string
+	client     *http.Client
+}
+
+func NewSlackChannel(webhookURL string) *SlackChannel {
+	return &SlackChannel{WebhookURL: webhookURL, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *SlackChannel) Name() string { return "slack" }
+
+func (c *SlackChannel) Send(ctx context.Context, event Event) error {
+	return postJSON(ctx, c.client, c.WebhookURL, map[string]string{"text": formatText(event)})
+}
+
+// DiscordChannel posts to a Discord channel webhook
+type DiscordChannel struct {
+	WebhookURL string
+	client     *http.Client
+}
+
+// discordContentLimit is the most characters Discord accepts in one message
+const discordContentLimit = 2000
+
+func NewDiscordChannel(webhookURL string) *DiscordChannel {
+	return &DiscordChannel{WebhookURL: webhookURL, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *DiscordChannel) Name() string { return "discord" }
+
+func (c *DiscordChannel) Send(ctx context.Context, event Event) error {
+	content := []rune(formatText(event))
+	if len(content) > discordContentLimit {
+		content = append(content[:discordContentLimit-1], '…')
+	}
+	return postJSON(ctx, c.client, c.WebhookURL, map[string]string{"content": string(content)})
+}
+
+// PagerDutyChannel triggers incidents through the PagerDuty Events API v2
+type PagerDutyChannel struct {
+	RoutingKey string
+	EventsURL  string
+	client     *http.Client
+}
+
+func NewPagerDutyChannel(routingKey string) *PagerDutyChannel {
+	return &PagerDutyChannel{
+		RoutingKey: routingKey,
+		EventsURL:  "https://events.pagerduty.com/v2/enqueue",
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c *PagerDutyChannel) Name() string { return "pagerduty" }
+
+func (c *PagerDutyChannel) Send(ctx context.Context, event Event) error {
+	severity := event.Severity
+	if severity != SeverityCritical && severity != SeverityWarning && severity != SeverityInfo {
+		severity = "error"
+	}
+
+	payload := map[string]interface{}{
+		"routing_key":  c.RoutingKey,
+		"event_action": "trigger",
+		"dedup_key":    "dbd-analytics/" + string(event.Type),
+		"payload": map[string]interface{}{
+			"summary":        event.Title + ": " + event.Message,
+			"source":         "dbd-analytics",
+			"severity":       severity,
+			"timestamp":      event.Time.Format(time.RFC3339),
+			"custom_details": event.Fields,
+		},
+	}
+	return postJSON(ctx, c.client, c.EventsURL, payload)
+}
+
+// EmailChannel sends plain-text mail through an SMTP relay
+type EmailChannel struct {
+	Addr     string // host:port
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+func (c *EmailChannel) Name() string { return "email" }
+
+func (c *EmailChannel) Send(ctx context.Context, event Event) error {
+	host, _, err := net.SplitHostPort(c.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP address %q: %w", c.Addr, err)
+	}
+
+	var auth smtp.Auth
+	if c.Username != "" {
+		auth = smtp.PlainAuth("", c.Username, c.Password, host)
+	}
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: [dbd-analytics] %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		c.From, strings.Join(c.To, ", "), event.Title, formatText(event))
+
+	// net/smtp has no context support; run it aside so the caller's deadline still applies
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(c.Addr, auth, c.From, c.To, []byte(msg))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ChannelsFromEnv builds every channel whose settings are present
+func ChannelsFromEnv() []Channel {
+	var channels []Channel
+
+	if url := os.Getenv("NOTIFY_WEBHOOK_URL"); url != "" {
+		webhook := NewWebhookChannel(url)
+		webhook.Secret = os.Getenv("NOTIFY_WEBHOOK_SECRET")
+		channels = append(channels, webhook)
+	}
+	if url := os.Getenv("NOTIFY_SLACK_WEBHOOK_URL"); url != "" {
+		channels = append(channels, NewSlackChannel(url))
+	}
+	if url := os.Getenv("NOTIFY_DISCORD_WEBHOOK_URL"); url != "" {
+		channels = append(channels, NewDiscordChannel(url))
+	}
+	if key := os.Getenv("NOTIFY_PAGERDUTY_ROUTING_KEY"); key != "" {
+		channels = append(channels, NewPagerDutyChannel(key))
+	}
+	if addr := os.Getenv("NOTIFY_SMTP_ADDR"); addr != "" {
+		var to []string
+		for _, recipient := range strings.Split(os.Getenv("NOTIFY_EMAIL_TO"), ",") {
+			if recipient = strings.TrimSpace(recipient); recipient != "" {
+				to = append(to, recipient)
+			}
+		}
+		if len(to) > 0 {
+			channels = append(channels, &EmailChannel{
+				Addr:     addr,
+				Username: os.Getenv("NOTIFY_SMTP_USERNAME"),
+				Password: os.Getenv("NOTIFY_SMTP_PASSWORD"),
+				From:     os.Getenv("NOTIFY_EMAIL_FROM"),
+				To:       to,
+			})
+		}
+	}
+
+	return channels
+}
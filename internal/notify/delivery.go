@@ -0,0 +1,290 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+)
+
+// Reasons a delivery ends up in the dead-letter list
+const (
+	ReasonRetriesExhausted = "retries_exhausted"
+	ReasonRejected         = "rejected"     // the endpoint refused the event outright (4xx)
+	ReasonCircuitOpen      = "circuit_open" // the channel had failed too often to try
+	ReasonQueueFull        = "queue_full"
+)
+
+var (
+	deliveryRetries = metrics.NewCounter("dbd_notify_retries",
+		"Notification sends retried after a failed attempt.")
+	deadLettered = metrics.NewCounter("dbd_notify_dead_letters",
+		"Notifications given up on and moved to the dead-letter list.")
+)
+
+// DeadLetter is an event a channel never accepted
+type DeadLetter struct {
+	Channel  string    `json:"channel"`
+	Event    Event     `json:"event"`
+	Reason   string    `json:"reason"`
+	Error    string    `json:"error,omitempty"`
+	Attempts int       `json:"attempts"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// ChannelHealth is a channel's delivery state for the admin API
+type ChannelHealth struct {
+	Channel             string     `json:"channel"`
+	Queued              int        `json:"queued"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	CircuitOpenUntil    *time.Time `json:"circuit_open_until,omitempty"`
+}
+
+// StatusError is a non-2xx answer from an HTTP channel
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d from notification endpoint", e.StatusCode)
+}
+
+// retryable reports whether a failed send may succeed if tried again. Client
+// errors won't, except timeouts and rate limiting.
+func retryable(err error) bool {
+	var status *StatusError
+	if !errors.As(err, &status) {
+		return true
+	}
+	return status.StatusCode >= 500 ||
+		status.StatusCode == http.StatusRequestTimeout ||
+		status.StatusCode == http.StatusTooManyRequests
+}
+
+// delivery is one event on its way to one channel
+type delivery struct {
+	event    Event
+	attempts int
+}
+
+// worker delivers to one channel in order, so a slow or failing endpoint
+// holds up only its own queue
+type worker struct {
+	channel Channel
+	queue   chan delivery
+
+	mu        sync.Mutex
+	failures  int // consecutive
+	openUntil time.Time
+}
+
+func newWorker(channel Channel, queueSize int) *worker {
+	return &worker{channel: channel, queue: make(chan delivery, queueSize)}
+}
+
+// circuitOpen reports whether the channel is resting after repeated failures
+func (w *worker) circuitOpen(now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return now.Before(w.openUntil)
+}
+
+func (w *worker) recordSuccess() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.failures = 0
+	w.openUntil = time.Time{}
+}
+
+// recordFailure counts a failed send, opening the circuit for cooldown once
+// threshold consecutive sends have failed. A send after the cooldown is the
+// probe: failing it reopens the circuit at once.
+func (w *worker) recordFailure(threshold int, cooldown time.Duration) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.failures++
+	if threshold <= 0 || w.failures < threshold {
+		return false
+	}
+	w.openUntil = time.Now().Add(cooldown)
+	return true
+}
+
+func (w *worker) health() ChannelHealth {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	health := ChannelHealth{
+		Channel:             w.channel.Name(),
+		Queued:              len(w.queue),
+		ConsecutiveFailures: w.failures,
+	}
+	if time.Now().Before(w.openUntil) {
+		openUntil := w.openUntil
+		health.CircuitOpenUntil = &openUntil
+	}
+	return health
+}
+
+// enqueue hands a delivery to the channel's worker, dead-lettering it when the
+// queue is full rather than blocking the dispatcher
+func (n *Notifier) enqueue(w *worker, d delivery) {
+	select {
+	case w.queue <- d:
+	default:
+		n.deadLetter(w.channel.Name(), d, ReasonQueueFull, nil)
+	}
+}
+
+func (n *Notifier) runWorker(w *worker) {
+	for {
+		select {
+		case d := <-w.queue:
+			n.attempt(w, d)
+		case <-n.stopCh:
+			return
+		}
+	}
+}
+
+// attempt sends d, retrying with exponential backoff until it is accepted,
+// rejected, out of attempts or stopped by an open circuit
+func (n *Notifier) attempt(w *worker, d delivery) {
+	name := w.channel.Name()
+	var lastErr error
+	for {
+		if w.circuitOpen(time.Now()) {
+			n.deadLetter(name, d, ReasonCircuitOpen, lastErr)
+			return
+		}
+
+		d.attempts++
+		ctx, cancel := context.WithTimeout(context.Background(), n.config.SendTimeout)
+		result := n.send(ctx, w.channel, d.event)
+		cancel()
+		if result.Success {
+			w.recordSuccess()
+			return
+		}
+		lastErr = result.err
+
+		// A rejected event says nothing about the endpoint's health
+		if !retryable(lastErr) {
+			n.deadLetter(name, d, ReasonRejected, lastErr)
+			return
+		}
+		if w.recordFailure(n.config.BreakerThreshold, n.config.BreakerCooldown) {
+			log.Warn("Notification channel circuit opened",
+				"channel", name,
+				"cooldown", n.config.BreakerCooldown)
+		}
+		if d.attempts >= n.config.MaxAttempts {
+			n.deadLetter(name, d, ReasonRetriesExhausted, lastErr)
+			return
+		}
+
+		deliveryRetries.Add(1)
+		timer := time.NewTimer(n.backoff(d.attempts))
+		select {
+		case <-timer.C:
+		case <-n.stopCh:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// backoff is the wait after the given failed attempt: RetryBaseDelay doubled
+// per attempt up to RetryMaxDelay, jittered to 50-100% so retries spread out
+func (n *Notifier) backoff(attempt int) time.Duration {
+	delay := n.config.RetryBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > n.config.RetryMaxDelay {
+		delay = n.config.RetryMaxDelay
+	}
+	return time.Duration(float64(delay) * (0.5 + rand.Float64()*0.5))
+}
+
+func (n *Notifier) deadLetter(channel string, d delivery, reason string, err error) {
+	letter := DeadLetter{
+		Channel:  channel,
+		Event:    d.event,
+		Reason:   reason,
+		Attempts: d.attempts,
+		FailedAt: time.Now().UTC(),
+	}
+	if err != nil {
+		letter.Error = err.Error()
+	}
+
+	n.deadMu.Lock()
+	n.dead = append(n.dead, letter)
+	if over := len(n.dead) - n.config.DeadLetterSize; over > 0 {
+		n.dead = append(n.dead[:0:0], n.dead[over:]...)
+	}
+	n.deadMu.Unlock()
+
+	deadLettered.Add(1)
+	log.Error("Notification moved to dead-letter list",
+		"channel", channel,
+		"event_type", d.event.Type,
+		"event_id", d.event.ID,
+		"reason", reason,
+		"attempts", d.attempts,
+		"error", letter.Error)
+}
+
+// DeadLetters returns undelivered events, oldest first
+func (n *Notifier) DeadLetters() []DeadLetter {
+	n.deadMu.Lock()
+	defer n.deadMu.Unlock()
+	return append([]DeadLetter{}, n.dead...)
+}
+
+// ClearDeadLetters empties the dead-letter list, returning how many were dropped
+func (n *Notifier) ClearDeadLetters() int {
+	n.deadMu.Lock()
+	defer n.deadMu.Unlock()
+	cleared := len(n.dead)
+	n.dead = nil
+	return cleared
+}
+
+// ReplayDeadLetters requeues every dead letter on its channel with a fresh
+// attempt budget, returning how many were requeued. Letters for channels no
+// longer configured stay in the list.
+func (n *Notifier) ReplayDeadLetters() int {
+	n.deadMu.Lock()
+	letters := n.dead
+	n.dead = nil
+	var kept []DeadLetter
+	for _, letter := range letters {
+		if _, ok := n.workers[letter.Channel]; !ok {
+			kept = append(kept, letter)
+		}
+	}
+	n.dead = kept
+	n.deadMu.Unlock()
+
+	replayed := 0
+	for _, letter := range letters {
+		if w, ok := n.workers[letter.Channel]; ok {
+			n.enqueue(w, delivery{event: letter.Event})
+			replayed++
+		}
+	}
+	return replayed
+}
+
+// Health reports each channel's queue depth and circuit state
+func (n *Notifier) Health() []ChannelHealth {
+	health := make([]ChannelHealth, 0, len(n.workers))
+	for _, name := range n.Channels() {
+		health = append(health, n.workers[name].health())
+	}
+	return health
+}
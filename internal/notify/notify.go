@@ -0,0 +1,458 @@
+// Package notify delivers operational events (cache corruption, circuit
+// breaker trips, exhausted Steam key quota) and player stat changes to
+// external channels such as Slack, Discord, PagerDuty, generic webhooks and
+// email.
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// EventType identifies what happened; channels are routed per event type
+type EventType string
+
+const (
+	EventCacheCorruption   EventType = "cache_corruption"
+	EventCircuitOpen       EventType = "circuit_open"
+	EventKeyQuotaExhausted EventType = "key_quota_exhausted"
+	EventStatsChanged      EventType = "stats_changed"
+	EventTest              EventType = "test"
+)
+
+// EventTypes lists every routable event type
+var EventTypes = []EventType{EventCacheCorruption, EventCircuitOpen, EventKeyQuotaExhausted, EventStatsChanged, EventTest}
+
+// defaultRoutes covers event types that shouldn't reach every channel when
+// NOTIFY_ROUTE_<EVENT> is unset; player recaps don't belong in PagerDuty
+var defaultRoutes = map[EventType][]string{
+	EventStatsChanged: {"webhook", "discord"},
+}
+
+// Severity levels, mapped onto each channel's own vocabulary
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Event is a single notification
+type Event struct {
+	ID         string                 `json:"id"` // stable across retries so receivers can drop duplicates
+	Type       EventType              `json:"type"`
+	Severity   string                 `json:"severity"`
+	Title      string                 `json:"title"`
+	Message    string                 `json:"message"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+	Subject    string                 `json:"subject,omitempty"` // e.g. a Steam ID; rate limits apply per subject
+	Time       time.Time              `json:"time"`
+	Suppressed int                    `json:"suppressed,omitempty"` // events of this type dropped by rate limiting since the last delivery
+}
+
+// Channel sends events to one destination
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, event Event) error
+}
+
+// DeliveryResult reports the outcome of sending one event to one channel
+type DeliveryResult struct {
+	Channel string `json:"channel"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	err     error
+}
+
+// Config controls routing and delivery
+type Config struct {
+	Routes      map[EventType][]string // channel names per event type; unlisted types go to every channel
+	MinInterval time.Duration          // minimum gap between deliveries of one event type to one channel
+	SendTimeout time.Duration
+	QueueSize   int // per channel
+
+	MaxAttempts      int           // sends per event and channel before it is dead-lettered
+	RetryBaseDelay   time.Duration // wait after the first failed send, doubled per retry
+	RetryMaxDelay    time.Duration
+	BreakerThreshold int           // consecutive failures that open a channel's circuit; 0 disables
+	BreakerCooldown  time.Duration // how long an open circuit dead-letters without trying
+	DeadLetterSize   int           // undelivered events kept for the admin API, oldest dropped first
+}
+
+// GetConfigFromEnv loads routing from NOTIFY_ROUTE_<EVENT_TYPE>=slack,email style variables
+func GetConfigFromEnv() Config {
+	config := Config{
+		Routes:      make(map[EventType][]string),
+		MinInterval: 5 * time.Minute,
+		SendTimeout: 10 * time.Second,
+		QueueSize:   100,
+
+		MaxAttempts:      5,
+		RetryBaseDelay:   2 * time.Second,
+		RetryMaxDelay:    5 * time.Minute,
+		BreakerThreshold: 5,
+		BreakerCooldown:  time.Minute,
+		DeadLetterSize:   100,
+	}
+
+	envDuration("NOTIFY_MIN_INTERVAL", &config.MinInterval, true)
+	envDuration("NOTIFY_RETRY_BASE_DELAY", &config.RetryBaseDelay, false)
+	envDuration("NOTIFY_RETRY_MAX_DELAY", &config.RetryMaxDelay, false)
+	envDuration("NOTIFY_BREAKER_COOLDOWN", &config.BreakerCooldown, false)
+	envInt("NOTIFY_MAX_ATTEMPTS", &config.MaxAttempts, 1)
+	envInt("NOTIFY_BREAKER_THRESHOLD", &config.BreakerThreshold, 0)
+	envInt("NOTIFY_DEAD_LETTER_SIZE", &config.DeadLetterSize, 1)
+
+	for _, eventType := range EventTypes {
+		value := os.Getenv("NOTIFY_ROUTE_" + strings.ToUpper(string(eventType)))
+		if value == "" {
+			continue
+		}
+		var names []string
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+		config.Routes[eventType] = names
+	}
+
+	return config
+}
+
+// envDuration overrides *target from a Go duration variable; zero is only
+// accepted when allowZero
+func envDuration(name string, target *time.Duration, allowZero bool) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	if parsed, err := time.ParseDuration(value); err == nil && (parsed > 0 || allowZero && parsed == 0) {
+		*target = parsed
+		return
+	}
+	log.Warn("Invalid "+name+", using default", "value", value, "default", *target)
+}
+
+// envInt overrides *target from an integer variable of at least min
+func envInt(name string, target *int, min int) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	if parsed, err := strconv.Atoi(value); err == nil && parsed >= min {
+		*target = parsed
+		return
+	}
+	log.Warn("Invalid "+name+", using default", "value", value, "default", *target)
+}
+
+type routeState struct {
+	channel    string
+	lastSent   time.Time
+	suppressed int
+}
+
+// Notifier routes events to channels with per-channel rate limiting. Each
+// channel has its own delivery worker that retries failed sends and
+// dead-letters events it gives up on.
+type Notifier struct {
+	config   Config
+	channels map[string]Channel
+	workers  map[string]*worker
+	queue    chan Event
+
+	mu     sync.Mutex
+	routes map[string]*routeState // keyed by event type + channel name + subject; pruned once idle
+
+	deadMu sync.Mutex
+	dead   []DeadLetter
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// New starts a notifier delivering to the given channels
+func New(config Config, channels ...Channel) *Notifier {
+	if config.QueueSize <= 0 {
+		config.QueueSize = 100
+	}
+	if config.SendTimeout <= 0 {
+		config.SendTimeout = 10 * time.Second
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 1
+	}
+	if config.RetryMaxDelay < config.RetryBaseDelay {
+		config.RetryMaxDelay = config.RetryBaseDelay
+	}
+	if config.DeadLetterSize <= 0 {
+		config.DeadLetterSize = 100
+	}
+
+	n := &Notifier{
+		config:   config,
+		channels: make(map[string]Channel, len(channels)),
+		workers:  make(map[string]*worker, len(channels)),
+		queue:    make(chan Event, config.QueueSize),
+		routes:   make(map[string]*routeState),
+		stopCh:   make(chan struct{}),
+	}
+	for _, ch := range channels {
+		n.channels[ch.Name()] = ch
+		n.workers[ch.Name()] = newWorker(ch, config.QueueSize)
+	}
+	for _, w := range n.workers {
+		go n.runWorker(w)
+	}
+
+	for eventType, names := range config.Routes {
+		for _, name := range names {
+			if _, ok := n.channels[name]; !ok {
+				log.Warn("Notification route references unconfigured channel",
+					"event_type", eventType, "channel", name)
+			}
+		}
+	}
+
+	go n.run()
+	return n
+}
+
+// Channels returns the names of configured channels, sorted
+func (n *Notifier) Channels() []string {
+	names := make([]string, 0, len(n.channels))
+	for name := range n.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// channelsFor resolves the channels an event type is routed to
+func (n *Notifier) channelsFor(eventType EventType) []Channel {
+	names, routed := n.config.Routes[eventType]
+	if !routed {
+		names, routed = defaultRoutes[eventType]
+	}
+	if !routed {
+		all := make([]Channel, 0, len(n.channels))
+		for _, ch := range n.channels {
+			all = append(all, ch)
+		}
+		return all
+	}
+
+	routedChannels := make([]Channel, 0, len(names))
+	for _, name := range names {
+		if ch, ok := n.channels[name]; ok {
+			routedChannels = append(routedChannels, ch)
+		}
+	}
+	return routedChannels
+}
+
+// Publish queues an event for asynchronous delivery; it never blocks the caller
+func (n *Notifier) Publish(event Event) {
+	stamp(&event)
+
+	select {
+	case n.queue <- event:
+	default:
+		log.Warn("Notification queue full, dropping event",
+			"event_type", event.Type,
+			"title", event.Title)
+	}
+}
+
+// Fire delivers an event synchronously to its routed channels, bypassing rate limits
+func (n *Notifier) Fire(ctx context.Context, event Event) []DeliveryResult {
+	stamp(&event)
+
+	channels := n.channelsFor(event.Type)
+	results := make([]DeliveryResult, 0, len(channels))
+	for _, ch := range channels {
+		results = append(results, n.send(ctx, ch, event))
+	}
+	return results
+}
+
+// stamp fills in an event's ID and time when the publisher left them empty
+func stamp(event *Event) {
+	if event.ID == "" {
+		buf := make([]byte, 8)
+		rand.Read(buf)
+		event.ID = hex.EncodeToString(buf)
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+}
+
+func (n *Notifier) run() {
+	prune := time.NewTicker(routePruneInterval)
+	defer prune.Stop()
+
+	for {
+		select {
+		case event := <-n.queue:
+			n.deliver(event)
+		case <-prune.C:
+			n.pruneRoutes()
+		case <-n.stopCh:
+			return
+		}
+	}
+}
+
+const (
+	routePruneInterval = time.Minute
+	// suppressedRetention is how long a route's count of suppressed events is
+	// kept waiting for a next event to report it on
+	suppressedRetention = time.Hour
+)
+
+// pruneRoutes drops rate limit state that no longer limits anything: routes
+// whose interval has passed with nothing suppressed, routes holding an old
+// suppressed count, and routes to channels no longer configured. Routes are
+// keyed per subject, so without this the map grows with every player seen.
+func (n *Notifier) pruneRoutes() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for key, state := range n.routes {
+		idle := time.Since(state.lastSent)
+		_, configured := n.channels[state.channel]
+		if !configured || (idle >= n.config.MinInterval && (state.suppressed == 0 || idle >= suppressedRetention)) {
+			delete(n.routes, key)
+		}
+	}
+}
+
+// deliver routes and rate limits an event, then hands it to each channel's worker
+func (n *Notifier) deliver(event Event) {
+	for _, ch := range n.channelsFor(event.Type) {
+		suppressed, allowed := n.admit(event, ch.Name())
+		if !allowed {
+			continue
+		}
+
+		e := event
+		e.Suppressed = suppressed
+		n.enqueue(n.workers[ch.Name()], delivery{event: e})
+	}
+}
+
+// admit applies the per-route rate limit, returning how many events were suppressed since the last send
+func (n *Notifier) admit(event Event, channel string) (int, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := string(event.Type) + "/" + channel
+	if event.Subject != "" {
+		key += "/" + event.Subject
+	}
+	state, ok := n.routes[key]
+	if !ok {
+		state = &routeState{channel: channel}
+		n.routes[key] = state
+	}
+
+	if !state.lastSent.IsZero() && time.Since(state.lastSent) < n.config.MinInterval {
+		state.suppressed++
+		return 0, false
+	}
+
+	suppressed := state.suppressed
+	state.suppressed = 0
+	state.lastSent = time.Now()
+	return suppressed, true
+}
+
+func (n *Notifier) send(ctx context.Context, ch Channel, event Event) DeliveryResult {
+	result := DeliveryResult{Channel: ch.Name(), Success: true}
+
+	if err := ch.Send(ctx, event); err != nil {
+		result.Success = false
+		result.Error = err.Error()
+		result.err = err
+		log.Error("Notification delivery failed",
+			"channel", ch.Name(),
+			"event_type", event.Type,
+			"event_id", event.ID,
+			"error", err)
+		return result
+	}
+
+	log.Info("Notification delivered",
+		"channel", ch.Name(),
+		"event_type", event.Type,
+		"suppressed", event.Suppressed)
+	return result
+}
+
+// Close stops background delivery; queued events and pending retries are dropped
+func (n *Notifier) Close() {
+	n.stopOnce.Do(func() { close(n.stopCh) })
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultNotifier *Notifier
+)
+
+// SetDefault installs the process-wide notifier used by Publish
+func SetDefault(n *Notifier) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultNotifier = n
+}
+
+// Default returns the process-wide notifier, or nil when none is configured
+func Default() *Notifier {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultNotifier
+}
+
+// Publish queues an event on the process-wide notifier; a no-op until one is installed
+func Publish(eventType EventType, severity, title, message string, fields map[string]interface{}) {
+	n := Default()
+	if n == nil {
+		return
+	}
+	n.Publish(Event{
+		Type:     eventType,
+		Severity: severity,
+		Title:    title,
+		Message:  message,
+		Fields:   fields,
+	})
+}
+
+// formatText renders an event as plain text for chat and email channels
+func formatText(event Event) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s\n%s", strings.ToUpper(event.Severity), event.Title, event.Message)
+	keys := make([]string, 0, len(event.Fields))
+	for key := range event.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\n• %s: %v", key, event.Fields[key])
+	}
+	if event.Suppressed > 0 {
+		fmt.Fprintf(&b, "\n(%d similar events suppressed)", event.Suppressed)
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type discardChannel struct{ name string }
+
+func (c discardChannel) Name() string                      { return c.name }
+func (c discardChannel) Send(context.Context, Event) error { return nil }
+
+func TestPruneRoutes(t *testing.T) {
+	n := New(Config{MinInterval: time.Minute}, discardChannel{name: "discord"})
+	defer n.Close()
+
+	now := time.Now()
+	n.routes = map[string]*routeState{
+		"active":        {channel: "discord", lastSent: now},
+		"idle":          {channel: "discord", lastSent: now.Add(-2 * time.Minute)},
+		"suppressed":    {channel: "discord", lastSent: now.Add(-2 * time.Minute), suppressed: 3},
+		"old_suppress":  {channel: "discord", lastSent: now.Add(-2 * suppressedRetention), suppressed: 3},
+		"unconfigured":  {channel: "slack", lastSent: now},
+		"never_allowed": {channel: "discord", lastSent: now.Add(-30 * time.Second), suppressed: 1},
+	}
+	n.pruneRoutes()
+
+	for key, kept := range map[string]bool{
+		"active":        true,
+		"idle":          false,
+		"suppressed":    true,
+		"old_suppress":  false,
+		"unconfigured":  false,
+		"never_allowed": true,
+	} {
+		if _, ok := n.routes[key]; ok != kept {
+			t.Errorf("route %s: kept = %v, want %v", key, ok, kept)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Package percentiles describes the community baseline file built by
+// cmd/percentiles: per-stat value distributions from a sample of public
+// profiles.
+package percentiles
+
+import (
+	"math"
+	"sort"
+)
+
+// FormatVersion is bumped when the file layout changes incompatibly
+const FormatVersion = 1
+
+// File is the on-disk distribution file. Everything in it is derived from the
+// sampled values, so the same sample always produces byte-identical output.
+type File struct {
+	Version     int                     `json:"version"`
+	GeneratedOn string                  `json:"generated_on"` // UTC date, YYYY-MM-DD
+	SampleSize  int                     `json:"sample_size"`  // profiles with public stats
+	Candidates  int                     `json:"candidates"`   // profiles crawled, including private ones
+	Seed        int64                   `json:"seed"`         // shuffle seed used to pick the sample
+	Source      string                  `json:"source"`       // where candidate Steam IDs came from
+	Stats       map[string]Distribution `json:"stats"`        // keyed by Steam stat ID
+}
+
+// Distribution holds the 0th..100th percentile values of one stat. Profiles
+// missing the stat are left out rather than counted as zero.
+type Distribution struct {
+	Samples   int       `json:"samples"`
+	Quantiles []float64 `json:"quantiles"` // 101 entries, index = percentile
+}
+
+// NewDistribution builds a distribution from raw values, which it sorts in place
+func NewDistribution(values []float64) Distribution {
+	sort.Float64s(values)
+	d := Distribution{Samples: len(values), Quantiles: make([]float64, 101)}
+	if len(values) == 0 {
+		return d
+	}
+	for p := range d.Quantiles {
+		// Nearest-rank, so every quantile is a value some player actually had
+		rank := int(math.Ceil(float64(p) / 100 * float64(len(values))))
+		if rank > 0 {
+			rank--
+		}
+		d.Quantiles[p] = values[rank]
+	}
+	return d
+}
@@ -0,0 +1,225 @@
+// Package pool provides a context-aware bounded worker pool for fan-out work
+// such as fetching several upstream resources for one request.
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+)
+
+// Tasks still running when their group's Wait gives up are abandoned: their
+// context is cancelled and nobody reads their results. The gauge should return to
+// zero quickly; a steady climb means some task ignores cancellation.
+var (
+	abandonedTotal    = metrics.NewCounter("dbd_fetch_abandoned", "Fan-out tasks still running when their request gave up waiting.")
+	abandonedInFlight = metrics.NewGauge("dbd_fetch_abandoned_in_flight", "Abandoned fan-out tasks that have not returned yet.")
+)
+
+// Task is a unit of work; it must honour ctx cancellation to release its slot promptly
+type Task func(ctx context.Context) error
+
+// Config controls pool sizing and per-task deadlines
+type Config struct {
+	Size        int           `json:"size"`         // maximum tasks running at once across all groups
+	TaskTimeout time.Duration `json:"task_timeout"` // per-task deadline, 0 disables
+}
+
+// DefaultConfig returns a pool sized for a handful of concurrent fan-out requests
+func DefaultConfig() Config {
+	return Config{
+		Size:        32,
+		TaskTimeout: 0,
+	}
+}
+
+// Pool bounds concurrent task execution with a shared semaphore
+type Pool struct {
+	config Config
+	slots  chan struct{}
+}
+
+// New creates a pool; a non-positive size falls back to the default
+func New(config Config) *Pool {
+	if config.Size <= 0 {
+		config.Size = DefaultConfig().Size
+		log.Warn("Invalid worker pool size, using default", "default", config.Size)
+	}
+	if config.TaskTimeout < 0 {
+		config.TaskTimeout = 0
+	}
+
+	return &Pool{
+		config: config,
+		slots:  make(chan struct{}, config.Size),
+	}
+}
+
+// Stats reports current utilisation
+func (p *Pool) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"size":                p.config.Size,
+		"in_use":              len(p.slots),
+		"task_timeout":        p.config.TaskTimeout.String(),
+		"abandoned_total":     abandonedTotal.Value(),
+		"abandoned_in_flight": abandonedInFlight.Value(),
+	}
+}
+
+// Group is a set of tasks submitted together and awaited with Wait
+type Group struct {
+	pool   *Pool
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	errs   []error
+	done   chan struct{}
+	closer sync.Once
+	limit  chan struct{} // nil unless the group has its own cap, see Limit
+
+	pending   int  // tasks submitted and not yet returned, guarded by mu
+	abandoned bool // Wait gave up on the group, guarded by mu
+}
+
+// Group starts a task group bound to ctx. Cancelling ctx stops queued tasks from
+// starting and cancels running ones; Wait cancels the group itself when it returns.
+func (p *Pool) Group(ctx context.Context) *Group {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{
+		pool:   p,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+}
+
+// Limit caps how many of the group's tasks run at once, below the pool's
+// size, so one large fan-out can't take every slot from concurrent requests.
+// Call it before the first Go.
+func (g *Group) Limit(n int) *Group {
+	if n > 0 && n < g.pool.config.Size {
+		g.limit = make(chan struct{}, n)
+	}
+	return g
+}
+
+// Go submits a named task. It blocks only the task's own goroutine while waiting
+// for a free slot, never the caller.
+func (g *Group) Go(name string, task Task) {
+	g.wg.Add(1)
+	g.mu.Lock()
+	g.pending++
+	g.mu.Unlock()
+
+	go func() {
+		defer g.wg.Done()
+		defer g.taskReturned()
+
+		if g.limit != nil {
+			select {
+			case g.limit <- struct{}{}:
+			case <-g.ctx.Done():
+				g.addError(name, g.ctx.Err())
+				return
+			}
+			defer func() { <-g.limit }()
+		}
+
+		select {
+		case g.pool.slots <- struct{}{}:
+		case <-g.ctx.Done():
+			g.addError(name, g.ctx.Err())
+			return
+		}
+		defer func() { <-g.pool.slots }()
+
+		taskCtx := g.ctx
+		if g.pool.config.TaskTimeout > 0 {
+			var cancel context.CancelFunc
+			taskCtx, cancel = context.WithTimeout(g.ctx, g.pool.config.TaskTimeout)
+			defer cancel()
+		}
+
+		if err := runTask(taskCtx, name, task); err != nil {
+			g.addError(name, err)
+		}
+	}()
+}
+
+func runTask(ctx context.Context, name string, task Task) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Error("Worker pool task panicked", "task", name, "panic", p)
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return task(ctx)
+}
+
+func (g *Group) taskReturned() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pending--
+	if g.abandoned {
+		abandonedInFlight.Add(-1)
+	}
+}
+
+// abandon cancels tasks still running after Wait gave up and counts them
+func (g *Group) abandon() {
+	g.cancel()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.abandoned {
+		return
+	}
+	g.abandoned = true
+	if g.pending > 0 {
+		abandonedTotal.Add(int64(g.pending))
+		abandonedInFlight.Add(int64(g.pending))
+		log.Warn("Abandoned fan-out tasks on cancelled request",
+			"tasks", g.pending,
+			"cause", g.ctx.Err())
+	}
+}
+
+func (g *Group) addError(name string, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.errs = append(g.errs, fmt.Errorf("%s: %w", name, err))
+}
+
+// Wait blocks until every task has returned or the group context ends. If the
+// context ends first its error is returned as-is and the tasks still running are
+// cancelled and counted as abandoned; otherwise all task errors are joined. The
+// caller must not read anything abandoned tasks write.
+func (g *Group) Wait() error {
+	go g.closer.Do(func() {
+		g.wg.Wait()
+		close(g.done)
+	})
+
+	select {
+	case <-g.done:
+	default:
+		select {
+		case <-g.done:
+		case <-g.ctx.Done():
+			err := g.ctx.Err()
+			g.abandon()
+			return err
+		}
+	}
+	g.cancel()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return errors.Join(g.errs...)
+}
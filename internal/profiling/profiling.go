@@ -0,0 +1,157 @@
+// Package profiling serves pprof and runtime statistics on a separate admin
+// listener, away from the public router, so memory growth and goroutine leaks
+// can be investigated on a running instance.
+package profiling
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/buildinfo"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// recentPauses is how many of the latest GC pauses /debug/runtime reports
+const recentPauses = 16
+
+// Config says where the admin listener binds. An empty Addr disables it.
+type Config struct {
+	Addr  string // e.g. 127.0.0.1:6060
+	Token string // required in X-Admin-Token; mandatory for non-loopback addresses
+}
+
+// FromEnv reads PROFILING_ADDR and reuses ADMIN_TOKEN for the token
+func FromEnv() Config {
+	return Config{
+		Addr:  os.Getenv("PROFILING_ADDR"),
+		Token: os.Getenv("ADMIN_TOKEN"),
+	}
+}
+
+// Start serves the profiling endpoints in the background. It returns nil when
+// profiling is disabled, and refuses to bind a non-loopback address without a
+// token since heap profiles expose request data.
+func Start(config Config) (*http.Server, error) {
+	if config.Addr == "" {
+		return nil, nil
+	}
+	if !isLoopback(config.Addr) && config.Token == "" {
+		return nil, fmt.Errorf("profiling address %s is not loopback; set ADMIN_TOKEN to expose it", config.Addr)
+	}
+
+	listener, err := net.Listen("tcp", config.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("profiling listener: %w", err)
+	}
+
+	server := &http.Server{
+		Handler: Handler(config.Token),
+		// No write timeout: CPU profiles and traces stream for ?seconds=N
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Profiling server stopped", "error", err.Error())
+		}
+	}()
+
+	log.Info("Profiling endpoints enabled",
+		"addr", listener.Addr().String(),
+		"token_required", config.Token != "")
+	return server, nil
+}
+
+// Handler routes /debug/pprof/* and /debug/runtime, gated on token when set
+func Handler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/runtime", serveRuntime)
+
+	if token == "" {
+		return mux
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Token")), []byte(token)) != 1 {
+			http.Error(w, "valid X-Admin-Token header required", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// serveRuntime reports heap, GC and goroutine figures as JSON. ReadMemStats
+// briefly stops the world, which is fine for an operator-driven endpoint.
+func serveRuntime(w http.ResponseWriter, r *http.Request) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	// PauseNs is a circular buffer with the latest pause at (NumGC+255)%256
+	pauses := make([]string, 0, recentPauses)
+	for i := 0; i < recentPauses && i < int(mem.NumGC); i++ {
+		pause := mem.PauseNs[(int(mem.NumGC)-1-i+len(mem.PauseNs))%len(mem.PauseNs)]
+		pauses = append(pauses, time.Duration(pause).String())
+	}
+
+	var lastGC string
+	if mem.LastGC > 0 {
+		lastGC = time.Unix(0, int64(mem.LastGC)).UTC().Format(time.RFC3339Nano)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"build":          buildinfo.Get(),
+		"uptime_seconds": int64(buildinfo.Uptime().Seconds()),
+		"go_version":     runtime.Version(),
+		"gomaxprocs":     runtime.GOMAXPROCS(0),
+		"goroutines":     runtime.NumGoroutine(),
+		"heap": map[string]uint64{
+			"alloc_bytes":    mem.HeapAlloc,
+			"in_use_bytes":   mem.HeapInuse,
+			"idle_bytes":     mem.HeapIdle,
+			"released_bytes": mem.HeapReleased,
+			"sys_bytes":      mem.HeapSys,
+			"objects":        mem.HeapObjects,
+			"total_alloc":    mem.TotalAlloc,
+		},
+		"gc": map[string]interface{}{
+			"count":         mem.NumGC,
+			"forced":        mem.NumForcedGC,
+			"next_target":   mem.NextGC,
+			"last_run":      lastGC,
+			"pause_total":   time.Duration(mem.PauseTotalNs).String(),
+			"recent_pauses": pauses, // newest first
+			"cpu_fraction":  mem.GCCPUFraction,
+		},
+		"memory": map[string]uint64{
+			"sys_bytes":    mem.Sys,
+			"stack_in_use": mem.StackInuse,
+			"mallocs":      mem.Mallocs,
+			"frees":        mem.Frees,
+		},
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/rgonzalez12/dbd-analytics/internal/demo"
 	"github.com/rgonzalez12/dbd-analytics/internal/log"
 )
 
@@ -26,6 +27,17 @@ func ValidateEnvironment() error {
 		},
 	}
 
+	// Demo mode serves bundled fixtures, so the Steam API key becomes optional
+	if demo.Enabled() {
+		config.RequiredEnvVars = []string{}
+		if err := demo.LoadError(); err != nil {
+			return fmt.Errorf("demo mode enabled but fixtures are unusable: %w", err)
+		}
+		log.Warn("Demo mode enabled: serving bundled fixture players instead of Steam data",
+			"steam_api_key_configured", os.Getenv("STEAM_API_KEY") != "",
+			"demo_players", len(demo.Players()))
+	}
+
 	// Check required environment variables
 	for _, envVar := range config.RequiredEnvVars {
 		value := os.Getenv(envVar)
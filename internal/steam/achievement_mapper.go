@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rgonzalez12/dbd-analytics/internal/cache"
@@ -22,7 +23,7 @@ type AchievementMapping struct {
 	IconGray    string  `json:"icon_gray,omitempty"`
 	Hidden      bool    `json:"hidden,omitempty"`
 	Character   string  `json:"character"`
-	Type        string  `json:"type"` // "adept_survivor", "adept_killer", "general"
+	Type        string  `json:"type"` // "adept_survivor", "adept_killer", "adept_unknown" (strict mode), "general"
 	Unlocked    bool    `json:"unlocked"`
 	UnlockTime  int64   `json:"unlock_time,omitempty"`
 	Rarity      float64 `json:"rarity,omitempty"` // 0-100 global completion percentage
@@ -34,29 +35,52 @@ type UnknownAchievement struct {
 	Occurrences int       `json:"occurrences"`
 }
 
+// UnknownAdept is an Adept achievement the app's adept registry doesn't
+// classify, listed until a registry update does
+type UnknownAdept struct {
+	AppID       string    `json:"app_id"`
+	APIName     string    `json:"api_name"`
+	Title       string    `json:"title"`
+	TypedAs     string    `json:"typed_as"` // adept_survivor, or adept_unknown in strict mode
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	Occurrences int       `json:"occurrences"`
+}
+
+// STRICT_ADEPTS=true types unclassified adepts adept_unknown, keeping them
+// out of survivor counts, instead of guessing survivor
+var strictAdepts = envBool("STRICT_ADEPTS", false)
+
+// StrictAdepts reports whether unclassified adepts are quarantined
+func StrictAdepts() bool {
+	return strictAdepts
+}
+
 type AchievementMapper struct {
 	unknownAchievements map[string]*UnknownAchievement
+	unknownAdepts       map[string]*UnknownAdept // app ID + " " + API name
 	unknownsMutex       sync.RWMutex
-	client              *Client
+	client              atomic.Pointer[Client] // nil until UseClient; only hardcoded adepts map then
 	adeptRegex          *regexp.Regexp
-	adeptsByAPI         map[string]string // apiName -> "killer"|"survivor"
 }
 
-func NewAchievementMapper() *AchievementMapper {
-	adeptsByAPI := make(map[string]string)
-	for apiName, adept := range AdeptAchievementMapping {
-		adeptsByAPI[apiName] = adept.Type
-	}
-
-	client := NewClient()
+// NewAchievementMapper creates a mapper that fetches schemas and global
+// percentages through client, so those calls count against its quota
+func NewAchievementMapper(client *Client) *AchievementMapper {
 	log.Info("Created achievement mapper", "steam_client_exists", client != nil)
 
-	return &AchievementMapper{
+	am := &AchievementMapper{
 		unknownAchievements: make(map[string]*UnknownAchievement),
-		client:              client,
+		unknownAdepts:       make(map[string]*UnknownAdept),
 		adeptRegex:          regexp.MustCompile(`^Adept\s+(?:The\s+)?(.+)$`),
-		adeptsByAPI:         adeptsByAPI,
 	}
+	am.client.Store(client)
+	return am
+}
+
+// UseClient routes the mapper's Steam calls through client
+func (am *AchievementMapper) UseClient(client *Client) {
+	am.client.Store(client)
 }
 
 func (am *AchievementMapper) trackUnknown(apiName string) {
@@ -70,12 +94,39 @@ func (am *AchievementMapper) trackUnknown(apiName string) {
 	u.Occurrences++
 }
 
+func (am *AchievementMapper) trackUnknownAdept(appID, apiName, title, typedAs string) {
+	am.unknownsMutex.Lock()
+	defer am.unknownsMutex.Unlock()
+	key := appID + " " + apiName
+	now := time.Now()
+	u := am.unknownAdepts[key]
+	if u == nil {
+		u = &UnknownAdept{AppID: appID, APIName: apiName, FirstSeen: now}
+		am.unknownAdepts[key] = u
+	}
+	u.Title, u.TypedAs, u.LastSeen = title, typedAs, now
+	u.Occurrences++
+}
+
 func (am *AchievementMapper) MapPlayerAchievements(achievements *PlayerAchievements) []AchievementMapping {
 	return am.MapPlayerAchievementsWithCache(achievements, nil)
 }
 
 func (am *AchievementMapper) MapPlayerAchievementsWithCache(achievements *PlayerAchievements, cacheManager cache.Cache) []AchievementMapping {
-	ctx := context.Background()
+	return am.MapPlayerAchievementsContext(context.Background(), achievements, cacheManager)
+}
+
+// MapPlayerAchievementsContext maps achievements against the schema, global
+// percentages and adept registry of the app ctx carries
+func (am *AchievementMapper) MapPlayerAchievementsContext(ctx context.Context, achievements *PlayerAchievements, cacheManager cache.Cache) []AchievementMapping {
+	mapped, _ := am.MapPlayerAchievementsWithSource(ctx, achievements, cacheManager)
+	return mapped
+}
+
+// MapPlayerAchievementsWithSource is MapPlayerAchievementsContext also
+// reporting which SchemaSource the mapping was built from
+func (am *AchievementMapper) MapPlayerAchievementsWithSource(ctx context.Context, achievements *PlayerAchievements, cacheManager cache.Cache) ([]AchievementMapping, string) {
+	app := AppFromContext(ctx)
 
 	// 1) Build map from player data
 	unlockedMap := make(map[string]SteamAchievement)
@@ -84,16 +135,21 @@ func (am *AchievementMapper) MapPlayerAchievementsWithCache(achievements *Player
 	}
 
 	// 2) Fetch global percentages early (needed for both schema and fallback paths)
+	client := am.client.Load()
+	skipPercentages := percentagesSkipped(ctx)
 	var globalPercentages map[string]float64
-	if cacheManager != nil && am.client != nil {
-		if percentages, err := am.client.GetGlobalAchievementPercentagesCached(ctx, cacheManager); err == nil {
+	if skipPercentages {
+		log.Debug("Global achievement percentages not wanted, leaving rarity unset")
+	}
+	if cacheManager != nil && client != nil && !skipPercentages {
+		if percentages, err := client.GetGlobalAchievementPercentagesCached(ctx, cacheManager); err == nil {
 			globalPercentages = percentages
 			log.Debug("Using cached global achievement percentages", "count", len(globalPercentages))
 		}
 	}
 
-	if globalPercentages == nil && am.client != nil {
-		if percentages, err := am.client.FetchGlobalAchievementPercentages(ctx); err == nil {
+	if globalPercentages == nil && client != nil && !skipPercentages {
+		if percentages, err := client.FetchGlobalAchievementPercentages(ctx); err == nil {
 			globalPercentages = percentages
 			log.Debug("Using direct global achievement percentages", "count", len(globalPercentages))
 		} else {
@@ -103,9 +159,11 @@ func (am *AchievementMapper) MapPlayerAchievementsWithCache(achievements *Player
 
 	// 3) Fetch schema (only direct call available)
 	var fullSchema *SchemaGame
-	if am.client != nil {
-		log.Debug("Attempting to fetch achievement schema from Steam API", "app_id", DBDAppID, "client_exists", true)
-		schema, err := am.client.GetSchemaForGame(DBDAppID)
+	var schemaSource string
+	if client != nil {
+		log.Debug("Attempting to fetch achievement schema from Steam API", "app_id", app.ID, "client_exists", true)
+		schema, source, err := client.GetSchemaWithSource(ctx, cacheManager)
+		schemaSource = source
 		if err != nil {
 			log.Error("Failed to get achievement schema, falling back to hardcoded", "error", err, "error_type", fmt.Sprintf("%T", err))
 		} else if schema == nil {
@@ -123,7 +181,7 @@ func (am *AchievementMapper) MapPlayerAchievementsWithCache(achievements *Player
 	// If schema missing/empty, fall back to processing all player achievements (with global percentages)
 	if fullSchema == nil || len(fullSchema.AvailableGameStats.Achievements) == 0 {
 		log.Warn("Schema unavailable or empty, processing all player achievements with fallback classification")
-		return am.buildAllAchievementMappings(unlockedMap, globalPercentages, cacheManager, ctx)
+		return am.buildAllAchievementMappings(unlockedMap, globalPercentages, cacheManager, ctx), SchemaSourceHardcoded
 	}
 
 	// 4) For each schema achievement, build mapping (preallocated)
@@ -159,16 +217,21 @@ func (am *AchievementMapper) MapPlayerAchievementsWithCache(achievements *Player
 		character := ""
 
 		if strings.HasPrefix(title, "Adept ") {
-			switch am.adeptsByAPI[id] {
+			switch app.Adepts[id].Type {
 			case "killer":
 				typ = "adept_killer"
 			case "survivor":
 				typ = "adept_survivor"
 			default:
-				typ = "adept_survivor" // safe default
+				// Survivor is the historical guess; strict mode quarantines instead
+				typ = "adept_survivor"
+				if strictAdepts {
+					typ = "adept_unknown"
+				}
 				// Track unknown adept with title for triage
 				am.trackUnknown(id)
-				log.Debug("Unknown adept achievement detected", "api_name", id, "title", title, "suggestion", "Consider adding to AdeptAchievementMapping")
+				am.trackUnknownAdept(app.ID, id, title, typ)
+				log.Debug("Unknown adept achievement detected", "api_name", id, "title", title, "typed_as", typ, "suggestion", "Consider adding to AdeptAchievementMapping")
 			}
 
 			// Extract character with regex (keep exact schema casing)
@@ -212,20 +275,22 @@ func (am *AchievementMapper) MapPlayerAchievementsWithCache(achievements *Player
 
 	log.Info("Schema-based achievement mapping completed",
 		"total_achievements", len(mapped),
-		"schema_source", "steam_api")
+		"schema_source", schemaSource)
 
-	return mapped
+	return mapped, schemaSource
 }
 
 // buildAllAchievementMappings processes all player achievements when schema is unavailable
-func (am *AchievementMapper) buildAllAchievementMappings(unlockedMap map[string]SteamAchievement, globalPercentages map[string]float64, _ cache.Cache, _ context.Context) []AchievementMapping {
+func (am *AchievementMapper) buildAllAchievementMappings(unlockedMap map[string]SteamAchievement, globalPercentages map[string]float64, _ cache.Cache, ctx context.Context) []AchievementMapping {
+	adepts := AppFromContext(ctx).Adepts
+
 	// In fallback mode, only process known adept achievements
 	// Since schema is unavailable, we can't validate general achievements reliably
-	mapped := make([]AchievementMapping, 0, len(AdeptAchievementMapping))
+	mapped := make([]AchievementMapping, 0, len(adepts))
 
 	for apiName, steamAch := range unlockedMap {
 		// Only process adept achievements in fallback mode to maintain data integrity
-		entry, isAdept := AdeptAchievementMapping[apiName]
+		entry, isAdept := adepts[apiName]
 		if !isAdept {
 			am.trackUnknown(apiName)
 			continue
@@ -287,12 +352,14 @@ func (am *AchievementMapper) GetAchievementSummary(mapped []AchievementMapping)
 		"general_count":        0,
 		"adept_survivor_count": 0,
 		"adept_killer_count":   0,
+		"adept_unknown_count":  0,
 		"adept_survivors":      []string{},
 		"adept_killers":        []string{},
 		"completion_rate":      0.0,
 	}
 
-	var adeptSurvivors, adeptKillers []string
+	// Non-nil so they encode as [] rather than null
+	adeptSurvivors, adeptKillers := []string{}, []string{}
 
 	for _, achievement := range mapped {
 		if achievement.Unlocked {
@@ -310,6 +377,8 @@ func (am *AchievementMapper) GetAchievementSummary(mapped []AchievementMapping)
 			if achievement.Character != "" {
 				adeptKillers = append(adeptKillers, achievement.Character)
 			}
+		case "adept_unknown":
+			summary["adept_unknown_count"] = summary["adept_unknown_count"].(int) + 1
 		case "general":
 			summary["general_count"] = summary["general_count"].(int) + 1
 		default:
@@ -337,7 +406,33 @@ func (am *AchievementMapper) GetUnknownAchievements() []*UnknownAchievement {
 	for _, unknown := range am.unknownAchievements {
 		unknowns = append(unknowns, unknown)
 	}
+	sort.Slice(unknowns, func(i, j int) bool { return unknowns[i].APIName < unknowns[j].APIName })
+
+	return unknowns
+}
+
+// UnknownAdepts lists the adepts seen that their app's registry still
+// doesn't classify, by app and API name
+func UnknownAdepts() []UnknownAdept {
+	am := getGlobalMapper()
+	am.unknownsMutex.RLock()
+	defer am.unknownsMutex.RUnlock()
 
+	unknowns := make([]UnknownAdept, 0, len(am.unknownAdepts))
+	for _, unknown := range am.unknownAdepts {
+		if app, ok := LookupApp(unknown.AppID); ok {
+			if _, classified := app.Adepts[unknown.APIName]; classified {
+				continue
+			}
+		}
+		unknowns = append(unknowns, *unknown)
+	}
+	sort.Slice(unknowns, func(i, j int) bool {
+		if unknowns[i].AppID == unknowns[j].AppID {
+			return unknowns[i].APIName < unknowns[j].APIName
+		}
+		return unknowns[i].AppID < unknowns[j].AppID
+	})
 	return unknowns
 }
 
@@ -362,6 +457,19 @@ func (am *AchievementMapper) ValidateMappingCoverage() map[string]interface{} {
 	}
 }
 
+type skipPercentagesKey struct{}
+
+// WithoutPercentages marks ctx so mapping skips fetching global achievement
+// percentages and leaves rarity unset, for when no client is shown it
+func WithoutPercentages(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipPercentagesKey{}, true)
+}
+
+func percentagesSkipped(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipPercentagesKey{}).(bool)
+	return skip
+}
+
 // Global mapper instance for caching (lazy initialization)
 var (
 	globalAchievementMapper *AchievementMapper
@@ -370,11 +478,18 @@ var (
 
 func getGlobalMapper() *AchievementMapper {
 	globalMapperOnce.Do(func() {
-		globalAchievementMapper = NewAchievementMapper()
+		globalAchievementMapper = NewAchievementMapper(nil)
 	})
 	return globalAchievementMapper
 }
 
+// UseClient makes the global mapper fetch schemas and global percentages
+// through client, the one the API serves players with, so its calls share
+// that client's quota, host health and hooks
+func UseClient(client *Client) {
+	getGlobalMapper().UseClient(client)
+}
+
 // MapAchievements is a convenience function using the global mapper
 func MapAchievements(achievements *PlayerAchievements) []AchievementMapping {
 	return getGlobalMapper().MapPlayerAchievements(achievements)
@@ -387,8 +502,13 @@ func GetMappedAchievements(achievements *PlayerAchievements) map[string]interfac
 
 // GetAchievements returns mapped achievements with schema-based mapping when cache is available
 func GetAchievements(achievements *PlayerAchievements, cacheManager cache.Cache) map[string]interface{} {
+	return GetAchievementsContext(context.Background(), achievements, cacheManager)
+}
+
+// GetAchievementsContext is GetAchievements for the app ctx carries
+func GetAchievementsContext(ctx context.Context, achievements *PlayerAchievements, cacheManager cache.Cache) map[string]interface{} {
 	mapper := getGlobalMapper()
-	mapped := mapper.MapPlayerAchievementsWithCache(achievements, cacheManager)
+	mapped, schemaSource := mapper.MapPlayerAchievementsWithSource(ctx, achievements, cacheManager)
 	summary := mapper.GetAchievementSummary(mapped)
 	unknowns := mapper.GetUnknownAchievements()
 
@@ -415,8 +535,9 @@ func GetAchievements(achievements *PlayerAchievements, cacheManager cache.Cache)
 	}
 
 	result := map[string]interface{}{
-		"achievements": mapped,
-		"summary":      summary,
+		"achievements":  mapped,
+		"summary":       summary,
+		"schema_source": schemaSource,
 	}
 
 	// Include unknown achievements in response
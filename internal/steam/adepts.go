@@ -2,17 +2,22 @@ package steam
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
 )
 
 type AdeptEntry struct {
 	APIName   string // schema 'name' (apiname)
 	Character string // normalized character name
-	Kind      string // "survivor" | "killer"
+	Kind      string // "survivor" | "killer" | "unknown" (strict mode)
 }
 
 var adeptRe = regexp.MustCompile(`(?i)^Adept\s+(.+)$`)
@@ -26,7 +31,12 @@ func normalizeChar(s string) string {
 }
 
 func (c *Client) BuildAdeptMap() (map[string]AdeptEntry, error) {
-	schema, err := c.GetSchemaForGame(DBDAppID)
+	return c.BuildAdeptMapContext(context.Background())
+}
+
+// BuildAdeptMapContext is BuildAdeptMap bounded by ctx
+func (c *Client) BuildAdeptMapContext(ctx context.Context) (map[string]AdeptEntry, error) {
+	schema, err := c.GetSchemaForGameContext(ctx, DBDAppID)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +82,9 @@ func (c *Client) BuildAdeptMap() (map[string]AdeptEntry, error) {
 				kind = "killer"
 			case survivorNames[normalizedChar]:
 				kind = "survivor"
+			case strictAdepts:
+				// Quarantined until the registry learns the character
+				kind = "unknown"
 			default:
 				// Fallback heuristics for unknown characters
 				if strings.HasPrefix(matches[1], "The ") {
@@ -92,23 +105,143 @@ func (c *Client) BuildAdeptMap() (map[string]AdeptEntry, error) {
 	return m, nil
 }
 
-// GetAdeptMapCached returns the adept map with caching support
+// adeptStore memoizes the adept map for the life of the process. The schema
+// worker replaces it; request paths only ever read it.
+type adeptStore struct {
+	mu          sync.RWMutex
+	entries     map[string]AdeptEntry
+	fingerprint string
+	refreshedAt time.Time
+
+	loadMu sync.Mutex // serializes first loads so concurrent misses share one schema fetch
+}
+
+func (s *adeptStore) get() (map[string]AdeptEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.entries, s.entries != nil
+}
+
+// swap installs a new map and reports whether its contents differ from the previous one
+func (s *adeptStore) swap(entries map[string]AdeptEntry) bool {
+	fingerprint := adeptFingerprint(entries)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := s.fingerprint != fingerprint
+	s.entries = entries
+	s.fingerprint = fingerprint
+	s.refreshedAt = time.Now()
+	return changed
+}
+
+// adeptFingerprint hashes the adept entries in a stable order so schema changes can be detected
+func adeptFingerprint(entries map[string]AdeptEntry) string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		e := entries[name]
+		h.Write([]byte(name + "\x00" + e.Character + "\x00" + e.Kind + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// GetAdeptMapCached returns the process-wide adept map. The first call loads it
+// from the shared cache or the schema; later calls never leave the process.
 func (c *Client) GetAdeptMapCached(ctx context.Context, cacheManager cache.Cache) (map[string]AdeptEntry, error) {
-	key := cache.GenerateKey(cache.AdeptMapPrefix, "dbd")
+	// The schema heuristics below only know DBD; other titles use their registry
+	if app := AppFromContext(ctx); !app.IsDBD() {
+		return app.adeptEntries(), nil
+	}
 
-	if cached, ok := cacheManager.Get(key); ok {
-		if adeptMap, ok := cached.(map[string]AdeptEntry); ok {
-			return adeptMap, nil
-		}
+	if m, ok := c.adepts.get(); ok {
+		return m, nil
+	}
+
+	c.adepts.loadMu.Lock()
+	defer c.adepts.loadMu.Unlock()
+
+	// Another request may have loaded it while we waited
+	if m, ok := c.adepts.get(); ok {
+		return m, nil
 	}
 
-	m, err := c.BuildAdeptMap()
+	key := adeptMapKey()
+	m, _, err := cache.GetOrLoad(ctx, cacheManager, key, 24*time.Hour, c.BuildAdeptMapContext)
 	if err != nil {
 		return nil, err
 	}
 
-	// Cache for 24 hours
-	_ = cacheManager.Set(key, m, 24*time.Hour)
-
+	c.adepts.swap(m)
 	return m, nil
 }
+
+// adeptMapKey keeps strict-mode maps, whose unknown adepts have no side,
+// apart from guessing replicas' in a shared cache
+func adeptMapKey() cache.Key[map[string]AdeptEntry] {
+	if strictAdepts {
+		return adeptMapCache.Key("dbd", "strict")
+	}
+	return adeptMapCache.Key("dbd")
+}
+
+// RefreshAdeptMap rebuilds the adept map from the current schema. When the
+// adept set changed the cached copy is invalidated and replaced.
+func (c *Client) RefreshAdeptMap(ctx context.Context, cacheManager cache.Cache) (bool, error) {
+	m, err := c.BuildAdeptMapContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	changed := c.adepts.swap(m)
+	if changed && cacheManager != nil {
+		key := adeptMapKey()
+		_ = cacheManager.Delete(string(key))
+		_ = cache.Set(cacheManager, key, m, 24*time.Hour)
+	}
+
+	return changed, nil
+}
+
+// StartSchemaWorker refreshes the adept map every interval until stop is called
+func (c *Client) StartSchemaWorker(interval time.Duration, cacheManager cache.Cache) (stop func()) {
+	stopCh := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), schemaRefreshTimeout)
+				changed, err := c.RefreshAdeptMap(ctx, cacheManager)
+				cancel()
+				if err != nil {
+					log.Warn("Scheduled adept map refresh failed, keeping previous map", "error", err)
+					continue
+				}
+				if changed {
+					log.Info("Adept map changed after schema refresh, cache invalidated")
+				} else {
+					log.Debug("Adept map unchanged after schema refresh")
+				}
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+
+	log.Info("Schema worker started", "interval", interval)
+	return func() { once.Do(func() { close(stopCh) }) }
+}
+
+// schemaRefreshTimeout bounds one scheduled schema fetch
+const schemaRefreshTimeout = 30 * time.Second
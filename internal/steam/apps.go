@@ -0,0 +1,190 @@
+package steam
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// App is a Steam title the service reports on. Stat aliases, renamed stat
+// IDs and adept achievements differ per title, so each app carries its own
+// registries; an app without them serves schema display names and raw stat
+// IDs and tracks no adepts or events.
+type App struct {
+	ID        string                    `json:"app_id"`
+	Name      string                    `json:"name,omitempty"`
+	Aliases   map[string]string         `json:"-"` // stat ID -> display name
+	Canonical map[string]string         `json:"-"` // renamed or duplicate stat ID -> canonical ID
+	Adepts    map[string]AdeptCharacter `json:"-"` // achievement API name -> character
+	Events    map[string]StatEvent      `json:"-"` // stat ID -> limited-time event
+	Units     map[string]StatUnit       `json:"-"` // stat ID -> unit of a fractional stat
+	Realms    map[string]Realm          `json:"-"` // realm code in per-map stat IDs -> realm
+}
+
+// IsDBD reports whether a is Dead by Daylight itself, the only title with
+// grades, snapshots and the other DBD-specific features
+func (a *App) IsDBD() bool {
+	return a.ID == DBDAppID
+}
+
+// ScopedID namespaces a per-player cache identity by app so titles sharing a
+// cache stay apart. DBD keeps the bare Steam ID, so its keys are unchanged.
+func (a *App) ScopedID(steamID string) string {
+	if a.IsDBD() {
+		return steamID
+	}
+	return steamID + ":" + a.ID
+}
+
+// cacheScope names a's shared cache entries (schema, percentages, adept map)
+func (a *App) cacheScope() string {
+	if a.IsDBD() {
+		return "dbd"
+	}
+	return a.ID
+}
+
+// adeptEntries is a's adept registry in the shape the schema-built DBD map takes
+func (a *App) adeptEntries() map[string]AdeptEntry {
+	entries := make(map[string]AdeptEntry, len(a.Adepts))
+	for apiName, character := range a.Adepts {
+		entries[apiName] = AdeptEntry{APIName: apiName, Character: character.Name, Kind: character.Type}
+	}
+	return entries
+}
+
+// NumericID is a's app ID as the integer some Steam endpoints take
+func (a *App) NumericID() int {
+	id, _ := strconv.Atoi(a.ID)
+	return id
+}
+
+var (
+	appsMu sync.RWMutex
+	apps   = map[string]*App{
+		DBDAppID: {
+			ID:        DBDAppID,
+			Name:      "Dead by Daylight",
+			Aliases:   aliases,
+			Canonical: canonicalStatIDs,
+			Adepts:    AdeptAchievementMapping,
+			Events:    eventStats,
+			Units:     statUnits,
+			Realms:    realms,
+		},
+	}
+	defaultAppID = DBDAppID
+)
+
+// RegisterApp adds or replaces a title. Registries left nil are empty.
+func RegisterApp(app App) error {
+	if id, err := strconv.Atoi(app.ID); err != nil || id <= 0 {
+		return fmt.Errorf("app ID %q is not a positive integer", app.ID)
+	}
+	appsMu.Lock()
+	defer appsMu.Unlock()
+	apps[app.ID] = &app
+	return nil
+}
+
+// LookupApp returns the registered title with the given app ID
+func LookupApp(id string) (*App, bool) {
+	appsMu.RLock()
+	defer appsMu.RUnlock()
+	app, ok := apps[id]
+	return app, ok
+}
+
+// AppIDs lists the registered app IDs in ascending order
+func AppIDs() []string {
+	appsMu.RLock()
+	defer appsMu.RUnlock()
+	ids := make([]string, 0, len(apps))
+	for id := range apps {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := strconv.Atoi(ids[i])
+		b, _ := strconv.Atoi(ids[j])
+		return a < b
+	})
+	return ids
+}
+
+// SetDefaultApp makes a registered title the one served when a request names none
+func SetDefaultApp(id string) error {
+	appsMu.Lock()
+	defer appsMu.Unlock()
+	if _, ok := apps[id]; !ok {
+		return fmt.Errorf("app %s is not registered", id)
+	}
+	defaultAppID = id
+	return nil
+}
+
+// DefaultApp returns the title served when a request names none
+func DefaultApp() *App {
+	appsMu.RLock()
+	defer appsMu.RUnlock()
+	return apps[defaultAppID]
+}
+
+// ConfigureAppsFromEnv registers the extra titles in STEAM_APP_IDS and selects
+// DEFAULT_APP_ID. Entries are "id" for a title with empty registries or
+// "id=base" to share another title's, e.g. a test branch reusing DBD's
+// aliases, renamed stat IDs, adepts, events and units. Invalid entries are logged and skipped.
+func ConfigureAppsFromEnv() {
+	for _, raw := range strings.Split(os.Getenv("STEAM_APP_IDS"), ",") {
+		entry := strings.TrimSpace(raw)
+		if entry == "" {
+			continue
+		}
+		id, baseID, inherits := strings.Cut(entry, "=")
+		app := App{ID: strings.TrimSpace(id)}
+		if inherits {
+			base, ok := LookupApp(strings.TrimSpace(baseID))
+			if !ok {
+				log.Warn("Skipping app whose base app is not registered", "entry", entry)
+				continue
+			}
+			app.Name, app.Aliases, app.Canonical, app.Adepts = base.Name, base.Aliases, base.Canonical, base.Adepts
+			app.Events, app.Units, app.Realms = base.Events, base.Units, base.Realms
+		}
+		if _, exists := LookupApp(app.ID); exists {
+			continue // never replace a built-in registry from the environment
+		}
+		if err := RegisterApp(app); err != nil {
+			log.Warn("Skipping invalid STEAM_APP_IDS entry", "entry", entry, "error", err)
+		}
+	}
+
+	if id := strings.TrimSpace(os.Getenv("DEFAULT_APP_ID")); id != "" {
+		if err := SetDefaultApp(id); err != nil {
+			log.Warn("Ignoring DEFAULT_APP_ID; add it to STEAM_APP_IDS first",
+				"app_id", id, "error", err, "default", DefaultApp().ID)
+		}
+	}
+}
+
+type appKey struct{}
+
+// WithApp returns ctx carrying the title Steam calls made with it target
+func WithApp(ctx context.Context, app *App) context.Context {
+	return context.WithValue(ctx, appKey{}, app)
+}
+
+// AppFromContext returns the title carried by ctx. Background work carries
+// none and targets DBD; the default app only applies to requests.
+func AppFromContext(ctx context.Context) *App {
+	if app, ok := ctx.Value(appKey{}).(*App); ok {
+		return app
+	}
+	app, _ := LookupApp(DBDAppID)
+	return app
+}
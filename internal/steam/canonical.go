@@ -0,0 +1,84 @@
+package steam
+
+import "github.com/rgonzalez12/dbd-analytics/internal/log"
+
+// canonicalStatIDs folds stat IDs BHVR has renamed or duplicated into the one
+// logical stat responses report. The _iam variants count the same events as
+// their originals, and a renamed counter carries on from the old total, so the
+// larger value wins: adding them would double count.
+var canonicalStatIDs = map[string]string{
+	"DBD_SacrificedCampers_iam": "DBD_SacrificedCampers",
+	"DBD_KilledCampers_iam":     "DBD_KilledCampers",
+	"DBD_KillerSkulls":          "DBD_SlasherSkulls",
+	"DBD_Escapes":               "DBD_Escape",
+	"DBD_EscapesKO":             "DBD_EscapeKO",
+	"DBD_UncloakAttacks":        "DBD_UncloakAttack",
+	"DBD_UnhookOrHealPostExit":  "DBD_UnhookOrHeal_PostExit",
+	"DBD_GeneratorPct":          "DBD_GeneratorPct_float",
+	"DBD_HealPct":               "DBD_HealPct_float",
+}
+
+// StatSource is one raw Steam stat folded into a canonical stat
+type StatSource struct {
+	ID    string  `json:"id"`
+	Value float64 `json:"value"`
+}
+
+// CanonicalStatID returns the ID a renamed or duplicate stat is reported under
+func (a *App) CanonicalStatID(id string) string {
+	if canonical, ok := a.Canonical[id]; ok {
+		return canonical
+	}
+	return id
+}
+
+// canonicalizeStats merges stats the app knows under several IDs into one
+// entry per canonical ID, in first-seen order, keeping the largest value. A
+// merged entry lists every raw stat that fed it in Sources. It returns the
+// input slice when no ID needed folding, so cached payloads pass through.
+func canonicalizeStats(stats []SteamStat, canonical map[string]string) []SteamStat {
+	folds := false
+	for _, stat := range stats {
+		if _, ok := canonical[stat.Name]; ok {
+			folds = true
+			break
+		}
+	}
+	if !folds {
+		return stats
+	}
+
+	merged := make([]SteamStat, 0, len(stats))
+	index := make(map[string]int, len(stats))
+	renamed := make(map[string]bool)
+	for _, stat := range stats {
+		id := stat.Name
+		if target, ok := canonical[id]; ok {
+			id = target
+			renamed[id] = true
+		}
+		source := StatSource{ID: stat.Name, Value: stat.Value}
+		i, seen := index[id]
+		if !seen {
+			index[id] = len(merged)
+			merged = append(merged, SteamStat{Name: id, Value: stat.Value, Sources: []StatSource{source}})
+			continue
+		}
+		merged[i].Sources = append(merged[i].Sources, source)
+		if stat.Value > merged[i].Value {
+			merged[i].Value = stat.Value
+		}
+	}
+
+	// Provenance only matters where an ID was folded
+	for i := range merged {
+		if !renamed[merged[i].Name] {
+			merged[i].Sources = nil
+		}
+	}
+	log.Debug("Merged renamed Steam stats",
+		"received", len(stats),
+		"kept", len(merged),
+		"canonical_ids", len(renamed))
+	return merged
+}
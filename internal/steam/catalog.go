@@ -0,0 +1,56 @@
+package steam
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+// CatalogFromSchema lists every achievement in schema, classifying adepts
+// from app's registry. Rarity is left for ApplyRarityTrends.
+func CatalogFromSchema(schema *SchemaGame, app *App) []models.CatalogAchievement {
+	entries := make([]models.CatalogAchievement, 0, len(schema.AvailableGameStats.Achievements))
+	for _, achievement := range schema.AvailableGameStats.Achievements {
+		entry := models.CatalogAchievement{
+			ID:          achievement.Name,
+			DisplayName: achievement.DisplayName,
+			Description: achievement.Description,
+			Icon:        achievement.Icon,
+			Hidden:      achievement.Hidden == 1,
+			Type:        "general",
+		}
+		if entry.DisplayName == "" {
+			entry.DisplayName = achievement.Name
+		}
+		if adept, ok := app.Adepts[achievement.Name]; ok {
+			entry.Character = adept.Name
+			entry.Type = "adept_survivor"
+			if adept.Type == "killer" {
+				entry.Type = "adept_killer"
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].DisplayName == entries[j].DisplayName {
+			return entries[i].ID < entries[j].ID
+		}
+		return entries[i].DisplayName < entries[j].DisplayName
+	})
+	return entries
+}
+
+// ApplyRarityTrends sets each entry's current rarity from percentages and its
+// trend against history
+func ApplyRarityTrends(entries []models.CatalogAchievement, percentages map[string]float64, history []models.RaritySnapshot, now time.Time, config RarityTrendConfig) {
+	for i := range entries {
+		current, ok := percentages[entries[i].ID]
+		if !ok {
+			continue
+		}
+		entries[i].Rarity = current
+		entries[i].Trend = RarityTrend(entries[i].ID, current, history, now, config)
+	}
+}
@@ -2,16 +2,18 @@ package steam
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
 	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/notify"
 )
 
 const (
@@ -19,6 +21,14 @@ const (
 	DBDAppID = "381210"
 )
 
+// Cache namespaces for the Steam payloads this package shares through the cache
+var (
+	userStatsCache         = cache.NewNamespace[*SteamPlayerstats](cache.UserStatsPrefix)
+	schemaCache            = cache.NewNamespace[*SchemaGame](cache.SchemaPrefix)
+	globalPercentagesCache = cache.NewNamespace[map[string]float64](cache.GlobalPercentagesPrefix)
+	adeptMapCache          = cache.NewNamespace[map[string]AdeptEntry](cache.AdeptMapPrefix)
+)
+
 func achievementTimeout() time.Duration {
 	if timeoutStr := os.Getenv("ACHIEVEMENTS_TIMEOUT_SECS"); timeoutStr != "" {
 		if timeoutSecs, err := strconv.Atoi(timeoutStr); err == nil && timeoutSecs > 0 {
@@ -66,6 +76,11 @@ type Client struct {
 	apiKey      string
 	client      *http.Client
 	retryConfig RetryConfig
+	hosts       *hostPool
+	adepts      *adeptStore
+	schemas     *schemaStore
+	quota       *Quota
+	hooks       atomic.Pointer[hookSet] // nil unless SetHooks was called
 }
 
 type playerSummaryResponse struct {
@@ -88,10 +103,35 @@ func NewClient() *Client {
 			Timeout: achievementTimeout(),
 		},
 		retryConfig: DefaultRetryConfig(),
+		hosts:       newHostPool(loadBaseURLs()),
+		adepts:      &adeptStore{},
+		schemas:     schemaStoreFromEnv(),
+		quota:       NewQuota(QuotaConfigFromEnv()),
 	}
 }
 
+// Quota returns the daily Steam API usage tracker
+func (c *Client) Quota() *Quota {
+	return c.quota
+}
+
+// Close stops the client's background host health probe. A client is
+// usable until then; short-lived clients must still be closed.
+func (c *Client) Close() {
+	c.hosts.Close()
+}
+
+// HostStatus returns the health of each configured Steam API base URL
+func (c *Client) HostStatus() []HostStatus {
+	return c.hosts.Status()
+}
+
 func (c *Client) GetPlayerSummary(steamIDOrVanity string) (*SteamPlayer, *APIError) {
+	return c.GetPlayerSummaryContext(context.Background(), steamIDOrVanity)
+}
+
+// GetPlayerSummaryContext is GetPlayerSummary bounded by ctx; cancellation aborts in-flight requests and retries
+func (c *Client) GetPlayerSummaryContext(ctx context.Context, steamIDOrVanity string) (*SteamPlayer, *APIError) {
 	start := time.Now()
 	if c.apiKey == "" {
 		return nil, NewValidationError("STEAM_API_KEY environment variable not set")
@@ -99,7 +139,7 @@ func (c *Client) GetPlayerSummary(steamIDOrVanity string) (*SteamPlayer, *APIErr
 
 	log.PlayerContext(steamIDOrVanity).Info("Starting player summary request", "steam_id_or_vanity", steamIDOrVanity)
 
-	steamID64, err := c.resolveSteamID(steamIDOrVanity)
+	steamID64, err := c.resolveSteamID(ctx, steamIDOrVanity)
 	if err != nil {
 		wrappedErr := &APIError{
 			Type:       err.Type,
@@ -112,7 +152,7 @@ func (c *Client) GetPlayerSummary(steamIDOrVanity string) (*SteamPlayer, *APIErr
 		return nil, wrappedErr
 	}
 
-	endpoint := fmt.Sprintf("%s/ISteamUser/GetPlayerSummaries/v0002/", BaseURL)
+	endpoint := "/ISteamUser/GetPlayerSummaries/v0002/"
 	logger := log.SteamAPIContext(steamIDOrVanity, endpoint)
 
 	logger.Info("Executing player summary request", "resolved_steam_id", steamID64)
@@ -124,7 +164,7 @@ func (c *Client) GetPlayerSummary(steamIDOrVanity string) (*SteamPlayer, *APIErr
 	var resp playerSummaryResponse
 
 	retryErr := withRetryAndLogging(c.retryConfig, func() (*APIError, bool) {
-		if err := c.makeRequest(endpoint, params, &resp); err != nil {
+		if err := c.makeRequestContext(ctx, endpoint, params, &resp); err != nil {
 			wrappedErr := &APIError{
 				Type:       err.Type,
 				Message:    fmt.Sprintf("GetPlayerSummary API request failed: %s", err.Message),
@@ -156,14 +196,88 @@ func (c *Client) GetPlayerSummary(steamIDOrVanity string) (*SteamPlayer, *APIErr
 	return &resp.Response.Players[0], nil
 }
 
+// MaxSummaryBatch is how many SteamID64s one GetPlayerSummaries call accepts
+const MaxSummaryBatch = 100
+
+// GetPlayerSummariesContext fetches up to MaxSummaryBatch players' summaries
+// in one call, keyed by SteamID64. The IDs must already be SteamID64s; ones
+// Steam knows no account for are absent from the result.
+func (c *Client) GetPlayerSummariesContext(ctx context.Context, steamIDs []string) (map[string]SteamPlayer, *APIError) {
+	start := time.Now()
+	if c.apiKey == "" {
+		return nil, NewValidationError("STEAM_API_KEY environment variable not set")
+	}
+	if len(steamIDs) == 0 || len(steamIDs) > MaxSummaryBatch {
+		return nil, NewValidationError(fmt.Sprintf("GetPlayerSummaries takes between 1 and %d Steam IDs", MaxSummaryBatch))
+	}
+
+	endpoint := "/ISteamUser/GetPlayerSummaries/v0002/"
+	params := url.Values{}
+	params.Set("key", c.apiKey)
+	params.Set("steamids", strings.Join(steamIDs, ","))
+
+	var resp playerSummaryResponse
+	retryErr := withRetryAndLogging(c.retryConfig, func() (*APIError, bool) {
+		if err := c.makeRequestContext(ctx, endpoint, params, &resp); err != nil {
+			return &APIError{
+				Type:       err.Type,
+				Message:    fmt.Sprintf("GetPlayerSummaries API request failed: %s", err.Message),
+				StatusCode: err.StatusCode,
+				Retryable:  err.Retryable,
+			}, false
+		}
+		return nil, false
+	}, "GetPlayerSummaries")
+	if retryErr != nil {
+		return nil, retryErr
+	}
+
+	players := make(map[string]SteamPlayer, len(resp.Response.Players))
+	for _, player := range resp.Response.Players {
+		players[player.SteamID] = player
+	}
+	durationMs := float64(time.Since(start).Nanoseconds()) / 1e6
+	logSteamPerformance("GetPlayerSummaries", steamIDs[0], endpoint, durationMs,
+		"requested", len(steamIDs),
+		"found", len(players),
+		"status_code", 200)
+	return players, nil
+}
+
+// keyCheckSteamID is a long-standing public profile used to exercise the key
+const keyCheckSteamID = "76561197960287930"
+
+// VerifyKey makes one cheap authenticated call (a single player summary) so a
+// rejected or missing key is caught before the server starts serving errors
+func (c *Client) VerifyKey(ctx context.Context) *APIError {
+	if c.apiKey == "" {
+		return NewValidationError("STEAM_API_KEY environment variable not set")
+	}
+	params := url.Values{}
+	params.Set("key", c.apiKey)
+	params.Set("steamids", keyCheckSteamID)
+
+	var resp playerSummaryResponse
+	return c.makeRequestContext(ctx, "/ISteamUser/GetPlayerSummaries/v0002/", params, &resp)
+}
+
 func (c *Client) GetPlayerStats(steamIDOrVanity string) (*SteamPlayerstats, *APIError) {
+	return c.GetPlayerStatsContext(context.Background(), steamIDOrVanity)
+}
+
+// GetPlayerStatsContext is GetPlayerStats bounded by ctx, for the app ctx carries
+func (c *Client) GetPlayerStatsContext(ctx context.Context, steamIDOrVanity string) (*SteamPlayerstats, *APIError) {
+	return c.getPlayerStats(ctx, steamIDOrVanity, AppFromContext(ctx).ID)
+}
+
+func (c *Client) getPlayerStats(ctx context.Context, steamIDOrVanity, appID string) (*SteamPlayerstats, *APIError) {
 	if c.apiKey == "" {
 		return nil, NewValidationError("STEAM_API_KEY environment variable not set")
 	}
 
 	logSteamInfo("Starting player stats request", steamIDOrVanity, "steam_id_or_vanity", steamIDOrVanity)
 
-	steamID64, err := c.resolveSteamID(steamIDOrVanity)
+	steamID64, err := c.resolveSteamID(ctx, steamIDOrVanity)
 	if err != nil {
 		wrappedErr := &APIError{
 			Type:       err.Type,
@@ -175,16 +289,16 @@ func (c *Client) GetPlayerStats(steamIDOrVanity string) (*SteamPlayerstats, *API
 		return nil, wrappedErr
 	}
 
-	endpoint := fmt.Sprintf("%s/ISteamUserStats/GetUserStatsForGame/v2/", BaseURL)
+	endpoint := "/ISteamUserStats/GetUserStatsForGame/v2/"
 	params := url.Values{}
-	params.Set("appid", DBDAppID)
+	params.Set("appid", appID)
 	params.Set("key", c.apiKey)
 	params.Set("steamid", steamID64)
 
 	var resp playerStatsResponse
 
 	retryErr := withRetryAndLogging(c.retryConfig, func() (*APIError, bool) {
-		if err := c.makeRequest(endpoint, params, &resp); err != nil {
+		if err := c.makeRequestContext(ctx, endpoint, params, &resp); err != nil {
 			// Wrap API request errors with additional context
 			wrappedErr := &APIError{
 				Type:       err.Type,
@@ -201,59 +315,51 @@ func (c *Client) GetPlayerStats(steamIDOrVanity string) (*SteamPlayerstats, *API
 		return nil, retryErr
 	}
 
+	resp.Playerstats.Stats = sanitizeStats(resp.Playerstats.Stats)
+	if app, ok := LookupApp(appID); ok {
+		resp.Playerstats.Stats = canonicalizeStats(resp.Playerstats.Stats, app.Canonical)
+	}
 	logSteamInfo("Successfully retrieved player stats", steamID64,
 		"stats_count", len(resp.Playerstats.Stats))
 	return &resp.Playerstats, nil
 }
 
-// GetUserStatsForGame gets user stats for a specific game - alias for GetPlayerStats with context
+// GetUserStatsForGame gets user stats for a specific game
 func (c *Client) GetUserStatsForGame(ctx context.Context, steamID string, appID int) (*SteamPlayerstats, *APIError) {
-	// Use existing GetPlayerStats method (it already uses DBDAppID)
-	return c.GetPlayerStats(steamID)
+	return c.getPlayerStats(ctx, steamID, strconv.Itoa(appID))
 }
 
-// GetUserStatsForGameCached retrieves user stats with caching support
-func (c *Client) GetUserStatsForGameCached(ctx context.Context, steamID string, appID int, cacheManager interface{}) (*SteamPlayerstats, *APIError) {
-	if cacheManager != nil {
-		cache, ok := cacheManager.(interface {
-			Get(key string) (interface{}, bool)
-			Set(key string, value interface{}, ttl time.Duration) error
-		})
-		if ok {
-			cacheKey := fmt.Sprintf("user_stats_%s_%d", steamID, appID)
-
-			if cached, found := cache.Get(cacheKey); found {
-				if stats, ok := cached.(*SteamPlayerstats); ok {
-					log.Debug("Using cached user stats", "steam_id", steamID, "app_id", appID,
-						"cache_key", cacheKey, "stats_count", len(stats.Stats))
-					return stats, nil
-				} else {
-					log.Warn("Invalid cached user stats type",
-						"cache_key", cacheKey, "expected", "*SteamPlayerstats", "actual", fmt.Sprintf("%T", cached))
-				}
-			}
-
-			// Cache miss - fetch from API
-			stats, err := c.GetUserStatsForGame(ctx, steamID, appID)
-			if err != nil {
-				return nil, err
-			}
-
-			// Cache the result
-			if cacheErr := cache.Set(cacheKey, stats, 2*time.Minute); cacheErr != nil {
-				log.Warn("Failed to cache user stats", "cache_key", cacheKey, "error", cacheErr)
-			} else {
-				log.Debug("User stats cached successfully", "cache_key", cacheKey, "stats_count", len(stats.Stats))
-			}
+// GetUserStatsForGameCached retrieves user stats with caching support; a nil cache fetches directly
+func (c *Client) GetUserStatsForGameCached(ctx context.Context, steamID string, appID int, cacheManager cache.Cache) (*SteamPlayerstats, *APIError) {
+	if cacheManager == nil {
+		return c.GetUserStatsForGame(ctx, steamID, appID)
+	}
 
-			return stats, nil
+	cacheKey := userStatsCache.Key(steamID, strconv.Itoa(appID))
+	ttl := time.Duration(float64(2*time.Minute) * c.quota.TTLMultiplier())
+	stats, hit, err := cache.GetOrLoad(ctx, cacheManager, cacheKey, ttl, func(ctx context.Context) (*SteamPlayerstats, error) {
+		stats, apiErr := c.GetUserStatsForGame(ctx, steamID, appID)
+		if apiErr != nil {
+			return nil, apiErr
 		}
+		return stats, nil
+	})
+	if err != nil {
+		return nil, AsAPIError(err)
 	}
-
-	return c.GetUserStatsForGame(ctx, steamID, appID)
+	if hit {
+		log.Debug("Using cached user stats", "steam_id", steamID, "app_id", appID,
+			"cache_key", cacheKey, "stats_count", len(stats.Stats))
+	}
+	return stats, nil
 }
 
 func (c *Client) GetPlayerAchievements(steamID string, appID int) (*PlayerAchievements, *APIError) {
+	return c.GetPlayerAchievementsContext(context.Background(), steamID, appID)
+}
+
+// GetPlayerAchievementsContext is GetPlayerAchievements bounded by ctx
+func (c *Client) GetPlayerAchievementsContext(ctx context.Context, steamID string, appID int) (*PlayerAchievements, *APIError) {
 	start := time.Now()
 	if c.apiKey == "" {
 		return nil, NewValidationError("STEAM_API_KEY environment variable not set")
@@ -262,7 +368,7 @@ func (c *Client) GetPlayerAchievements(steamID string, appID int) (*PlayerAchiev
 	logSteamInfo("Starting player achievements request", steamID,
 		"steam_id", steamID, "app_id", appID)
 
-	steamID64, err := c.resolveSteamID(steamID)
+	steamID64, err := c.resolveSteamID(ctx, steamID)
 	if err != nil {
 		wrappedErr := &APIError{
 			Type:       err.Type,
@@ -275,7 +381,7 @@ func (c *Client) GetPlayerAchievements(steamID string, appID int) (*PlayerAchiev
 		return nil, wrappedErr
 	}
 
-	endpoint := fmt.Sprintf("%s/ISteamUserStats/GetPlayerAchievements/v0001/", BaseURL)
+	endpoint := "/ISteamUserStats/GetPlayerAchievements/v0001/"
 	params := url.Values{}
 	params.Set("key", c.apiKey)
 	params.Set("steamid", steamID64)
@@ -285,7 +391,7 @@ func (c *Client) GetPlayerAchievements(steamID string, appID int) (*PlayerAchiev
 	var resp playerAchievementsResponse
 
 	retryErr := withRetryAndLogging(c.retryConfig, func() (*APIError, bool) {
-		if err := c.makeRequest(endpoint, params, &resp); err != nil {
+		if err := c.makeRequestContext(ctx, endpoint, params, &resp); err != nil {
 			wrappedErr := &APIError{
 				Type:       err.Type,
 				Message:    fmt.Sprintf("GetPlayerAchievements API request failed: %s", err.Message),
@@ -317,53 +423,85 @@ func (c *Client) GetPlayerAchievements(steamID string, appID int) (*PlayerAchiev
 	return &resp.Playerstats, nil
 }
 
-func (c *Client) resolveSteamID(steamIDOrVanity string) (string, *APIError) {
-	if len(steamIDOrVanity) == 17 && isNumeric(steamIDOrVanity) {
-		return steamIDOrVanity, nil
-	}
+// GetPlayerBans retrieves VAC, game and community ban status for a resolved Steam ID
+func (c *Client) GetPlayerBans(steamID string) (*PlayerBans, *APIError) {
+	return c.GetPlayerBansContext(context.Background(), steamID)
+}
 
-	logSteamInfo("Resolving vanity URL to Steam ID", steamIDOrVanity, "vanity_url", steamIDOrVanity)
+// GetPlayerBansContext is GetPlayerBans bounded by ctx
+func (c *Client) GetPlayerBansContext(ctx context.Context, steamID string) (*PlayerBans, *APIError) {
+	start := time.Now()
+	if c.apiKey == "" {
+		return nil, NewValidationError("STEAM_API_KEY environment variable not set")
+	}
 
-	endpoint := fmt.Sprintf("%s/ISteamUser/ResolveVanityURL/v0001/", BaseURL)
+	endpoint := "/ISteamUser/GetPlayerBans/v1/"
 	params := url.Values{}
 	params.Set("key", c.apiKey)
-	params.Set("vanityurl", steamIDOrVanity)
+	params.Set("steamids", steamID)
 
-	var resp VanityURLResponse
+	var resp playerBansResponse
 
 	retryErr := withRetryAndLogging(c.retryConfig, func() (*APIError, bool) {
-		if err := c.makeRequest(endpoint, params, &resp); err != nil {
-			return err, false
+		if err := c.makeRequestContext(ctx, endpoint, params, &resp); err != nil {
+			wrappedErr := &APIError{
+				Type:       err.Type,
+				Message:    fmt.Sprintf("GetPlayerBans API request failed: %s", err.Message),
+				StatusCode: err.StatusCode,
+				Retryable:  err.Retryable,
+			}
+			return wrappedErr, false
 		}
 		return nil, false
-	}, "ResolveVanityURL")
+	}, "GetPlayerBans")
 
 	if retryErr != nil {
-		return "", retryErr
+		return nil, retryErr
 	}
 
-	if resp.Response.Success != 1 {
-		return "", NewNotFoundError("Vanity URL")
+	if len(resp.Players) == 0 {
+		notFoundErr := NewNotFoundError("Player bans")
+		notFoundErr.Message = fmt.Sprintf("GetPlayerBans: no ban record for Steam ID %s", steamID)
+		return nil, notFoundErr
 	}
 
-	log.Info("Successfully resolved vanity URL",
-		"vanity_url", steamIDOrVanity,
-		"steam_id", resp.Response.SteamID)
-	return resp.Response.SteamID, nil
+	logSteamInfo("Successfully retrieved player bans", steamID,
+		"vac_banned", resp.Players[0].VACBanned,
+		"game_bans", resp.Players[0].NumberOfGameBans,
+		"duration", time.Since(start))
+
+	return &resp.Players[0], nil
+}
+
+func (c *Client) resolveSteamID(ctx context.Context, steamIDOrVanity string) (string, *APIError) {
+	steamID, _, err := c.ResolveSteamIDAs(ctx, steamIDOrVanity, IDTypeAuto)
+	return steamID, err
 }
 
 // ResolveSteamID resolves a vanity URL to Steam ID, or returns input if already a Steam ID
 func (c *Client) ResolveSteamID(steamIDOrVanity string) (string, *APIError) {
-	return c.resolveSteamID(steamIDOrVanity)
+	return c.resolveSteamID(context.Background(), steamIDOrVanity)
 }
 
 func (c *Client) makeRequest(endpoint string, params url.Values, result interface{}) *APIError {
+	return c.makeRequestContext(context.Background(), endpoint, params, result)
+}
+
+// makeRequestContext is makeRequest with cancellation: a done ctx stops retries,
+// interrupts backoff sleeps and aborts the in-flight HTTP request.
+func (c *Client) makeRequestContext(ctx context.Context, endpoint string, params url.Values, result interface{}) *APIError {
 	var lastErr *APIError
 
 	for attempt := 0; attempt <= c.retryConfig.MaxAttempts; attempt++ {
 		// Wait before retry attempt
 		if attempt > 0 {
 			delay := c.calculateRetryDelay(lastErr, attempt-1)
+			c.hooksOrNop().OnRetry(RetryInfo{
+				Endpoint: endpoint,
+				Attempt:  attempt + 1,
+				Delay:    delay,
+				LastErr:  hookError(lastErr),
+			})
 
 			log.Info("steam_api_retry_attempt",
 				"attempt", attempt,
@@ -371,122 +509,162 @@ func (c *Client) makeRequest(endpoint string, params url.Values, result interfac
 				"delay_seconds", delay.Seconds(),
 				"endpoint", endpoint)
 
-			time.Sleep(delay)
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return NewTimeoutError(ctx.Err())
+			}
 		}
 
-		apiURL := endpoint + "?" + params.Encode()
-		start := time.Now()
-
-		log.Info("steam_api_request_start",
-			"endpoint", endpoint,
-			"method", "GET",
-			"url", apiURL,
-			"attempt", attempt+1)
+		// Try each host in health order; only host-level failures move on to the next one
+		for _, baseURL := range c.hosts.candidates() {
+			lastErr = c.requestFromHost(ctx, baseURL, endpoint, params, result, attempt)
+			if ctx.Err() != nil {
+				// Our own deadline, not the host's fault
+				return NewTimeoutError(ctx.Err())
+			}
+			if lastErr == nil {
+				c.hosts.markSuccess(baseURL)
+				return nil // Success!
+			}
+			if !isHostFailure(lastErr) {
+				break
+			}
+			c.hosts.markFailure(baseURL, lastErr.Message)
+		}
 
-		resp, err := c.client.Get(apiURL)
-		requestDuration := time.Since(start)
-
-		if err != nil {
-			log.Error("steam_api_request_failed",
-				"error", err.Error(),
-				"endpoint", endpoint,
-				"duration", requestDuration,
-				"duration_ms", fmt.Sprintf("%.2f", requestDuration.Seconds()*1000),
-				"error_type", "network_error",
-				"attempt", attempt+1)
-			lastErr = NewInternalError(fmt.Errorf("error making GET request to %s: %w", apiURL, err))
-			if !shouldRetryError(lastErr) || attempt >= c.retryConfig.MaxAttempts {
-				return lastErr
+		if !shouldRetryError(lastErr) || attempt >= c.retryConfig.MaxAttempts {
+			if lastErr.Type == ErrorTypeRateLimit {
+				notify.Publish(notify.EventKeyQuotaExhausted, notify.SeverityCritical,
+					"Steam API key rate limited",
+					"Steam kept returning 429 after all retries",
+					map[string]interface{}{
+						"endpoint":    endpoint,
+						"retry_after": lastErr.RetryAfter,
+						"attempts":    attempt + 1,
+					})
 			}
-			continue
+			return lastErr
 		}
-		defer resp.Body.Close()
+	}
+
+	return lastErr
+}
+
+// requestFromHost performs a single GET against one Steam API host and decodes the JSON body
+func (c *Client) requestFromHost(ctx context.Context, baseURL, endpoint string, params url.Values, result interface{}, attempt int) (apiErr *APIError) {
+	apiURL := baseURL + endpoint + "?" + params.Encode()
+	start := time.Now()
 
-		log.Info("steam_api_request_completed",
+	statusCode := 0
+	endObserve := c.observeRequest(ctx, endpoint, baseURL, attempt+1)
+	defer func() { endObserve(statusCode, hookError(apiErr)) }()
+
+	log.Info("steam_api_request_start",
+		"endpoint", endpoint,
+		"host", baseURL,
+		"method", "GET",
+		"attempt", attempt+1)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return NewInternalError(fmt.Errorf("failed to create request for %s%s: %w", baseURL, endpoint, err))
+	}
+
+	c.quota.RecordCall()
+	resp, err := c.client.Do(req)
+	requestDuration := time.Since(start)
+
+	if err != nil {
+		log.Error("steam_api_request_failed",
+			"error", err.Error(),
 			"endpoint", endpoint,
-			"status_code", resp.StatusCode,
+			"host", baseURL,
 			"duration", requestDuration,
 			"duration_ms", fmt.Sprintf("%.2f", requestDuration.Seconds()*1000),
-			"content_length", resp.Header.Get("Content-Length"),
+			"error_type", "network_error",
 			"attempt", attempt+1)
+		return NewInternalError(fmt.Errorf("error making GET request to %s%s: %w", baseURL, endpoint, err))
+	}
+	defer resp.Body.Close()
+	statusCode = resp.StatusCode
 
-		// Handle rate limiting with header parsing
-		if resp.StatusCode == http.StatusTooManyRequests {
-			retryAfter := c.parseRateLimitHeaders(resp.Header)
-			log.Warn("steam_api_rate_limited",
-				"status_code", resp.StatusCode,
-				"endpoint", endpoint,
-				"duration", requestDuration,
-				"retry_after_seconds", retryAfter,
-				"retry_after_header", resp.Header.Get("Retry-After"),
-				"rate_limit_reset_header", resp.Header.Get("X-RateLimit-Reset"),
-				"attempt", attempt+1)
-			lastErr = NewRateLimitErrorWithRetryAfter(retryAfter)
-			if !shouldRetryError(lastErr) || attempt >= c.retryConfig.MaxAttempts {
-				return lastErr
-			}
-			continue
-		}
+	log.Info("steam_api_request_completed",
+		"endpoint", endpoint,
+		"host", baseURL,
+		"status_code", resp.StatusCode,
+		"duration", requestDuration,
+		"duration_ms", fmt.Sprintf("%.2f", requestDuration.Seconds()*1000),
+		"content_length", resp.Header.Get("Content-Length"),
+		"attempt", attempt+1)
+
+	// Handle rate limiting with header parsing
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := c.parseRateLimitHeaders(resp.Header)
+		log.Warn("steam_api_rate_limited",
+			"status_code", resp.StatusCode,
+			"endpoint", endpoint,
+			"duration", requestDuration,
+			"retry_after_seconds", retryAfter,
+			"retry_after_header", resp.Header.Get("Retry-After"),
+			"rate_limit_reset_header", resp.Header.Get("X-RateLimit-Reset"),
+			"attempt", attempt+1)
+		c.hooksOrNop().OnRateLimit(RateLimitInfo{
+			Endpoint:   endpoint,
+			Host:       baseURL,
+			RetryAfter: time.Duration(retryAfter) * time.Second,
+		})
+		return NewRateLimitErrorWithRetryAfter(retryAfter)
+	}
 
-		// Handle other HTTP errors using specific retryable status codes
-		if resp.StatusCode != http.StatusOK {
-			log.Error("steam_api_http_error",
-				"status_code", resp.StatusCode,
-				"endpoint", endpoint,
-				"duration", requestDuration,
-				"error_type", "http_error",
-				"attempt", attempt+1)
-			lastErr = NewAPIError(resp.StatusCode, fmt.Sprintf("HTTP %d from %s", resp.StatusCode, apiURL))
-			if !shouldRetryError(lastErr) || attempt >= c.retryConfig.MaxAttempts {
-				return lastErr
-			}
-			continue
-		}
+	// Handle other HTTP errors using specific retryable status codes
+	if resp.StatusCode != http.StatusOK {
+		log.Error("steam_api_http_error",
+			"status_code", resp.StatusCode,
+			"endpoint", endpoint,
+			"host", baseURL,
+			"duration", requestDuration,
+			"error_type", "http_error",
+			"attempt", attempt+1)
+		return NewAPIError(resp.StatusCode, fmt.Sprintf("HTTP %d from %s%s", resp.StatusCode, baseURL, endpoint))
+	}
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			log.Error("steam_api_response_read_failed",
-				"error", err.Error(),
-				"endpoint", endpoint,
-				"duration", requestDuration,
-				"attempt", attempt+1)
-			lastErr = NewInternalError(fmt.Errorf("failed to read response body from %s: %w", apiURL, err))
-			if !shouldRetryError(lastErr) || attempt >= c.retryConfig.MaxAttempts {
-				return lastErr
-			}
-			continue
-		}
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		log.Error("steam_api_response_read_failed",
+			"error", err.Error(),
+			"endpoint", endpoint,
+			"duration", requestDuration,
+			"attempt", attempt+1)
+		return NewInternalError(fmt.Errorf("failed to read response body from %s%s: %w", baseURL, endpoint, err))
+	}
 
-		if err := json.Unmarshal(body, result); err != nil {
-			previewLen := len(body)
-			if previewLen > 200 {
-				previewLen = 200
-			}
-			log.Error("steam_api_json_parse_failed",
-				"error", err.Error(),
-				"endpoint", endpoint,
-				"duration", requestDuration,
-				"response_size", len(body),
-				"body_preview", string(body)[:previewLen],
-				"attempt", attempt+1)
-			lastErr = NewInternalError(fmt.Errorf("failed to parse JSON response from %s: %w", apiURL, err))
-			if !shouldRetryError(lastErr) || attempt >= c.retryConfig.MaxAttempts {
-				return lastErr
-			}
-			continue
+	if err := decodeSteamJSON(endpoint, body, result); err != nil {
+		previewLen := len(body)
+		if previewLen > 200 {
+			previewLen = 200
 		}
-
-		log.Info("steam_api_request_success",
+		log.Error("steam_api_json_parse_failed",
+			"error", err.Error(),
 			"endpoint", endpoint,
-			"status_code", resp.StatusCode,
 			"duration", requestDuration,
-			"duration_ms", fmt.Sprintf("%.2f", requestDuration.Seconds()*1000),
+			"response_size", len(body),
+			"body_preview", string(body)[:previewLen],
 			"attempt", attempt+1)
-
-		return nil // Success!
+		return NewInternalError(fmt.Errorf("failed to parse JSON response from %s%s: %w", baseURL, endpoint, err))
 	}
 
-	return lastErr
+	log.Info("steam_api_request_success",
+		"endpoint", endpoint,
+		"host", baseURL,
+		"status_code", resp.StatusCode,
+		"duration", requestDuration,
+		"duration_ms", fmt.Sprintf("%.2f", requestDuration.Seconds()*1000),
+		"attempt", attempt+1)
+
+	return nil
 }
 
 func (c *Client) calculateRetryDelay(lastErr *APIError, attempt int) time.Duration {
@@ -546,6 +724,11 @@ func isNumeric(s string) bool {
 
 // GetSchemaForGame retrieves the game schema including achievements and stats
 func (c *Client) GetSchemaForGame(appID string) (*SchemaGame, *APIError) {
+	return c.GetSchemaForGameContext(context.Background(), appID)
+}
+
+// GetSchemaForGameContext is GetSchemaForGame bounded by ctx
+func (c *Client) GetSchemaForGameContext(ctx context.Context, appID string) (_ *SchemaGame, apiErr *APIError) {
 	log.Info("GetSchemaForGame called", "app_id", appID, "api_key_exists", c.apiKey != "", "api_key_length", len(c.apiKey))
 
 	if c.apiKey == "" {
@@ -553,32 +736,47 @@ func (c *Client) GetSchemaForGame(appID string) (*SchemaGame, *APIError) {
 		return nil, NewValidationError("STEAM_API_KEY environment variable not set")
 	}
 
+	baseURL := c.hosts.primary()
 	url := fmt.Sprintf("%s/ISteamUserStats/GetSchemaForGame/v2/?key=%s&appid=%s&l=en",
-		BaseURL, c.apiKey, appID)
+		baseURL, c.apiKey, appID)
+
+	log.Info("Making schema request", "host", baseURL, "app_id", appID)
 
-	log.Info("Making schema request", "url", url)
+	statusCode := 0
+	endObserve := c.observeRequest(ctx, "/ISteamUserStats/GetSchemaForGame/v2/", baseURL, 1)
+	defer func() { endObserve(statusCode, hookError(apiErr)) }()
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, NewInternalError(err)
 	}
 
+	c.quota.RecordCall()
 	resp, err := c.client.Do(req)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, NewTimeoutError(ctx.Err())
+		}
 		log.Error("Network error in schema request", "error", err)
+		c.hosts.markFailure(baseURL, err.Error())
 		return nil, NewInternalError(err)
 	}
 	defer resp.Body.Close()
+	statusCode = resp.StatusCode
 
 	log.Info("Schema request completed", "status_code", resp.StatusCode)
 
 	if resp.StatusCode != http.StatusOK {
-		log.Error("Non-200 response from schema request", "status_code", resp.StatusCode, "url", url)
+		log.Error("Non-200 response from schema request", "status_code", resp.StatusCode, "host", baseURL)
+		if resp.StatusCode >= 500 {
+			c.hosts.markFailure(baseURL, fmt.Sprintf("HTTP %d", resp.StatusCode))
+		}
 		return nil, NewAPIError(resp.StatusCode,
-			fmt.Sprintf("HTTP %d from %s", resp.StatusCode, url))
+			fmt.Sprintf("HTTP %d from %s schema endpoint", resp.StatusCode, baseURL))
 	}
+	c.hosts.markSuccess(baseURL)
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponseBody(resp.Body)
 	if err != nil {
 		log.Error("Error reading schema response body", "error", err)
 		return nil, NewInternalError(err)
@@ -587,7 +785,7 @@ func (c *Client) GetSchemaForGame(appID string) (*SchemaGame, *APIError) {
 	log.Info("Schema response read", "body_length", len(body))
 
 	var response schemaForGameResponse
-	if err := json.Unmarshal(body, &response); err != nil {
+	if err := decodeSteamJSON("/ISteamUserStats/GetSchemaForGame/v2/", body, &response); err != nil {
 		bodyPreview := string(body)
 		if len(bodyPreview) > 200 {
 			bodyPreview = bodyPreview[:200] + "..."
@@ -602,90 +800,130 @@ func (c *Client) GetSchemaForGame(appID string) (*SchemaGame, *APIError) {
 		log.Info("Schema response parsed successfully", "achievement_count", len(response.Game.AvailableGameStats.Achievements))
 	}
 
+	c.schemas.save(appID, &response.Game)
 	return &response.Game, nil
 }
 
-// FetchGlobalAchievementPercentages retrieves global achievement percentages for the specified app
-func (c *Client) FetchGlobalAchievementPercentages(ctx context.Context) (map[string]float64, error) {
+// GetSchemaForGameCached returns the schema of the app ctx carries, shared
+// through the cache for 24h like global percentages; a nil cache fetches
+// directly. When Steam fails, the last schema it served is read back from disk.
+func (c *Client) GetSchemaForGameCached(ctx context.Context, cacheManager cache.Cache) (*SchemaGame, *APIError) {
+	schema, _, apiErr := c.GetSchemaWithSource(ctx, cacheManager)
+	return schema, apiErr
+}
+
+// GetSchemaWithSource is GetSchemaForGameCached also reporting whether the
+// schema came from Steam or from disk (SchemaSourceSteam, SchemaSourceDisk)
+func (c *Client) GetSchemaWithSource(ctx context.Context, cacheManager cache.Cache) (*SchemaGame, string, *APIError) {
+	schema, apiErr := c.fetchSchemaCached(ctx, cacheManager)
+	if apiErr == nil {
+		return schema, SchemaSourceSteam, nil
+	}
+	appID := AppFromContext(ctx).ID
+	if persisted, ok := c.schemas.load(appID); ok {
+		log.Warn("Steam schema unavailable, using the persisted copy",
+			"app_id", appID,
+			"error", apiErr)
+		return persisted, SchemaSourceDisk, nil
+	}
+	return nil, "", apiErr
+}
+
+// fetchSchemaCached is GetSchemaForGameCached without the disk fallback
+func (c *Client) fetchSchemaCached(ctx context.Context, cacheManager cache.Cache) (*SchemaGame, *APIError) {
+	appID := AppFromContext(ctx).ID
+	if cacheManager == nil {
+		return c.GetSchemaForGameContext(ctx, appID)
+	}
+
+	cacheKey := schemaCache.Key(appID)
+	schema, hit, err := cache.GetOrLoad(ctx, cacheManager, cacheKey, 24*time.Hour, func(ctx context.Context) (*SchemaGame, error) {
+		schema, apiErr := c.GetSchemaForGameContext(ctx, appID)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		// An empty schema is usually a transient Steam hiccup; don't pin it for a day
+		if len(schema.AvailableGameStats.Achievements) == 0 {
+			return schema, cache.SkipStore
+		}
+		return schema, nil
+	})
+	if err != nil {
+		return nil, AsAPIError(err)
+	}
+	if hit {
+		log.Debug("Game schema cache hit", "cache_key", cacheKey)
+	}
+	return schema, nil
+}
+
+// FetchGlobalAchievementPercentages retrieves global achievement percentages for the app ctx carries
+func (c *Client) FetchGlobalAchievementPercentages(ctx context.Context) (_ map[string]float64, fetchErr error) {
 	if c.apiKey == "" {
 		return nil, fmt.Errorf("STEAM_API_KEY environment variable not set")
 	}
 
+	baseURL := c.hosts.primary()
 	url := fmt.Sprintf("%s/ISteamUserStats/GetGlobalAchievementPercentagesForApp/v0002/?gameid=%s",
-		BaseURL, DBDAppID)
+		baseURL, AppFromContext(ctx).ID)
+
+	statusCode := 0
+	endObserve := c.observeRequest(ctx, "/ISteamUserStats/GetGlobalAchievementPercentagesForApp/v0002/", baseURL, 1)
+	defer func() { endObserve(statusCode, fetchErr) }()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	c.quota.RecordCall()
 	resp, err := c.client.Do(req)
 	if err != nil {
+		c.hosts.markFailure(baseURL, err.Error())
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
+	statusCode = resp.StatusCode
 
 	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode >= 500 {
+			c.hosts.markFailure(baseURL, fmt.Sprintf("HTTP %d", resp.StatusCode))
+		}
 		return nil, fmt.Errorf("HTTP %d from Steam API", resp.StatusCode)
 	}
+	c.hosts.markSuccess(baseURL)
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponseBody(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	var response globalAchievementPercentagesResponse
-	if err := json.Unmarshal(body, &response); err != nil {
+	if err := decodeSteamJSON("/ISteamUserStats/GetGlobalAchievementPercentagesForApp/v0002/", body, &response); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	percentages := make(map[string]float64)
 	for _, ach := range response.AchievementPercentages.Achievements {
-		percentages[ach.Name] = ach.Percent
+		percentages[ach.Name] = clampPercent(ach.Percent)
 	}
 
 	return percentages, nil
 }
 
-// GetGlobalAchievementPercentagesCached retrieves global achievement percentages with caching
-func (c *Client) GetGlobalAchievementPercentagesCached(ctx context.Context, cacheManager interface{}) (map[string]float64, error) {
-	// Type assertion to get the cache interface
-	cache, ok := cacheManager.(interface {
-		Get(string) (interface{}, bool)
-		Set(string, interface{}, time.Duration) error
-	})
-	if !ok || cache == nil {
-		// No cache available, fetch directly
+// GetGlobalAchievementPercentagesCached retrieves global achievement percentages with caching; a nil cache fetches directly
+func (c *Client) GetGlobalAchievementPercentagesCached(ctx context.Context, cacheManager cache.Cache) (map[string]float64, error) {
+	if cacheManager == nil {
 		return c.FetchGlobalAchievementPercentages(ctx)
 	}
 
-	cacheKey := "global_percentages:dbd"
-
-	// Try to get from cache first
-	if cached, found := cache.Get(cacheKey); found {
-		if percentages, ok := cached.(map[string]float64); ok {
-			log.Debug("Global achievement percentages cache hit", "cache_key", cacheKey)
-			return percentages, nil
-		} else {
-			log.Warn("Invalid global percentages cache entry type, removing",
-				"cache_key", cacheKey, "expected", "map[string]float64", "actual", fmt.Sprintf("%T", cached))
-			// Continue to fetch fresh data
-		}
-	}
-
-	// Fetch fresh data
-	percentages, err := c.FetchGlobalAchievementPercentages(ctx)
+	cacheKey := globalPercentagesCache.Key(AppFromContext(ctx).cacheScope())
+	percentages, hit, err := cache.GetOrLoad(ctx, cacheManager, cacheKey, 24*time.Hour, c.FetchGlobalAchievementPercentages)
 	if err != nil {
 		return nil, err
 	}
-
-	// Cache the result for 24 hours
-	if err := cache.Set(cacheKey, percentages, 24*time.Hour); err != nil {
-		log.Error("Failed to cache global achievement percentages", "error", err, "cache_key", cacheKey)
-		// Don't fail the request if caching fails
-	} else {
-		log.Debug("Global achievement percentages cached successfully", "cache_key", cacheKey, "count", len(percentages))
+	if hit {
+		log.Debug("Global achievement percentages cache hit", "cache_key", cacheKey)
 	}
-
 	return percentages, nil
 }
@@ -0,0 +1,178 @@
+package steam
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+// unknownRarityPoints scores an achievement without global percentages as if
+// a tenth of players had it
+const unknownRarityPoints = 1.0
+
+// ComparePlayerData is what an achievement comparison needs from one player
+type ComparePlayerData struct {
+	SteamID      string
+	DisplayName  string
+	Achievements *models.AchievementData
+}
+
+// rarityPoints weighs an unlock by how few players have it: -log10(rarity/100),
+// so each tenfold rarer achievement is worth one point more (10% = 1, 1% = 2)
+// and one everybody has is worth nothing
+func rarityPoints(rarity float64) float64 {
+	if rarity <= 0 {
+		return unknownRarityPoints
+	}
+	if rarity >= 100 {
+		return 0
+	}
+	return round2(-math.Log10(rarity / 100))
+}
+
+// BuildAchievementComparison compares a and b's unlocks. Both players need
+// visible achievements.
+//
+//	points          = -log10(rarity / 100), 1 when rarity is unknown
+//	rarity_score    = Σ points over the player's unlocks
+//	exclusive_score = Σ points over unlocks the other player lacks
+//	bragging_rights = the higher exclusive_score
+func BuildAchievementComparison(a, b ComparePlayerData) models.AchievementComparison {
+	comparison := models.AchievementComparison{
+		Players: [2]models.ComparedPlayer{
+			{SteamID: a.SteamID, DisplayName: a.DisplayName},
+			{SteamID: b.SteamID, DisplayName: b.DisplayName},
+		},
+		Achievements: models.AchievementOverlap{
+			Both:  []models.ComparedAchievement{},
+			OnlyA: []models.ComparedAchievement{},
+			OnlyB: []models.ComparedAchievement{},
+		},
+	}
+
+	unlockedB := make(map[string]bool)
+	for _, ach := range b.Achievements.MappedAchievements {
+		if ach.Unlocked {
+			unlockedB[ach.ID] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	add := func(ach models.MappedAchievement, inA, inB bool) {
+		entry := models.ComparedAchievement{
+			ID:          ach.ID,
+			DisplayName: ach.DisplayName,
+			Type:        ach.Type,
+			Rarity:      ach.Rarity,
+			Points:      rarityPoints(ach.Rarity),
+		}
+		if inA {
+			comparison.Players[0].UnlockedCount++
+			comparison.Players[0].RarityScore += entry.Points
+		}
+		if inB {
+			comparison.Players[1].UnlockedCount++
+			comparison.Players[1].RarityScore += entry.Points
+		}
+		switch {
+		case inA && inB:
+			comparison.Achievements.Both = append(comparison.Achievements.Both, entry)
+		case inA:
+			comparison.Achievements.OnlyA = append(comparison.Achievements.OnlyA, entry)
+			comparison.Players[0].ExclusiveScore += entry.Points
+		case inB:
+			comparison.Achievements.OnlyB = append(comparison.Achievements.OnlyB, entry)
+			comparison.Players[1].ExclusiveScore += entry.Points
+		}
+	}
+	for _, ach := range a.Achievements.MappedAchievements {
+		seen[ach.ID] = true
+		add(ach, ach.Unlocked, unlockedB[ach.ID])
+	}
+	// Achievements only b's schema listed
+	for _, ach := range b.Achievements.MappedAchievements {
+		if !seen[ach.ID] && ach.Unlocked {
+			add(ach, false, true)
+		}
+	}
+
+	for _, list := range [][]models.ComparedAchievement{comparison.Achievements.Both, comparison.Achievements.OnlyA, comparison.Achievements.OnlyB} {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Points > list[j].Points })
+	}
+
+	comparison.Adepts.Survivors = characterOverlap(a.Achievements.AdeptSurvivors, b.Achievements.AdeptSurvivors)
+	comparison.Adepts.Killers = characterOverlap(a.Achievements.AdeptKillers, b.Achievements.AdeptKillers)
+	for i, data := range []ComparePlayerData{a, b} {
+		comparison.Players[i].AdeptCount = countUnlocked(data.Achievements.AdeptSurvivors) + countUnlocked(data.Achievements.AdeptKillers)
+	}
+
+	for i := range comparison.Players {
+		comparison.Players[i].RarityScore = round2(comparison.Players[i].RarityScore)
+		comparison.Players[i].ExclusiveScore = round2(comparison.Players[i].ExclusiveScore)
+	}
+	comparison.BraggingRights = braggingRights(comparison)
+	return comparison
+}
+
+func braggingRights(comparison models.AchievementComparison) models.BraggingRights {
+	a, b := comparison.Players[0], comparison.Players[1]
+	margin := round2(math.Abs(a.ExclusiveScore - b.ExclusiveScore))
+	if margin == 0 {
+		return models.BraggingRights{Reason: "Exclusive unlocks are worth the same"}
+	}
+
+	winner, loser := a, b
+	exclusive := len(comparison.Achievements.OnlyA)
+	if b.ExclusiveScore > a.ExclusiveScore {
+		winner, loser = b, a
+		exclusive = len(comparison.Achievements.OnlyB)
+	}
+	return models.BraggingRights{
+		Winner: winner.SteamID,
+		Margin: margin,
+		Reason: fmt.Sprintf("%d unlocks %s lacks, worth %.2f rarity points", exclusive, playerLabel(loser), winner.ExclusiveScore),
+	}
+}
+
+func playerLabel(player models.ComparedPlayer) string {
+	if player.DisplayName != "" {
+		return player.DisplayName
+	}
+	return player.SteamID
+}
+
+// characterOverlap splits adept characters by who has unlocked them, alphabetically
+func characterOverlap(a, b map[string]bool) models.CharacterOverlap {
+	overlap := models.CharacterOverlap{Both: []string{}, OnlyA: []string{}, OnlyB: []string{}}
+	for character, unlocked := range a {
+		if !unlocked {
+			continue
+		}
+		if b[character] {
+			overlap.Both = append(overlap.Both, character)
+		} else {
+			overlap.OnlyA = append(overlap.OnlyA, character)
+		}
+	}
+	for character, unlocked := range b {
+		if unlocked && !a[character] {
+			overlap.OnlyB = append(overlap.OnlyB, character)
+		}
+	}
+	sort.Strings(overlap.Both)
+	sort.Strings(overlap.OnlyA)
+	sort.Strings(overlap.OnlyB)
+	return overlap
+}
+
+func countUnlocked(characters map[string]bool) int {
+	count := 0
+	for _, unlocked := range characters {
+		if unlocked {
+			count++
+		}
+	}
+	return count
+}
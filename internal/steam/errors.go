@@ -1,6 +1,8 @@
 package steam
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 )
@@ -14,6 +16,7 @@ const (
 	ErrorTypeNetwork    ErrorType = "network_error"
 	ErrorTypeValidation ErrorType = "validation_error"
 	ErrorTypeInternal   ErrorType = "internal_error"
+	ErrorTypeTimeout    ErrorType = "timeout"
 )
 
 type APIError struct {
@@ -98,3 +101,27 @@ func NewInternalError(err error) *APIError {
 		Retryable: false,
 	}
 }
+
+// NewTimeoutError reports that the caller's deadline expired or the request was cancelled
+func NewTimeoutError(err error) *APIError {
+	return &APIError{
+		Type:       ErrorTypeTimeout,
+		Message:    fmt.Sprintf("Request cancelled: %v", err),
+		StatusCode: http.StatusRequestTimeout,
+		Retryable:  false,
+	}
+}
+
+// AsAPIError recovers the *APIError a cache loader failed with, wrapping a
+// caller's cancellation or anything else in one
+func AsAPIError(err error) *APIError {
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return NewTimeoutError(err)
+	default:
+		return NewInternalError(err)
+	}
+}
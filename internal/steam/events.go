@@ -0,0 +1,37 @@
+package steam
+
+import "time"
+
+// StatEvent is a limited-time event whose stats only move while it runs
+type StatEvent struct {
+	ID     string    `json:"id"`
+	Name   string    `json:"name"`
+	Starts time.Time `json:"starts_at"`
+	Ends   time.Time `json:"ends_at"` // zero while the event has no announced end
+}
+
+// Ended reports whether e was over at now
+func (e StatEvent) Ended(now time.Time) bool {
+	return !e.Ends.IsZero() && now.After(e.Ends)
+}
+
+var hallowedBlight2017 = StatEvent{
+	ID:     "hallowed_blight_2017",
+	Name:   "Hallowed Blight",
+	Starts: time.Date(2017, time.October, 19, 15, 0, 0, 0, time.UTC),
+	Ends:   time.Date(2017, time.November, 2, 15, 0, 0, 0, time.UTC),
+}
+
+// eventStats maps DBD's event stat IDs to the event that counted them. Steam
+// keeps reporting them long after the event, frozen at their final values.
+var eventStats = map[string]StatEvent{
+	"DBD_Event1_Stat1": hallowedBlight2017,
+	"DBD_Event1_Stat2": hallowedBlight2017,
+	"DBD_Event1_Stat3": hallowedBlight2017,
+}
+
+// StatEventFor returns the limited-time event a stat belongs to, if any
+func (a *App) StatEventFor(id string) (StatEvent, bool) {
+	event, ok := a.Events[a.CanonicalStatID(id)]
+	return event, ok
+}
@@ -0,0 +1,266 @@
+package steam
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxStatExprLength = 256
+	maxStatExprDepth  = 16
+	maxStatExprTerms  = 32
+)
+
+// StatExpr is a parsed arithmetic expression over raw Steam stat IDs, e.g.
+// DBD_Escape / DBD_MatchesPlayed. Only numbers, stat IDs, parentheses, unary
+// minus and + - * / are recognised; there are no functions or variables.
+type StatExpr struct {
+	source string
+	root   exprNode
+	stats  []string
+}
+
+// StatExprResult is the outcome of evaluating a StatExpr against one player's stats
+type StatExprResult struct {
+	Value     float64
+	Undefined bool     // a divisor evaluated to zero
+	Missing   []string // referenced stats the player has no value for, counted as zero
+}
+
+type exprNode interface {
+	eval(values map[string]float64, missing map[string]bool) (float64, bool)
+}
+
+type exprNumber float64
+
+type exprStat string
+
+type exprNegate struct{ operand exprNode }
+
+type exprBinary struct {
+	op          byte
+	left, right exprNode
+}
+
+func (n exprNumber) eval(map[string]float64, map[string]bool) (float64, bool) {
+	return float64(n), true
+}
+
+func (n exprStat) eval(values map[string]float64, missing map[string]bool) (float64, bool) {
+	value, ok := values[string(n)]
+	if !ok {
+		missing[string(n)] = true
+	}
+	return value, true
+}
+
+func (n exprNegate) eval(values map[string]float64, missing map[string]bool) (float64, bool) {
+	v, ok := n.operand.eval(values, missing)
+	return -v, ok
+}
+
+func (n exprBinary) eval(values map[string]float64, missing map[string]bool) (float64, bool) {
+	l, lok := n.left.eval(values, missing)
+	r, rok := n.right.eval(values, missing)
+	if !lok || !rok {
+		return 0, false
+	}
+	switch n.op {
+	case '+':
+		return l + r, true
+	case '-':
+		return l - r, true
+	case '*':
+		return l * r, true
+	default:
+		if r == 0 {
+			return 0, false
+		}
+		return l / r, true
+	}
+}
+
+// ParseStatExpr parses a stat expression, rejecting anything outside the grammar
+// and expressions too long or deeply nested to be a reasonable widget formula
+func ParseStatExpr(source string) (*StatExpr, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	if len(source) > maxStatExprLength {
+		return nil, fmt.Errorf("expression exceeds %d characters", maxStatExprLength)
+	}
+
+	p := &exprParser{src: source, seen: make(map[string]bool)}
+	root, err := p.parseSum(0)
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.src[p.pos], p.pos+1)
+	}
+	return &StatExpr{source: source, root: root, stats: p.stats}, nil
+}
+
+// String returns the expression as written, minus surrounding whitespace
+func (e *StatExpr) String() string {
+	return e.source
+}
+
+// Stats lists the distinct stat IDs referenced, in order of first appearance
+func (e *StatExpr) Stats() []string {
+	return append([]string(nil), e.stats...)
+}
+
+// Eval evaluates the expression against raw stat values keyed by stat ID.
+// Steam omits counters a player has never incremented, so absent stats count as
+// zero and are reported in Missing.
+func (e *StatExpr) Eval(values map[string]float64) StatExprResult {
+	missing := make(map[string]bool)
+	value, ok := e.root.eval(values, missing)
+
+	result := StatExprResult{Value: value, Undefined: !ok, Missing: []string{}}
+	for _, id := range e.stats {
+		if missing[id] {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+	if result.Undefined {
+		result.Value = 0
+	}
+	return result
+}
+
+type exprParser struct {
+	src   string
+	pos   int
+	terms int
+	stats []string
+	seen  map[string]bool
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// parseSum handles + and -, the lowest-precedence operators
+func (p *exprParser) parseSum(depth int) (exprNode, error) {
+	left, err := p.parseProduct(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseProduct(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = exprBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseProduct(depth int) (exprNode, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = exprBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseUnary(depth int) (exprNode, error) {
+	if depth > maxStatExprDepth {
+		return nil, fmt.Errorf("expression nests deeper than %d levels", maxStatExprDepth)
+	}
+	if p.peek() == '-' {
+		p.pos++
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return exprNegate{operand: operand}, nil
+	}
+	return p.parseOperand(depth)
+}
+
+func (p *exprParser) parseOperand(depth int) (exprNode, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end of expression")
+	case c == '(':
+		p.pos++
+		inner, err := p.parseSum(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis at position %d", p.pos+1)
+		}
+		p.pos++
+		return inner, nil
+	}
+
+	p.terms++
+	if p.terms > maxStatExprTerms {
+		return nil, fmt.Errorf("expression has more than %d operands", maxStatExprTerms)
+	}
+
+	start := p.pos
+	switch {
+	case isExprDigit(c) || c == '.':
+		for p.pos < len(p.src) && (isExprDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		value, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", p.src[start:p.pos])
+		}
+		return exprNumber(value), nil
+	case isExprIdentStart(c):
+		for p.pos < len(p.src) && (isExprIdentStart(p.src[p.pos]) || isExprDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		id := p.src[start:p.pos]
+		if !p.seen[id] {
+			p.seen[id] = true
+			p.stats = append(p.stats, id)
+		}
+		return exprStat(id), nil
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", c, p.pos+1)
+}
+
+func isExprDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isExprIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
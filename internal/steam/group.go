@@ -0,0 +1,130 @@
+package steam
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+// GroupMemberData is what the group dashboard needs from one player. Stats is
+// nil when they could not be fetched; Achievements is nil for private profiles.
+type GroupMemberData struct {
+	SteamID       string
+	Stats         *models.PlayerStats
+	Achievements  *models.AchievementData
+	KillerGrade   string
+	SurvivorGrade string
+}
+
+// groupMetrics are the leaderboards every group gets, keyed by metric name
+var groupMetrics = map[string]func(models.GroupMember) float64{
+	"escapes":         func(m models.GroupMember) float64 { return float64(m.Escapes) },
+	"escape_rate":     func(m models.GroupMember) float64 { return m.EscapeRate },
+	"total_matches":   func(m models.GroupMember) float64 { return float64(m.TotalMatches) },
+	"sacrifices":      func(m models.GroupMember) float64 { return float64(m.Sacrifices) },
+	"kills":           func(m models.GroupMember) float64 { return float64(m.Kills) },
+	"killer_pips":     func(m models.GroupMember) float64 { return float64(m.KillerPips) },
+	"survivor_pips":   func(m models.GroupMember) float64 { return float64(m.SurvivorPips) },
+	"bloodweb_points": func(m models.GroupMember) float64 { return float64(m.BloodwebPoints) },
+	"hours_played":    func(m models.GroupMember) float64 { return float64(m.HoursPlayed) },
+	"adepts_unlocked": func(m models.GroupMember) float64 { return float64(m.AdeptsUnlocked) },
+}
+
+// BuildGroupStats combines members in group order. Unavailable members are
+// listed but left out of the aggregate and leaderboards.
+//
+//	escape_rate           = escapes / total matches (all roles)
+//	aggregate escape_rate = Σ escapes / Σ matches
+//	leaderboard rank      = 1 + members strictly ahead, so ties share a rank
+func BuildGroupStats(members []GroupMemberData) models.GroupStats {
+	stats := models.GroupStats{
+		Members:      make([]models.GroupMember, 0, len(members)),
+		Leaderboards: make(map[string][]models.GroupLeaderboard, len(groupMetrics)),
+		GeneratedAt:  time.Now().UTC(),
+	}
+
+	distinctAdepts := make(map[string]bool)
+	rateSum := 0.0
+	var available []models.GroupMember
+
+	for _, data := range members {
+		member := models.GroupMember{SteamID: data.SteamID}
+		if data.Stats == nil {
+			stats.Members = append(stats.Members, member)
+			continue
+		}
+
+		player := data.Stats
+		member.DisplayName = player.DisplayName
+		member.Avatar = player.Avatar
+		member.Available = true
+		member.Escapes = player.Escapes
+		member.TotalMatches = player.TotalMatches
+		member.EscapeRate = round2(ratio(float64(player.Escapes), float64(player.TotalMatches)))
+		member.Sacrifices = player.SacrificedCampers
+		member.Kills = player.KilledCampers
+		member.KillerGrade = data.KillerGrade
+		member.SurvivorGrade = data.SurvivorGrade
+		member.KillerPips = player.KillerPips
+		member.SurvivorPips = player.SurvivorPips
+		member.BloodwebPoints = player.BloodwebPoints
+		member.HoursPlayed = player.TimePlayed
+		if data.Achievements != nil {
+			for character, unlocked := range data.Achievements.AdeptSurvivors {
+				if unlocked {
+					member.AdeptsUnlocked++
+					distinctAdepts["survivor:"+character] = true
+				}
+			}
+			for character, unlocked := range data.Achievements.AdeptKillers {
+				if unlocked {
+					member.AdeptsUnlocked++
+					distinctAdepts["killer:"+character] = true
+				}
+			}
+		}
+
+		stats.Members = append(stats.Members, member)
+		available = append(available, member)
+
+		agg := &stats.Aggregate
+		agg.Escapes += member.Escapes
+		agg.TotalMatches += member.TotalMatches
+		agg.Sacrifices += member.Sacrifices
+		agg.Kills += member.Kills
+		agg.BloodwebPoints += member.BloodwebPoints
+		agg.HoursPlayed += member.HoursPlayed
+		agg.TotalAdepts += member.AdeptsUnlocked
+		rateSum += member.EscapeRate
+	}
+
+	agg := &stats.Aggregate
+	agg.Members = len(members)
+	agg.AvailableMembers = len(available)
+	agg.DistinctAdepts = len(distinctAdepts)
+	agg.EscapeRate = round2(ratio(float64(agg.Escapes), float64(agg.TotalMatches)))
+	agg.AverageEscapeRate = round2(ratio(rateSum, float64(len(available))))
+
+	for metric, value := range groupMetrics {
+		stats.Leaderboards[metric] = groupLeaderboard(available, value)
+	}
+	return stats
+}
+
+// groupLeaderboard ranks members on one metric, best first; ties keep group order
+func groupLeaderboard(members []models.GroupMember, value func(models.GroupMember) float64) []models.GroupLeaderboard {
+	board := make([]models.GroupLeaderboard, len(members))
+	for i, m := range members {
+		board[i] = models.GroupLeaderboard{SteamID: m.SteamID, DisplayName: m.DisplayName, Value: value(m)}
+	}
+	sort.SliceStable(board, func(i, j int) bool { return board[i].Value > board[j].Value })
+	for i := range board {
+		if i > 0 && board[i].Value == board[i-1].Value {
+			board[i].Rank = board[i-1].Rank
+		} else {
+			board[i].Rank = i + 1
+		}
+	}
+	return board
+}
@@ -0,0 +1,120 @@
+package steam
+
+import (
+	"context"
+	"time"
+)
+
+// Hooks observes the client's HTTP traffic, so programs using this package
+// directly (the CLIs, other services) can attach their own metrics or logging.
+// Methods run synchronously on the requesting goroutine: keep them fast and
+// safe for concurrent use. Embed NopHooks to implement only some of them.
+type Hooks interface {
+	// OnRequestStart is called before each HTTP attempt against one host
+	OnRequestStart(RequestInfo)
+	// OnRequestEnd is called once per started attempt with its outcome
+	OnRequestEnd(RequestInfo, RequestResult)
+	// OnRetry is called before the client sleeps ahead of another attempt
+	OnRetry(RetryInfo)
+	// OnRateLimit is called whenever Steam answers 429
+	OnRateLimit(RateLimitInfo)
+}
+
+// RequestInfo identifies one HTTP attempt
+type RequestInfo struct {
+	Endpoint string // path such as /ISteamUserStats/GetUserStatsForGame/v2/, without the query
+	Host     string // base URL the attempt went to
+	Attempt  int    // 1 for the first try
+}
+
+// RequestResult is how an attempt ended. StatusCode is 0 when no response
+// arrived; Err is nil only for a fully decoded success.
+type RequestResult struct {
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+// RetryInfo describes an upcoming retry
+type RetryInfo struct {
+	Endpoint string
+	Attempt  int           // the attempt about to be made
+	Delay    time.Duration // backoff before it
+	LastErr  error         // why the previous attempt failed
+}
+
+// RateLimitInfo describes a 429 from Steam
+type RateLimitInfo struct {
+	Endpoint   string
+	Host       string
+	RetryAfter time.Duration // from Retry-After / X-RateLimit-Reset, or the 60s default
+}
+
+// NopHooks implements Hooks with methods that do nothing
+type NopHooks struct{}
+
+func (NopHooks) OnRequestStart(RequestInfo)              {}
+func (NopHooks) OnRequestEnd(RequestInfo, RequestResult) {}
+func (NopHooks) OnRetry(RetryInfo)                       {}
+func (NopHooks) OnRateLimit(RateLimitInfo)               {}
+
+// hookSet boxes the interface so it can live in an atomic.Pointer
+type hookSet struct {
+	hooks Hooks
+}
+
+var nopHooks Hooks = NopHooks{}
+
+// SetHooks installs h for every later request; nil removes them. It is safe
+// to call while requests are in flight.
+func (c *Client) SetHooks(h Hooks) {
+	if h == nil {
+		c.hooks.Store(nil)
+		return
+	}
+	c.hooks.Store(&hookSet{hooks: h})
+}
+
+func (c *Client) hooksOrNop() Hooks {
+	if set := c.hooks.Load(); set != nil {
+		return set.hooks
+	}
+	return nopHooks
+}
+
+// observeRequest reports the start of an attempt to the Hooks and returns the
+// function that reports its end, to them and to any observer ctx carries
+func (c *Client) observeRequest(ctx context.Context, endpoint, host string, attempt int) func(statusCode int, err error) {
+	hooks := c.hooksOrNop()
+	observe, _ := ctx.Value(requestObserverKey{}).(func(RequestInfo, RequestResult))
+	if hooks == nopHooks && observe == nil {
+		return func(int, error) {}
+	}
+	info := RequestInfo{Endpoint: endpoint, Host: host, Attempt: attempt}
+	start := time.Now()
+	hooks.OnRequestStart(info)
+	return func(statusCode int, err error) {
+		result := RequestResult{StatusCode: statusCode, Duration: time.Since(start), Err: err}
+		hooks.OnRequestEnd(info, result)
+		if observe != nil {
+			observe(info, result)
+		}
+	}
+}
+
+type requestObserverKey struct{}
+
+// WithRequestObserver returns ctx reporting each HTTP attempt made with it to
+// observe once the attempt ends, alongside the client's Hooks. It suits
+// per-request breakdowns; Hooks see every request. The innermost observer wins.
+func WithRequestObserver(ctx context.Context, observe func(RequestInfo, RequestResult)) context.Context {
+	return context.WithValue(ctx, requestObserverKey{}, observe)
+}
+
+// hookError keeps a nil *APIError from becoming a non-nil error
+func hookError(err *APIError) error {
+	if err == nil {
+		return nil
+	}
+	return err
+}
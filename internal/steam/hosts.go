@@ -0,0 +1,233 @@
+package steam
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+)
+
+const (
+	hostFailureThreshold = 3                // consecutive failures before a host is marked down
+	hostCooldown         = 30 * time.Second // how long a down host is skipped before re-probing
+	hostProbeInterval    = 15 * time.Second
+	hostProbePath        = "/ISteamWebAPIUtil/GetServerInfo/v1/"
+)
+
+// apiHost tracks the health of a single Steam Web API base URL
+type apiHost struct {
+	baseURL             string
+	consecutiveFailures int
+	downUntil           time.Time
+	lastError           string
+	lastSuccess         time.Time
+}
+
+// HostStatus is a point-in-time snapshot of an upstream host's health
+type HostStatus struct {
+	BaseURL             string    `json:"base_url"`
+	Healthy             bool      `json:"healthy"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	DownUntil           time.Time `json:"down_until,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastSuccess         time.Time `json:"last_success,omitempty"`
+}
+
+// hostPool selects between alternate Steam API base URLs in priority order,
+// skipping hosts that recently failed until a health probe brings them back.
+type hostPool struct {
+	mu     sync.RWMutex
+	hosts  []*apiHost
+	probe  *http.Client
+	stopCh chan struct{}
+}
+
+// loadBaseURLs reads STEAM_API_BASE_URLS (comma separated, priority order) with BaseURL as fallback
+func loadBaseURLs() []string {
+	urls := make([]string, 0)
+	for _, raw := range strings.Split(os.Getenv("STEAM_API_BASE_URLS"), ",") {
+		trimmed := strings.TrimRight(strings.TrimSpace(raw), "/")
+		if trimmed != "" {
+			urls = append(urls, trimmed)
+		}
+	}
+	if len(urls) == 0 {
+		urls = append(urls, BaseURL)
+	}
+	return urls
+}
+
+func newHostPool(baseURLs []string) *hostPool {
+	pool := &hostPool{
+		hosts:  make([]*apiHost, 0, len(baseURLs)),
+		probe:  &http.Client{Timeout: 5 * time.Second},
+		stopCh: make(chan struct{}),
+	}
+	for _, baseURL := range baseURLs {
+		pool.hosts = append(pool.hosts, &apiHost{baseURL: baseURL})
+	}
+
+	// Only run active health checks when there is somewhere to fail over to
+	if len(pool.hosts) > 1 {
+		log.Info("Steam API host failover enabled",
+			"hosts", baseURLs,
+			"failure_threshold", hostFailureThreshold,
+			"cooldown", hostCooldown)
+		go pool.probeLoop()
+	}
+
+	return pool
+}
+
+// candidates returns base URLs to try for a request: healthy hosts first in
+// priority order, then down hosts so a request is never refused outright.
+func (p *hostPool) candidates() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	now := time.Now()
+	healthy := make([]string, 0, len(p.hosts))
+	down := make([]string, 0)
+	for _, host := range p.hosts {
+		if now.Before(host.downUntil) {
+			down = append(down, host.baseURL)
+		} else {
+			healthy = append(healthy, host.baseURL)
+		}
+	}
+	return append(healthy, down...)
+}
+
+// primary returns the preferred base URL for one-shot requests
+func (p *hostPool) primary() string {
+	return p.candidates()[0]
+}
+
+func (p *hostPool) find(baseURL string) *apiHost {
+	for _, host := range p.hosts {
+		if host.baseURL == baseURL {
+			return host
+		}
+	}
+	return nil
+}
+
+func (p *hostPool) markSuccess(baseURL string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	host := p.find(baseURL)
+	if host == nil {
+		return
+	}
+	if host.consecutiveFailures >= hostFailureThreshold {
+		log.Info("Steam API host recovered", "base_url", baseURL)
+	}
+	host.consecutiveFailures = 0
+	host.downUntil = time.Time{}
+	host.lastSuccess = time.Now()
+}
+
+func (p *hostPool) markFailure(baseURL string, reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	host := p.find(baseURL)
+	if host == nil {
+		return
+	}
+	metrics.Record(metrics.UpstreamFailures)
+	host.consecutiveFailures++
+	host.lastError = reason
+	if host.consecutiveFailures >= hostFailureThreshold && len(p.hosts) > 1 {
+		host.downUntil = time.Now().Add(hostCooldown)
+		log.Warn("Steam API host marked down, failing over",
+			"base_url", baseURL,
+			"consecutive_failures", host.consecutiveFailures,
+			"reason", reason,
+			"down_until", host.downUntil)
+	}
+}
+
+// isHostFailure reports whether an error indicates a host-level problem
+// (network failure or 5xx) as opposed to a request-specific one.
+func isHostFailure(err *APIError) bool {
+	if err == nil {
+		return false
+	}
+	if err.Type == ErrorTypeInternal || err.Type == ErrorTypeNetwork {
+		return true
+	}
+	return err.StatusCode >= 500
+}
+
+// Status returns health snapshots for all configured hosts
+func (p *hostPool) Status() []HostStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	now := time.Now()
+	statuses := make([]HostStatus, 0, len(p.hosts))
+	for _, host := range p.hosts {
+		statuses = append(statuses, HostStatus{
+			BaseURL:             host.baseURL,
+			Healthy:             !now.Before(host.downUntil),
+			ConsecutiveFailures: host.consecutiveFailures,
+			DownUntil:           host.downUntil,
+			LastError:           host.lastError,
+			LastSuccess:         host.lastSuccess,
+		})
+	}
+	return statuses
+}
+
+// probeLoop periodically checks down hosts so they can rejoin rotation early
+func (p *hostPool) probeLoop() {
+	ticker := time.NewTicker(hostProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.probeDownHosts()
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
+func (p *hostPool) probeDownHosts() {
+	p.mu.RLock()
+	down := make([]string, 0)
+	for _, host := range p.hosts {
+		if host.consecutiveFailures >= hostFailureThreshold {
+			down = append(down, host.baseURL)
+		}
+	}
+	p.mu.RUnlock()
+
+	for _, baseURL := range down {
+		resp, err := p.probe.Get(baseURL + hostProbePath)
+		if err != nil {
+			log.Debug("Steam API host probe failed", "base_url", baseURL, "error", err.Error())
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 500 {
+			p.markSuccess(baseURL)
+		}
+	}
+}
+
+// Close stops the background health probe
+func (p *hostPool) Close() {
+	select {
+	case <-p.stopCh:
+	default:
+		close(p.stopCh)
+	}
+}
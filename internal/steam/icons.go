@@ -0,0 +1,219 @@
+package steam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+const (
+	defaultIconMirrorDir = "data/icons"
+	maxIconBytes         = 1 << 20
+	// schemaLookupInterval throttles schema fetches for icons the mirror has not
+	// seen yet, so requests for made-up IDs cannot burn through the Steam quota
+	schemaLookupInterval = 10 * time.Minute
+)
+
+var iconIDPattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,64}$`)
+
+// iconHosts are the CDN domains Steam serves achievement icons from; anything
+// else is refused so the mirror never fetches arbitrary URLs
+var iconHosts = []string{"steamstatic.com", "akamaihd.net", "steampowered.com", "steamcontent.com", "steamcommunity.com"}
+
+// ErrIconNotFound is returned when no schema entry names the requested icon
+var ErrIconNotFound = errors.New("achievement icon not found")
+
+// IconMirror downloads achievement icons from the Steam CDN on first request and
+// serves them from a local directory afterwards
+type IconMirror struct {
+	dir    string
+	client *Client
+	http   *http.Client
+
+	mu           sync.Mutex
+	sources      map[string]string // file name -> CDN URL
+	lastSchemaAt time.Time
+}
+
+// NewIconMirror stores icons under dir; client resolves unknown IDs via the schema
+func NewIconMirror(dir string, client *Client) *IconMirror {
+	return &IconMirror{
+		dir:     dir,
+		client:  client,
+		http:    &http.Client{Timeout: 10 * time.Second},
+		sources: make(map[string]string),
+	}
+}
+
+// IconMirrorFromEnv stores icons under ICON_MIRROR_DIR (default data/icons)
+func IconMirrorFromEnv(client *Client) *IconMirror {
+	dir := os.Getenv("ICON_MIRROR_DIR")
+	if dir == "" {
+		dir = defaultIconMirrorDir
+	}
+	log.Info("Achievement icon mirror enabled", "dir", dir)
+	return NewIconMirror(dir, client)
+}
+
+// ValidIconID reports whether id is safe to use as an icon file name
+func ValidIconID(id string) bool {
+	return iconIDPattern.MatchString(id)
+}
+
+// Remember records the CDN URLs for an achievement's icons so a later request
+// for them does not need a schema lookup
+func (m *IconMirror) Remember(id, icon, iconGray string) {
+	if !ValidIconID(id) {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if icon != "" {
+		m.sources[iconFileName(id, false)] = icon
+	}
+	if iconGray != "" {
+		m.sources[iconFileName(id, true)] = iconGray
+	}
+}
+
+// Path returns the local file for an icon, downloading it first if it is not
+// mirrored yet
+func (m *IconMirror) Path(ctx context.Context, id string, gray bool) (string, error) {
+	if !ValidIconID(id) {
+		return "", ErrIconNotFound
+	}
+	name := iconFileName(id, gray)
+	path := filepath.Join(m.dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	source, err := m.source(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if err := m.download(ctx, source, path); err != nil {
+		return "", err
+	}
+	log.Info("Mirrored achievement icon", "id", id, "gray", gray, "source", source)
+	return path, nil
+}
+
+func (m *IconMirror) source(ctx context.Context, name string) (string, error) {
+	m.mu.Lock()
+	source, ok := m.sources[name]
+	fetchSchema := !ok && time.Since(m.lastSchemaAt) >= schemaLookupInterval
+	if fetchSchema {
+		m.lastSchemaAt = time.Now()
+	}
+	m.mu.Unlock()
+
+	if ok {
+		return source, nil
+	}
+	if !fetchSchema || m.client == nil {
+		return "", ErrIconNotFound
+	}
+
+	schema, apiErr := m.client.GetSchemaForGameContext(ctx, DBDAppID)
+	if apiErr != nil {
+		return "", apiErr
+	}
+	for _, ach := range schema.AvailableGameStats.Achievements {
+		m.Remember(ach.Name, ach.Icon, ach.IconGray)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if source, ok := m.sources[name]; ok {
+		return source, nil
+	}
+	return "", ErrIconNotFound
+}
+
+// download fetches an icon and moves it into place atomically so concurrent
+// readers never see a partial file
+func (m *IconMirror) download(ctx context.Context, source, path string) error {
+	if !isSteamIconURL(source) {
+		return fmt.Errorf("refusing to mirror icon from non-Steam host: %s", source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := m.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download icon: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("icon download returned HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxIconBytes+1))
+	if err != nil {
+		return fmt.Errorf("failed to read icon: %w", err)
+	}
+	if len(data) > maxIconBytes {
+		return fmt.Errorf("icon exceeds %d bytes", maxIconBytes)
+	}
+	if !strings.HasPrefix(http.DetectContentType(data), "image/") {
+		return fmt.Errorf("icon download is not an image")
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create icon directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(m.dir, ".icon-*")
+	if err != nil {
+		return fmt.Errorf("failed to create icon file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write icon: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write icon: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to store icon: %w", err)
+	}
+	return nil
+}
+
+func iconFileName(id string, gray bool) string {
+	if gray {
+		return id + "_gray.png"
+	}
+	return id + ".png"
+}
+
+func isSteamIconURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, allowed := range iconHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
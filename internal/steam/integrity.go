@@ -0,0 +1,138 @@
+package steam
+
+import (
+	"fmt"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+// Per-hour ceilings are deliberately generous: a flag only fires when a counter
+// exceeds what a player could plausibly reach even with back-to-back short matches.
+const (
+	maxKillsPerHour          = 40     // ~4 kills in a 6 minute match, queue time ignored
+	maxEscapesPerHour        = 15     // ~4 minute escapes, queue time ignored
+	maxBloodpointsPerHour    = 250000 // all offerings/event multipliers stacked
+	maxSkillChecksPerHour    = 600    // continuous generator/heal skill checks
+	maxSurvivorKillsPerMatch = 4
+)
+
+// EvaluateIntegrity runs heuristic plausibility checks against a player's stats and,
+// when available, their Steam ban record. A nil bans argument skips ban correlation.
+func EvaluateIntegrity(stats models.PlayerStats, bans *PlayerBans) []models.IntegrityFlag {
+	flags := make([]models.IntegrityFlag, 0)
+
+	// Impossible ratios: subsets that exceed their superset
+	subsetChecks := []struct {
+		code     string
+		label    string
+		subset   int
+		superset int
+		severity string
+	}{
+		{"escapes_ko_exceed_escapes", "Escapes while injured exceed total escapes", stats.EscapesKO, stats.Escapes, "high"},
+		{"hatch_escapes_exceed_escapes", "Hatch escapes exceed total escapes", stats.EscapeThroughHatch, stats.Escapes, "high"},
+		{"hooked_escapes_exceed_escapes", "Hooked-and-escaped count exceeds total escapes", stats.HookedAndEscape, stats.Escapes, "high"},
+		{"survivor_perfect_games_exceed_escapes", "Survivor perfect games exceed total escapes", stats.CamperPerfectGames, stats.Escapes, "medium"},
+		{"mori_kills_exceed_kills", "Mori kills exceed total kills", stats.MoriKills, stats.KilledCampers, "medium"},
+	}
+
+	for _, check := range subsetChecks {
+		if check.subset > check.superset {
+			flags = append(flags, models.IntegrityFlag{
+				Code:      check.code,
+				Severity:  check.severity,
+				Message:   check.label,
+				Observed:  float64(check.subset),
+				Threshold: float64(check.superset),
+			})
+		}
+	}
+
+	totalKills := stats.KilledCampers + stats.SacrificedCampers
+
+	// Match-bounded counters
+	if stats.TotalMatches > 0 {
+		if limit := stats.TotalMatches * maxSurvivorKillsPerMatch; totalKills > limit {
+			flags = append(flags, models.IntegrityFlag{
+				Code:      "kills_exceed_match_capacity",
+				Severity:  "high",
+				Message:   fmt.Sprintf("More than %d kills per recorded match", maxSurvivorKillsPerMatch),
+				Observed:  float64(totalKills),
+				Threshold: float64(limit),
+			})
+		}
+		if stats.Escapes > stats.TotalMatches {
+			flags = append(flags, models.IntegrityFlag{
+				Code:      "escapes_exceed_matches",
+				Severity:  "high",
+				Message:   "More escapes than recorded matches",
+				Observed:  float64(stats.Escapes),
+				Threshold: float64(stats.TotalMatches),
+			})
+		}
+	}
+
+	// Counters exceeding the maximum achievable per hour played
+	if stats.TimePlayed > 0 {
+		hours := float64(stats.TimePlayed)
+		hourlyChecks := []struct {
+			code    string
+			label   string
+			value   int
+			ceiling float64
+		}{
+			{"kills_per_hour", "Kills per hour played", totalKills, maxKillsPerHour},
+			{"escapes_per_hour", "Escapes per hour played", stats.Escapes, maxEscapesPerHour},
+			{"bloodpoints_per_hour", "Bloodpoints per hour played", stats.BloodwebPoints, maxBloodpointsPerHour},
+			{"skill_checks_per_hour", "Skill checks per hour played", stats.SkillCheckSuccess, maxSkillChecksPerHour},
+		}
+
+		for _, check := range hourlyChecks {
+			rate := float64(check.value) / hours
+			if rate > check.ceiling {
+				flags = append(flags, models.IntegrityFlag{
+					Code:      check.code + "_exceeded",
+					Severity:  "medium",
+					Message:   fmt.Sprintf("%s exceeds plausible maximum", check.label),
+					Observed:  rate,
+					Threshold: check.ceiling,
+				})
+			}
+		}
+	}
+
+	if bans == nil {
+		return flags
+	}
+
+	statFlagCount := len(flags)
+
+	if bans.VACBanned || bans.NumberOfVACBans > 0 {
+		flags = append(flags, models.IntegrityFlag{
+			Code:     "vac_banned",
+			Severity: "medium",
+			Message:  fmt.Sprintf("Account has %d VAC ban(s) on record", bans.NumberOfVACBans),
+			Observed: float64(bans.NumberOfVACBans),
+		})
+	}
+	if bans.NumberOfGameBans > 0 {
+		flags = append(flags, models.IntegrityFlag{
+			Code:     "game_banned",
+			Severity: "medium",
+			Message:  fmt.Sprintf("Account has %d game ban(s) on record", bans.NumberOfGameBans),
+			Observed: float64(bans.NumberOfGameBans),
+		})
+	}
+
+	// Ban history combined with implausible stats is the strongest review signal
+	if statFlagCount > 0 && len(flags) > statFlagCount {
+		flags = append(flags, models.IntegrityFlag{
+			Code:     "ban_correlation",
+			Severity: "high",
+			Message:  "Implausible stats on an account with a ban history",
+			Observed: float64(statFlagCount),
+		})
+	}
+
+	return flags
+}
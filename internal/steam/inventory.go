@@ -0,0 +1,220 @@
+package steam
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+const (
+	CommunityURL = "https://steamcommunity.com"
+
+	inventoryContextID = "2"
+	inventoryPageSize  = 2000
+	inventoryMaxPages  = 5
+	iconCDNPrefix      = "https://community.cloudflare.steamstatic.com/economy/image/"
+)
+
+// communityInventoryResponse mirrors steamcommunity.com/inventory/{steamid}/{appid}/{contextid}
+type communityInventoryResponse struct {
+	Assets []struct {
+		AssetID    string `json:"assetid"`
+		ClassID    string `json:"classid"`
+		InstanceID string `json:"instanceid"`
+		Amount     string `json:"amount"`
+	} `json:"assets"`
+	Descriptions []struct {
+		ClassID        string `json:"classid"`
+		InstanceID     string `json:"instanceid"`
+		Name           string `json:"name"`
+		MarketHashName string `json:"market_hash_name"`
+		Type           string `json:"type"`
+		IconURL        string `json:"icon_url"`
+		Tradable       int    `json:"tradable"`
+		Marketable     int    `json:"marketable"`
+		Tags           []struct {
+			Category          string `json:"category"`
+			LocalizedTagName  string `json:"localized_tag_name"`
+			LocalizedCategory string `json:"localized_category_name"`
+		} `json:"tags"`
+	} `json:"descriptions"`
+	MoreItems           int    `json:"more_items"`
+	LastAssetID         string `json:"last_assetid"`
+	TotalInventoryCount int    `json:"total_inventory_count"`
+	Success             int    `json:"success"`
+}
+
+// GetPlayerInventory fetches a player's public DBD inventory from the Steam Community
+// inventory endpoint. Private inventories surface as a 403 API error.
+func (c *Client) GetPlayerInventory(ctx context.Context, steamID string) (*models.PlayerInventory, *APIError) {
+	start := time.Now()
+	inventory := &models.PlayerInventory{
+		SteamID: steamID,
+		Items:   make([]models.InventoryItem, 0),
+		Summary: make(map[string]int),
+	}
+
+	startAssetID := ""
+	for page := 0; page < inventoryMaxPages; page++ {
+		resp, apiErr := c.fetchInventoryPage(ctx, steamID, startAssetID)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+
+		appendInventoryPage(inventory, resp)
+		inventory.TotalCount = resp.TotalInventoryCount
+
+		if resp.MoreItems == 0 || resp.LastAssetID == "" {
+			break
+		}
+		startAssetID = resp.LastAssetID
+		inventory.Truncated = page == inventoryMaxPages-1
+	}
+
+	inventory.LastUpdated = time.Now()
+
+	logSteamInfo("Successfully retrieved player inventory", steamID,
+		"items", len(inventory.Items),
+		"total_inventory_count", inventory.TotalCount,
+		"truncated", inventory.Truncated,
+		"duration", time.Since(start))
+
+	return inventory, nil
+}
+
+func (c *Client) fetchInventoryPage(ctx context.Context, steamID, startAssetID string) (_ *communityInventoryResponse, apiErr *APIError) {
+	params := url.Values{}
+	params.Set("l", "english")
+	params.Set("count", strconv.Itoa(inventoryPageSize))
+	if startAssetID != "" {
+		params.Set("start_assetid", startAssetID)
+	}
+	endpoint := fmt.Sprintf("/inventory/%s/%s/%s", steamID, DBDAppID, inventoryContextID)
+
+	statusCode := 0
+	endObserve := c.observeRequest(ctx, endpoint, CommunityURL, 1)
+	defer func() { endObserve(statusCode, hookError(apiErr)) }()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", CommunityURL+endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, NewInternalError(fmt.Errorf("failed to create inventory request: %w", err))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, NewTimeoutError(ctx.Err())
+		}
+		return nil, NewInternalError(fmt.Errorf("error making GET request to %s%s: %w", CommunityURL, endpoint, err))
+	}
+	defer resp.Body.Close()
+	statusCode = resp.StatusCode
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden, http.StatusUnauthorized:
+		privateErr := NewAPIError(http.StatusForbidden, "inventory is private")
+		privateErr.Message = "Player inventory is private"
+		return nil, privateErr
+	case http.StatusTooManyRequests:
+		retryAfter := c.parseRateLimitHeaders(resp.Header)
+		c.hooksOrNop().OnRateLimit(RateLimitInfo{
+			Endpoint:   endpoint,
+			Host:       CommunityURL,
+			RetryAfter: time.Duration(retryAfter) * time.Second,
+		})
+		return nil, NewRateLimitErrorWithRetryAfter(retryAfter)
+	default:
+		return nil, NewAPIError(resp.StatusCode, fmt.Sprintf("HTTP %d from %s%s", resp.StatusCode, CommunityURL, endpoint))
+	}
+
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return nil, NewInternalError(fmt.Errorf("failed to read inventory response: %w", err))
+	}
+
+	// Steam answers "null" for accounts that have never owned an item for the app
+	if strings.TrimSpace(string(body)) == "null" {
+		return &communityInventoryResponse{Success: 1}, nil
+	}
+
+	var page communityInventoryResponse
+	// Keyed without the Steam ID so telemetry groups every player's inventory
+	if err := decodeSteamJSON("/inventory", body, &page); err != nil {
+		return nil, NewInternalError(fmt.Errorf("failed to parse inventory response: %w", err))
+	}
+	if page.Success != 1 {
+		log.Warn("Steam inventory request unsuccessful", "steam_id", steamID, "success", page.Success)
+		return nil, NewNotFoundError("Player inventory")
+	}
+
+	return &page, nil
+}
+
+func appendInventoryPage(inventory *models.PlayerInventory, page *communityInventoryResponse) {
+	type descKey struct{ classID, instanceID string }
+	descriptions := make(map[descKey]int, len(page.Descriptions))
+	for i, desc := range page.Descriptions {
+		descriptions[descKey{desc.ClassID, desc.InstanceID}] = i
+	}
+
+	for _, asset := range page.Assets {
+		amount, err := strconv.Atoi(asset.Amount)
+		if err != nil || amount <= 0 {
+			amount = 1
+		}
+
+		item := models.InventoryItem{
+			AssetID:  asset.AssetID,
+			ClassID:  asset.ClassID,
+			Amount:   amount,
+			Category: "other",
+		}
+
+		if idx, ok := descriptions[descKey{asset.ClassID, asset.InstanceID}]; ok {
+			desc := page.Descriptions[idx]
+			item.Name = desc.Name
+			if item.Name == "" {
+				item.Name = desc.MarketHashName
+			}
+			item.Type = desc.Type
+			item.Tradable = desc.Tradable == 1
+			item.Marketable = desc.Marketable == 1
+			if desc.IconURL != "" {
+				item.Icon = iconCDNPrefix + desc.IconURL
+			}
+
+			labels := []string{desc.Type}
+			for _, tag := range desc.Tags {
+				labels = append(labels, tag.LocalizedTagName)
+			}
+			item.Category = categorizeInventoryItem(labels)
+		}
+
+		inventory.Items = append(inventory.Items, item)
+		inventory.Summary[item.Category] += amount
+	}
+}
+
+// categorizeInventoryItem buckets an item by its type line and tags
+func categorizeInventoryItem(labels []string) string {
+	for _, label := range labels {
+		lower := strings.ToLower(label)
+		switch {
+		case strings.Contains(lower, "charm"):
+			return "charm"
+		case strings.Contains(lower, "outfit"), strings.Contains(lower, "cosmetic"),
+			strings.Contains(lower, "head"), strings.Contains(lower, "torso"), strings.Contains(lower, "legs"),
+			strings.Contains(lower, "weapon"):
+			return "outfit"
+		}
+	}
+	return "other"
+}
@@ -0,0 +1,130 @@
+package steam
+
+import (
+	"math"
+	"strings"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+// killerPowerStats are the power counters used for specialization indices.
+// Leatherface's chainsaw is tracked separately from the shared chainsaw stat.
+var killerPowerStats = []struct {
+	power  string
+	statID string
+}{
+	{"wraith_uncloak", "DBD_UncloakAttack"},
+	{"chainsaw", "DBD_ChainsawHit"},
+	{"leatherface_chainsaw", "DBD_DLC6_Slasher_Stat1"},
+	{"trapper_trap", "DBD_TrapPickup"},
+	{"hag_phantasm", "DBD_DLC3_Slasher_Stat1"},
+	{"ghostface_stealth", "DBD_Chapter12_Slasher_Stat2"},
+}
+
+// BuildKillerAnalytics derives killer insights from flat stats plus raw schema
+// stat values (which may be empty when structured stats were unavailable).
+//
+//	eliminations            = sacrificed + killed (mori)
+//	hooks_per_sacrifice     = hooks / sacrificed
+//	hooks_per_elimination   = hooks / eliminations
+//	mori_share              = killed / eliminations
+//	hits_near_hook_per_hook = DBD_HitNearHook / hooks
+//	basement_hook_ratio     = Σ stats whose ID mentions basement and hook / hooks
+//	per_hundred_eliminations= power count / eliminations × 100
+//	index                   = power count / Σ tracked power counts
+//	pressure_score          = clamp(0.5 × power 4K rate + 0.5 × perfect game rate, 0, 1)
+//	                          where rates are per lobby equivalent (eliminations / 4)
+func BuildKillerAnalytics(stats models.PlayerStats, statValues map[string]float64) *models.KillerAnalytics {
+	sacrificed := stats.SacrificedCampers
+	killed := stats.KilledCampers
+	hooks := stats.HooksPerformed
+	eliminations := sacrificed + killed
+
+	analytics := &models.KillerAnalytics{
+		Eliminations:        eliminations,
+		HooksPerSacrifice:   ratio(float64(hooks), float64(sacrificed)),
+		HooksPerElimination: ratio(float64(hooks), float64(eliminations)),
+		MoriShare:           ratio(float64(killed), float64(eliminations)),
+		HitsNearHookPerHook: ratio(statValues["DBD_HitNearHook"], float64(hooks)),
+		Specializations:     []models.PowerSpecialization{},
+	}
+
+	basementHooks, basementTracked := 0.0, false
+	for id, value := range statValues {
+		lower := strings.ToLower(id)
+		if strings.Contains(lower, "basement") && strings.Contains(lower, "hook") {
+			basementHooks += value
+			basementTracked = true
+		}
+	}
+	if basementTracked && hooks > 0 {
+		r := ratio(basementHooks, float64(hooks))
+		analytics.BasementHookRatio = &r
+	}
+
+	// Uncloak attacks are also mapped onto the flat stats; prefer the raw value when present
+	powerCounts := make(map[string]float64, len(killerPowerStats))
+	for _, p := range killerPowerStats {
+		powerCounts[p.statID] = statValues[p.statID]
+	}
+	if powerCounts["DBD_UncloakAttack"] == 0 {
+		powerCounts["DBD_UncloakAttack"] = float64(stats.UncloakAttacks)
+	}
+
+	totalPower := 0.0
+	for _, count := range powerCounts {
+		totalPower += count
+	}
+	for _, p := range killerPowerStats {
+		count := powerCounts[p.statID]
+		if count <= 0 {
+			continue
+		}
+		analytics.Specializations = append(analytics.Specializations, models.PowerSpecialization{
+			Power:                  p.power,
+			StatID:                 p.statID,
+			Count:                  int(count),
+			PerHundredEliminations: round2(ratio(count, float64(eliminations)) * 100),
+			Index:                  round2(ratio(count, totalPower)),
+		})
+	}
+
+	lobbies := float64(eliminations) / 4
+	powerFourKs := int(statValues["DBD_SlasherPowerKillAllCampers"])
+	pressure := models.FourKPressure{
+		LobbyEquivalents: round2(lobbies),
+		PowerFourKs:      powerFourKs,
+		PerfectGames:     stats.KillerPerfectGames,
+		PowerFourKRate:   round2(math.Min(ratio(float64(powerFourKs), lobbies), 1)),
+		PerfectGameRate:  round2(math.Min(ratio(float64(stats.KillerPerfectGames), lobbies), 1)),
+	}
+	pressure.PressureScore = round2(math.Min(0.5*pressure.PowerFourKRate+0.5*pressure.PerfectGameRate, 1))
+	switch {
+	case pressure.PressureScore >= 0.15:
+		pressure.PressureRating = "high"
+	case pressure.PressureScore >= 0.05:
+		pressure.PressureRating = "moderate"
+	default:
+		pressure.PressureRating = "low"
+	}
+	analytics.FourKPressure = pressure
+
+	analytics.HooksPerSacrifice = round2(analytics.HooksPerSacrifice)
+	analytics.HooksPerElimination = round2(analytics.HooksPerElimination)
+	analytics.MoriShare = round2(analytics.MoriShare)
+	analytics.HitsNearHookPerHook = round2(analytics.HitsNearHookPerHook)
+
+	return analytics
+}
+
+// ratio divides, returning 0 for a zero or negative denominator
+func ratio(numerator, denominator float64) float64 {
+	if denominator <= 0 {
+		return 0
+	}
+	return numerator / denominator
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
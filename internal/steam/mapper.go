@@ -2,6 +2,8 @@ package steam
 
 import "time"
 
+// statMapping is keyed by canonical stat IDs; see canonicalStatIDs for the
+// older names Steam may still report
 var statMapping = map[string]string{
 	// Killer Statistics
 	"DBD_KilledCampers":      "killer.total_kills",
@@ -11,23 +13,23 @@ var statMapping = map[string]string{
 	"DBD_KillerPerfectGames": "killer.perfect_games",
 	"DBD_KillerFullLoadout":  "killer.full_loadout_games",
 	"DBD_KillerPips":         "killer.killer_pips",
-	"DBD_UncloakAttacks":     "killer.uncloak_attacks",
+	"DBD_UncloakAttack":      "killer.uncloak_attacks",
 
 	// Survivor Statistics
-	"DBD_Escapes":              "survivor.total_escapes",
-	"DBD_EscapeThroughHatch":   "survivor.escapes_through_hatch",
-	"DBD_EscapesKO":            "survivor.escapes_knocked_out",
-	"DBD_HookedAndEscape":      "survivor.hooked_and_escaped",
-	"DBD_GeneratorPct":         "survivor.generators_completed_pct",
-	"DBD_HealPct":              "survivor.healing_completed_pct",
-	"DBD_SkillCheckSuccess":    "survivor.skill_checks_hit",
-	"DBD_UnhookOrHeal":         "survivor.unhooks_performed",
-	"DBD_HealsPerformed":       "survivor.heals_performed",
-	"DBD_UnhookOrHealPostExit": "survivor.post_exit_actions",
-	"DBD_CamperPerfectGames":   "survivor.perfect_games",
-	"DBD_CamperFullLoadout":    "survivor.full_loadout_games",
-	"DBD_CamperNewItem":        "survivor.new_items_found",
-	"DBD_SurvivorPips":         "survivor.survivor_pips",
+	"DBD_Escape":                "survivor.total_escapes",
+	"DBD_EscapeThroughHatch":    "survivor.escapes_through_hatch",
+	"DBD_EscapeKO":              "survivor.escapes_knocked_out",
+	"DBD_HookedAndEscape":       "survivor.hooked_and_escaped",
+	"DBD_GeneratorPct_float":    "survivor.generators_completed_pct",
+	"DBD_HealPct_float":         "survivor.healing_completed_pct",
+	"DBD_SkillCheckSuccess":     "survivor.skill_checks_hit",
+	"DBD_UnhookOrHeal":          "survivor.unhooks_performed",
+	"DBD_HealsPerformed":        "survivor.heals_performed",
+	"DBD_UnhookOrHeal_PostExit": "survivor.post_exit_actions",
+	"DBD_CamperPerfectGames":    "survivor.perfect_games",
+	"DBD_CamperFullLoadout":     "survivor.full_loadout_games",
+	"DBD_CamperNewItem":         "survivor.new_items_found",
+	"DBD_SurvivorPips":          "survivor.survivor_pips",
 
 	// General Statistics
 	"DBD_BloodwebPoints": "general.bloodweb_points",
@@ -50,7 +52,7 @@ func MapSteamStats(raw []SteamStat, steamID, displayName string) DBDPlayerStats
 	}
 
 	rawStatsMap := make(map[string]interface{})
-	for _, stat := range raw {
+	for _, stat := range sanitizeStats(raw) {
 		rawStatsMap[stat.Name] = int(stat.Value)
 	}
 
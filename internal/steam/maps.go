@@ -0,0 +1,64 @@
+package steam
+
+import "regexp"
+
+// Realm is a group of DBD maps sharing a setting, keyed in stat IDs by a
+// three-letter code
+type Realm struct {
+	Name string
+	Maps map[string]string // map code -> display name
+}
+
+// StatMap is the realm and map a per-map stat counts on. Identified is
+// false for codes the realm table doesn't know yet, whose names fall back to
+// the codes.
+type StatMap struct {
+	RealmID    string
+	Realm      string
+	MapID      string
+	Map        string
+	Identified bool
+}
+
+// mapStatID matches the realm and map suffix of per-map stat IDs, such as
+// DBD_FixSecondFloorGenerator_MapAsy_Asylum. One ID lacks the underscore
+// before Map.
+var mapStatID = regexp.MustCompile(`_?Map([A-Z][a-z]{2})_(\w+)$`)
+
+// realms names the realms and maps DBD's per-map stats use
+var realms = map[string]Realm{
+	"Asy": {Name: "Crotus Prenn Asylum", Maps: map[string]string{"Asylum": "Disturbed Ward", "Chapel": "Father Campbell's Chapel"}},
+	"Sub": {Name: "Haddonfield", Maps: map[string]string{"Street": "Lampkin Lane"}},
+	"Swp": {Name: "Backwater Swamp", Maps: map[string]string{"PaleRose": "The Pale Rose"}},
+	"Brl": {Name: "Red Forest", Maps: map[string]string{"MaHouse": "Mother's Dwelling", "Temple": "Temple of Purgation"}},
+	"Fin": {Name: "Gideon Meat Plant", Maps: map[string]string{"Hideout": "The Game"}},
+	"Hti": {Name: "Yamaoka Estate", Maps: map[string]string{"Manor": "Family Residence", "Shrine": "Sanctum of Wrath"}},
+	"Kny": {Name: "Ormond", Maps: map[string]string{"Cottage": "Mount Ormond Resort"}},
+	"Qat": {Name: "Hawkins National Laboratory", Maps: map[string]string{"Lab": "The Underground Complex"}},
+	"Ukr": {Name: "Grave of Glenvale", Maps: map[string]string{"Saloon": "Dead Dawg Saloon"}},
+	"Wal": {Name: "Silent Hill", Maps: map[string]string{"Level_01": "Midwich Elementary School"}},
+	"Ecl": {Name: "Raccoon City", Maps: map[string]string{"Level_01": "Raccoon City Police Station"}},
+}
+
+// StatMapFor returns the realm and map a stat counts on, if it is a per-map
+// stat of an app with a realm table
+func (a *App) StatMapFor(id string) (StatMap, bool) {
+	if len(a.Realms) == 0 {
+		return StatMap{}, false
+	}
+	match := mapStatID.FindStringSubmatch(a.CanonicalStatID(id))
+	if match == nil {
+		return StatMap{}, false
+	}
+	statMap := StatMap{RealmID: match[1], Realm: match[1], MapID: match[2], Map: match[2]}
+	realm, ok := a.Realms[statMap.RealmID]
+	if !ok {
+		return statMap, true
+	}
+	statMap.Realm = realm.Name
+	if name, ok := realm.Maps[statMap.MapID]; ok {
+		statMap.Map = name
+		statMap.Identified = true
+	}
+	return statMap, true
+}
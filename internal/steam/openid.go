@@ -0,0 +1,115 @@
+package steam
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultOpenIDURL is Steam's OpenID 2.0 provider endpoint
+const DefaultOpenIDURL = CommunityURL + "/openid/login"
+
+const (
+	openIDNamespace        = "http://specs.openid.net/auth/2.0"
+	openIDIdentifierSelect = "http://specs.openid.net/auth/2.0/identifier_select"
+	maxOpenIDResponseBytes = 4 << 10
+)
+
+// openIDClaimedID is the identity Steam asserts: the account's community URL
+var openIDClaimedID = regexp.MustCompile(`^https?://steamcommunity\.com/openid/id/(7656119[0-9]{10})$`)
+
+// openIDSignedFields must all be covered by Steam's signature, or a forged
+// callback could swap in another account under a genuine signature
+var openIDSignedFields = []string{"op_endpoint", "claimed_id", "identity", "return_to", "response_nonce", "assoc_handle"}
+
+// OpenIDLoginURL is where to send a player to sign in with Steam; Steam
+// returns them to returnTo, which must lie under realm
+func OpenIDLoginURL(endpoint, returnTo, realm string) string {
+	params := url.Values{}
+	params.Set("openid.ns", openIDNamespace)
+	params.Set("openid.mode", "checkid_setup")
+	params.Set("openid.return_to", returnTo)
+	params.Set("openid.realm", realm)
+	params.Set("openid.identity", openIDIdentifierSelect)
+	params.Set("openid.claimed_id", openIDIdentifierSelect)
+	return endpoint + "?" + params.Encode()
+}
+
+// VerifyOpenID checks the assertion Steam redirected a player back with and
+// returns the SteamID64 they signed in as. returnTo is the URL the callback
+// was served on, which the assertion must name. The signature is checked by
+// asking Steam directly (check_authentication), so no association state is kept.
+func (c *Client) VerifyOpenID(ctx context.Context, endpoint, returnTo string, params url.Values) (_ string, apiErr *APIError) {
+	switch params.Get("openid.mode") {
+	case "id_res":
+	case "cancel":
+		return "", NewUnauthorizedError("Steam sign-in was cancelled")
+	default:
+		return "", NewValidationError("Request is not a Steam OpenID assertion")
+	}
+	if params.Get("openid.ns") != openIDNamespace || params.Get("openid.op_endpoint") != endpoint {
+		return "", NewUnauthorizedError("OpenID assertion is not from Steam")
+	}
+	if params.Get("openid.return_to") != returnTo {
+		return "", NewUnauthorizedError("OpenID assertion was issued for a different return URL")
+	}
+	claimedID := params.Get("openid.claimed_id")
+	match := openIDClaimedID.FindStringSubmatch(claimedID)
+	if match == nil || params.Get("openid.identity") != claimedID {
+		return "", NewUnauthorizedError("OpenID assertion does not name a Steam account")
+	}
+	signed := strings.Split(params.Get("openid.signed"), ",")
+	for _, field := range openIDSignedFields {
+		if !slices.Contains(signed, field) {
+			return "", NewUnauthorizedError(fmt.Sprintf("OpenID assertion does not sign %s", field))
+		}
+	}
+
+	check := url.Values{}
+	for key, values := range params {
+		if strings.HasPrefix(key, "openid.") {
+			check[key] = values
+		}
+	}
+	check.Set("openid.mode", "check_authentication")
+
+	statusCode := 0
+	endObserve := c.observeRequest(ctx, "/openid/login", endpoint, 1)
+	defer func() { endObserve(statusCode, hookError(apiErr)) }()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(check.Encode()))
+	if err != nil {
+		return "", NewInternalError(fmt.Errorf("failed to create OpenID verification request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", NewTimeoutError(ctx.Err())
+		}
+		return "", NewInternalError(fmt.Errorf("error verifying OpenID assertion with %s: %w", endpoint, err))
+	}
+	defer resp.Body.Close()
+	statusCode = resp.StatusCode
+	if resp.StatusCode != http.StatusOK {
+		return "", NewAPIError(resp.StatusCode, fmt.Sprintf("HTTP %d verifying OpenID assertion", resp.StatusCode))
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOpenIDResponseBytes))
+	if err != nil {
+		return "", NewInternalError(fmt.Errorf("failed to read OpenID verification response: %w", err))
+	}
+
+	// Key-value form: one "key:value" per line
+	for _, line := range strings.Split(string(body), "\n") {
+		if strings.TrimSpace(line) == "is_valid:true" {
+			return match[1], nil
+		}
+	}
+	return "", NewUnauthorizedError("Steam did not confirm the OpenID assertion")
+}
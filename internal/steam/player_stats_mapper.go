@@ -24,6 +24,14 @@ type Stat struct {
 	SortWeight  int     `json:"sort_weight"`
 	Icon        string  `json:"icon,omitempty"`
 	Alias       string  `json:"alias,omitempty"`
+
+	// What a fractional stat counts, and its value rounded for display, when
+	// the app's unit registry knows it
+	Unit    string   `json:"unit,omitempty"`
+	Rounded *float64 `json:"rounded,omitempty"`
+
+	// Raw stats merged into this one when Steam reports it under renamed or duplicate IDs
+	Sources []StatSource `json:"sources,omitempty"`
 }
 
 // PlayerStatsResponse represents the complete stats response
@@ -35,7 +43,6 @@ type PlayerStatsResponse struct {
 
 var aliases = map[string]string{
 	"DBD_CamperSkulls":                 "Survivor Bloodpoints (Skulls)",
-	"DBD_KillerSkulls":                 "Killer Bloodpoints (Skulls)",
 	"DBD_SlasherSkulls":                "Killer Bloodpoints (Skulls)",
 	"DBD_GeneratorPct_float":           "Generators Repaired (equivalent)",
 	"DBD_HealPct_float":                "Survivors Healed (equivalent)",
@@ -72,8 +79,6 @@ var aliases = map[string]string{
 
 	"DBD_SacrificedCampers":          "Survivors Sacrificed",
 	"DBD_KilledCampers":              "Survivors Killed (Mori)",
-	"DBD_SacrificedCampers_iam":      "Sacrificed (IAM Tracked)",
-	"DBD_KilledCampers_iam":          "Killed (IAM Tracked)",
 	"DBD_HitNearHook":                "Hits Near Hooks",
 	"DBD_SlasherFullLoadout":         "Killer Full Loadout Matches",
 	"DBD_SlasherMaxScoreByCategory":  "Killer Max Score by Category",
@@ -514,7 +519,7 @@ func MapPlayerStats(ctx context.Context, steamID string, cacheManager cache.Cach
 	}
 
 	// 1) Fetch schema for stats definitions with forced English
-	schema, err := client.GetSchemaForGame(DBDAppID)
+	schema, err := client.GetSchemaForGameCached(ctx, cacheManager)
 	if err != nil {
 		log.Warn("Failed to get stats schema, proceeding with user stats only", "error", err, "steam_id", steamID)
 		// Don't fail completely - continue with user stats only
@@ -524,11 +529,8 @@ func MapPlayerStats(ctx context.Context, steamID string, cacheManager cache.Cach
 	var userStats *SteamPlayerstats
 	var apiErr *APIError
 
-	appID, parseErr := strconv.Atoi(DBDAppID)
-	if parseErr != nil || appID == 0 {
-		log.Error("Invalid DBDAppID; defaulting", "DBDAppID", DBDAppID, "err", parseErr)
-		appID = 381210
-	}
+	app := AppFromContext(ctx)
+	appID := app.NumericID()
 
 	if cacheManager != nil {
 		userStats, apiErr = client.GetUserStatsForGameCached(ctx, steamID, appID, cacheManager)
@@ -553,11 +555,22 @@ func MapPlayerStats(ctx context.Context, steamID string, cacheManager cache.Cach
 		}
 	}
 
-	// 4) Build user stats lookup map
+	// 4) Build user stats lookup map; renamed IDs were already folded at fetch time
 	userByID := map[string]float64{}
+	sourcesByID := map[string][]StatSource{}
 	if userStats != nil && userStats.Stats != nil {
-		for _, us := range userStats.Stats {
+		for _, us := range sanitizeStats(userStats.Stats) {
 			userByID[us.Name] = us.Value
+			if len(us.Sources) > 0 {
+				sourcesByID[us.Name] = us.Sources
+			}
+		}
+	}
+
+	// Schema entries for folded IDs would otherwise come back as their own rows
+	for id := range schemaByID {
+		if app.CanonicalStatID(id) != id {
+			delete(schemaByID, id)
 		}
 	}
 
@@ -573,6 +586,7 @@ func MapPlayerStats(ctx context.Context, steamID string, cacheManager cache.Cach
 			keys = append(keys, k)
 		}
 	}
+	sort.Strings(keys) // map order must not leak into unmapped_stats or sort ties
 
 	// 6) Map each stat with rule detection
 	mapped := make([]Stat, 0, len(keys))
@@ -591,7 +605,7 @@ func MapPlayerStats(ctx context.Context, steamID string, cacheManager cache.Cach
 		var category, valueType string
 		var sortWeight int
 
-		if aliasName, hasAlias := aliases[id]; hasAlias {
+		if aliasName, hasAlias := app.Aliases[id]; hasAlias {
 			displayName = aliasName
 			alias = id
 			matchedBy = "alias"
@@ -635,6 +649,11 @@ func MapPlayerStats(ctx context.Context, steamID string, cacheManager cache.Cach
 			ValueType:   valueType,
 			SortWeight:  sortWeight,
 			Alias:       alias,
+			Sources:     sourcesByID[id],
+		}
+		if unit, ok := app.Units[id]; ok {
+			rounded := unit.Round(value)
+			stat.Unit, stat.Rounded = unit.Unit, &rounded
 		}
 
 		mapped = append(mapped, stat)
@@ -649,7 +668,7 @@ func MapPlayerStats(ctx context.Context, steamID string, cacheManager cache.Cach
 	}
 
 	// 7) Sort stats: killer → survivor → general, then by weight, then by display name
-	sort.Slice(mapped, func(i, j int) bool {
+	sort.SliceStable(mapped, func(i, j int) bool {
 		if mapped[i].Category != mapped[j].Category {
 			return categoryOrder(mapped[i].Category) < categoryOrder(mapped[j].Category)
 		}
@@ -763,21 +782,9 @@ func categoryOrder(category string) int {
 	}
 }
 
-// formatValue formats a raw value according to its type
+// formatValue formats a raw value according to its type in the default locale
 func formatValue(v float64, valueType string, fieldID string) string {
-	switch valueType {
-	case "float":
-		return fmt.Sprintf("%.1f", v)
-	case "grade":
-		_, human, _ := decodeGrade(v, fieldID)
-		return human
-	case "level":
-		return strconv.Itoa(int(v))
-	case "duration":
-		return formatDuration(int64(v))
-	default: // "count"
-		return formatInt(int(v))
-	}
+	return FormatStatValue(englishFormatter{}, v, valueType, fieldID)
 }
 
 // formatInt formats an integer with commas for readability
@@ -816,6 +823,46 @@ func formatDuration(seconds int64) string {
 
 // decodeGrade converts raw grade value to human readable format
 func decodeGrade(v float64, fieldID string) (Grade, string, string) {
+	// If we found a valid grade index, convert it to the DBD grade structure
+	if gradeIndex, found := gradeIndexFor(v, fieldID); found {
+		gradeInfo := dbdGrades[gradeIndex]
+		grade := Grade{Tier: gradeInfo.Tier, Sub: gradeInfo.Sub}
+
+		if gradeInfo.Tier == "Unranked" {
+			return grade, "Unranked", ""
+		}
+
+		human := fmt.Sprintf("%s %s", gradeInfo.Tier, roman(gradeInfo.Sub))
+		return grade, human, roman(gradeInfo.Sub)
+	}
+
+	// Unknown grade - return question mark
+	return Grade{Tier: "Unknown", Sub: 1}, "?", "?"
+}
+
+// GradeRank places a raw grade stat on the 0 (Ash IV) to 19 (Iridescent I)
+// scale so grades can be compared; ok is false when the value is not recognised
+func GradeRank(v float64, fieldID string) (rank int, human string, ok bool) {
+	rank, ok = gradeIndexFor(v, fieldID)
+	if !ok {
+		return 0, "", false
+	}
+	_, human, _ = decodeGrade(v, fieldID)
+	return rank, human, true
+}
+
+// GradeNames lists every grade in rank order, so GradeNames()[rank] is the
+// name GradeRank returns for that rank
+func GradeNames() []string {
+	names := make([]string, len(dbdGrades))
+	for i, grade := range dbdGrades {
+		names[i] = fmt.Sprintf("%s %s", grade.Tier, roman(grade.Sub))
+	}
+	return names
+}
+
+// gradeIndexFor maps a raw grade value to its index in dbdGrades
+func gradeIndexFor(v float64, fieldID string) (int, bool) {
 	gradeCode := int(v)
 
 	// Determine role based on field name
@@ -852,21 +899,7 @@ func decodeGrade(v float64, fieldID string) (Grade, string, string) {
 		}
 	}
 
-	// If we found a valid grade index, convert it to the DBD grade structure
-	if found && gradeIndex >= 0 && gradeIndex < len(dbdGrades) {
-		gradeInfo := dbdGrades[gradeIndex]
-		grade := Grade{Tier: gradeInfo.Tier, Sub: gradeInfo.Sub}
-
-		if gradeInfo.Tier == "Unranked" {
-			return grade, "Unranked", ""
-		}
-
-		human := fmt.Sprintf("%s %s", gradeInfo.Tier, roman(gradeInfo.Sub))
-		return grade, human, roman(gradeInfo.Sub)
-	}
-
-	// Unknown grade - return question mark
-	return Grade{Tier: "Unknown", Sub: 1}, "?", "?"
+	return gradeIndex, found && gradeIndex >= 0 && gradeIndex < len(dbdGrades)
 }
 
 // estimateKillerGrade attempts to estimate killer grade based on value patterns
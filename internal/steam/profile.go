@@ -0,0 +1,104 @@
+package steam
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+// ProfileAccountData is what the profile view needs from one linked account.
+// Stats is nil when they could not be fetched; Achievements is nil for
+// private profiles.
+type ProfileAccountData struct {
+	SteamID      string
+	LinkedAt     time.Time
+	Stats        *models.PlayerStats
+	Achievements *models.AchievementData
+	Grades       models.PeakGrades
+}
+
+// BuildProfileStats combines a profile's accounts in link order. Each
+// account's line is built as for a group member; the combined totals treat
+// the accounts as one player, so an adept earned on two accounts counts once.
+func BuildProfileStats(accounts []ProfileAccountData) models.ProfileStats {
+	members := make([]GroupMemberData, len(accounts))
+	for i, account := range accounts {
+		members[i] = GroupMemberData{
+			SteamID:      account.SteamID,
+			Stats:        account.Stats,
+			Achievements: account.Achievements,
+		}
+		if account.Grades.Killer != nil {
+			members[i].KillerGrade = account.Grades.Killer.Grade
+		}
+		if account.Grades.Survivor != nil {
+			members[i].SurvivorGrade = account.Grades.Survivor.Grade
+		}
+	}
+	lines := BuildGroupStats(members).Members
+
+	stats := models.ProfileStats{
+		Accounts:    make([]models.ProfileAccount, len(lines)),
+		GeneratedAt: time.Now().UTC(),
+	}
+	combined := &stats.Combined
+	combined.Accounts = len(accounts)
+	var bestKiller, bestSurvivor *models.GradePeak
+	survivors, killers := make(map[string]bool), make(map[string]bool)
+
+	for i, line := range lines {
+		stats.Accounts[i] = models.ProfileAccount{GroupMember: line, LinkedAt: accounts[i].LinkedAt}
+		if !line.Available {
+			continue
+		}
+		combined.AvailableAccounts++
+		combined.Escapes += line.Escapes
+		combined.TotalMatches += line.TotalMatches
+		combined.Sacrifices += line.Sacrifices
+		combined.Kills += line.Kills
+		combined.KillerPips += line.KillerPips
+		combined.SurvivorPips += line.SurvivorPips
+		combined.BloodwebPoints += line.BloodwebPoints
+		combined.HoursPlayed += line.HoursPlayed
+
+		if grade := accounts[i].Grades.Killer; grade.Better(bestKiller) {
+			bestKiller = grade
+		}
+		if grade := accounts[i].Grades.Survivor; grade.Better(bestSurvivor) {
+			bestSurvivor = grade
+		}
+		if achievements := accounts[i].Achievements; achievements != nil {
+			for character, unlocked := range achievements.AdeptSurvivors {
+				survivors[character] = survivors[character] || unlocked
+			}
+			for character, unlocked := range achievements.AdeptKillers {
+				killers[character] = killers[character] || unlocked
+			}
+		}
+	}
+
+	combined.EscapeRate = round2(ratio(float64(combined.Escapes), float64(combined.TotalMatches)))
+	if bestKiller != nil {
+		combined.KillerGrade = bestKiller.Grade
+	}
+	if bestSurvivor != nil {
+		combined.SurvivorGrade = bestSurvivor.Grade
+	}
+	combined.AdeptSurvivors = unlockedCharacters(survivors)
+	combined.AdeptKillers = unlockedCharacters(killers)
+	combined.AdeptsUnlocked = len(combined.AdeptSurvivors) + len(combined.AdeptKillers)
+	return stats
+}
+
+// unlockedCharacters lists the unlocked characters in name order
+func unlockedCharacters(unlocked map[string]bool) []string {
+	characters := make([]string, 0, len(unlocked))
+	for character, ok := range unlocked {
+		if ok {
+			characters = append(characters, character)
+		}
+	}
+	sort.Strings(characters)
+	return characters
+}
@@ -0,0 +1,221 @@
+package steam
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/metrics"
+)
+
+// Quota pressure levels, in increasing order of severity
+const (
+	QuotaNormal   = "normal"
+	QuotaElevated = "elevated" // past the soft limit: TTLs stretch, fresh bypasses are refused
+	QuotaCritical = "critical" // quota used up; only cache misses still reach Steam
+)
+
+// QuotaConfig sets the soft daily budget for Steam Web API calls
+type QuotaConfig struct {
+	DailyLimit       int64   `json:"daily_limit"`        // 0 disables quota tracking
+	SoftLimit        float64 `json:"soft_limit"`         // fraction of DailyLimit where pressure starts
+	MaxTTLMultiplier float64 `json:"max_ttl_multiplier"` // TTL scale reached at 100% usage
+}
+
+// QuotaConfigFromEnv reads STEAM_DAILY_QUOTA, STEAM_QUOTA_SOFT_LIMIT and STEAM_QUOTA_MAX_TTL_MULTIPLIER.
+// Steam documents 100,000 calls per key per day.
+func QuotaConfigFromEnv() QuotaConfig {
+	config := QuotaConfig{DailyLimit: 100000, SoftLimit: 0.8, MaxTTLMultiplier: 4}
+	if raw := os.Getenv("STEAM_DAILY_QUOTA"); raw != "" {
+		if parsed, err := strconv.ParseInt(raw, 10, 64); err == nil && parsed >= 0 {
+			config.DailyLimit = parsed
+		}
+	}
+	if raw := os.Getenv("STEAM_QUOTA_SOFT_LIMIT"); raw != "" {
+		if parsed, err := strconv.ParseFloat(raw, 64); err == nil && parsed > 0 && parsed < 1 {
+			config.SoftLimit = parsed
+		}
+	}
+	if raw := os.Getenv("STEAM_QUOTA_MAX_TTL_MULTIPLIER"); raw != "" {
+		if parsed, err := strconv.ParseFloat(raw, 64); err == nil && parsed >= 1 {
+			config.MaxTTLMultiplier = parsed
+		}
+	}
+	return config
+}
+
+// Quota tracks Steam calls over a rolling 24h window against a soft daily budget
+type Quota struct {
+	config QuotaConfig
+	calls  *metrics.RollingCounter
+
+	mu        sync.Mutex
+	lastLevel string
+
+	// shared counts calls from every replica sharing the Steam key. The last
+	// cluster-wide figure is reused for sharedMaxAge so reading usage doesn't
+	// reach Redis on every request; local counts take over while it fails.
+	shared        cache.CounterStore
+	sharedUsed    atomic.Int64
+	sharedAt      atomic.Int64 // unix nanos of sharedUsed
+	sharedRead    atomic.Int64 // unix nanos of the last refresh attempt
+	sharedFailing atomic.Bool
+}
+
+const (
+	sharedQuotaKey    = "steam-quota"
+	sharedMaxAge      = 10 * time.Second
+	sharedStaleAfter  = time.Minute
+	sharedCallTimeout = time.Second
+)
+
+// NewQuota creates a quota tracker
+func NewQuota(config QuotaConfig) *Quota {
+	return &Quota{config: config, calls: metrics.NewRollingCounter(), lastLevel: QuotaNormal}
+}
+
+// ShareCounts tracks usage across every replica through store rather than
+// per instance
+func (q *Quota) ShareCounts(store cache.CounterStore) {
+	q.shared = store
+}
+
+// RecordCall counts one outbound Steam API request
+func (q *Quota) RecordCall() {
+	q.calls.Add(1)
+	if q.shared != nil && q.config.DailyLimit > 0 {
+		q.syncShared(1)
+	}
+	q.observeLevel()
+}
+
+// syncShared adds n to the cluster-wide count and keeps the total it reports
+func (q *Quota) syncShared(n int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), sharedCallTimeout)
+	defer cancel()
+	count, err := q.shared.Take(ctx, sharedQuotaKey, n, 0, 24*time.Hour)
+	if err != nil {
+		if !errors.Is(err, cache.ErrCountersBusy) && !q.sharedFailing.Swap(true) {
+			log.Warn("Shared Steam quota counter unavailable, counting per instance", "error", err.Error())
+		}
+		return
+	}
+	if q.sharedFailing.Swap(false) {
+		log.Info("Shared Steam quota counter recovered")
+	}
+	q.sharedUsed.Store(count.Count)
+	q.sharedAt.Store(time.Now().UnixNano())
+}
+
+// used24h is the calls in the last 24h: cluster-wide when shared and
+// reachable, otherwise this instance's
+func (q *Quota) used24h() (int64, bool) {
+	if q.shared != nil {
+		// One caller refreshes a stale figure; the rest use it meanwhile
+		now, read := time.Now().UnixNano(), q.sharedRead.Load()
+		if time.Duration(now-read) > sharedMaxAge && q.sharedRead.CompareAndSwap(read, now) {
+			q.syncShared(0)
+		}
+		if time.Since(time.Unix(0, q.sharedAt.Load())) <= sharedStaleAfter {
+			return q.sharedUsed.Load(), true
+		}
+	}
+	return q.calls.Sum(24 * time.Hour), false
+}
+
+// Usage returns the fraction of the daily budget used in the last 24h
+func (q *Quota) Usage() float64 {
+	if q.config.DailyLimit <= 0 {
+		return 0
+	}
+	used, _ := q.used24h()
+	return float64(used) / float64(q.config.DailyLimit)
+}
+
+// Level reports the current pressure level
+func (q *Quota) Level() string {
+	return levelFor(q.Usage(), q.config.SoftLimit)
+}
+
+func levelFor(usage, soft float64) string {
+	switch {
+	case usage >= 1:
+		return QuotaCritical
+	case usage >= soft:
+		return QuotaElevated
+	}
+	return QuotaNormal
+}
+
+// TTLMultiplier scales cache TTLs: 1 below the soft limit, rising linearly to
+// MaxTTLMultiplier as usage reaches the daily limit
+func (q *Quota) TTLMultiplier() float64 {
+	usage := q.Usage()
+	soft := q.config.SoftLimit
+	if q.config.DailyLimit <= 0 || usage < soft {
+		return 1
+	}
+	progress := math.Min((usage-soft)/(1-soft), 1)
+	return 1 + progress*(q.config.MaxTTLMultiplier-1)
+}
+
+// AllowFresh reports whether a client may bypass the cache; refused once past the soft limit
+func (q *Quota) AllowFresh() bool {
+	return q.config.DailyLimit <= 0 || q.Usage() < q.config.SoftLimit
+}
+
+// Status summarises quota consumption for the status endpoint
+func (q *Quota) Status() map[string]interface{} {
+	if q.config.DailyLimit <= 0 {
+		return map[string]interface{}{"enabled": false}
+	}
+	used, shared := q.used24h()
+	usage := float64(used) / float64(q.config.DailyLimit)
+	scope := "instance"
+	if shared {
+		scope = "cluster"
+	}
+	return map[string]interface{}{
+		"enabled":        true,
+		"daily_limit":    q.config.DailyLimit,
+		"scope":          scope, // of used_24h; used_1h is always this instance's
+		"used_24h":       used,
+		"instance_24h":   q.calls.Sum(24 * time.Hour),
+		"used_1h":        q.calls.Sum(time.Hour),
+		"remaining":      max(q.config.DailyLimit-used, 0),
+		"usage":          round2(usage),
+		"soft_limit":     q.config.SoftLimit,
+		"level":          levelFor(usage, q.config.SoftLimit),
+		"ttl_multiplier": round2(q.TTLMultiplier()),
+		"fresh_allowed":  q.AllowFresh(),
+	}
+}
+
+// observeLevel logs transitions between pressure levels
+func (q *Quota) observeLevel() {
+	if q.config.DailyLimit <= 0 {
+		return
+	}
+	level := q.Level()
+
+	q.mu.Lock()
+	previous := q.lastLevel
+	q.lastLevel = level
+	q.mu.Unlock()
+
+	if level != previous {
+		log.Warn("Steam API quota pressure changed",
+			"from", previous,
+			"to", level,
+			"usage", round2(q.Usage()),
+			"daily_limit", q.config.DailyLimit,
+			"ttl_multiplier", round2(q.TTLMultiplier()))
+	}
+}
@@ -0,0 +1,96 @@
+package steam
+
+import (
+	"math"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+const (
+	raritySteadyPoints = 0.1 // changes smaller than this read as steady
+	// A day-over-day jump is sharp when it moves at least the configured
+	// points, or halves or doubles a rarity while moving at least sharpMinPoints
+	sharpRatio     = 2.0
+	sharpMinPoints = 0.5
+)
+
+// RarityTrendConfig sets the window a trend is measured over and how large a
+// single-day jump must be to be marked as sharp
+type RarityTrendConfig struct {
+	WindowDays  int
+	SharpPoints float64
+}
+
+// RarityTrend compares current with the snapshot at the start of the window
+// ending at now: the latest one on or before now-WindowDays, else the oldest
+// held. It is nil when there is no earlier snapshot with apiName.
+func RarityTrend(apiName string, current float64, history []models.RaritySnapshot, now time.Time, config RarityTrendConfig) *models.RarityTrend {
+	today := now.UTC().Format("2006-01-02")
+	windowStart := now.UTC().AddDate(0, 0, -config.WindowDays).Format("2006-01-02")
+
+	// history is oldest first; the window is every snapshot from the
+	// baseline on, with today's rarity as the final point
+	baseline := -1
+	for i, snapshot := range history {
+		if snapshot.Date >= today {
+			break
+		}
+		if _, ok := snapshot.Percentages[apiName]; !ok {
+			continue
+		}
+		if baseline < 0 || snapshot.Date <= windowStart {
+			baseline = i
+		}
+	}
+	if baseline < 0 {
+		return nil
+	}
+
+	previous := history[baseline].Percentages[apiName]
+	trend := &models.RarityTrend{
+		Since:     history[baseline].Date,
+		Previous:  previous,
+		Change:    roundPoints(current - previous),
+		Direction: models.RaritySteady,
+	}
+	switch {
+	case trend.Change <= -raritySteadyPoints:
+		trend.Direction = models.RarityRarer
+	case trend.Change >= raritySteadyPoints:
+		trend.Direction = models.RarityMoreCommon
+	}
+
+	prevValue := previous
+	for _, snapshot := range history[baseline+1:] {
+		if snapshot.Date >= today {
+			break
+		}
+		value, ok := snapshot.Percentages[apiName]
+		if !ok {
+			continue
+		}
+		trend.SharpChange = largerSharpChange(trend.SharpChange, snapshot.Date, prevValue, value, config.SharpPoints)
+		prevValue = value
+	}
+	trend.SharpChange = largerSharpChange(trend.SharpChange, today, prevValue, current, config.SharpPoints)
+	return trend
+}
+
+// largerSharpChange returns the jump from->to as of date if it is sharp and
+// larger than best, else best
+func largerSharpChange(best *models.RaritySharpChange, date string, from, to, sharpPoints float64) *models.RaritySharpChange {
+	delta := math.Abs(to - from)
+	sharp := sharpPoints > 0 && delta >= sharpPoints
+	if !sharp && delta >= sharpMinPoints && math.Min(from, to) > 0 {
+		sharp = math.Max(from, to)/math.Min(from, to) >= sharpRatio
+	}
+	if !sharp || (best != nil && delta <= math.Abs(best.Change)) {
+		return best
+	}
+	return &models.RaritySharpChange{Date: date, From: from, To: to, Change: roundPoints(to - from)}
+}
+
+func roundPoints(points float64) float64 {
+	return math.Round(points*100) / 100
+}
@@ -0,0 +1,131 @@
+package steam
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// IDType says how a player identifier in a request should be interpreted
+type IDType string
+
+const (
+	IDTypeAuto    IDType = "auto"    // SteamID64 when well-formed, otherwise vanity
+	IDTypeSteamID IDType = "steamid" // must be a SteamID64; never resolved
+	IDTypeVanity  IDType = "vanity"  // always resolved, even when all digits
+)
+
+// ParseIDType parses an id_type query value; empty means auto
+func ParseIDType(raw string) (IDType, bool) {
+	switch IDType(strings.ToLower(strings.TrimSpace(raw))) {
+	case "", IDTypeAuto:
+		return IDTypeAuto, true
+	case IDTypeSteamID:
+		return IDTypeSteamID, true
+	case IDTypeVanity:
+		return IDTypeVanity, true
+	}
+	return "", false
+}
+
+// ParseProfileURL extracts the identifier from a pasted Steam Community profile
+// URL such as https://steamcommunity.com/id/foo or steamcommunity.com/profiles/7656119...
+// The scheme, www. prefix, trailing path and query are optional. ok is false when
+// input is not a steamcommunity.com URL; a recognised host with an unexpected
+// path returns an empty identifier so callers can reject it specifically.
+func ParseProfileURL(input string) (id string, idType IDType, ok bool) {
+	raw := strings.TrimSpace(input)
+	if !strings.Contains(strings.ToLower(raw), "steamcommunity.com") {
+		return "", "", false
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", true
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if host != "steamcommunity.com" {
+		return "", "", false
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) < 2 || segments[1] == "" {
+		return "", "", true
+	}
+	switch strings.ToLower(segments[0]) {
+	case "id":
+		return segments[1], IDTypeVanity, true
+	case "profiles":
+		return segments[1], IDTypeSteamID, true
+	}
+	return "", "", true
+}
+
+// IsSteamID64 reports whether s is a well-formed individual-account SteamID64
+func IsSteamID64(s string) bool {
+	return len(s) == 17 && isNumeric(s) && strings.HasPrefix(s, "7656119")
+}
+
+// ResolveSteamIDAs resolves an identifier under the given interpretation and
+// reports which interpretation produced the Steam ID. In auto mode a well-formed
+// SteamID64 is used as-is and anything else, including all-digit input, is
+// tried as a vanity URL.
+//
+// Auto mode does not fall back to a vanity lookup when a well-formed SteamID64
+// names no account: telling that apart costs a Steam call on every request by
+// SteamID64, the common case, to serve vanity names shaped exactly like one.
+// Such names need id_type=vanity.
+func (c *Client) ResolveSteamIDAs(ctx context.Context, input string, idType IDType) (string, IDType, *APIError) {
+	switch idType {
+	case IDTypeSteamID:
+		if !IsSteamID64(input) {
+			return "", "", NewValidationError("Invalid Steam ID format. Must be 17 digits starting with 7656119")
+		}
+		return input, IDTypeSteamID, nil
+
+	case IDTypeVanity:
+		steamID, err := c.resolveVanityURL(ctx, input)
+		if err != nil {
+			return "", "", err
+		}
+		return steamID, IDTypeVanity, nil
+	}
+
+	if IsSteamID64(input) {
+		return input, IDTypeSteamID, nil
+	}
+
+	steamID, err := c.resolveVanityURL(ctx, input)
+	if err != nil {
+		return "", "", err
+	}
+	return steamID, IDTypeVanity, nil
+}
+
+func (c *Client) resolveVanityURL(ctx context.Context, vanity string) (string, *APIError) {
+	logSteamInfo("Resolving vanity URL to Steam ID", vanity, "vanity_url", vanity)
+
+	endpoint := "/ISteamUser/ResolveVanityURL/v0001/"
+	params := url.Values{}
+	params.Set("key", c.apiKey)
+	params.Set("vanityurl", vanity)
+
+	var resp VanityURLResponse
+	if err := c.makeRequestContext(ctx, endpoint, params, &resp); err != nil {
+		return "", err
+	}
+
+	if resp.Response.Success != 1 {
+		return "", NewNotFoundError("Vanity URL")
+	}
+
+	log.Info("Successfully resolved vanity URL",
+		"vanity_url", vanity,
+		"steam_id", resp.Response.SteamID)
+	return resp.Response.SteamID, nil
+}
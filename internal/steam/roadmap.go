@@ -0,0 +1,135 @@
+package steam
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+// Weights for the effort score; progress dominates because it is player-specific
+const (
+	roadmapProgressWeight = 0.6
+	roadmapRarityWeight   = 0.4
+)
+
+// achievementStatLinks associates achievement descriptions with the counter that
+// drives them. Order matters: more specific phrases come first.
+var achievementStatLinks = []struct {
+	keyword string
+	statID  string
+}{
+	{`(?:through|via) the hatch`, "DBD_EscapeThroughHatch"},
+	{`generators?`, "DBD_GeneratorPct_float"},
+	{`skill checks?`, "DBD_SkillCheckSuccess"},
+	{`unhooks?`, "DBD_UnhookOrHeal"},
+	{`chainsaw`, "DBD_ChainsawHit"},
+	{`bear traps?`, "DBD_TrapPickup"},
+	{`uncloak`, "DBD_UncloakAttack"},
+	{`bloodpoints`, "DBD_BloodwebPoints"},
+	{`survivors? (?:to the entity|on hooks?)|sacrific\w*`, "DBD_SacrificedCampers"},
+	{`(?:kills?|mori\w*)`, "DBD_KilledCampers"},
+	{`heal|survivors? healed`, "DBD_HealPct_float"},
+	{`escapes?|times? escaped`, "DBD_Escape"},
+}
+
+// Counts must sit next to the counted noun or verb ("repair 50 generators", "escape 25 times", "heal 50")
+// so incidental numbers such as "3 unique perks" are not mistaken for targets.
+var achievementStatPatterns = func() []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0, len(achievementStatLinks)*3)
+	for _, link := range achievementStatLinks {
+		patterns = append(patterns,
+			regexp.MustCompile(`(?i)(\d[\d,]*)\s+(?:[a-z'-]+\s+){0,3}?(?:`+link.keyword+`)\b`),
+			regexp.MustCompile(`(?i)\b(?:`+link.keyword+`)\b(?:\s+[a-z'-]+){0,5}?\s+(\d[\d,]*)\s+times`),
+			regexp.MustCompile(`(?i)\b(?:`+link.keyword+`)\w*\s+(\d[\d,]*)\b`))
+	}
+	return patterns
+}()
+
+// linkAchievementToStat returns the stat counter and target implied by a description
+func linkAchievementToStat(description string) (string, float64, bool) {
+	if description == "" {
+		return "", 0, false
+	}
+
+	for i, pattern := range achievementStatPatterns {
+		match := pattern.FindStringSubmatch(description)
+		if len(match) != 2 {
+			continue
+		}
+		target, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64)
+		// Single-match feats are not counter-driven
+		if err != nil || target <= 1 {
+			continue
+		}
+		return achievementStatLinks[i/3].statID, target, true
+	}
+	return "", 0, false
+}
+
+// BuildRoadmap scores locked achievements by effort and returns the easiest first.
+// statValues maps Steam stat IDs to the player's current counters.
+func BuildRoadmap(achievements []models.MappedAchievement, statValues map[string]float64, limit int) []models.RoadmapEntry {
+	entries := make([]models.RoadmapEntry, 0)
+
+	for _, ach := range achievements {
+		if ach.Unlocked {
+			continue
+		}
+
+		// Unknown rarity is treated as the hardest case
+		rarityDifficulty := 1 - ach.Rarity/100
+		if ach.Rarity <= 0 {
+			rarityDifficulty = 1
+		}
+
+		entry := models.RoadmapEntry{
+			ID:          ach.ID,
+			DisplayName: ach.DisplayName,
+			Description: ach.Description,
+			Icon:        ach.IconGray,
+			Type:        ach.Type,
+			Character:   ach.Character,
+			Rarity:      ach.Rarity,
+			EffortScore: rarityDifficulty,
+			Reason:      fmt.Sprintf("%.1f%% of players have unlocked this", ach.Rarity),
+		}
+		if entry.Icon == "" {
+			entry.Icon = ach.Icon
+		}
+
+		// Adepts are single-match feats; only general achievements track counters
+		if statID, target, ok := linkAchievementToStat(ach.Description); ok && !strings.HasPrefix(ach.Type, "adept") {
+			if current, found := statValues[statID]; found {
+				progress := math.Min(current/target, 1)
+				entry.Progress = &models.RoadmapProgress{
+					Stat:    statID,
+					Current: current,
+					Target:  target,
+					Percent: math.Round(progress*1000) / 10,
+				}
+				entry.EffortScore = roadmapProgressWeight*(1-progress) + roadmapRarityWeight*rarityDifficulty
+				entry.Reason = fmt.Sprintf("%.0f%% of the way to %s", entry.Progress.Percent, formatInt(int(target)))
+			}
+		}
+
+		entry.EffortScore = math.Round(entry.EffortScore*1000) / 1000
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].EffortScore == entries[j].EffortScore {
+			return entries[i].Rarity > entries[j].Rarity
+		}
+		return entries[i].EffortScore < entries[j].EffortScore
+	})
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
@@ -0,0 +1,130 @@
+package steam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// Where a mapped response's achievement schema came from
+const (
+	SchemaSourceSteam     = "steam"     // fetched from Steam, possibly through the cache
+	SchemaSourceDisk      = "disk"      // Steam failed; the last schema it served, read back from disk
+	SchemaSourceHardcoded = "hardcoded" // no schema at all; only known adepts are mapped
+)
+
+// schemaStore keeps the last schema Steam served for each app on disk, so a
+// restart during a Steam outage still maps the full achievement list instead
+// of only the hardcoded adepts
+type schemaStore struct {
+	dir string
+
+	mu     sync.Mutex
+	loaded map[string]*SchemaGame // app ID -> schema, once read or saved
+}
+
+type schemaFile struct {
+	AppID    string      `json:"app_id"`
+	StoredAt time.Time   `json:"stored_at"`
+	Schema   *SchemaGame `json:"schema"`
+}
+
+// schemaStoreFromEnv stores schemas under SCHEMA_STORE_DIR; persistence is
+// off unless it is set
+func schemaStoreFromEnv() *schemaStore {
+	return &schemaStore{dir: os.Getenv("SCHEMA_STORE_DIR"), loaded: make(map[string]*SchemaGame)}
+}
+
+func (s *schemaStore) path(appID string) string {
+	return filepath.Join(s.dir, "schema_"+appID+".json")
+}
+
+// save persists a schema Steam just served. Schemas without achievements are
+// the transient hiccups the cache refuses too, so they never replace a good one.
+func (s *schemaStore) save(appID string, schema *SchemaGame) {
+	if s == nil || s.dir == "" || schema == nil || len(schema.AvailableGameStats.Achievements) == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.loaded[appID] = schema
+	s.mu.Unlock()
+
+	data, err := json.Marshal(schemaFile{AppID: appID, StoredAt: time.Now().UTC(), Schema: schema})
+	if err == nil {
+		err = writeSchemaFile(s.dir, s.path(appID), data)
+	}
+	if err != nil {
+		log.Warn("Failed to persist achievement schema", "app_id", appID, "dir", s.dir, "error", err)
+	}
+}
+
+// load returns the last schema saved for appID. A file that doesn't decode
+// to a non-empty schema for that app is moved aside as .corrupt, so a bad
+// write is reported once rather than on every failed fetch.
+func (s *schemaStore) load(appID string) (*SchemaGame, bool) {
+	if s == nil || s.dir == "" {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if schema, ok := s.loaded[appID]; ok {
+		return schema, true
+	}
+
+	path := s.path(appID)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Warn("Failed to read persisted achievement schema", "path", path, "error", err)
+		}
+		return nil, false
+	}
+	var file schemaFile
+	if err := json.Unmarshal(data, &file); err != nil || file.AppID != appID ||
+		file.Schema == nil || len(file.Schema.AvailableGameStats.Achievements) == 0 {
+		log.Error("Persisted achievement schema is invalid, quarantining it",
+			"path", path, "error", err)
+		if err := os.Rename(path, path+".corrupt"); err != nil {
+			log.Warn("Failed to quarantine persisted achievement schema", "path", path, "error", err)
+		}
+		return nil, false
+	}
+
+	log.Info("Loaded persisted achievement schema",
+		"app_id", appID,
+		"stored_at", file.StoredAt,
+		"achievements", len(file.Schema.AvailableGameStats.Achievements))
+	s.loaded[appID] = file.Schema
+	return file.Schema, true
+}
+
+// writeSchemaFile writes then renames so a crash never leaves a truncated schema behind
+func writeSchemaFile(dir, path string, data []byte) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, ".schema-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package steam
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// maxUnknownFields bounds the telemetry table if Steam starts echoing
+// arbitrary keys (e.g. a map we modelled as a struct)
+const maxUnknownFields = 500
+
+// UnknownField is a JSON path Steam sent that our response types don't model
+type UnknownField struct {
+	Endpoint    string    `json:"endpoint"`
+	Path        string    `json:"path"` // e.g. playerstats.stats[].unit
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	Occurrences int       `json:"occurrences"`
+}
+
+type shapeTelemetry struct {
+	enabled bool
+	mu      sync.Mutex
+	fields  map[string]*UnknownField // endpoint + " " + path
+	dropped int
+}
+
+// STEAM_JSON_TELEMETRY=false turns the strict second decode off
+var telemetry = &shapeTelemetry{
+	enabled: envBool("STEAM_JSON_TELEMETRY", true),
+	fields:  make(map[string]*UnknownField),
+}
+
+func envBool(key string, fallback bool) bool {
+	if parsed, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return parsed
+	}
+	return fallback
+}
+
+// decodeSteamJSON unmarshals a Steam payload as before. In telemetry mode it
+// then re-decodes with DisallowUnknownFields and, when that objects, records
+// every unmodelled path so API shape changes show up before data goes missing.
+// Telemetry never fails the decode.
+func decodeSteamJSON(endpoint string, body []byte, v interface{}) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return err
+	}
+	if target := reflect.TypeOf(v); telemetry.enabled && target != nil && target.Kind() == reflect.Pointer {
+		telemetry.inspect(endpoint, body, target)
+	}
+	return nil
+}
+
+func (t *shapeTelemetry) inspect(endpoint string, body []byte, target reflect.Type) {
+	strict := json.NewDecoder(bytes.NewReader(body))
+	strict.DisallowUnknownFields()
+	scratch := reflect.New(target.Elem()).Interface()
+	if err := strict.Decode(scratch); err == nil || !strings.Contains(err.Error(), "unknown field") {
+		return
+	}
+
+	// The strict decoder stops at the first unknown key; walk the payload for all of them
+	var raw interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return
+	}
+	paths := make(map[string]bool)
+	collectUnknownFields(raw, target, "", paths)
+	t.record(endpoint, paths)
+}
+
+func (t *shapeTelemetry) record(endpoint string, paths map[string]bool) {
+	now := time.Now().UTC()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for path := range paths {
+		key := endpoint + " " + path
+		if field, ok := t.fields[key]; ok {
+			field.LastSeen = now
+			field.Occurrences++
+			continue
+		}
+		if len(t.fields) >= maxUnknownFields {
+			t.dropped++
+			continue
+		}
+		t.fields[key] = &UnknownField{Endpoint: endpoint, Path: path, FirstSeen: now, LastSeen: now, Occurrences: 1}
+		log.Warn("Steam response contains an unmodelled field",
+			"endpoint", endpoint,
+			"path", path)
+	}
+}
+
+// collectUnknownFields compares a decoded payload against the Go type it is
+// unmarshalled into, matching keys case-insensitively as encoding/json does
+func collectUnknownFields(raw interface{}, t reflect.Type, path string, out map[string]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// Types that decode themselves define their own shape
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := raw.(map[string]interface{})
+		if !ok {
+			return
+		}
+		known := make(map[string]reflect.Type)
+		structFields(t, known)
+		for key, value := range object {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			fieldType, ok := known[strings.ToLower(key)]
+			if !ok {
+				out[child] = true
+				continue
+			}
+			collectUnknownFields(value, fieldType, child, out)
+		}
+	case reflect.Slice, reflect.Array:
+		if items, ok := raw.([]interface{}); ok {
+			for _, item := range items {
+				collectUnknownFields(item, t.Elem(), path+"[]", out)
+			}
+		}
+	case reflect.Map:
+		if object, ok := raw.(map[string]interface{}); ok {
+			for _, value := range object {
+				collectUnknownFields(value, t.Elem(), path+".*", out)
+			}
+		}
+	}
+}
+
+// structFields lists the JSON names a struct accepts, lowercased, including
+// promoted fields of embedded structs
+func structFields(t reflect.Type, known map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				structFields(embedded, known)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		known[strings.ToLower(name)] = field.Type
+	}
+}
+
+// UnknownFields returns the unmodelled Steam fields seen since startup, most
+// recent first, and how many more were not recorded because the table was full
+func UnknownFields() ([]UnknownField, int) {
+	telemetry.mu.Lock()
+	defer telemetry.mu.Unlock()
+
+	fields := make([]UnknownField, 0, len(telemetry.fields))
+	for _, field := range telemetry.fields {
+		fields = append(fields, *field)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].LastSeen.After(fields[j].LastSeen)
+	})
+	return fields, telemetry.dropped
+}
+
+// ShapeTelemetryEnabled reports whether Steam payloads are checked for unknown fields
+func ShapeTelemetryEnabled() bool {
+	return telemetry.enabled
+}
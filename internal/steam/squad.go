@@ -0,0 +1,186 @@
+package steam
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+// SquadMemberData is what the squad report needs from one player. Achievements
+// is nil for private profiles; StatValues may be empty when raw stats failed.
+type SquadMemberData struct {
+	Stats        models.PlayerStats
+	Achievements *models.AchievementData
+	StatValues   map[string]float64
+}
+
+// squadRoles ranks members on survivor analytics; ties go to the earlier member
+var squadRoles = []struct {
+	role  string
+	value func(*models.SurvivorAnalytics, models.SquadMember) float64
+}{
+	{"escape_artist", func(_ *models.SurvivorAnalytics, m models.SquadMember) float64 { return m.EscapeRate }},
+	{"altruist", func(a *models.SurvivorAnalytics, _ models.SquadMember) float64 { return a.AltruismScore }},
+	{"objective", func(a *models.SurvivorAnalytics, _ models.SquadMember) float64 { return a.ObjectiveFocus }},
+	{"stealth", func(a *models.SurvivorAnalytics, _ models.SquadMember) float64 { return a.Stealth.Index }},
+	{"chase", func(a *models.SurvivorAnalytics, _ models.SquadMember) float64 { return a.Chase.Index }},
+}
+
+// BuildSquadReport combines members in request order.
+//
+//	escape_rate          = escapes / total matches (all roles; Steam has no per-role match count)
+//	aggregate escape_rate= Σ escapes / Σ matches
+//	main_role            = killer or survivor when its grade pips are 25% ahead, else balanced
+//	unlocked_by_all      = achievements unlocked by every member with visible achievements
+func BuildSquadReport(members []SquadMemberData) models.SquadReport {
+	report := models.SquadReport{
+		Members:        make([]models.SquadMember, 0, len(members)),
+		StrongestRoles: []models.SquadRole{},
+		SharedAchievements: models.SquadAchievements{
+			UnlockedByAll: []models.SquadAchievement{},
+		},
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	analytics := make([]*models.SurvivorAnalytics, len(members))
+	distinctAdepts := make(map[string]bool)
+	rateSum := 0.0
+
+	for i, data := range members {
+		stats := data.Stats
+		survivor := BuildSurvivorAnalytics(stats, data.StatValues)
+		analytics[i] = survivor
+
+		member := models.SquadMember{
+			SteamID:               stats.SteamID,
+			DisplayName:           stats.DisplayName,
+			Avatar:                stats.Avatar,
+			Escapes:               stats.Escapes,
+			TotalMatches:          stats.TotalMatches,
+			EscapeRate:            round2(ratio(float64(stats.Escapes), float64(stats.TotalMatches))),
+			MainRole:              mainRole(stats.KillerPips, stats.SurvivorPips),
+			PlayStyle:             survivor.PlayStyle,
+			AltruismRating:        survivor.AltruismRating,
+			AchievementsAvailable: data.Achievements != nil,
+		}
+		if data.Achievements != nil {
+			for character, unlocked := range data.Achievements.AdeptSurvivors {
+				if unlocked {
+					member.AdeptsUnlocked++
+					distinctAdepts["survivor:"+character] = true
+				}
+			}
+			for character, unlocked := range data.Achievements.AdeptKillers {
+				if unlocked {
+					member.AdeptsUnlocked++
+					distinctAdepts["killer:"+character] = true
+				}
+			}
+		}
+
+		report.Members = append(report.Members, member)
+		report.Aggregate.Escapes += member.Escapes
+		report.Aggregate.TotalMatches += member.TotalMatches
+		report.Aggregate.TotalAdepts += member.AdeptsUnlocked
+		rateSum += member.EscapeRate
+	}
+
+	report.Aggregate.DistinctAdepts = len(distinctAdepts)
+	report.Aggregate.EscapeRate = round2(ratio(float64(report.Aggregate.Escapes), float64(report.Aggregate.TotalMatches)))
+	report.Aggregate.AverageEscapeRate = round2(ratio(rateSum, float64(len(members))))
+
+	for _, r := range squadRoles {
+		best := -1
+		bestValue := 0.0
+		for i := range report.Members {
+			if v := r.value(analytics[i], report.Members[i]); v > bestValue {
+				best, bestValue = i, v
+			}
+		}
+		if best >= 0 {
+			report.StrongestRoles = append(report.StrongestRoles, models.SquadRole{
+				Role:    r.role,
+				SteamID: report.Members[best].SteamID,
+				Value:   round2(bestValue),
+			})
+		}
+	}
+
+	report.SharedAchievements = sharedAchievements(members)
+	return report
+}
+
+func mainRole(killerPips, survivorPips int) string {
+	switch {
+	case killerPips == 0 && survivorPips == 0:
+		return "balanced"
+	case float64(killerPips) >= 1.25*float64(survivorPips):
+		return "killer"
+	case float64(survivorPips) >= 1.25*float64(killerPips):
+		return "survivor"
+	}
+	return "balanced"
+}
+
+// sharedAchievements counts unlock overlap across members with visible achievements.
+// The shared list is rarest first so the most impressive common unlocks lead.
+func sharedAchievements(members []SquadMemberData) models.SquadAchievements {
+	result := models.SquadAchievements{UnlockedByAll: []models.SquadAchievement{}}
+
+	type tally struct {
+		achievement models.MappedAchievement
+		unlocked    int
+	}
+	tallies := make(map[string]*tally)
+	var order []string
+
+	for _, data := range members {
+		if data.Achievements == nil || len(data.Achievements.MappedAchievements) == 0 {
+			continue
+		}
+		result.ComparedMembers++
+		for _, ach := range data.Achievements.MappedAchievements {
+			t, ok := tallies[ach.ID]
+			if !ok {
+				t = &tally{achievement: ach}
+				tallies[ach.ID] = t
+				order = append(order, ach.ID)
+			}
+			if ach.Unlocked {
+				t.unlocked++
+			}
+		}
+	}
+
+	if result.ComparedMembers == 0 {
+		return result
+	}
+
+	for _, id := range order {
+		t := tallies[id]
+		switch {
+		case t.unlocked == result.ComparedMembers:
+			result.UnlockedByAll = append(result.UnlockedByAll, models.SquadAchievement{
+				ID:          t.achievement.ID,
+				DisplayName: t.achievement.DisplayName,
+				Rarity:      t.achievement.Rarity,
+			})
+			result.UnlockedByAny++
+		case t.unlocked > 0:
+			result.UnlockedByAny++
+		default:
+			result.UnlockedByNone++
+		}
+	}
+
+	sort.SliceStable(result.UnlockedByAll, func(i, j int) bool {
+		a, b := result.UnlockedByAll[i].Rarity, result.UnlockedByAll[j].Rarity
+		// Unknown rarity (0) sorts last
+		if (a == 0) != (b == 0) {
+			return b == 0
+		}
+		return a < b
+	})
+	return result
+}
@@ -0,0 +1,184 @@
+package steam
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// DefaultLocale is the locale the built-in Formatted values are rendered in
+const DefaultLocale = "en-US"
+
+// StatFormatter renders stat values for one locale
+type StatFormatter interface {
+	Locale() string
+	Int(n int) string
+	Float(v float64) string
+	Duration(seconds int64) string
+}
+
+// DurationUnits are the unit labels a locale writes durations with, e.g. "2 Std. 5 Min."
+type DurationUnits struct {
+	Hour, Minute, Second string
+	Spaced               bool // "2 h 5 min" rather than "2h5min"
+}
+
+// englishFormatter keeps the original comma-grouped, h/m/s output
+type englishFormatter struct{}
+
+func (englishFormatter) Locale() string                { return DefaultLocale }
+func (englishFormatter) Int(n int) string              { return formatInt(n) }
+func (englishFormatter) Float(v float64) string        { return fmt.Sprintf("%.1f", v) }
+func (englishFormatter) Duration(seconds int64) string { return formatDuration(seconds) }
+
+// localeFormatter groups digits with golang.org/x/text and labels durations from a table
+type localeFormatter struct {
+	tag     language.Tag
+	printer *message.Printer
+	units   DurationUnits
+}
+
+// NewLocaleFormatter returns a formatter using the CLDR number conventions of tag
+func NewLocaleFormatter(tag language.Tag, units DurationUnits) StatFormatter {
+	return &localeFormatter{tag: tag, printer: message.NewPrinter(tag), units: units}
+}
+
+func (f *localeFormatter) Locale() string         { return f.tag.String() }
+func (f *localeFormatter) Int(n int) string       { return f.printer.Sprintf("%d", n) }
+func (f *localeFormatter) Float(v float64) string { return f.printer.Sprintf("%.1f", v) }
+
+func (f *localeFormatter) Duration(seconds int64) string {
+	d := time.Duration(seconds) * time.Second
+	switch {
+	case d < time.Minute:
+		return f.unit(int(d.Seconds()), f.units.Second)
+	case d < time.Hour:
+		return f.join(f.unit(int(d.Minutes()), f.units.Minute), f.unit(int(d.Seconds())%60, f.units.Second))
+	default:
+		return f.join(f.unit(int(d.Hours()), f.units.Hour), f.unit(int(d.Minutes())%60, f.units.Minute))
+	}
+}
+
+func (f *localeFormatter) unit(n int, label string) string {
+	if f.units.Spaced {
+		return f.printer.Sprintf("%d", n) + " " + label
+	}
+	return f.printer.Sprintf("%d", n) + label
+}
+
+func (f *localeFormatter) join(a, b string) string {
+	if f.units.Spaced {
+		return a + " " + b
+	}
+	return a + b
+}
+
+var (
+	formattersMu  sync.RWMutex
+	formatters    = map[string]StatFormatter{DefaultLocale: englishFormatter{}}
+	formatterTags []language.Tag
+	localeMatcher language.Matcher
+)
+
+func init() {
+	spaced := func(h, m, s string) DurationUnits { return DurationUnits{Hour: h, Minute: m, Second: s, Spaced: true} }
+	compact := func(h, m, s string) DurationUnits { return DurationUnits{Hour: h, Minute: m, Second: s} }
+
+	for locale, units := range map[string]DurationUnits{
+		"de-DE": spaced("Std.", "Min.", "Sek."),
+		"es-ES": spaced("h", "min", "s"),
+		"fr-FR": spaced("h", "min", "s"),
+		"it-IT": spaced("h", "min", "s"),
+		"pt-BR": spaced("h", "min", "s"),
+		"pl-PL": spaced("godz.", "min", "s"),
+		"ru-RU": spaced("ч", "мин", "с"),
+		"ja-JP": compact("時間", "分", "秒"),
+		"ko-KR": spaced("시간", "분", "초"),
+		"zh-CN": compact("小时", "分", "秒"),
+	} {
+		RegisterStatFormatter(NewLocaleFormatter(language.MustParse(locale), units))
+	}
+}
+
+// RegisterStatFormatter adds or replaces the formatter for f.Locale(); requests
+// for nearby locales (de-AT for de-DE) are matched to it
+func RegisterStatFormatter(f StatFormatter) {
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+
+	formatters[f.Locale()] = f
+
+	// The default locale leads so unmatched requests never pick a regional variant by accident
+	formatterTags = []language.Tag{language.MustParse(DefaultLocale)}
+	for locale := range formatters {
+		if locale != DefaultLocale {
+			formatterTags = append(formatterTags, language.MustParse(locale))
+		}
+	}
+	sort.Slice(formatterTags[1:], func(i, j int) bool {
+		return formatterTags[i+1].String() < formatterTags[j+1].String()
+	})
+	localeMatcher = language.NewMatcher(formatterTags)
+}
+
+// Locales lists the locales with a registered formatter, the default first
+func Locales() []string {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+
+	locales := make([]string, len(formatterTags))
+	for i, tag := range formatterTags {
+		locales[i] = tag.String()
+	}
+	return locales
+}
+
+// StatFormatterFor resolves a locale query value ("de-DE", "pt_br", "fr") to the
+// closest registered formatter. Empty means the default; unknown languages fail.
+func StatFormatterFor(raw string) (StatFormatter, bool) {
+	raw = strings.TrimSpace(strings.ReplaceAll(raw, "_", "-"))
+	if raw == "" {
+		return englishFormatter{}, true
+	}
+	tag, err := language.Parse(raw)
+	if err != nil {
+		return nil, false
+	}
+
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+
+	_, index, confidence := localeMatcher.Match(tag)
+	if confidence < language.High {
+		return nil, false
+	}
+	return formatters[formatterTags[index].String()], true
+}
+
+// FormatStatValue renders a value the way the mapper fills Stat.Formatted
+func FormatStatValue(f StatFormatter, v float64, valueType, fieldID string) string {
+	switch valueType {
+	case "float":
+		return f.Float(v)
+	case "grade":
+		_, human, _ := decodeGrade(v, fieldID)
+		return human
+	case "level":
+		return strconv.Itoa(int(v))
+	case "duration":
+		// Formatters work in time.Duration, which overflows past ~292 years
+		if seconds := float64(math.MaxInt64 / int64(time.Second)); v > seconds {
+			v = seconds
+		}
+		return f.Duration(int64(v))
+	default: // "count"
+		return f.Int(int(v))
+	}
+}
@@ -0,0 +1,153 @@
+package steam
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Stat categories and value types assigned by MapPlayerStats
+var (
+	StatCategories = []string{"killer", "survivor", "general"}
+	StatValueTypes = []string{"count", "float", "duration", "grade", "level"}
+	StatSorts      = []string{"default", "value_desc", "value_asc", "name_asc", "name_desc"}
+)
+
+// StatListQuery narrows and orders a mapped stat list. Empty filters match
+// everything; the default sort keeps MapPlayerStats' category/weight order.
+type StatListQuery struct {
+	Category  string
+	ValueType string
+	Sort      string
+	Limit     int
+	Offset    int
+}
+
+// FilterStats applies q to stats and returns the requested page along with the
+// number of stats that matched before paging. The input slice is not modified.
+func FilterStats(stats []Stat, q StatListQuery) ([]Stat, int) {
+	matched := make([]Stat, 0, len(stats))
+	for _, stat := range stats {
+		if q.Category != "" && stat.Category != q.Category {
+			continue
+		}
+		if q.ValueType != "" && stat.ValueType != q.ValueType {
+			continue
+		}
+		matched = append(matched, stat)
+	}
+
+	switch q.Sort {
+	case "value_desc":
+		sort.SliceStable(matched, func(i, j int) bool { return matched[i].Value > matched[j].Value })
+	case "value_asc":
+		sort.SliceStable(matched, func(i, j int) bool { return matched[i].Value < matched[j].Value })
+	case "name_asc":
+		sort.SliceStable(matched, func(i, j int) bool {
+			return strings.ToLower(matched[i].DisplayName) < strings.ToLower(matched[j].DisplayName)
+		})
+	case "name_desc":
+		sort.SliceStable(matched, func(i, j int) bool {
+			return strings.ToLower(matched[i].DisplayName) > strings.ToLower(matched[j].DisplayName)
+		})
+	}
+
+	total := len(matched)
+	if q.Offset >= total {
+		return []Stat{}, total
+	}
+	end := total
+	if q.Limit > 0 && q.Offset+q.Limit < total {
+		end = q.Offset + q.Limit
+	}
+	return matched[q.Offset:end], total
+}
+
+// StatGroupings are the keys AggregateStats can group by
+var StatGroupings = []string{"category", "value_type"}
+
+// additiveValueTypes are the value types whose values can be summed; grades
+// and levels are positions on a scale, so only their range is reported
+var additiveValueTypes = map[string]bool{"count": true, "float": true, "duration": true}
+
+// StatAggregate totals the stats of one value type
+type StatAggregate struct {
+	ValueType string   `json:"value_type"`
+	Count     int      `json:"count"`
+	Sum       *float64 `json:"sum,omitempty"` // additive value types only
+	Min       float64  `json:"min"`
+	Max       float64  `json:"max"`
+}
+
+// StatCategoryGroup totals a category's stats per value type
+type StatCategoryGroup struct {
+	Category   string          `json:"category"`
+	Count      int             `json:"count"`
+	ValueTypes []StatAggregate `json:"value_types"`
+}
+
+// GroupStatsByCategory aggregates stats per category and, within each, per
+// value type, in the order of StatCategories and StatValueTypes with any
+// others after by name
+func GroupStatsByCategory(stats []Stat) []StatCategoryGroup {
+	byCategory := make(map[string][]Stat)
+	for _, stat := range stats {
+		byCategory[stat.Category] = append(byCategory[stat.Category], stat)
+	}
+	groups := make([]StatCategoryGroup, 0, len(byCategory))
+	for _, category := range orderedKeys(byCategory, StatCategories) {
+		members := byCategory[category]
+		groups = append(groups, StatCategoryGroup{
+			Category:   category,
+			Count:      len(members),
+			ValueTypes: AggregateStatsByValueType(members),
+		})
+	}
+	return groups
+}
+
+// AggregateStatsByValueType aggregates stats per value type, in the order of
+// StatValueTypes with any others after by name
+func AggregateStatsByValueType(stats []Stat) []StatAggregate {
+	byType := make(map[string]*StatAggregate)
+	for _, stat := range stats {
+		agg, ok := byType[stat.ValueType]
+		if !ok {
+			agg = &StatAggregate{ValueType: stat.ValueType, Min: stat.Value, Max: stat.Value}
+			if additiveValueTypes[stat.ValueType] {
+				agg.Sum = new(float64)
+			}
+			byType[stat.ValueType] = agg
+		}
+		agg.Count++
+		agg.Min = math.Min(agg.Min, stat.Value)
+		agg.Max = math.Max(agg.Max, stat.Value)
+		if agg.Sum != nil {
+			*agg.Sum += stat.Value
+		}
+	}
+	aggregates := make([]StatAggregate, 0, len(byType))
+	for _, valueType := range orderedKeys(byType, StatValueTypes) {
+		aggregates = append(aggregates, *byType[valueType])
+	}
+	return aggregates
+}
+
+// orderedKeys returns m's keys in the order listed by known, then the rest sorted
+func orderedKeys[V any](m map[string]V, known []string) []string {
+	keys := make([]string, 0, len(m))
+	for _, key := range known {
+		if _, ok := m[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	var rest []string
+	for key := range m {
+		if !slices.Contains(known, key) {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
@@ -0,0 +1,93 @@
+package steam
+
+import (
+	"math"
+	"strings"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+// BuildSurvivorAnalytics derives a survivor style profile from flat stats plus
+// raw schema stat values (which may be empty when structured stats were unavailable).
+//
+//	altruistic_actions    = unhooks/heals + post-exit saves
+//	altruism_per_escape   = altruistic_actions / escapes
+//	altruism_score        = altruistic_actions / (altruistic_actions + escapes)
+//	objective_focus       = generators / (generators + heals), both in "equivalent" units
+//	map_objective_repairs = Σ DBD_FixSecondFloorGenerator* stats
+//	stealth.signals       = untouched escapes (Σ DBD_EscapeNoBlood* stats) / escapes,
+//	                        hatch escapes / escapes
+//	chase.signals         = escapes while injured / escapes, hooked-and-escaped / escapes
+//	index                 = mean of the signals, capped at 1
+//	play_style            = whichever index is at least 25% higher, otherwise balanced
+func BuildSurvivorAnalytics(stats models.PlayerStats, statValues map[string]float64) *models.SurvivorAnalytics {
+	escapes := float64(stats.Escapes)
+	actions := stats.UnhookOrHeal + stats.UnhookOrHealPostExit
+
+	analytics := &models.SurvivorAnalytics{
+		Escapes:           stats.Escapes,
+		AltruisticActions: actions,
+		AltruismPerEscape: round2(ratio(float64(actions), escapes)),
+		AltruismScore:     round2(ratio(float64(actions), float64(actions)+escapes)),
+		ObjectiveFocus:    round2(ratio(stats.GeneratorPct, stats.GeneratorPct+stats.HealPct)),
+	}
+
+	switch {
+	case actions == 0 && escapes == 0:
+		analytics.AltruismRating = "unknown"
+	case analytics.AltruismScore >= 0.6:
+		analytics.AltruismRating = "altruist"
+	case analytics.AltruismScore >= 0.35:
+		analytics.AltruismRating = "balanced"
+	default:
+		analytics.AltruismRating = "selfish"
+	}
+
+	untouchedEscapes := 0.0
+	mapRepairs := 0.0
+	for id, value := range statValues {
+		switch {
+		case strings.HasPrefix(id, "DBD_EscapeNoBlood"):
+			untouchedEscapes += value
+		case strings.HasPrefix(id, "DBD_FixSecondFloorGenerator"):
+			mapRepairs += value
+		}
+	}
+	analytics.MapObjectiveRepairs = int(mapRepairs)
+
+	analytics.Stealth = indicators(map[string]float64{
+		"untouched_escape_share": ratio(untouchedEscapes, escapes),
+		"hatch_escape_share":     ratio(float64(stats.EscapeThroughHatch), escapes),
+	})
+	analytics.Chase = indicators(map[string]float64{
+		"injured_escape_share": ratio(float64(stats.EscapesKO), escapes),
+		"hooked_escape_share":  ratio(float64(stats.HookedAndEscape), escapes),
+	})
+
+	stealth, chase := analytics.Stealth.Index, analytics.Chase.Index
+	switch {
+	case escapes == 0:
+		analytics.PlayStyle = "unknown"
+	case stealth > chase*1.25:
+		analytics.PlayStyle = "stealth"
+	case chase > stealth*1.25:
+		analytics.PlayStyle = "chase"
+	default:
+		analytics.PlayStyle = "balanced"
+	}
+
+	return analytics
+}
+
+func indicators(signals map[string]float64) models.SurvivalIndicators {
+	sum := 0.0
+	for name, value := range signals {
+		value = round2(math.Min(value, 1))
+		signals[name] = value
+		sum += value
+	}
+	return models.SurvivalIndicators{
+		Index:   round2(ratio(sum, float64(len(signals)))),
+		Signals: signals,
+	}
+}
@@ -0,0 +1,147 @@
+package steam
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// DefaultLanguage is the language of the built-in alias display names
+const DefaultLanguage = "en"
+
+// Community-contributed display name translations, one file per language code.
+// Keys are stat IDs; anything missing falls back to the English alias.
+//
+//go:embed translations/*.json
+var translationFS embed.FS
+
+type translationFile struct {
+	Name         string            `json:"name"`
+	DisplayNames map[string]string `json:"display_names"`
+}
+
+var (
+	translationsOnce sync.Once
+	translations     map[string]translationFile
+)
+
+func loadTranslations() {
+	translations = make(map[string]translationFile)
+
+	entries, err := translationFS.ReadDir("translations")
+	if err != nil {
+		log.Error("Failed to read bundled translations", "error", err)
+		return
+	}
+	for _, entry := range entries {
+		data, err := translationFS.ReadFile("translations/" + entry.Name())
+		if err != nil {
+			log.Error("Failed to read translation file", "file", entry.Name(), "error", err)
+			continue
+		}
+		var file translationFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			log.Error("Skipping malformed translation file", "file", entry.Name(), "error", err)
+			continue
+		}
+		lang := strings.ToLower(strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+		translations[lang] = file
+	}
+}
+
+// Languages lists the supported response languages, English first
+func Languages() []string {
+	translationsOnce.Do(loadTranslations)
+
+	langs := make([]string, 0, len(translations)+1)
+	for lang := range translations {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return append([]string{DefaultLanguage}, langs...)
+}
+
+// ParseLanguage normalises a lang query value to a supported language code.
+// Region variants fall back to their base language ("es-MX" -> "es"); empty means English.
+func ParseLanguage(raw string) (string, bool) {
+	translationsOnce.Do(loadTranslations)
+
+	lang := strings.ToLower(strings.TrimSpace(strings.ReplaceAll(raw, "_", "-")))
+	if lang == "" {
+		return DefaultLanguage, true
+	}
+	base, _, _ := strings.Cut(lang, "-")
+	if base == DefaultLanguage {
+		return DefaultLanguage, true
+	}
+	if _, ok := translations[lang]; ok {
+		return lang, true
+	}
+	if _, ok := translations[base]; ok {
+		return base, true
+	}
+	return "", false
+}
+
+// LocalizedDisplayName returns the translated name for a stat, or fallback when
+// the language has no entry for it
+func LocalizedDisplayName(lang, statID, fallback string) string {
+	if lang == DefaultLanguage {
+		return fallback
+	}
+	translationsOnce.Do(loadTranslations)
+
+	if name := translations[lang].DisplayNames[statID]; name != "" {
+		return name
+	}
+	return fallback
+}
+
+// TranslationCoverage reports how much of the English alias table a language covers
+type TranslationCoverage struct {
+	Language   string   `json:"language"`
+	Name       string   `json:"name"`
+	Translated int      `json:"translated"`
+	Total      int      `json:"total"`
+	Percent    float64  `json:"percent"`
+	Missing    []string `json:"missing"`
+	Unknown    []string `json:"unknown,omitempty"` // translated IDs with no English alias
+}
+
+// TranslationCoverageReport lists coverage for every bundled language
+func TranslationCoverageReport() []TranslationCoverage {
+	translationsOnce.Do(loadTranslations)
+
+	report := make([]TranslationCoverage, 0, len(translations))
+	for _, lang := range Languages()[1:] {
+		file := translations[lang]
+		coverage := TranslationCoverage{
+			Language: lang,
+			Name:     file.Name,
+			Total:    len(aliases),
+			Missing:  []string{},
+		}
+		for id := range aliases {
+			if file.DisplayNames[id] != "" {
+				coverage.Translated++
+			} else {
+				coverage.Missing = append(coverage.Missing, id)
+			}
+		}
+		for id := range file.DisplayNames {
+			if _, ok := aliases[id]; !ok {
+				coverage.Unknown = append(coverage.Unknown, id)
+			}
+		}
+		sort.Strings(coverage.Missing)
+		sort.Strings(coverage.Unknown)
+		coverage.Percent = round2(ratio(float64(coverage.Translated), float64(coverage.Total)) * 100)
+		report = append(report, coverage)
+	}
+	return report
+}
@@ -30,8 +30,9 @@ type SteamPlayerstats struct {
 }
 
 type SteamStat struct {
-	Name  string  `json:"name"`
-	Value float64 `json:"value"`
+	Name    string       `json:"name"`
+	Value   float64      `json:"value"`
+	Sources []StatSource `json:"sources,omitempty"` // raw stats merged into this one; see canonicalizeStats
 }
 
 type VanityURLResponse struct {
@@ -162,3 +163,19 @@ type GlobalAchievementPercentage struct {
 	Name    string  `json:"name"`    // API name
 	Percent float64 `json:"percent"` // 0-100 percentage
 }
+
+// Steam Player Bans API Response Types
+
+type playerBansResponse struct {
+	Players []PlayerBans `json:"players"`
+}
+
+type PlayerBans struct {
+	SteamID          string `json:"SteamId"`
+	CommunityBanned  bool   `json:"CommunityBanned"`
+	VACBanned        bool   `json:"VACBanned"`
+	NumberOfVACBans  int    `json:"NumberOfVACBans"`
+	DaysSinceLastBan int    `json:"DaysSinceLastBan"`
+	NumberOfGameBans int    `json:"NumberOfGameBans"`
+	EconomyBan       string `json:"EconomyBan"`
+}
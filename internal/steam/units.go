@@ -0,0 +1,23 @@
+package steam
+
+import "math"
+
+// StatUnit says what a fractional stat counts. Steam reports "equivalent"
+// stats as raw floats: DBD_GeneratorPct_float adds up the share of each
+// generator a player repaired, so 12.37 is a bit over twelve whole generators.
+type StatUnit struct {
+	Unit      string `json:"unit"`
+	Precision int    `json:"precision"` // decimals kept in the rounded presentation value
+}
+
+// Round returns v rounded to u's precision
+func (u StatUnit) Round(v float64) float64 {
+	scale := math.Pow10(u.Precision)
+	return math.Round(v*scale) / scale
+}
+
+// statUnits is DBD's unit registry
+var statUnits = map[string]StatUnit{
+	"DBD_GeneratorPct_float": {Unit: "generators_equivalent", Precision: 1},
+	"DBD_HealPct_float":      {Unit: "survivors_healed_equivalent", Precision: 1},
+}
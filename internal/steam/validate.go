@@ -0,0 +1,92 @@
+package steam
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+const (
+	// maxResponseBytes caps how much of a Steam response is read. The largest
+	// real payload (the game schema) is well under 2 MiB.
+	maxResponseBytes = 16 << 20
+	// maxStatValue is far above any real counter (bloodpoints included) while
+	// still exact in a float64 and safe to convert to int
+	maxStatValue = 1e12
+	// maxStatNameLength bounds stat names echoed into responses and logs
+	maxStatNameLength = 128
+)
+
+// readResponseBody reads a Steam response body, failing rather than buffering
+// an unbounded one
+func readResponseBody(r io.Reader) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, maxResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxResponseBytes {
+		return nil, fmt.Errorf("response exceeds %d bytes", maxResponseBytes)
+	}
+	return body, nil
+}
+
+// sanitizeStatValue bounds a raw stat value. Every DBD stat is a counter,
+// grade code or percentage, so negatives clamp to 0 and absurd magnitudes to
+// maxStatValue; ok is false for NaN and infinities, which carry no value.
+func sanitizeStatValue(v float64) (_ float64, ok bool) {
+	switch {
+	case math.IsNaN(v) || math.IsInf(v, 0):
+		return 0, false
+	case v < 0:
+		return 0, true
+	case v > maxStatValue:
+		return maxStatValue, true
+	}
+	return v, true
+}
+
+// sanitizeStats bounds every value and drops unnamed, overlong or valueless
+// entries, so a corrupt payload can't reach formatting or grade decoding. It
+// returns the input slice when nothing needed fixing.
+func sanitizeStats(stats []SteamStat) []SteamStat {
+	dirty := false
+	for _, stat := range stats {
+		if value, ok := sanitizeStatValue(stat.Value); !ok || value != stat.Value || !validStatName(stat.Name) {
+			dirty = true
+			break
+		}
+	}
+	if !dirty {
+		return stats
+	}
+
+	clean := make([]SteamStat, 0, len(stats))
+	for _, stat := range stats {
+		value, ok := sanitizeStatValue(stat.Value)
+		if !ok || !validStatName(stat.Name) {
+			continue
+		}
+		clean = append(clean, SteamStat{Name: stat.Name, Value: value})
+	}
+	log.Warn("Sanitized malformed Steam stats",
+		"received", len(stats),
+		"kept", len(clean))
+	return clean
+}
+
+func validStatName(name string) bool {
+	return name != "" && len(name) <= maxStatNameLength
+}
+
+// clampPercent bounds a global achievement percentage to 0-100
+func clampPercent(p float64) float64 {
+	switch {
+	case math.IsNaN(p) || p < 0:
+		return 0
+	case p > 100:
+		return 100
+	}
+	return p
+}
@@ -0,0 +1,137 @@
+package steam
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+const fuzzSteamID = "76561198000000042"
+
+// userStatsSeeds are GetUserStatsForGame bodies covering the shapes a corrupt
+// or hostile response takes; the recorded golden payload is added alongside
+func userStatsSeeds(tb testing.TB) [][]byte {
+	seeds := [][]byte{
+		[]byte(`{"playerstats":{"steamID":"76561198000000042","stats":[{"name":"DBD_KilledCampers","value":12}]}}`),
+		[]byte(`{"playerstats":{"stats":[{"name":"DBD_Escape","value":-5},{"name":"DBD_BloodwebPoints","value":1e300}]}}`),
+		[]byte(`{"playerstats":{"stats":[{"name":"","value":1},{"name":"DBD_SlasherTierIncrement","value":4.7}]}}`),
+		[]byte(`{"playerstats":{"stats":[{"name":"DBD_UnlockRanking","value":"12"}]}}`),
+		[]byte(`{"playerstats":{"stats":null}}`),
+		[]byte(`{"playerstats":[]}`),
+		[]byte(`{}`),
+		[]byte(`[`),
+		[]byte(``),
+	}
+	recorded, err := os.ReadFile(filepath.Join("testdata", "golden", "full_profile", "user_stats.json"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return append(seeds, recorded)
+}
+
+// fuzzServer serves whatever body the current fuzz input holds, for every endpoint
+func fuzzServer(tb testing.TB) *atomic.Pointer[[]byte] {
+	tb.Helper()
+	var body atomic.Pointer[[]byte]
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if b := body.Load(); b != nil {
+			w.Write(*b)
+		}
+	}))
+	tb.Cleanup(server.Close)
+
+	// Decode failures must not be retried with backoff, or each input takes seconds
+	tb.Setenv("STEAM_API_KEY", "fuzz")
+	tb.Setenv("STEAM_API_BASE_URLS", server.URL)
+	tb.Setenv("STEAM_MAX_RETRIES", "0")
+	tb.Setenv("SCHEMA_STORE_DIR", "")
+	tb.Setenv("LOG_LEVEL", "error")
+	log.Initialize()
+	return &body
+}
+
+// checkStats requires every stat to have passed sanitizeStats
+func checkStats(t *testing.T, stats []SteamStat) {
+	t.Helper()
+	for _, stat := range stats {
+		if !validStatName(stat.Name) {
+			t.Fatalf("stat name %q survived sanitizing", stat.Name)
+		}
+		if math.IsNaN(stat.Value) || stat.Value < 0 || stat.Value > maxStatValue {
+			t.Fatalf("stat %s value %v survived sanitizing", stat.Name, stat.Value)
+		}
+	}
+}
+
+// FuzzGetPlayerStats feeds arbitrary response bodies through makeRequest's
+// decoding and the stat sanitizer: they may fail, but never panic or hand
+// out an unbounded value
+func FuzzGetPlayerStats(f *testing.F) {
+	for _, seed := range userStatsSeeds(f) {
+		f.Add(seed)
+	}
+	body := fuzzServer(f)
+	client := NewClient()
+	f.Cleanup(client.Close)
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		body.Store(&payload)
+		stats, apiErr := client.GetPlayerStatsContext(context.Background(), fuzzSteamID)
+		if apiErr != nil {
+			return
+		}
+		checkStats(t, stats.Stats)
+		if _, err := json.Marshal(MapSteamStats(stats.Stats, fuzzSteamID, "fuzz")); err != nil {
+			t.Fatalf("mapped stats don't encode: %v", err)
+		}
+	})
+}
+
+// FuzzMapPlayerStats runs arbitrary user stats bodies through the full
+// MapPlayerStats pipeline; whatever it returns must encode as JSON, which
+// rules out NaN and infinities reaching a response
+func FuzzMapPlayerStats(f *testing.F) {
+	for _, seed := range userStatsSeeds(f) {
+		f.Add(seed)
+	}
+	body := fuzzServer(f)
+	client := NewClient()
+	f.Cleanup(client.Close)
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		body.Store(&payload)
+		response, err := MapPlayerStats(context.Background(), fuzzSteamID, nil, client)
+		if err != nil {
+			return
+		}
+		if _, err := json.Marshal(response); err != nil {
+			t.Fatalf("mapped response doesn't encode: %v", err)
+		}
+	})
+}
+
+// FuzzSanitizeStats checks the bounds on raw stat values directly, including
+// the NaN and infinities JSON can't carry but a decoder bug could produce
+func FuzzSanitizeStats(f *testing.F) {
+	f.Add("DBD_KilledCampers", 12.0, "DBD_Escape", -1.0)
+	f.Add("DBD_BloodwebPoints", math.Inf(1), "", 3.0)
+	f.Add("DBD_SlasherTierIncrement", math.NaN(), "DBD_UnlockRanking", 1e13)
+	f.Add("DBD_GeneratorPct_float", 1834.25, "DBD_CamperMaxScoreByCategory", math.Inf(-1))
+
+	f.Fuzz(func(t *testing.T, name1 string, value1 float64, name2 string, value2 float64) {
+		stats := sanitizeStats([]SteamStat{{Name: name1, Value: value1}, {Name: name2, Value: value2}})
+		checkStats(t, stats)
+		if _, err := json.Marshal(MapSteamStats(stats, fuzzSteamID, "fuzz")); err != nil {
+			t.Fatalf("mapped stats don't encode: %v", err)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package steam
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/cache"
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+// WarmupResult reports how each startup prefetch went
+type WarmupResult struct {
+	Duration time.Duration
+	Errors   map[string]string // step -> error, for steps that failed
+}
+
+// Warmup prefetches the shared, player-independent data every profile request
+// needs: global achievement percentages, the game schema and the adept map.
+// Steps run concurrently under ctx and failures are only reported, so a slow or
+// unavailable Steam leaves the lazy per-request path to retry as before.
+func (c *Client) Warmup(ctx context.Context, cacheManager cache.Cache) WarmupResult {
+	start := time.Now()
+
+	steps := map[string]func(context.Context) error{
+		"adept_map": func(ctx context.Context) error {
+			_, err := c.GetAdeptMapCached(ctx, cacheManager)
+			return err
+		},
+	}
+	// Without a cache these results would be thrown away, so only the
+	// process-memoized adept map is worth fetching
+	if cacheManager != nil {
+		steps["global_percentages"] = func(ctx context.Context) error {
+			_, err := c.GetGlobalAchievementPercentagesCached(ctx, cacheManager)
+			return err
+		}
+		steps["schema"] = func(ctx context.Context) error {
+			// A persisted copy is no substitute here; warmup reports on Steam
+			if _, apiErr := c.fetchSchemaCached(ctx, cacheManager); apiErr != nil {
+				return apiErr
+			}
+			return nil
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := make(map[string]string)
+	finished := make(map[string]bool)
+	for name, step := range steps {
+		wg.Add(1)
+		go func(name string, step func(context.Context) error) {
+			defer wg.Done()
+			err := step(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			finished[name] = true
+			if err != nil {
+				errs[name] = err.Error()
+			}
+		}(name, step)
+	}
+
+	// Steps observe ctx, so stragglers give up shortly after the deadline
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		mu.Lock()
+		for name := range steps {
+			if !finished[name] {
+				errs[name] = "warmup deadline exceeded"
+			}
+		}
+		mu.Unlock()
+	}
+
+	mu.Lock()
+	result := WarmupResult{Duration: time.Since(start), Errors: make(map[string]string, len(errs))}
+	for name, msg := range errs {
+		result.Errors[name] = msg
+	}
+	mu.Unlock()
+
+	if len(result.Errors) > 0 {
+		log.Warn("Startup warmup incomplete, affected data will load on first request",
+			"failed_steps", result.Errors,
+			"duration", result.Duration)
+	} else {
+		log.Info("Startup warmup completed",
+			"steps", len(steps),
+			"duration", result.Duration)
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrOwnerGroupLimit is returned when a client already has its maximum number of groups
+var ErrOwnerGroupLimit = errors.New("group limit reached for this client")
+
+// Group is a named set of players viewed together as a team. Anyone holding
+// the ID can read it; only the creating client can delete it.
+type Group struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Members   []string  `json:"members"` // Steam IDs in the order given
+	CreatedAt time.Time `json:"created_at"`
+	owner     string
+}
+
+// Groups is the in-memory registry of player groups
+type Groups struct {
+	mu          sync.Mutex
+	maxPerOwner int
+	groups      map[string]*Group
+	owned       map[string]int // owner -> groups
+}
+
+// NewGroups returns an empty registry allowing maxPerOwner groups per client
+// (0 for no limit)
+func NewGroups(maxPerOwner int) *Groups {
+	return &Groups{
+		maxPerOwner: maxPerOwner,
+		groups:      make(map[string]*Group),
+		owned:       make(map[string]int),
+	}
+}
+
+// Create registers a group for owner under a new random ID
+func (g *Groups) Create(owner, name string, members []string) (Group, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.maxPerOwner > 0 && g.owned[owner] >= g.maxPerOwner {
+		return Group{}, ErrOwnerGroupLimit
+	}
+
+	id := newGroupID()
+	for g.groups[id] != nil {
+		id = newGroupID()
+	}
+	group := &Group{
+		ID:        id,
+		Name:      name,
+		Members:   append([]string(nil), members...),
+		CreatedAt: time.Now().UTC(),
+		owner:     owner,
+	}
+	g.groups[id] = group
+	g.owned[owner]++
+	return group.view(), nil
+}
+
+// Get returns the group with the given ID
+func (g *Groups) Get(id string) (Group, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	group, ok := g.groups[id]
+	if !ok {
+		return Group{}, false
+	}
+	return group.view(), true
+}
+
+// List returns owner's groups, newest first
+func (g *Groups) List(owner string) []Group {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	groups := make([]Group, 0, g.owned[owner])
+	for _, group := range g.groups {
+		if group.owner == owner {
+			groups = append(groups, group.view())
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].CreatedAt.After(groups[j].CreatedAt)
+	})
+	return groups
+}
+
+// Delete removes owner's group, reporting false when owner has no group with that ID
+func (g *Groups) Delete(owner, id string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	group, ok := g.groups[id]
+	if !ok || group.owner != owner {
+		return false
+	}
+	delete(g.groups, id)
+	if g.owned[owner]--; g.owned[owner] <= 0 {
+		delete(g.owned, owner)
+	}
+	return true
+}
+
+// view copies the group so callers never share its member slice
+func (group *Group) view() Group {
+	view := *group
+	view.Members = append([]string(nil), group.Members...)
+	return view
+}
+
+func newGroupID() string {
+	return randomHex(8)
+}
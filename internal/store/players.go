@@ -0,0 +1,648 @@
+// Package store persists small amounts of service state that outlive the
+// cache, such as the directory of players the API has served.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+const (
+	defaultMaxPlayers    = 100000
+	defaultFlushInterval = 30 * time.Second
+	maxPreviousNames     = 5
+)
+
+// PlayerRecord is what we remember about a player we have served
+type PlayerRecord struct {
+	SteamID       string    `json:"steam_id"`
+	PersonaName   string    `json:"persona_name"`
+	Avatar        string    `json:"avatar,omitempty"`
+	PreviousNames []string  `json:"previous_names,omitempty"`
+	Vanity        string    `json:"vanity,omitempty"` // vanity name last resolved to this account
+	FirstSeen     time.Time `json:"first_seen"`
+	LastSeen      time.Time `json:"last_seen"`
+
+	// Replaced, never mutated, so copies handed out by Get and Search stay valid
+	PeakGrades   *models.PeakGrades    `json:"peak_grades,omitempty"`
+	LastSnapshot *models.StatsSnapshot `json:"last_snapshot,omitempty"` // counters at the last fresh fetch
+}
+
+// SearchResult is a player record with its match score (0-1)
+type SearchResult struct {
+	PlayerRecord
+	Score     float64 `json:"score"`
+	MatchedOn string  `json:"matched_on"` // the name that matched, current or previous
+}
+
+// Players is a directory of previously-seen players keyed by Steam ID. When a
+// path is configured it is loaded on start and flushed to disk periodically.
+type Players struct {
+	mu         sync.RWMutex
+	records    map[string]*PlayerRecord
+	vanities   map[string]string // normalized vanity name -> Steam ID
+	path       string
+	dirty      bool
+	maxRecords int
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewPlayers opens a player directory; an empty path keeps it in memory only
+func NewPlayers(path string) (*Players, error) {
+	p := &Players{
+		records:    make(map[string]*PlayerRecord),
+		vanities:   make(map[string]string),
+		path:       path,
+		maxRecords: defaultMaxPlayers,
+		stopCh:     make(chan struct{}),
+		doneCh:     make(chan struct{}),
+	}
+
+	if path != "" {
+		if err := p.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	go p.flushLoop()
+	return p, nil
+}
+
+// PlayersFromEnv opens the directory at PLAYER_STORE_PATH, falling back to memory on error
+func PlayersFromEnv() *Players {
+	path := os.Getenv("PLAYER_STORE_PATH")
+	p, err := NewPlayers(path)
+	if err != nil {
+		log.Error("Failed to load player store, continuing in memory",
+			"path", path,
+			"error", err)
+		p, _ = NewPlayers("")
+	}
+	return p
+}
+
+func (p *Players) load() error {
+	data, err := os.ReadFile(p.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read player store %s: %w", p.path, err)
+	}
+
+	var records []*PlayerRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return fmt.Errorf("failed to parse player store %s: %w", p.path, err)
+	}
+	for _, record := range records {
+		if record.SteamID != "" {
+			p.records[record.SteamID] = record
+			if record.Vanity != "" {
+				p.vanities[normalizeVanity(record.Vanity)] = record.SteamID
+			}
+		}
+	}
+
+	log.Info("Player store loaded", "path", p.path, "players", len(p.records))
+	return nil
+}
+
+// Record notes that a player was served, tracking persona name changes
+func (p *Players) Record(steamID, personaName, avatar string) {
+	if steamID == "" || strings.TrimSpace(personaName) == "" {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now().UTC()
+	record, ok := p.records[steamID]
+	if !ok {
+		if len(p.records) >= p.maxRecords {
+			p.evictOldestLocked()
+		}
+		p.records[steamID] = &PlayerRecord{
+			SteamID:     steamID,
+			PersonaName: personaName,
+			Avatar:      avatar,
+			FirstSeen:   now,
+			LastSeen:    now,
+		}
+		p.dirty = true
+		return
+	}
+
+	if record.PersonaName != personaName {
+		record.PreviousNames = append([]string{record.PersonaName}, record.PreviousNames...)
+		if len(record.PreviousNames) > maxPreviousNames {
+			record.PreviousNames = record.PreviousNames[:maxPreviousNames]
+		}
+		record.PersonaName = personaName
+	}
+	if avatar != "" {
+		record.Avatar = avatar
+	}
+	record.LastSeen = now
+	p.dirty = true
+}
+
+// MergeOutcome says what Merge did with a record
+type MergeOutcome int
+
+const (
+	MergeAdded MergeOutcome = iota
+	MergeUpdated
+	MergeUnchanged
+)
+
+// Merge folds an externally sourced record (e.g. an imported snapshot) into the
+// directory. The most recently seen persona name wins, other names join the
+// history, and the seen window widens to cover both records.
+func (p *Players) Merge(incoming PlayerRecord) MergeOutcome {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing, ok := p.records[incoming.SteamID]
+	if !ok {
+		if len(p.records) >= p.maxRecords {
+			p.evictOldestLocked()
+		}
+		record := incoming
+		if record.FirstSeen.IsZero() || (!record.LastSeen.IsZero() && record.LastSeen.Before(record.FirstSeen)) {
+			record.FirstSeen = record.LastSeen
+		}
+		record.Vanity = ""
+		p.records[record.SteamID] = &record
+		if incoming.Vanity != "" {
+			p.bindVanityLocked(incoming.Vanity, record.SteamID)
+		}
+		p.dirty = true
+		return MergeAdded
+	}
+
+	before := *existing
+	beforeNames := strings.Join(existing.PreviousNames, "\x00")
+
+	names := append([]string{}, existing.PreviousNames...)
+	if incoming.LastSeen.After(existing.LastSeen) {
+		if incoming.PersonaName != existing.PersonaName {
+			names = append([]string{existing.PersonaName}, names...)
+		}
+		existing.PersonaName = incoming.PersonaName
+		existing.LastSeen = incoming.LastSeen
+		if incoming.Avatar != "" {
+			existing.Avatar = incoming.Avatar
+		}
+		if incoming.Vanity != "" {
+			p.bindVanityLocked(incoming.Vanity, existing.SteamID)
+		}
+	} else if incoming.PersonaName != existing.PersonaName {
+		names = append(names, incoming.PersonaName)
+	}
+	names = append(names, incoming.PreviousNames...)
+	existing.PreviousNames = dedupeNames(names, existing.PersonaName)
+
+	if !incoming.FirstSeen.IsZero() && (existing.FirstSeen.IsZero() || incoming.FirstSeen.Before(existing.FirstSeen)) {
+		existing.FirstSeen = incoming.FirstSeen
+	}
+
+	peaksChanged := false
+	if incoming.PeakGrades != nil {
+		existing.PeakGrades, peaksChanged = mergePeakGrades(existing.PeakGrades, *incoming.PeakGrades)
+	}
+	snapshotChanged := false
+	if incoming.LastSnapshot != nil && (existing.LastSnapshot == nil || incoming.LastSnapshot.TakenAt.After(existing.LastSnapshot.TakenAt)) {
+		snapshot := *incoming.LastSnapshot
+		existing.LastSnapshot, snapshotChanged = &snapshot, true
+	}
+
+	if before.PersonaName == existing.PersonaName && before.Avatar == existing.Avatar && before.Vanity == existing.Vanity &&
+		before.FirstSeen.Equal(existing.FirstSeen) && before.LastSeen.Equal(existing.LastSeen) &&
+		beforeNames == strings.Join(existing.PreviousNames, "\x00") && !peaksChanged && !snapshotChanged {
+		return MergeUnchanged
+	}
+	p.dirty = true
+	return MergeUpdated
+}
+
+// ObserveGrades folds the grades seen in a live response into the player's
+// all-time peaks and returns them. Unknown players (Record not yet called) and
+// players never seen with a recognised grade return nil.
+func (p *Players) ObserveGrades(steamID string, observed models.PeakGrades) *models.PeakGrades {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	record, ok := p.records[steamID]
+	if !ok {
+		return nil
+	}
+	merged, changed := mergePeakGrades(record.PeakGrades, observed)
+	if changed {
+		record.PeakGrades = merged
+		p.dirty = true
+	}
+	return record.PeakGrades
+}
+
+// ObserveSnapshot stores the latest stat counters for a player and returns the
+// ones they replace, or nil for unknown players and first observations
+func (p *Players) ObserveSnapshot(steamID string, snapshot models.StatsSnapshot) *models.StatsSnapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	record, ok := p.records[steamID]
+	if !ok {
+		return nil
+	}
+	previous := record.LastSnapshot
+	record.LastSnapshot = &snapshot
+	p.dirty = true
+	return previous
+}
+
+// LastSnapshots returns a copy of every player's latest snapshot, keyed by Steam ID
+func (p *Players) LastSnapshots() map[string]models.StatsSnapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	snapshots := make(map[string]models.StatsSnapshot)
+	for steamID, record := range p.records {
+		if record.LastSnapshot != nil {
+			snapshots[steamID] = *record.LastSnapshot
+		}
+	}
+	return snapshots
+}
+
+// mergePeakGrades returns a new PeakGrades with observed folded in, or existing
+// unchanged when nothing moved
+func mergePeakGrades(existing *models.PeakGrades, observed models.PeakGrades) (*models.PeakGrades, bool) {
+	var current models.PeakGrades
+	if existing != nil {
+		current = *existing
+	}
+	killer, killerChanged := mergePeak(current.Killer, observed.Killer)
+	survivor, survivorChanged := mergePeak(current.Survivor, observed.Survivor)
+	if !killerChanged && !survivorChanged {
+		return existing, false
+	}
+	return &models.PeakGrades{Killer: killer, Survivor: survivor}, true
+}
+
+// mergePeak keeps the higher grade. At the same grade the earliest achievement
+// wins, so a player who holds Iridescent I for weeks keeps the date they first
+// reached it.
+func mergePeak(existing, observed *models.GradePeak) (*models.GradePeak, bool) {
+	switch {
+	case observed == nil:
+		return existing, false
+	case observed.Better(existing):
+		peak := *observed
+		return &peak, true
+	case existing.Rank != observed.Rank:
+		return existing, false
+	}
+
+	peak := *existing
+	if !observed.AchievedAt.IsZero() && (peak.AchievedAt.IsZero() || observed.AchievedAt.Before(peak.AchievedAt)) {
+		peak.AchievedAt = observed.AchievedAt
+	}
+	if peak == *existing {
+		return existing, false
+	}
+	return &peak, true
+}
+
+// dedupeNames keeps the first occurrence of each name, drops the current one and caps the history
+func dedupeNames(names []string, current string) []string {
+	seen := map[string]bool{current: true}
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+		if len(out) == maxPreviousNames {
+			break
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func (p *Players) evictOldestLocked() {
+	var oldestID string
+	var oldest time.Time
+	for id, record := range p.records {
+		if oldestID == "" || record.LastSeen.Before(oldest) {
+			oldestID, oldest = id, record.LastSeen
+		}
+	}
+	if vanity := p.records[oldestID].Vanity; vanity != "" {
+		delete(p.vanities, normalizeVanity(vanity))
+	}
+	delete(p.records, oldestID)
+}
+
+// normalizeVanity folds case, which Steam ignores in vanity names
+func normalizeVanity(vanity string) string {
+	return strings.ToLower(strings.TrimSpace(vanity))
+}
+
+// VanityOwner returns the player a vanity name was last bound to
+func (p *Players) VanityOwner(vanity string) (PlayerRecord, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	record, ok := p.records[p.vanities[normalizeVanity(vanity)]]
+	if !ok {
+		return PlayerRecord{}, false
+	}
+	return *record, true
+}
+
+// BindVanity records that vanity now resolves to steamID, taking it from any
+// account that held it before and returning that account's Steam ID. Only a
+// known player (Record already called) is bound; for others the old binding
+// is just released.
+func (p *Players) BindVanity(vanity, steamID string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.bindVanityLocked(vanity, steamID)
+}
+
+func (p *Players) bindVanityLocked(vanity, steamID string) string {
+	key := normalizeVanity(vanity)
+	if key == "" {
+		return ""
+	}
+
+	previous := p.vanities[key]
+	if previous == steamID {
+		return ""
+	}
+	if record, ok := p.records[previous]; ok {
+		record.Vanity = ""
+	}
+	delete(p.vanities, key)
+
+	if record, ok := p.records[steamID]; ok {
+		// An account has one vanity name, so a new one replaces its old binding
+		if record.Vanity != "" {
+			delete(p.vanities, normalizeVanity(record.Vanity))
+		}
+		record.Vanity = vanity
+		p.vanities[key] = steamID
+	}
+	p.dirty = true
+	return previous
+}
+
+// Persistent reports whether the directory is saved to disk
+func (p *Players) Persistent() bool {
+	return p.path != ""
+}
+
+// Count returns the number of known players
+func (p *Players) Count() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.records)
+}
+
+// Get returns a copy of one player's record
+func (p *Players) Get(steamID string) (PlayerRecord, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	record, ok := p.records[steamID]
+	if !ok {
+		return PlayerRecord{}, false
+	}
+	return *record, true
+}
+
+// Search fuzzy-matches persona names (current and previous) and returns one page
+// of results ordered by score then recency, plus the total number of matches.
+func (p *Players) Search(query string, limit, offset int) ([]SearchResult, int) {
+	needle := normalizeName(query)
+	if needle == "" {
+		return []SearchResult{}, 0
+	}
+
+	p.mu.RLock()
+	matches := make([]SearchResult, 0)
+	for _, record := range p.records {
+		best, matchedOn := matchScore(needle, record.PersonaName), record.PersonaName
+		for _, previous := range record.PreviousNames {
+			// Old names rank below an equally good match on the current name
+			if score := matchScore(needle, previous) * 0.9; score > best {
+				best, matchedOn = score, previous
+			}
+		}
+		if best > 0 {
+			matches = append(matches, SearchResult{PlayerRecord: *record, Score: best, MatchedOn: matchedOn})
+		}
+	}
+	p.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].LastSeen.After(matches[j].LastSeen)
+	})
+
+	total := len(matches)
+	if offset >= total {
+		return []SearchResult{}, total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return matches[offset:end], total
+}
+
+// normalizeName lowercases and drops everything but letters and digits so
+// "xX_Dwight_Xx" and "xx dwight xx" compare equal
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// matchScore ranks how well a normalized query matches a name: exact, prefix,
+// substring, then typo-tolerant edit distance and in-order subsequence matches.
+func matchScore(needle, name string) float64 {
+	hay := normalizeName(name)
+	if hay == "" {
+		return 0
+	}
+
+	switch {
+	case hay == needle:
+		return 1.0
+	case strings.HasPrefix(hay, needle):
+		return 0.9
+	case strings.Contains(hay, needle):
+		return 0.8
+	}
+
+	// Typos: compare against the best same-length window of the name
+	n := []rune(needle)
+	h := []rune(hay)
+	best := 0.0
+	if len(n) >= 3 {
+		window := len(n)
+		if window > len(h) {
+			window = len(h)
+		}
+		for start := 0; start+window <= len(h); start++ {
+			dist := levenshtein(n, h[start:start+window])
+			longest := len(n)
+			if similarity := 1 - float64(dist)/float64(longest); similarity > best {
+				best = similarity
+			}
+		}
+	}
+	if best >= 0.66 {
+		return 0.7 * best
+	}
+
+	if isSubsequence(n, h) {
+		return 0.4
+	}
+	return 0
+}
+
+func isSubsequence(needle, hay []rune) bool {
+	i := 0
+	for _, r := range hay {
+		if i < len(needle) && needle[i] == r {
+			i++
+		}
+	}
+	return i == len(needle)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func (p *Players) flushLoop() {
+	defer close(p.doneCh)
+
+	ticker := time.NewTicker(defaultFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.Flush(); err != nil {
+				log.Warn("Failed to flush player store", "path", p.path, "error", err)
+			}
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
+// Flush writes the directory to disk if it changed; a no-op without a path
+func (p *Players) Flush() error {
+	if p.path == "" {
+		return nil
+	}
+
+	p.mu.Lock()
+	if !p.dirty {
+		p.mu.Unlock()
+		return nil
+	}
+	records := make([]PlayerRecord, 0, len(p.records))
+	for _, record := range p.records {
+		records = append(records, *record)
+	}
+	p.dirty = false
+	p.mu.Unlock()
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to encode player store: %w", err)
+	}
+
+	if err := writeFileAtomic(p.path, data); err != nil {
+		p.mu.Lock()
+		p.dirty = true // retry on the next flush
+		p.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// writeFileAtomic writes then renames so a crash never leaves a truncated file behind
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".store-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// Close stops the flush loop and writes any pending changes
+func (p *Players) Close() error {
+	p.stopOnce.Do(func() { close(p.stopCh) })
+	<-p.doneCh
+	return p.Flush()
+}
@@ -0,0 +1,319 @@
+package store
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+)
+
+var (
+	// ErrProfileNotFound is returned for unknown profile IDs
+	ErrProfileNotFound = errors.New("profile not found")
+	// ErrProfileToken is returned when the profile token doesn't match
+	ErrProfileToken = errors.New("profile token does not match")
+	// ErrLinkExpired is returned for unknown, used or expired link states
+	ErrLinkExpired = errors.New("link request expired or already used")
+	// ErrAccountLinked is returned when an account already belongs to another profile
+	ErrAccountLinked = errors.New("account is linked to another profile")
+	// ErrProfileAccountLimit is returned when a profile already has its maximum number of accounts
+	ErrProfileAccountLimit = errors.New("account limit reached for this profile")
+	// ErrAccountNotLinked is returned when unlinking an account the profile doesn't hold
+	ErrAccountNotLinked = errors.New("account is not linked to this profile")
+)
+
+// Profile is one player's set of Steam accounts, such as a main and alts.
+// Every account was linked by signing in to it through Steam. Anyone holding
+// the ID can read it; changing it takes the profile token.
+type Profile struct {
+	ID        string          `json:"id"`
+	Accounts  []LinkedAccount `json:"accounts"` // in link order
+	CreatedAt time.Time       `json:"created_at"`
+	tokenHash [sha256.Size]byte
+}
+
+// LinkedAccount is a Steam account verified as belonging to a profile
+type LinkedAccount struct {
+	SteamID  string    `json:"steam_id"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+// pendingLink is a Steam sign-in under way; profileID is empty when it will
+// create a profile or recover one
+type pendingLink struct {
+	profileID string
+	expires   time.Time
+}
+
+// storedProfile is a profile as written to disk, with its token hash
+type storedProfile struct {
+	Profile
+	TokenHash string `json:"token_hash"`
+}
+
+// Profiles is the registry of profiles and the sign-ins linking accounts to
+// them. When a path is configured profiles are loaded on start and rewritten
+// after every change; links are rare, so there is nothing to batch. Sign-ins
+// under way are never persisted.
+type Profiles struct {
+	mu          sync.Mutex
+	path        string
+	maxAccounts int
+	linkTTL     time.Duration
+	profiles    map[string]*Profile
+	byAccount   map[string]string // steam ID -> profile ID
+	pending     map[string]pendingLink
+}
+
+// NewProfiles opens a registry allowing maxAccounts accounts per profile;
+// sign-ins must complete within linkTTL. An empty path keeps it in memory
+// only.
+func NewProfiles(path string, maxAccounts int, linkTTL time.Duration) (*Profiles, error) {
+	p := &Profiles{
+		path:        path,
+		maxAccounts: maxAccounts,
+		linkTTL:     linkTTL,
+		profiles:    make(map[string]*Profile),
+		byAccount:   make(map[string]string),
+		pending:     make(map[string]pendingLink),
+	}
+	if path != "" {
+		if err := p.load(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// ProfilesFromEnv opens PROFILE_STORE_PATH, falling back to memory on error
+func ProfilesFromEnv(maxAccounts int, linkTTL time.Duration) *Profiles {
+	path := os.Getenv("PROFILE_STORE_PATH")
+	p, err := NewProfiles(path, maxAccounts, linkTTL)
+	if err != nil {
+		log.Error("Failed to load profile store, continuing in memory",
+			"path", path,
+			"error", err)
+		p, _ = NewProfiles("", maxAccounts, linkTTL)
+	}
+	return p
+}
+
+func (p *Profiles) load() error {
+	data, err := os.ReadFile(p.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read profile store %s: %w", p.path, err)
+	}
+
+	var stored []storedProfile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to parse profile store %s: %w", p.path, err)
+	}
+	for _, entry := range stored {
+		hash, err := hex.DecodeString(entry.TokenHash)
+		if entry.ID == "" || len(entry.Accounts) == 0 || err != nil || len(hash) != sha256.Size {
+			continue
+		}
+		profile := entry.Profile
+		copy(profile.tokenHash[:], hash)
+		p.profiles[profile.ID] = &profile
+		for _, account := range profile.Accounts {
+			p.byAccount[account.SteamID] = profile.ID
+		}
+	}
+
+	log.Info("Profile store loaded", "path", p.path, "profiles", len(p.profiles))
+	return nil
+}
+
+// saveLocked rewrites the store after a change. A failed write is logged
+// rather than failing the change, which already took effect in memory.
+func (p *Profiles) saveLocked() {
+	if p.path == "" {
+		return
+	}
+	stored := make([]storedProfile, 0, len(p.profiles))
+	for _, profile := range p.profiles {
+		stored = append(stored, storedProfile{Profile: *profile, TokenHash: hex.EncodeToString(profile.tokenHash[:])})
+	}
+	data, err := json.Marshal(stored)
+	if err == nil {
+		err = writeFileAtomic(p.path, data)
+	}
+	if err != nil {
+		log.Error("Failed to save profile store", "path", p.path, "error", err)
+	}
+}
+
+// Persistent reports whether profiles survive a restart
+func (p *Profiles) Persistent() bool {
+	return p.path != ""
+}
+
+// BeginLink starts a sign-in that will link an account to profileID, checked
+// against token, or to a new profile when profileID is empty. It returns the
+// single-use state that CompleteLink takes and when it expires.
+func (p *Profiles) BeginLink(profileID, token string) (string, time.Time, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if profileID != "" {
+		if _, err := p.authorizeLocked(profileID, token); err != nil {
+			return "", time.Time{}, err
+		}
+	}
+
+	now := time.Now()
+	for state, link := range p.pending {
+		if now.After(link.expires) {
+			delete(p.pending, state)
+		}
+	}
+	state := randomHex(16)
+	expires := now.Add(p.linkTTL)
+	p.pending[state] = pendingLink{profileID: profileID, expires: expires}
+	return state, expires, nil
+}
+
+// CompleteLink consumes state once Steam has verified steamID. Linking to an
+// existing profile adds the account; otherwise the account's profile is
+// created, or recovered if the account is already linked. A new token is
+// returned for created and recovered profiles and is empty otherwise; only
+// its hash is kept.
+func (p *Profiles) CompleteLink(state, steamID string) (Profile, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	link, ok := p.pending[state]
+	delete(p.pending, state)
+	if !ok || time.Now().After(link.expires) {
+		return Profile{}, "", ErrLinkExpired
+	}
+
+	current := p.byAccount[steamID]
+	if link.profileID == "" {
+		if current != "" {
+			// Signing in to any linked account proves ownership of the profile
+			profile := p.profiles[current]
+			token := p.issueTokenLocked(profile)
+			p.saveLocked()
+			return profile.view(), token, nil
+		}
+		id := randomHex(8)
+		for p.profiles[id] != nil {
+			id = randomHex(8)
+		}
+		now := time.Now().UTC()
+		profile := &Profile{
+			ID:        id,
+			Accounts:  []LinkedAccount{{SteamID: steamID, LinkedAt: now}},
+			CreatedAt: now,
+		}
+		token := p.issueTokenLocked(profile)
+		p.profiles[id] = profile
+		p.byAccount[steamID] = id
+		p.saveLocked()
+		return profile.view(), token, nil
+	}
+
+	profile, ok := p.profiles[link.profileID]
+	if !ok {
+		return Profile{}, "", ErrProfileNotFound // deleted while the sign-in was under way
+	}
+	switch {
+	case current == profile.ID:
+		return profile.view(), "", nil
+	case current != "":
+		return Profile{}, "", ErrAccountLinked
+	case p.maxAccounts > 0 && len(profile.Accounts) >= p.maxAccounts:
+		return Profile{}, "", ErrProfileAccountLimit
+	}
+	profile.Accounts = append(profile.Accounts, LinkedAccount{SteamID: steamID, LinkedAt: time.Now().UTC()})
+	p.byAccount[steamID] = profile.ID
+	p.saveLocked()
+	return profile.view(), "", nil
+}
+
+// Get returns the profile with the given ID
+func (p *Profiles) Get(id string) (Profile, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	profile, ok := p.profiles[id]
+	if !ok {
+		return Profile{}, false
+	}
+	return profile.view(), true
+}
+
+// Unlink removes steamID from the profile. Removing the last account deletes
+// the profile, reported by a false second result.
+func (p *Profiles) Unlink(profileID, token, steamID string) (Profile, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	profile, err := p.authorizeLocked(profileID, token)
+	if err != nil {
+		return Profile{}, false, err
+	}
+	if p.byAccount[steamID] != profileID {
+		return Profile{}, false, ErrAccountNotLinked
+	}
+	delete(p.byAccount, steamID)
+	for i, account := range profile.Accounts {
+		if account.SteamID == steamID {
+			profile.Accounts = append(profile.Accounts[:i], profile.Accounts[i+1:]...)
+			break
+		}
+	}
+	if len(profile.Accounts) == 0 {
+		delete(p.profiles, profileID)
+		p.saveLocked()
+		return Profile{}, false, nil
+	}
+	p.saveLocked()
+	return profile.view(), true, nil
+}
+
+func (p *Profiles) authorizeLocked(profileID, token string) (*Profile, error) {
+	profile, ok := p.profiles[profileID]
+	if !ok {
+		return nil, ErrProfileNotFound
+	}
+	sum := sha256.Sum256([]byte(token))
+	if subtle.ConstantTimeCompare(sum[:], profile.tokenHash[:]) != 1 {
+		return nil, ErrProfileToken
+	}
+	return profile, nil
+}
+
+// issueTokenLocked replaces the profile's token, invalidating the old one
+func (p *Profiles) issueTokenLocked(profile *Profile) string {
+	token := randomHex(32)
+	profile.tokenHash = sha256.Sum256([]byte(token))
+	return token
+}
+
+// view copies the profile so callers never share its account slice
+func (profile *Profile) view() Profile {
+	view := *profile
+	view.Accounts = append([]LinkedAccount(nil), profile.Accounts...)
+	view.tokenHash = [sha256.Size]byte{}
+	return view
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
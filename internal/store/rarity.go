@@ -0,0 +1,161 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+const (
+	defaultRarityHistoryDays = 120
+	rarityDateLayout         = "2006-01-02"
+)
+
+// RarityHistory keeps one snapshot of global achievement percentages per day
+// and title, so rarity can be compared over time. When a path is configured
+// it is loaded on start and rewritten after every new snapshot; at most one
+// is taken a day, so there is nothing to batch.
+type RarityHistory struct {
+	mu        sync.RWMutex
+	snapshots map[string][]models.RaritySnapshot // app ID -> snapshots, oldest first
+	path      string
+	days      int
+}
+
+// NewRarityHistory opens a rarity history keeping days of snapshots; an empty
+// path keeps it in memory only
+func NewRarityHistory(path string, days int) (*RarityHistory, error) {
+	if days <= 0 {
+		days = defaultRarityHistoryDays
+	}
+	h := &RarityHistory{
+		snapshots: make(map[string][]models.RaritySnapshot),
+		path:      path,
+		days:      days,
+	}
+	if path != "" {
+		if err := h.load(); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// RarityHistoryFromEnv opens RARITY_HISTORY_PATH keeping RARITY_HISTORY_DAYS
+// of snapshots, falling back to memory on error
+func RarityHistoryFromEnv() *RarityHistory {
+	path := os.Getenv("RARITY_HISTORY_PATH")
+	days, _ := strconv.Atoi(os.Getenv("RARITY_HISTORY_DAYS"))
+	h, err := NewRarityHistory(path, days)
+	if err != nil {
+		log.Error("Failed to load rarity history, continuing in memory",
+			"path", path,
+			"error", err)
+		h, _ = NewRarityHistory("", days)
+	}
+	return h
+}
+
+func (h *RarityHistory) load() error {
+	data, err := os.ReadFile(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read rarity history %s: %w", h.path, err)
+	}
+
+	var snapshots map[string][]models.RaritySnapshot
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return fmt.Errorf("failed to parse rarity history %s: %w", h.path, err)
+	}
+	total := 0
+	for appID, list := range snapshots {
+		sort.Slice(list, func(i, j int) bool { return list[i].Date < list[j].Date })
+		h.snapshots[appID] = list
+		total += len(list)
+	}
+
+	log.Info("Rarity history loaded", "path", h.path, "apps", len(h.snapshots), "snapshots", total)
+	return nil
+}
+
+// Has reports whether appID already has a snapshot for day
+func (h *RarityHistory) Has(appID string, day time.Time) bool {
+	date := day.UTC().Format(rarityDateLayout)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	list := h.snapshots[appID]
+	return len(list) > 0 && list[len(list)-1].Date == date
+}
+
+// Record stores percentages as appID's snapshot for day, dropping snapshots
+// older than the retention. It returns false if day was already recorded.
+func (h *RarityHistory) Record(appID string, day time.Time, percentages map[string]float64) (bool, error) {
+	date := day.UTC().Format(rarityDateLayout)
+	cutoff := day.UTC().AddDate(0, 0, -h.days).Format(rarityDateLayout)
+
+	copied := make(map[string]float64, len(percentages))
+	for name, percent := range percentages {
+		copied[name] = percent
+	}
+
+	h.mu.Lock()
+	list := h.snapshots[appID]
+	for _, snapshot := range list {
+		if snapshot.Date == date {
+			h.mu.Unlock()
+			return false, nil
+		}
+	}
+	list = append(list, models.RaritySnapshot{Date: date, Percentages: copied})
+	sort.Slice(list, func(i, j int) bool { return list[i].Date < list[j].Date })
+	kept := list[:0]
+	for _, snapshot := range list {
+		if snapshot.Date > cutoff {
+			kept = append(kept, snapshot)
+		}
+	}
+	h.snapshots[appID] = kept
+
+	var data []byte
+	var err error
+	if h.path != "" {
+		data, err = json.Marshal(h.snapshots)
+	}
+	h.mu.Unlock()
+
+	if h.path == "" {
+		return true, nil
+	}
+	if err != nil {
+		return true, fmt.Errorf("failed to encode rarity history: %w", err)
+	}
+	return true, writeFileAtomic(h.path, data)
+}
+
+// Snapshots returns appID's snapshots, oldest first. Snapshots are replaced,
+// never mutated, so the percentages maps may be read without copying.
+func (h *RarityHistory) Snapshots(appID string) []models.RaritySnapshot {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]models.RaritySnapshot(nil), h.snapshots[appID]...)
+}
+
+// Days is the retention in days
+func (h *RarityHistory) Days() int {
+	return h.days
+}
+
+// Persistent reports whether snapshots survive a restart
+func (h *RarityHistory) Persistent() bool {
+	return h.path != ""
+}
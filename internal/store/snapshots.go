@@ -0,0 +1,109 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rgonzalez12/dbd-analytics/internal/log"
+	"github.com/rgonzalez12/dbd-analytics/internal/models"
+)
+
+// Snapshots persists the last full player response, one file per player, so
+// it can be served while the cache is cold after a restart
+type Snapshots struct {
+	dir string
+}
+
+type snapshotFile struct {
+	StoredAt time.Time                          `json:"stored_at"`
+	Response models.PlayerStatsWithAchievements `json:"response"`
+}
+
+// NewSnapshots opens a snapshot directory, creating it if needed; an empty dir
+// disables persistence and every method becomes a no-op
+func NewSnapshots(dir string) (*Snapshots, error) {
+	if dir == "" {
+		return &Snapshots{}, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory %s: %w", dir, err)
+	}
+	return &Snapshots{dir: dir}, nil
+}
+
+// SnapshotsFromEnv opens SNAPSHOT_STORE_DIR, falling back to disabled on error
+func SnapshotsFromEnv() *Snapshots {
+	dir := os.Getenv("SNAPSHOT_STORE_DIR")
+	s, err := NewSnapshots(dir)
+	if err != nil {
+		log.Error("Failed to open snapshot store, continuing without it",
+			"dir", dir,
+			"error", err)
+		s, _ = NewSnapshots("")
+	}
+	return s
+}
+
+// Enabled reports whether snapshots are persisted
+func (s *Snapshots) Enabled() bool {
+	return s.dir != ""
+}
+
+// Save replaces steamID's snapshot with response
+func (s *Snapshots) Save(steamID string, response models.PlayerStatsWithAchievements) error {
+	if s.dir == "" {
+		return nil
+	}
+	data, err := json.Marshal(snapshotFile{StoredAt: time.Now().UTC(), Response: response})
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	return writeFileAtomic(s.path(steamID), data)
+}
+
+// Load returns steamID's snapshot if one exists and is no older than maxAge.
+// Expired snapshots are deleted.
+func (s *Snapshots) Load(steamID string, maxAge time.Duration) (models.PlayerStatsWithAchievements, time.Time, bool) {
+	if s.dir == "" {
+		return models.PlayerStatsWithAchievements{}, time.Time{}, false
+	}
+	path := s.path(steamID)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Warn("Failed to read player snapshot", "steam_id", steamID, "error", err)
+		}
+		return models.PlayerStatsWithAchievements{}, time.Time{}, false
+	}
+
+	var file snapshotFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		log.Warn("Discarding unreadable player snapshot", "steam_id", steamID, "error", err)
+		os.Remove(path)
+		return models.PlayerStatsWithAchievements{}, time.Time{}, false
+	}
+	if time.Since(file.StoredAt) > maxAge {
+		os.Remove(path)
+		return models.PlayerStatsWithAchievements{}, time.Time{}, false
+	}
+	return file.Response, file.StoredAt, true
+}
+
+// Delete removes steamID's snapshot
+func (s *Snapshots) Delete(steamID string) {
+	if s.dir == "" {
+		return
+	}
+	if err := os.Remove(s.path(steamID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Warn("Failed to delete player snapshot", "steam_id", steamID, "error", err)
+	}
+}
+
+// path is only ever built from validated SteamID64s, so it can't escape dir
+func (s *Snapshots) path(steamID string) string {
+	return filepath.Join(s.dir, steamID+".json")
+}
@@ -0,0 +1,255 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tracking limit errors; the API reports which one was hit
+var (
+	ErrOwnerTrackLimit  = errors.New("tracked player limit reached for this client")
+	ErrGlobalTrackLimit = errors.New("tracked player limit reached for this service")
+)
+
+// TrackedConfig bounds what the background refresher has to poll
+type TrackedConfig struct {
+	MaxPerOwner   int           // registrations per API key (or IP for anonymous clients)
+	MaxTotal      int           // distinct players across all owners
+	InactiveAfter time.Duration // untrack players whose stats haven't changed for this long
+}
+
+// TrackedPlayer is one player registered for background refreshes. Refresh
+// metadata is shared by every owner tracking the same player.
+type TrackedPlayer struct {
+	SteamID     string     `json:"steam_id"`
+	TrackedAt   time.Time  `json:"tracked_at"` // when the requesting owner registered
+	Owners      int        `json:"owners"`     // clients tracking this player
+	LastRefresh *time.Time `json:"last_refresh,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastChange  *time.Time `json:"last_change,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	Refreshes   int        `json:"refreshes"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // untracked then unless the stats change
+}
+
+type trackedEntry struct {
+	owners      map[string]time.Time // owner -> tracked at
+	lastRefresh time.Time            // last attempt, successful or not
+	lastSuccess time.Time
+	lastChange  time.Time
+	lastError   string
+	refreshes   int
+}
+
+// lastActive is when inactivity is measured from: the last stat change, or the
+// most recent registration when nothing has changed since
+func (e *trackedEntry) lastActive() time.Time {
+	active := e.lastChange
+	for _, trackedAt := range e.owners {
+		if trackedAt.After(active) {
+			active = trackedAt
+		}
+	}
+	return active
+}
+
+// Tracked is the in-memory registry of players refreshed in the background
+type Tracked struct {
+	mu      sync.Mutex
+	config  TrackedConfig
+	entries map[string]*trackedEntry
+	owned   map[string]int // owner -> registrations
+}
+
+func NewTracked(config TrackedConfig) *Tracked {
+	return &Tracked{
+		config:  config,
+		entries: make(map[string]*trackedEntry),
+		owned:   make(map[string]int),
+	}
+}
+
+// Config returns the limits the registry enforces
+func (t *Tracked) Config() TrackedConfig {
+	return t.config
+}
+
+// Track registers steamID for owner. Re-tracking an existing registration
+// refreshes its inactivity window and reports created=false.
+func (t *Tracked) Track(owner, steamID string) (TrackedPlayer, bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now().UTC()
+	entry, exists := t.entries[steamID]
+	if exists {
+		if _, owned := entry.owners[owner]; owned {
+			entry.owners[owner] = now
+			return t.viewLocked(steamID, entry, owner), false, nil
+		}
+	}
+
+	if t.config.MaxPerOwner > 0 && t.owned[owner] >= t.config.MaxPerOwner {
+		return TrackedPlayer{}, false, ErrOwnerTrackLimit
+	}
+	if !exists {
+		if t.config.MaxTotal > 0 && len(t.entries) >= t.config.MaxTotal {
+			return TrackedPlayer{}, false, ErrGlobalTrackLimit
+		}
+		entry = &trackedEntry{owners: make(map[string]time.Time)}
+		t.entries[steamID] = entry
+	}
+	entry.owners[owner] = now
+	t.owned[owner]++
+	return t.viewLocked(steamID, entry, owner), true, nil
+}
+
+// Untrack removes owner's registration, dropping the player once nobody tracks it
+func (t *Tracked) Untrack(owner, steamID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.entries[steamID]
+	if !ok {
+		return false
+	}
+	if _, owned := entry.owners[owner]; !owned {
+		return false
+	}
+	t.removeOwnerLocked(owner, steamID, entry)
+	return true
+}
+
+func (t *Tracked) removeOwnerLocked(owner, steamID string, entry *trackedEntry) {
+	delete(entry.owners, owner)
+	if t.owned[owner]--; t.owned[owner] <= 0 {
+		delete(t.owned, owner)
+	}
+	if len(entry.owners) == 0 {
+		delete(t.entries, steamID)
+	}
+}
+
+// List returns owner's registrations, most recently tracked first
+func (t *Tracked) List(owner string) []TrackedPlayer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	players := make([]TrackedPlayer, 0, t.owned[owner])
+	for steamID, entry := range t.entries {
+		if _, owned := entry.owners[owner]; owned {
+			players = append(players, t.viewLocked(steamID, entry, owner))
+		}
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].TrackedAt.After(players[j].TrackedAt)
+	})
+	return players
+}
+
+// Count returns the number of distinct tracked players
+func (t *Tracked) Count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.entries)
+}
+
+// Has reports whether anyone tracks steamID
+func (t *Tracked) Has(steamID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.entries[steamID]
+	return ok
+}
+
+// Due returns players not refreshed within interval, those whose last
+// successful refresh is oldest first, so a backlog built up while refreshes
+// were failing or held back is worked off stalest data first
+func (t *Tracked) Due(interval time.Duration) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := time.Now().Add(-interval)
+	due := make([]string, 0)
+	for steamID, entry := range t.entries {
+		if entry.lastRefresh.Before(cutoff) {
+			due = append(due, steamID)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return t.entries[due[i]].lastSuccess.Before(t.entries[due[j]].lastSuccess)
+	})
+	return due
+}
+
+// MarkRefreshed records the outcome of a background refresh
+func (t *Tracked) MarkRefreshed(steamID string, changed bool, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.entries[steamID]
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	entry.lastRefresh = now
+	entry.refreshes++
+	entry.lastError = ""
+	if err != nil {
+		entry.lastError = err.Error()
+	} else {
+		entry.lastSuccess = now
+	}
+	if changed {
+		entry.lastChange = now
+	}
+}
+
+// ExpireInactive untracks players whose stats haven't changed within
+// InactiveAfter and returns their Steam IDs
+func (t *Tracked) ExpireInactive() []string {
+	if t.config.InactiveAfter <= 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := time.Now().Add(-t.config.InactiveAfter)
+	var expired []string
+	for steamID, entry := range t.entries {
+		if entry.lastActive().After(cutoff) {
+			continue
+		}
+		for owner := range entry.owners {
+			t.removeOwnerLocked(owner, steamID, entry)
+		}
+		expired = append(expired, steamID)
+	}
+	return expired
+}
+
+func (t *Tracked) viewLocked(steamID string, entry *trackedEntry, owner string) TrackedPlayer {
+	view := TrackedPlayer{
+		SteamID:     steamID,
+		TrackedAt:   entry.owners[owner],
+		Owners:      len(entry.owners),
+		LastRefresh: timeOrNil(entry.lastRefresh),
+		LastSuccess: timeOrNil(entry.lastSuccess),
+		LastChange:  timeOrNil(entry.lastChange),
+		LastError:   entry.lastError,
+		Refreshes:   entry.refreshes,
+	}
+	if t.config.InactiveAfter > 0 {
+		view.ExpiresAt = timeOrNil(entry.lastActive().Add(t.config.InactiveAfter))
+	}
+	return view
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
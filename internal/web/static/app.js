@@ -0,0 +1,226 @@
+// Minimal explorer for the DBD Analytics API. Everything from the API is
+// rendered with textContent; display names come from Steam and are untrusted.
+'use strict';
+
+const $ = (id) => document.getElementById(id);
+
+function el(tag, text, className) {
+  const node = document.createElement(tag);
+  if (text !== undefined && text !== null) node.textContent = String(text);
+  if (className) node.className = className;
+  return node;
+}
+
+function apiHeaders() {
+  const headers = { Accept: 'application/json' };
+  const key = $('apikey').value.trim();
+  if (key) headers['X-API-Key'] = key;
+  return headers;
+}
+
+function playerPath(id, suffix, params) {
+  const query = params && params.toString() ? '?' + params.toString() : '';
+  return '/api/player/' + encodeURIComponent(id) + (suffix || '') + query;
+}
+
+function show(id, visible) {
+  $(id).hidden = !visible;
+}
+
+function showError(message) {
+  $('error').textContent = message;
+  show('error', true);
+}
+
+async function loadStatus() {
+  try {
+    const res = await fetch('/api/status', { headers: { Accept: 'application/json' } });
+    const status = await res.json();
+    const build = status.build || {};
+    $('status').textContent = 'Service ' + status.status + ' · version ' + build.version +
+      ' (' + build.commit + ') · up ' + status.uptime;
+    if (status.demo_mode) showDemoPlayers(status.demo_players || []);
+  } catch (err) {
+    $('status').textContent = 'Could not reach /api/status: ' + err.message;
+  }
+}
+
+function showDemoPlayers(players) {
+  const list = $('demo-players');
+  list.replaceChildren();
+  for (const player of players) {
+    const item = el('li');
+    const link = el('a', player.display_name + ' (' + (player.vanity || player.steam_id) + ')');
+    link.href = '#/player/' + encodeURIComponent(player.steam_id);
+    item.append(link);
+    list.append(item);
+  }
+  show('demo', players.length > 0);
+}
+
+async function lookup(id) {
+  show('error', false);
+  show('result', false);
+  show('loading', true);
+
+  const params = new URLSearchParams();
+  if ($('lang').value.trim()) params.set('lang', $('lang').value.trim());
+  if ($('locale').value.trim()) params.set('locale', $('locale').value.trim());
+
+  try {
+    const res = await fetch(playerPath(id, '', params), { headers: apiHeaders() });
+    const body = await res.json().catch(() => ({}));
+    if (!res.ok) {
+      const details = body.details || {};
+      if (details.demo_players) showDemoPlayers(details.demo_players);
+      showError(res.status + ': ' + (body.message || body.error || res.statusText));
+      return;
+    }
+    render(id, body, params);
+  } catch (err) {
+    showError('Request failed: ' + err.message);
+  } finally {
+    show('loading', false);
+  }
+}
+
+function render(id, player, params) {
+  $('name').textContent = player.display_name || player.steam_id;
+  const meta = [player.steam_id];
+  if (player.resolved_as) meta.push('resolved as ' + player.resolved_as);
+  if (player.demo) meta.push('demo data');
+  if (player.cache_hit) meta.push('served from cache');
+  $('meta').textContent = meta.join(' · ');
+
+  if (player.avatar) {
+    $('avatar').src = player.avatar;
+    show('avatar', true);
+  } else {
+    show('avatar', false);
+  }
+
+  const links = $('raw-links');
+  links.replaceChildren(el('span', 'Raw JSON: '));
+  for (const [label, suffix] of [['player', ''], ['stats', '/stats'], ['roadmap', '/roadmap']]) {
+    const link = el('a', label);
+    link.href = playerPath(id, suffix, params);
+    link.target = '_blank';
+    link.rel = 'noopener';
+    links.append(link, document.createTextNode(' '));
+  }
+
+  renderSummary(player);
+  renderStats(player.stats);
+  renderAchievements(player.achievements);
+  show('result', true);
+}
+
+function renderSummary(player) {
+  const summary = (player.stats && player.stats.summary) || {};
+  const rows = [
+    ['Killer grade', summary.killer_grade],
+    ['Survivor grade', summary.survivor_grade],
+    ['Highest prestige', summary.prestige_max],
+    ['Killer pips', player.killer_pips],
+    ['Survivor pips', player.survivor_pips],
+    ['Escapes', player.escapes],
+    ['Last updated', player.last_updated],
+  ];
+  const list = $('summary');
+  list.replaceChildren();
+  for (const [label, value] of rows) {
+    if (value === undefined || value === null || value === '') continue;
+    list.append(el('dt', label), el('dd', value));
+  }
+}
+
+function renderStats(data) {
+  const container = $('stats');
+  container.replaceChildren();
+  const stats = (data && data.stats) || [];
+  if (stats.length === 0) {
+    container.append(el('p', 'No stats available.', 'muted'));
+    return;
+  }
+
+  const byCategory = new Map();
+  for (const stat of stats) {
+    const category = stat.category || 'general';
+    if (!byCategory.has(category)) byCategory.set(category, []);
+    byCategory.get(category).push(stat);
+  }
+
+  for (const [category, rows] of byCategory) {
+    const table = el('table');
+    table.append(el('caption', category + ' (' + rows.length + ')'));
+    for (const stat of rows) {
+      const tr = el('tr');
+      tr.title = stat.id;
+      tr.append(el('td', stat.display_name), el('td', stat.formatted ?? stat.value, 'value'));
+      table.append(tr);
+    }
+    container.append(table);
+  }
+}
+
+function renderAchievements(data) {
+  const list = $('achievements');
+  list.replaceChildren();
+  if (!data) {
+    $('achievement-summary').textContent = 'Achievements unavailable (the profile may be private).';
+    return;
+  }
+
+  const summary = data.summary || {};
+  $('achievement-summary').textContent = (summary.unlocked_count ?? 0) + ' of ' +
+    (summary.total_achievements ?? 0) + ' unlocked · ' +
+    (summary.adept_survivors || []).length + ' survivor and ' +
+    (summary.adept_killers || []).length + ' killer adepts';
+
+  const achievements = [...(data.mapped_achievements || [])];
+  // Unlocked first, then rarest
+  achievements.sort((a, b) => (b.unlocked - a.unlocked) || ((a.rarity || 101) - (b.rarity || 101)));
+  for (const ach of achievements) {
+    const item = el('li', undefined, ach.unlocked ? 'unlocked' : 'locked');
+    const icon = ach.unlocked ? ach.icon : (ach.icon_gray || ach.icon);
+    if (icon) {
+      const img = el('img');
+      img.src = icon;
+      img.alt = '';
+      img.loading = 'lazy';
+      item.append(img);
+    }
+    const text = el('span', ach.display_name || ach.name || ach.id);
+    text.title = ach.description || '';
+    item.append(text);
+    if (ach.rarity) item.append(el('span', ach.rarity.toFixed(1) + '%', 'muted'));
+    list.append(item);
+  }
+}
+
+function routeFromHash() {
+  const match = location.hash.match(/^#\/player\/(.+)$/);
+  if (!match) return;
+  const id = decodeURIComponent(match[1]);
+  $('player').value = id;
+  lookup(id);
+}
+
+document.addEventListener('DOMContentLoaded', () => {
+  $('apikey').value = sessionStorage.getItem('dbd-api-key') || '';
+  $('apikey').addEventListener('change', () => sessionStorage.setItem('dbd-api-key', $('apikey').value.trim()));
+
+  $('lookup').addEventListener('submit', (event) => {
+    event.preventDefault();
+    const id = $('player').value.trim();
+    if (!id) return;
+    const hash = '#/player/' + encodeURIComponent(id);
+    // Reassigning the same hash fires no event, so look up directly in that case
+    if (location.hash === hash) lookup(id);
+    else location.hash = hash;
+  });
+
+  window.addEventListener('hashchange', routeFromHash);
+  loadStatus();
+  routeFromHash();
+});
@@ -0,0 +1,57 @@
+// Package web serves a small embedded page for exploring the API, so demos and
+// deployment checks work without building the separate frontend
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+//go:embed static
+var staticFS embed.FS
+
+// contentSecurityPolicy allows only the page's own assets; images may come from
+// the Steam CDN since avatars and achievement icons link there directly
+const contentSecurityPolicy = "default-src 'self'; img-src 'self' https: data:; " +
+	"script-src 'self'; style-src 'self'; connect-src 'self'; frame-ancestors 'none'"
+
+// Register mounts the page at / and its assets under /ui/
+func Register(r *mux.Router) {
+	assets, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		panic(err) // the embed pattern guarantees the directory exists
+	}
+	index, err := fs.ReadFile(assets, "index.html")
+	if err != nil {
+		panic(err)
+	}
+
+	r.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		setHeaders(w)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(index)
+	}).Methods("GET", "HEAD")
+
+	files := http.StripPrefix("/ui/", http.FileServerFS(assets))
+	r.PathPrefix("/ui/").Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// No directory listings
+		if strings.HasSuffix(req.URL.Path, "/") {
+			http.NotFound(w, req)
+			return
+		}
+		setHeaders(w)
+		files.ServeHTTP(w, req)
+	})).Methods("GET", "HEAD")
+}
+
+func setHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	// Revalidate every load so a redeploy is picked up immediately
+	w.Header().Set("Cache-Control", "no-cache")
+}
@@ -0,0 +1,97 @@
+// Package server builds the whole dbd-analytics HTTP service, so other Go
+// programs can mount the API under their own router and middleware instead of
+// running the binary; cmd/app is a thin wrapper around it.
+//
+// Everything but Config is read from the environment exactly as the binary
+// reads it (STEAM_API_KEY, cache, stores, feature switches; see .env.example).
+// Routes keep their /api paths, so mount the handler under a stripped prefix:
+//
+//	srv, err := server.New(server.Config{})
+//	if err != nil {
+//		return err
+//	}
+//	defer srv.Close()
+//	router.Handle("/dbd/", http.StripPrefix("/dbd", srv))
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rgonzalez12/dbd-analytics/internal/api"
+	"github.com/rgonzalez12/dbd-analytics/internal/security"
+	"github.com/rgonzalez12/dbd-analytics/internal/web"
+)
+
+// Config selects what New mounts besides the API
+type Config struct {
+	// Explorer serves the embedded explorer UI at / with its assets under /ui/
+	Explorer bool
+
+	// DevCORS allows every origin, as the binary does for local development;
+	// embedding programs usually apply their own CORS policy instead
+	DevCORS bool
+}
+
+// Server is the dbd-analytics service as an http.Handler. On shutdown, call
+// Drain, wait for in-flight requests (http.Server.Shutdown), then Close.
+type Server struct {
+	router  *mux.Router
+	handler *api.Handler
+}
+
+// New validates the environment and builds the service, starting the
+// background work the API relies on (cache retry, schema refresh, tracked
+// player refreshes); Close stops it.
+func New(cfg Config) (*Server, error) {
+	if err := security.ValidateEnvironment(); err != nil {
+		return nil, fmt.Errorf("invalid environment: %w", err)
+	}
+
+	// Match on the encoded path so a percent-encoded profile URL pasted as
+	// {steamid} stays a single segment instead of splitting on its slashes
+	r := mux.NewRouter().UseEncodedPath()
+
+	if cfg.DevCORS {
+		r.Use(devCORS)
+	}
+	if cfg.Explorer {
+		web.Register(r)
+	}
+
+	handler := api.RegisterRoutes(r.PathPrefix("/api").Subrouter())
+	return &Server{router: r, handler: handler}, nil
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// Drain stops cache writes so requests still in flight during shutdown read
+// the cache without their writes failing
+func (s *Server) Drain() {
+	s.handler.Drain()
+}
+
+// Close stops background work and releases the cache and stores
+func (s *Server) Close() error {
+	return s.handler.Close()
+}
+
+// devCORS answers any origin and short-circuits preflight requests
+func devCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+		if req.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}